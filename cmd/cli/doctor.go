@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/sources"
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+// runDoctor prints what the dependency probe finds on this machine and which
+// parsers can play because of it, and returns the exit code: 1 when the Opus
+// codec is missing or a source is left with no parser that can play, 0
+// otherwise. A missing ffmpeg or yt-dlp alone is a warning; what it costs is
+// the parsers listed as disabled. It opens no audio device and needs no
+// config, so it is the first thing to run on a host that will not play.
+func runDoctor(w io.Writer, srcs []sources.Source) int {
+	report := stream.ProbeDependencies(context.Background())
+
+	fmt.Fprintln(w, "Dependencies:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	code := 0
+	for _, d := range report.Dependencies {
+		switch {
+		case d.OK():
+			fmt.Fprintf(tw, "  ok\t%s\t%s\t%s\n", d.Name, d.Version, d.Path)
+		case d.Optional:
+			fmt.Fprintf(tw, "  warn\t%s\t\t%v\n", d.Name, d.Err)
+		default:
+			fmt.Fprintf(tw, "  FAIL\t%s\t\t%v\n", d.Name, d.Err)
+			code = 1
+		}
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w, "\nParsers:")
+	keys := make([]string, 0, len(stream.Registry()))
+	for key := range stream.Registry() {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, key := range keys {
+		state := "ok"
+		if dep, off := report.Disabled[key]; off {
+			state = "disabled: needs " + dep
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", key, needs(stream.Registry()[key]), state)
+	}
+	tw.Flush()
+
+	if stranded := report.Stranded(srcs); len(stranded) > 0 {
+		fmt.Fprintf(w, "\nSources that cannot play here: %s\n", strings.Join(stranded, ", "))
+		code = 1
+	}
+	return code
+}
+
+// needs lists the binaries a parser runs, or "native" when it runs none.
+func needs(s parsers.Streamer) string {
+	caps, ok := parsers.CapabilitiesOf(s)
+	if !ok {
+		return "?"
+	}
+	var out []string
+	if caps.NeedsYtdlp {
+		out = append(out, stream.DepYtdlp)
+	}
+	if caps.NeedsFFmpeg {
+		out = append(out, stream.DepFFmpeg)
+	}
+	if len(out) == 0 {
+		return "native"
+	}
+	return strings.Join(out, "+")
+}
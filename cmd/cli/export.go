@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	"github.com/keshon/melodix/internal/storage"
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/resolve"
+	"github.com/keshon/melodix/pkg/music/sink"
+	"github.com/keshon/melodix/pkg/music/sources"
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+// errHistoryNeedsStorage is returned for a history id when the CLI runs
+// without storage (cache disabled, or the bot holds the data directory lock).
+var errHistoryNeedsStorage = errors.New("history ids need storage: enable the cache, and stop the bot if it holds the data directory")
+
+// exportTrack records one track into out, in the container its extension
+// names. It goes through the same RecoveryStream the player uses, so parser
+// fallback and the track cache apply exactly as they would for playback — but
+// not the player itself: an export must not touch the queue or what is
+// currently playing.
+//
+// input is a URL, a search query, or a playback history id written
+// "<guild-id>:<id>" — ids are per guild, and the CLI has no guild of its own.
+func exportTrack(res *resolve.Resolver, store *storage.Storage, log zerolog.Logger, input, out string, stop <-chan struct{}) error {
+	info, err := exportTarget(res, store, input)
+	if err != nil {
+		return err
+	}
+	if len(info.AvailableParsers) == 0 {
+		return fmt.Errorf("no parsers for %q", input)
+	}
+
+	fs, err := sink.NewFileSink(out)
+	if err != nil {
+		return err
+	}
+	track := &parsers.Track{
+		URL:           info.URL,
+		Title:         info.Title,
+		CurrentParser: info.AvailableParsers[0],
+		SourceInfo:    info,
+	}
+	rs := stream.NewRecoveryStreamWithLogger(track, log)
+	if err := rs.Open(0); err != nil {
+		_ = fs.Close()
+		_ = os.Remove(out)
+		return err
+	}
+	streamErr := fs.Stream(rs, stop)
+	_ = rs.Close()
+	if err := fs.Close(); err != nil && streamErr == nil {
+		streamErr = err
+	}
+	if streamErr != nil {
+		return streamErr
+	}
+	fmt.Printf("💾 %s → %s (via %s)\n", track.Title, out, rs.Parser())
+	return nil
+}
+
+// exportTarget turns the export argument into one track. A playlist URL
+// exports its first entry; recording a whole list is what --sink=file is for.
+func exportTarget(res *resolve.Resolver, store *storage.Storage, input string) (sources.TrackInfo, error) {
+	if guildID, id, ok := parseHistoryID(input); ok {
+		if store == nil {
+			return sources.TrackInfo{}, errHistoryNeedsStorage
+		}
+		row, err := store.MusicPlayback(guildID, id)
+		if err != nil {
+			return sources.TrackInfo{}, err
+		}
+		return storage.TrackInfoFromMusicPlayback(row), nil
+	}
+	tracks, err := res.Resolve(input, "", "")
+	if err != nil {
+		return sources.TrackInfo{}, err
+	}
+	if len(tracks) == 0 {
+		return sources.TrackInfo{}, fmt.Errorf("nothing found for %q", input)
+	}
+	return tracks[0], nil
+}
+
+// parseHistoryID recognises "<guild-id>:<id>" with both parts numeric, which no
+// URL or sensible search query looks like.
+func parseHistoryID(s string) (guildID string, id uint64, ok bool) {
+	guildID, rest, found := strings.Cut(s, ":")
+	if !found || guildID == "" {
+		return "", 0, false
+	}
+	if _, err := strconv.ParseUint(guildID, 10, 64); err != nil {
+		return "", 0, false
+	}
+	id, err := strconv.ParseUint(rest, 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return guildID, id, true
+}
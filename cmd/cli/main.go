@@ -5,6 +5,7 @@ import (
 	"bufio"
 	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
@@ -19,10 +20,22 @@ import (
 	"github.com/keshon/melodix/internal/storage"
 	"github.com/keshon/melodix/pkg/music/player"
 	"github.com/keshon/melodix/pkg/music/resolve"
-	"github.com/keshon/melodix/pkg/music/sink"
+	"github.com/keshon/melodix/pkg/music/stream"
 )
 
 func main() {
+	sinkSpec := flag.String("sink", string(sinkSpeaker), sinkUsage)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [doctor]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	// doctor reports on the host and exits, before anything opens a device.
+	if flag.Arg(0) == "doctor" {
+		os.Exit(runDoctor(os.Stdout, resolve.New().Sources()))
+	}
+
 	info := buildinfo.Get()
 
 	cfg, err := config.NewConfig()
@@ -34,8 +47,13 @@ func main() {
 	log := applog.Setup("cli", cfg)
 	log.Info().Str("project", info.Project).Msg("cli_starting")
 
-	provider := sink.NewSpeakerProviderWithLogger(log)
-	defer provider.Close()
+	// null and file sinks need no audio device, which is what lets the CLI run
+	// headless (CI, a server, over ssh).
+	provider, closeProvider, err := newProvider(*sinkSpec, log)
+	if err != nil {
+		log.Fatal().Err(err).Msg("sink_init_failed")
+	}
+	defer func() { _ = closeProvider() }()
 
 	res := resolve.New()
 	recoveryMode, ok := player.ParseTransportRecoveryMode(cfg.PlayerTransportRecoveryMode)
@@ -88,6 +106,10 @@ func main() {
 					if track := p.CurrentTrack(); track != nil {
 						fmt.Println("▶", track.Title)
 					}
+				case player.StatusStreamTitle:
+					if track := p.CurrentTrack(); track != nil && track.StreamTitle != "" {
+						fmt.Println("📻", track.StreamTitle)
+					}
 				case player.StatusAdded:
 					fmt.Println("🎶 Added to queue")
 				case player.StatusStopped:
@@ -106,10 +128,11 @@ func main() {
 		fmt.Println("\nShutting down...")
 		cancel()
 		_ = p.Stop(true)
+		_ = closeProvider()
 		os.Exit(0)
 	}()
 
-	fmt.Println("Commands: play <url|query> [source] [parser] | next | stop | queue | status | quit")
+	fmt.Println("Commands: play <url|query> [source] [parser] | next | stop | queue | status | why [current] | export <guild:id|url> <out.ogg> | quit")
 	scanner := bufio.NewScanner(os.Stdin)
 	for {
 		fmt.Print("> ")
@@ -171,7 +194,11 @@ func main() {
 				fmt.Println("Now playing:", cur.Title)
 			}
 			for i, t := range p.Queue() {
-				fmt.Printf("  %d. %s\n", i+1, t.Title)
+				title := t.Title
+				if t.SourceInfo.Lazy != nil {
+					title = t.SourceInfo.Lazy.Label()
+				}
+				fmt.Printf("  %d. %s\n", i+1, title)
 			}
 			if cur == nil && len(p.Queue()) == 0 {
 				fmt.Println("(empty)")
@@ -182,8 +209,32 @@ func main() {
 			} else {
 				fmt.Println("Stopped. Queue:", len(p.Queue()))
 			}
+		case "why":
+			// The last failure is what someone typing "why" after a track
+			// went silent is asking about; "why current" asks about now.
+			var t stream.Trace
+			var ok bool
+			if len(args) == 0 || args[0] != "current" {
+				t, ok = p.LastFailedTrace()
+			}
+			if !ok {
+				t, ok = p.CurrentTrace()
+			}
+			if !ok {
+				fmt.Println("Nothing has failed and nothing is playing.")
+				continue
+			}
+			fmt.Print(t.String())
+		case "export":
+			if len(args) != 2 {
+				fmt.Println("Usage: export <guild-id:history-id|url|query> <out.ogg|.opus|.webm|.wav>")
+				continue
+			}
+			if err := exportTrack(res, store, log, args[0], args[1], ctx.Done()); err != nil {
+				fmt.Println("Export error:", err)
+			}
 		default:
-			fmt.Println("Unknown command. Use: play | next | stop | queue | status | quit")
+			fmt.Println("Unknown command. Use: play | next | stop | queue | status | why | export | quit")
 		}
 	}
 	if err := scanner.Err(); err != nil {
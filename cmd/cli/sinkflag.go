@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	"github.com/keshon/melodix/pkg/music/sink"
+)
+
+// sinkKind is the part of --sink before the colon.
+type sinkKind string
+
+const (
+	sinkSpeaker sinkKind = "speaker"
+	sinkNull    sinkKind = "null"
+	sinkFile    sinkKind = "file"
+)
+
+const sinkUsage = "where audio goes: speaker, null (discard in real time), or file:<dir> (record each session to <dir> as Ogg Opus)"
+
+// newProvider builds the provider --sink names. The returned close must run on
+// exit: for the speaker it frees the audio device, for files it finishes the
+// recordings still open.
+func newProvider(spec string, log zerolog.Logger) (sink.Provider, func() error, error) {
+	kind, arg, _ := strings.Cut(spec, ":")
+	switch sinkKind(kind) {
+	case sinkSpeaker:
+		p := sink.NewSpeakerProviderWithLogger(log)
+		return p, p.Close, nil
+	case sinkNull:
+		return sink.NullProvider{}, func() error { return nil }, nil
+	case sinkFile:
+		if arg == "" {
+			return nil, nil, fmt.Errorf("--sink=file needs a directory: file:<dir>")
+		}
+		p, err := sink.NewFileProvider(arg, sink.FormatOgg, log)
+		if err != nil {
+			return nil, nil, err
+		}
+		return p, p.Close, nil
+	}
+	return nil, nil, fmt.Errorf("unknown --sink %q (want speaker, null or file:<dir>)", spec)
+}
@@ -23,9 +23,12 @@ import (
 	"github.com/keshon/melodix/internal/command/music/history"
 	"github.com/keshon/melodix/internal/command/music/next"
 	"github.com/keshon/melodix/internal/command/music/play"
+	"github.com/keshon/melodix/internal/command/music/playmessage"
 	"github.com/keshon/melodix/internal/command/music/queue"
 	"github.com/keshon/melodix/internal/command/music/search"
+	"github.com/keshon/melodix/internal/command/music/station"
 	"github.com/keshon/melodix/internal/command/music/stop"
+	"github.com/keshon/melodix/internal/command/music/why"
 
 	"github.com/keshon/melodix/internal/config"
 	"github.com/keshon/melodix/internal/discord"
@@ -138,14 +141,17 @@ func defaultMiddleware(log zerolog.Logger) []command.Middleware {
 
 func registerCommands(bot *discord.Bot, log zerolog.Logger) {
 	mw := defaultMiddleware(log)
-	cmdadapter.Register(&settings.SettingsCommand{}, mw...)
+	cmdadapter.Register(&settings.SettingsCommand{Bot: bot}, mw...)
 	cmdadapter.Register(&about.About{}, mw...)
 	cmdadapter.Register(&help.Help{}, mw...)
 	cmdadapter.Register(&maintenance.Maintenance{}, mw...)
 	cmdadapter.Register(&play.Play{Bot: bot}, mw...)
+	cmdadapter.Register(&playmessage.PlayMessage{Bot: bot}, mw...)
 	cmdadapter.Register(&search.Search{Bot: bot}, mw...)
+	cmdadapter.Register(&station.Station{Bot: bot}, mw...)
 	cmdadapter.Register(&next.Next{Bot: bot}, mw...)
 	cmdadapter.Register(&queue.Queue{Bot: bot}, mw...)
 	cmdadapter.Register(&stop.Stop{Bot: bot}, mw...)
 	cmdadapter.Register(&history.History{Bot: bot}, mw...)
+	cmdadapter.Register(&why.Why{Bot: bot}, mw...)
 }
@@ -11,13 +11,13 @@ import (
 
 // RunCmdEnable enables a command group for the guild.
 func RunCmdEnable(s *discordgo.Session, e *discordgo.InteractionCreate, stor storage.Storage, syncer cmdadapter.CommandSyncer, sub *discordgo.ApplicationCommandInteractionDataOption) error {
-	group := subOptionString(sub, "group")
+	group := SubOptionString(sub, "group")
 	return runCmdSetGroupState(s, e, stor, syncer, group, true)
 }
 
 // RunCmdDisable disables a command group for the guild.
 func RunCmdDisable(s *discordgo.Session, e *discordgo.InteractionCreate, stor storage.Storage, syncer cmdadapter.CommandSyncer, sub *discordgo.ApplicationCommandInteractionDataOption) error {
-	group := subOptionString(sub, "group")
+	group := SubOptionString(sub, "group")
 	return runCmdSetGroupState(s, e, stor, syncer, group, false)
 }
 
@@ -62,7 +62,8 @@ func runCmdSetGroupState(s *discordgo.Session, e *discordgo.InteractionCreate, s
 	return reply.RespondEmbedEphemeral(s, e, embed)
 }
 
-func subOptionString(sub *discordgo.ApplicationCommandInteractionDataOption, name string) string {
+// SubOptionString returns the named string option of a subcommand, or "".
+func SubOptionString(sub *discordgo.ApplicationCommandInteractionDataOption, name string) string {
 	for _, opt := range sub.Options {
 		if opt.Name == name {
 			return opt.StringValue()
@@ -39,6 +39,11 @@ func (c *Maintenance) SlashDefinition() *discordgo.ApplicationCommand {
 				Name:        "status",
 				Description: "Retrieve statistics about the guild",
 			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "parsers",
+				Description: "Show stream parser health and fallback demotions",
+			},
 		},
 	}
 }
@@ -69,6 +74,8 @@ func (c *Maintenance) Run(ctx interface{}) error {
 		return runDownloadDB(s, e, *storage)
 	case "status":
 		return runStatus(s, e, *storage)
+	case "parsers":
+		return runParsers(s, e)
 	default:
 		return reply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
 			Description: fmt.Sprintf("Unknown subcommand: %s", sub.Name),
@@ -0,0 +1,57 @@
+package maintenance
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/melodix/internal/discord/reply"
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+// maxParsersDescription keeps the table inside Discord's 4096-character embed
+// description, with room for the code fence and the truncation note.
+const maxParsersDescription = 3900
+
+// runParsers shows the process-wide parser health: what every guild's
+// playback has seen, and the order fallbacks are currently tried in.
+func runParsers(s *discordgo.Session, e *discordgo.InteractionCreate) error {
+	rows := stream.Health().Snapshot()
+	if len(rows) == 0 {
+		return reply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🩺 Parser Health",
+			Description: "Nothing has been played since the bot started.",
+			Color:       reply.EmbedColor,
+		})
+	}
+
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "source\tparser\topens\tfailed\tdrops\tscore\tlatency\t")
+	for _, r := range rows {
+		state := ""
+		if r.Demoted {
+			state = "demoted"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%.2f\t%s\t%s\n",
+			r.Source, r.Parser, r.Opens, r.FailedOpens, r.MidTrackFailures,
+			r.Score, r.Latency.Round(10*time.Millisecond), state)
+	}
+	tw.Flush()
+
+	table := b.String()
+	if len(table) > maxParsersDescription {
+		cut := strings.LastIndexByte(table[:maxParsersDescription], '\n')
+		table = table[:cut+1] + "…\n"
+	}
+
+	return reply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+		Title: "🩺 Parser Health",
+		Description: "```\n" + table + "```\n" +
+			"Score is the recent success rate (older outcomes count half every 30 minutes). " +
+			"Demoted parsers are tried last, with an occasional probe.",
+		Color: reply.EmbedColor,
+	})
+}
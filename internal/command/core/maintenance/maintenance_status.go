@@ -2,10 +2,14 @@ package maintenance
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/keshon/melodix/internal/discord/reply"
 	"github.com/keshon/melodix/internal/storage"
+	"github.com/keshon/melodix/pkg/music/breaker"
+	"github.com/keshon/melodix/pkg/music/stream"
 )
 
 func runStatus(s *discordgo.Session, e *discordgo.InteractionCreate, storage storage.Storage) error {
@@ -38,9 +42,63 @@ func runStatus(s *discordgo.Session, e *discordgo.InteractionCreate, storage sto
 		channelCount,
 	)
 
+	desc += dependencyNotice(stream.Dependencies())
+	desc += degradedNotice(breaker.Default().Degraded())
+
 	return reply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
 		Title:       "📊 Guild Status",
 		Description: desc,
 		Color:       reply.EmbedColor,
 	})
 }
+
+// degradedNotice lists the playback paths whose circuit breakers are open:
+// parsers or APIs that failed often enough to be skipped for now. Empty when
+// everything is closed.
+func degradedNotice(open []breaker.Status) string {
+	if len(open) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n**⚠️ Degraded mode — skipped until they recover:**\n")
+	for _, st := range open {
+		fmt.Fprintf(&b, "- `%s` %s for %s", st.Name, st.State, time.Since(st.Since).Round(time.Second))
+		if st.LastErr != nil {
+			fmt.Fprintf(&b, ": %s", truncate(st.LastErr.Error(), 120))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// dependencyNotice lists what the startup probe found: each binary or codec
+// playback relies on with its version, and the parsers taken out of the
+// fallback order for want of one. Empty when no probe has run.
+func dependencyNotice(r stream.DependencyReport, ok bool) string {
+	if !ok {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n**Dependencies:**\n")
+	for _, d := range r.Dependencies {
+		switch {
+		case d.OK():
+			fmt.Fprintf(&b, "- ✅ `%s` %s\n", d.Name, d.Version)
+		case d.Optional:
+			fmt.Fprintf(&b, "- ⚠️ `%s` missing: %s\n", d.Name, truncate(d.Err.Error(), 120))
+		default:
+			fmt.Fprintf(&b, "- ❌ `%s` missing: %s\n", d.Name, truncate(d.Err.Error(), 120))
+		}
+	}
+	if parsers := r.DisabledParsers(); len(parsers) > 0 {
+		fmt.Fprintf(&b, "Disabled parsers: `%s`\n", strings.Join(parsers, "`, `"))
+	}
+	return b.String()
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
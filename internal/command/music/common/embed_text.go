@@ -10,9 +10,15 @@ func PlaybackErrorString(s string) string {
 }
 
 // PlaybackErrorDescription formats an error for a Discord embed description (length-capped).
+// A failure with its own category (see playbackerr.Classify) is explained rather than quoted.
 func PlaybackErrorDescription(err error) string {
 	if err == nil {
 		return "Unknown error."
 	}
-	return playbackerr.String(err.Error())
+	return playbackerr.Describe(err)
+}
+
+// PlaybackErrorTitle is the embed title for err: its category's, or fallback.
+func PlaybackErrorTitle(err error, fallback string) string {
+	return playbackerr.Title(err, fallback)
 }
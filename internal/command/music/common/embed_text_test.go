@@ -1,8 +1,11 @@
 package common
 
 import (
+	"fmt"
 	"strings"
 	"testing"
+
+	"github.com/keshon/melodix/pkg/music/parsers"
 )
 
 func TestPlaybackErrorDescription_truncates(t *testing.T) {
@@ -23,6 +26,24 @@ func TestPlaybackErrorDescription_nil(t *testing.T) {
 	}
 }
 
+// A sign-in failure arrives wrapped several layers deep (player, stream,
+// parser) and is explained rather than quoted.
+func TestPlaybackErrorDescription_signInRequired(t *testing.T) {
+	err := fmt.Errorf("track failed to start: %w",
+		fmt.Errorf("all parsers failed or exceeded recovery attempts: %w",
+			fmt.Errorf("ytdlp: get url: %w: Sign in to confirm your age", parsers.ErrSignInRequired)))
+	got := PlaybackErrorDescription(err)
+	if !strings.Contains(got, "age-restricted") || strings.Contains(got, "ytdlp") {
+		t.Fatalf("description = %q, want the sign-in explanation", got)
+	}
+	if title := PlaybackErrorTitle(err, "fallback"); title == "fallback" {
+		t.Fatal("sign-in failure kept the generic title")
+	}
+	if title := PlaybackErrorTitle(errorString("boom"), "fallback"); title != "fallback" {
+		t.Fatalf("generic failure title = %q", title)
+	}
+}
+
 func TestPlaybackErrorString_empty(t *testing.T) {
 	if got := PlaybackErrorString(""); got != "" {
 		t.Fatalf("expected empty, got %q", got)
@@ -52,8 +52,9 @@ func fitTitleToLineLimit(title string, build func(string) string) string {
 }
 
 // historyLine: `id` [title](url) `tail` (spaces only; tail is backtick-wrapped date or ×N play count).
+// Only http(s) URLs are linked.
 func historyLine(id uint64, title, url, tail string) string {
-	if url != "" {
+	if isHTTPURL(url) {
 		return fmt.Sprintf("`%d` [%s](%s) `%s`", id, title, url, tail)
 	}
 	return fmt.Sprintf("`%d` %s `%s`", id, title, tail)
@@ -82,3 +83,29 @@ func FormatCountsLine(id uint64, title, url string, count int) string {
 	name = fitTitleToLineLimit(name, build)
 	return build(name)
 }
+
+// heardShown is how many of a radio row's songs the timeline lists; the row
+// keeps more, but three is enough to jog a memory of what was on.
+const heardShown = 3
+
+// FormatHeardLine renders the songs a radio session announced as a line to go
+// under its timeline row: the last heardShown, oldest first, and a count of the
+// rest. It returns "" when there are none.
+func FormatHeardLine(heard []string) string {
+	if len(heard) == 0 {
+		return ""
+	}
+	shown := heard
+	if len(shown) > heardShown {
+		shown = shown[len(shown)-heardShown:]
+	}
+	names := make([]string, 0, len(shown))
+	for _, h := range shown {
+		names = append(names, truncateTitleMiddle(displayTrackTitle(h), 40))
+	}
+	line := "↳ 📻 " + strings.Join(names, " · ")
+	if rest := len(heard) - len(shown); rest > 0 {
+		line += fmt.Sprintf(" (+%d earlier)", rest)
+	}
+	return line
+}
@@ -40,3 +40,17 @@ func TestFormatCountsLineNoDate(t *testing.T) {
 		t.Fatalf("got %q", s)
 	}
 }
+
+func TestFormatHeardLine(t *testing.T) {
+	t.Parallel()
+	if got := FormatHeardLine(nil); got != "" {
+		t.Fatalf("no songs should render nothing, got %q", got)
+	}
+	got := FormatHeardLine([]string{"A", "B", "C", "D", "E"})
+	if got != "↳ 📻 C · D · E (+2 earlier)" {
+		t.Fatalf("got %q", got)
+	}
+	if got := FormatHeardLine([]string{"Only"}); got != "↳ 📻 Only" {
+		t.Fatalf("got %q", got)
+	}
+}
@@ -0,0 +1,45 @@
+package common
+
+import (
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/melodix/pkg/music/audiotag"
+	"github.com/keshon/melodix/pkg/music/httpfile"
+)
+
+// PlayableAttachment reports whether an attachment is worth handing to the
+// direct source: a content type it plays (httpfile.ContentFormat), or an
+// audio or video type on a file whose extension it plays. The extension alone
+// does when Discord did not detect a type (it leaves some .opus and .flac
+// uploads untyped). Video counts only in a container the engine reads, so an
+// .mp4 clip's audio plays while a .mov or .mkv is left alone.
+func PlayableAttachment(a *discordgo.MessageAttachment) bool {
+	if a == nil || a.URL == "" {
+		return false
+	}
+	if _, ok := httpfile.ContentFormat(a.ContentType); ok {
+		return true
+	}
+	_, ok := audiotag.FormatOf(a.Filename)
+	ct := strings.ToLower(a.ContentType)
+	return ok && (ct == "" || strings.HasPrefix(ct, "audio/") || strings.HasPrefix(ct, "video/"))
+}
+
+// MessageLinks returns the http(s) links in a message's text, in order. Chat
+// wraps links in more than whitespace: <...> suppresses the embed, markdown
+// puts them in [text](...), and sentences end in punctuation.
+func MessageLinks(content string) []string {
+	var out []string
+	for _, f := range strings.Fields(content) {
+		if i := strings.Index(f, "]("); i >= 0 {
+			f = f[i+2:]
+		}
+		f = strings.TrimLeft(f, "<(")
+		f = strings.TrimRight(f, ">).,!?;:'\"")
+		if isHTTPURL(f) && len(f) > len("https://") {
+			out = append(out, f)
+		}
+	}
+	return out
+}
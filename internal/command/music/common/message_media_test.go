@@ -0,0 +1,62 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestPlayableAttachment(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name string
+		a    *discordgo.MessageAttachment
+		want bool
+	}{
+		{"audio type", &discordgo.MessageAttachment{URL: "u", Filename: "a.mp3", ContentType: "audio/mpeg"}, true},
+		{"video type", &discordgo.MessageAttachment{URL: "u", Filename: "clip.mp4", ContentType: "video/mp4"}, true},
+		{"untyped audio extension", &discordgo.MessageAttachment{URL: "u", Filename: "a.opus"}, true},
+		{"known type, odd name", &discordgo.MessageAttachment{URL: "u", Filename: "voice-message", ContentType: "audio/ogg"}, true},
+		// Only containers the engine reads: an audio/video type is not enough.
+		{"unplayable video", &discordgo.MessageAttachment{URL: "u", Filename: "clip.mov", ContentType: "video/quicktime"}, false},
+		{"unplayable audio", &discordgo.MessageAttachment{URL: "u", Filename: "a.mid", ContentType: "audio/midi"}, false},
+		{"image", &discordgo.MessageAttachment{URL: "u", Filename: "a.png", ContentType: "image/png"}, false},
+		// A declared type wins over a misleading name.
+		{"text named like audio", &discordgo.MessageAttachment{URL: "u", Filename: "a.mp3", ContentType: "text/plain"}, false},
+		{"no url", &discordgo.MessageAttachment{Filename: "a.mp3", ContentType: "audio/mpeg"}, false},
+		{"nil", nil, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := PlayableAttachment(tc.a); got != tc.want {
+				t.Fatalf("PlayableAttachment = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMessageLinks(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"plain", "listen https://youtu.be/x now", []string{"https://youtu.be/x"}},
+		{"suppressed embed", "<https://a.com/s.mp3>", []string{"https://a.com/s.mp3"}},
+		{"markdown", "[song](https://a.com/s.mp3)", []string{"https://a.com/s.mp3"}},
+		{"sentence end", "try https://a.com/x.", []string{"https://a.com/x"}},
+		{"several in order", "https://a.com/1 and http://b.com/2", []string{"https://a.com/1", "http://b.com/2"}},
+		{"none", "no links here, just https://", nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := MessageLinks(tc.in); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("MessageLinks(%q) = %q, want %q", tc.in, got, tc.want)
+			}
+		})
+	}
+}
@@ -45,7 +45,19 @@ func FormatQueueBody(current *parsers.Track, upcoming []parsers.Track) string {
 	}
 	lines := make([]string, 0, len(shown))
 	for i, t := range shown {
-		lines = append(lines, FormatQueueLine(i+1, t.Title, t.URL, t.Duration))
+		if lazy := t.SourceInfo.Lazy; lazy != nil {
+			// A long playlist is one row however many tracks it holds; its
+			// tracks join the list a page at a time as the queue reaches it.
+			lines = append(lines, FormatQueueLine(i+1, "📃 "+lazy.Label(), t.URL, 0))
+			continue
+		}
+		// A catalogue track links to where it was queued from, not to the
+		// YouTube video standing in for it.
+		url := t.URL
+		if o := t.SourceInfo.OriginURL; o != "" {
+			url = o
+		}
+		lines = append(lines, FormatQueueLine(i+1, t.Title, url, t.Duration))
 	}
 	b.WriteString(strings.Join(lines, "\n"))
 
@@ -62,7 +74,7 @@ func FormatQueueLine(pos int, title, url string, d time.Duration) string {
 
 // trackLabel renders a track as a link with an optional duration chip, falling
 // back to the URL when there is no title and to a placeholder when there is
-// neither. Long titles get the same middle ellipsis as history rows.
+// neither. Only an http(s) URL is linked; a library track's is an id. Long titles get the same middle ellipsis as history rows.
 func trackLabel(title, url string, d time.Duration) string {
 	name := strings.TrimSpace(title)
 	if name == "" {
@@ -77,7 +89,7 @@ func trackLabel(title, url string, d time.Duration) string {
 		tail = " `" + formatQueueDuration(d) + "`"
 	}
 	build := func(tt string) string {
-		if url == "" {
+		if !isHTTPURL(url) {
 			return tt + tail
 		}
 		return "[" + tt + "](" + url + ")" + tail
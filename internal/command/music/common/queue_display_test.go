@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/sources"
 )
 
 func TestFormatQueueLineShape(t *testing.T) {
@@ -37,6 +38,18 @@ func TestTrackLabelFallsBackToURL(t *testing.T) {
 	}
 }
 
+func TestTrackLabelDoesNotLinkLibraryTracks(t *testing.T) {
+	t.Parallel()
+	// A library track's URL is an id, and an old history row's a host path:
+	// neither is a link, and the path must not reach the channel as one.
+	for _, url := range []string{"local:3f2a9c", "/srv/music/a.flac"} {
+		s := FormatQueueLine(1, "Song", url, 0)
+		if s != "`1` Song" {
+			t.Fatalf("%s: got %q", url, s)
+		}
+	}
+}
+
 func TestFormatQueueLineHoursDuration(t *testing.T) {
 	t.Parallel()
 	s := FormatQueueLine(1, "Long", "https://x.test/a", 3602*time.Second)
@@ -93,3 +106,19 @@ func TestFormatQueueBodyTruncatesWithRemainder(t *testing.T) {
 		t.Fatalf("got %q", got)
 	}
 }
+
+func TestFormatQueueBodyLazyListIsOneRow(t *testing.T) {
+	t.Parallel()
+	lazy := sources.NewLazyList("PL1", "Big List", 2000, nil, "tok", nil)
+	upcoming := []parsers.Track{
+		{Title: "Big List", URL: "https://x.test/list", SourceInfo: sources.TrackInfo{Lazy: lazy}},
+		{Title: "After", URL: "https://x.test/b"},
+	}
+	got := FormatQueueBody(nil, upcoming)
+	if !strings.Contains(got, "`1` [📃 Big List (0/2000)](https://x.test/list)") {
+		t.Fatalf("lazy row missing progress: %q", got)
+	}
+	if !strings.Contains(got, "`2` [After]") {
+		t.Fatalf("track after the list should be row 2: %q", got)
+	}
+}
@@ -0,0 +1,34 @@
+package common
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/melodix/pkg/music/resolve"
+	"github.com/keshon/melodix/pkg/music/sources"
+)
+
+// maxChoices is Discord's limit on an option's fixed choices.
+const maxChoices = 25
+
+// SourceChoices lists r's sources as slash-command choices, by display name in
+// registration order, keeping those keep accepts (nil keeps all). A nil r
+// lists the built-in set: that is what a definition built without a bot, for
+// README generation, shows.
+func SourceChoices(r *resolve.Resolver, keep func(sources.Source) bool) []*discordgo.ApplicationCommandOptionChoice {
+	if r == nil {
+		r = resolve.New()
+	}
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, src := range r.Sources() {
+		if keep != nil && !keep(src) {
+			continue
+		}
+		if len(choices) == maxChoices {
+			break
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  sources.DisplayName(src),
+			Value: src.SourceName(),
+		})
+	}
+	return choices
+}
@@ -0,0 +1,31 @@
+package common
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/keshon/melodix/pkg/music/resolve"
+	"github.com/keshon/melodix/pkg/music/sources"
+)
+
+func TestSourceChoicesFollowRegistration(t *testing.T) {
+	choices := SourceChoices(resolve.New(), nil)
+	if len(choices) == 0 || choices[0].Value != sources.YouTube || choices[0].Name != "YouTube" {
+		t.Fatalf("first choice = %+v, want YouTube", choices[0])
+	}
+	for _, c := range choices {
+		if c.Name == c.Value {
+			t.Errorf("built-in source %q has no display name", c.Value)
+		}
+	}
+
+	query := SourceChoices(nil, sources.ResolvesQueries)
+	var names []string
+	for _, c := range query {
+		names = append(names, c.Value.(string))
+	}
+	want := []string{sources.YouTube, sources.SoundCloud, sources.Radio, sources.Local}
+	if !slices.Equal(names, want) {
+		t.Fatalf("query sources = %q, want %q", names, want)
+	}
+}
@@ -145,7 +145,11 @@ func (c *History) Run(ctx interface{}) error {
 		embedTitle = "🎵 Playback history (timeline)"
 		footerExtra = "Chronological; " + historyFooterReplay
 		for _, m := range rows {
-			lines = append(lines, common.FormatTimelineLine(m.ID, m.Title, m.URL, m.PlayedAt))
+			line := common.FormatTimelineLine(m.ID, m.Title, m.URL, m.PlayedAt)
+			if heard := common.FormatHeardLine(m.Heard); heard != "" {
+				line += "\n" + heard
+			}
+			lines = append(lines, line)
 		}
 	}
 
@@ -87,10 +87,10 @@ func (c *Next) Run(ctx interface{}) error {
 	}
 
 	_ = player.Stop(false)
-	if err = player.PlayNext(voiceState.ChannelID); err != nil {
+	if err = player.PlayNextContext(slashCtx.Context(), voiceState.ChannelID); err != nil {
 		if errors.Is(err, musicplayer.ErrTrackStartFailed) {
 			reply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
-				Title:       "🎵 Playback Error",
+				Title:       common.PlaybackErrorTitle(err, "🎵 Playback Error"),
 				Description: common.PlaybackErrorDescription(err),
 				Color:       reply.EmbedColor,
 			})
@@ -3,6 +3,7 @@ package play
 import (
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/keshon/melodix/internal/command/music/common"
@@ -11,13 +12,18 @@ import (
 	"github.com/keshon/melodix/internal/discord/cmdadapter"
 	"github.com/keshon/melodix/internal/discord/reply"
 	"github.com/keshon/melodix/internal/storage"
+	"github.com/keshon/melodix/pkg/music/resolve"
 	"github.com/keshon/melodix/pkg/music/sources"
+	"github.com/keshon/melodix/pkg/music/sources/local"
+	"github.com/keshon/melodix/pkg/music/stream"
 )
 
 type Play struct {
 	Bot discord.VoiceAPI
 }
 
+var _ cmdadapter.AutocompleteHandler = (*Play)(nil)
+
 func (c *Play) Name() string             { return "play" }
 func (c *Play) Description() string      { return "Play a music track" }
 func (c *Play) Group() string            { return "music" }
@@ -33,17 +39,17 @@ func (c *Play) SlashDefinition() *discordgo.ApplicationCommand {
 				Type:        discordgo.ApplicationCommandOptionString,
 				Name:        "input",
 				Description: "Link, search query, or history id(s)",
-				Required:    true,
+				// Not required so that an attachment alone will do; Run checks
+				// that one of the two was given.
+				// Suggests library tracks when source is Local; see Autocomplete.
+				Autocomplete: true,
 			},
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
 				Name:        "source",
 				Description: "Specify a source if search query is used",
-				Choices: []*discordgo.ApplicationCommandOptionChoice{
-					{Name: "YouTube", Value: sources.YouTube},
-					{Name: "SoundCloud", Value: sources.SoundCloud},
-					{Name: "Radio", Value: sources.Radio},
-				},
+				// Whatever the resolver has registered, built-in or not.
+				Choices: common.SourceChoices(c.resolver(), nil),
 			},
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
@@ -52,17 +58,35 @@ func (c *Play) SlashDefinition() *discordgo.ApplicationCommand {
 				Choices: []*discordgo.ApplicationCommandOptionChoice{
 					{Name: "youtube native", Value: sources.ParserYtnativeLink},
 					{Name: "soundcloud native", Value: sources.ParserScnativeLink},
+					{Name: "bandcamp native", Value: sources.ParserBcnativeLink},
 					{Name: "ytdlp pipe", Value: sources.ParserYtdlpPipe},
 					{Name: "ytdlp link", Value: sources.ParserYtdlpLink},
 					{Name: "kkdai pipe", Value: sources.ParserKkdaiPipe},
 					{Name: "kkdai link", Value: sources.ParserKkdaiLink},
 					{Name: "ffmpeg direct link", Value: sources.ParserFFmpegLink},
+					{Name: "local passthrough", Value: sources.ParserLocalPassthrough},
+					{Name: "local ffmpeg", Value: sources.ParserLocalFFmpeg},
+					{Name: "direct file passthrough", Value: sources.ParserDirectPassthrough},
+					{Name: "direct file ffmpeg", Value: sources.ParserDirectFFmpeg},
 				},
 			},
+			{
+				Type:        discordgo.ApplicationCommandOptionAttachment,
+				Name:        "attachment",
+				Description: "An audio or video file to play instead of input",
+			},
 		},
 	}
 }
 
+// resolver is the bot's, or nil when the command was built without one.
+func (c *Play) resolver() *resolve.Resolver {
+	if c.Bot == nil {
+		return nil
+	}
+	return c.Bot.Resolver()
+}
+
 func (c *Play) Run(ctx interface{}) error {
 	slashCtx, ok := ctx.(*cmdadapter.SlashInteractionContext)
 	if !ok {
@@ -73,7 +97,7 @@ func (c *Play) Run(ctx interface{}) error {
 	e := slashCtx.Event
 	store := slashCtx.Storage
 
-	var input, source, parser string
+	var input, source, parser, attachmentID string
 	for _, opt := range e.ApplicationCommandData().Options {
 		switch opt.Name {
 		case "input":
@@ -82,17 +106,46 @@ func (c *Play) Run(ctx interface{}) error {
 			source = opt.StringValue()
 		case "parser":
 			parser = opt.StringValue()
+		case "attachment":
+			// An attachment option's value is the attachment id; the file
+			// itself is in the resolved data.
+			attachmentID, _ = opt.Value.(string)
 		}
 	}
 
-	if input == "" {
+	if input == "" && attachmentID == "" {
+		return reply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Error",
+			Description: "Give a link, a search query or history id(s), or attach a file.",
+		})
+	}
+	if input != "" && attachmentID != "" {
 		return reply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
 			Title:       "🎵 Error",
-			Description: "Input is required.",
+			Description: "Give either input or an attachment, not both.",
 		})
 	}
 
-	parsed, err := common.ParsePlayInput(input)
+	var parsed common.ParsedPlayInput
+	var err error
+	if attachmentID != "" {
+		var att *discordgo.MessageAttachment
+		if res := e.ApplicationCommandData().Resolved; res != nil {
+			att = res.Attachments[attachmentID]
+		}
+		if !common.PlayableAttachment(att) {
+			return reply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+				Title:       "🎵 Error",
+				Description: "That attachment is not an audio or video file.",
+			})
+		}
+		// Attachments are always direct files, whatever source was picked;
+		// auto-detection would only spend a request finding that out.
+		parsed = common.ParsedPlayInput{Kind: common.PlayInputKindQuery, Query: att.URL}
+		source = sources.Direct
+	} else {
+		parsed, err = common.ParsePlayInput(input)
+	}
 	if err != nil {
 		if errors.Is(err, common.ErrPlayInputTooManyItems) {
 			return reply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
@@ -158,7 +211,7 @@ func (c *Play) Run(ctx interface{}) error {
 	case common.PlayInputKindURLs:
 		batch := make([]sources.TrackInfo, 0, len(parsed.URLs))
 		for _, u := range parsed.URLs {
-			tracks, resErr := c.Bot.ResolveTracks(guildID, u, source, parser)
+			tracks, resErr := c.Bot.ResolveTracksContext(slashCtx.Context(), guildID, u, source, parser)
 			if resErr != nil || len(tracks) == 0 {
 				reply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
 					Title:       "🎵 Error",
@@ -168,6 +221,9 @@ func (c *Play) Run(ctx interface{}) error {
 			}
 			batch = append(batch, tracks...)
 		}
+		if !parserPlays(s, e, parser, batch) {
+			return nil
+		}
 		if err := p.EnqueueTrackInfos(batch); err != nil {
 			playback.QueueError(s, e, err)
 			return nil
@@ -175,7 +231,7 @@ func (c *Play) Run(ctx interface{}) error {
 		added = len(batch)
 
 	case common.PlayInputKindQuery:
-		tracks, resErr := c.Bot.ResolveTracks(guildID, parsed.Query, source, parser)
+		tracks, resErr := c.Bot.ResolveTracksContext(slashCtx.Context(), guildID, parsed.Query, source, parser)
 		if resErr != nil || len(tracks) == 0 {
 			reply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
 				Title:       "🎵 Error",
@@ -183,6 +239,9 @@ func (c *Play) Run(ctx interface{}) error {
 			})
 			return nil
 		}
+		if !parserPlays(s, e, parser, tracks) {
+			return nil
+		}
 		if err := p.EnqueueTrackInfos(tracks); err != nil {
 			playback.QueueError(s, e, err)
 			return nil
@@ -190,6 +249,78 @@ func (c *Play) Run(ctx interface{}) error {
 		added = len(tracks)
 	}
 
-	playback.StartAndRender(c.Bot, s, e, slashCtx.AppLog, target, added)
+	playback.StartAndRender(slashCtx.Context(), c.Bot, s, e, slashCtx.AppLog, target, added)
 	return nil
 }
+
+// parserPlays checks the parser override, if any, against the tracks before
+// anything is queued. The override only goes first in each track's list,
+// ahead of the source's own fallbacks, so a pick that cannot work here is
+// reported and the tracks are queued anyway; only a track that none of its
+// parsers can play refuses the batch, telling the user why.
+func parserPlays(s *discordgo.Session, e *discordgo.InteractionCreate, parser string, tracks []sources.TrackInfo) bool {
+	if parser == "" {
+		return true
+	}
+	var pickErr error
+	for _, t := range tracks {
+		if err := stream.ValidateTrack(t); err != nil {
+			reply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+				Title:       "🎵 Error",
+				Description: fmt.Sprintf("Can't play with that parser or any fallback: %v", err),
+			})
+			return false
+		}
+		if pickErr == nil && slices.Contains(t.AvailableParsers, parser) {
+			pickErr = stream.ValidateParser(parser, t)
+		}
+	}
+	if pickErr != nil {
+		reply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Parser",
+			Description: fmt.Sprintf("%v. Queued anyway: the next parser that can will play it.", pickErr),
+		})
+	}
+	return true
+}
+
+// choiceLimit is Discord's cap on an autocomplete choice's name and value.
+const choiceLimit = 100
+
+// Autocomplete suggests library tracks for "input" once source is set to
+// Local. Other sources get no suggestions: a YouTube search per keystroke
+// would spend quota on prefixes nobody wants, and /search exists for that.
+// The value is "local:<id>", which the resolver routes back to the library.
+func (c *Play) Autocomplete(ac *cmdadapter.AutocompleteContext) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+	var input, source string
+	for _, opt := range ac.Event.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "input":
+			input = opt.StringValue()
+		case "source":
+			source = opt.StringValue()
+		}
+	}
+	if source != sources.Local {
+		return nil, nil
+	}
+	hits, err := local.New().Search(input, 25)
+	if err != nil {
+		return nil, err
+	}
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(hits))
+	for _, h := range hits {
+		name := h.Title
+		if h.Author != "" {
+			name = h.Author + " - " + name
+		}
+		if r := []rune(name); len(r) > choiceLimit {
+			name = string(r[:choiceLimit-1]) + "…"
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  name,
+			Value: local.IDPrefix + h.ID,
+		})
+	}
+	return choices, nil
+}
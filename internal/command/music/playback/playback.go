@@ -5,6 +5,7 @@
 package playback
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/keshon/melodix/internal/discord/perm"
 	"github.com/keshon/melodix/internal/discord/reply"
 	"github.com/keshon/melodix/pkg/music/player"
+	"github.com/keshon/melodix/pkg/music/stream"
 )
 
 // Target is a validated place to play: the guild's player plus the voice
@@ -74,11 +76,12 @@ func Join(bot discord.VoiceAPI, s *discordgo.Session, e *discordgo.InteractionCr
 //
 // The outcome is known here, so it is rendered synchronously; asynchronous
 // transitions such as auto-advance and queue end belong to the voice service's
-// status watcher instead.
-func StartAndRender(bot discord.VoiceAPI, s *discordgo.Session, e *discordgo.InteractionCreate, log zerolog.Logger, t Target, added int) {
+// status watcher instead. ctx is the command's: the wait for the first track
+// to open ends with it, the playback does not.
+func StartAndRender(ctx context.Context, bot discord.VoiceAPI, s *discordgo.Session, e *discordgo.InteractionCreate, log zerolog.Logger, t Target, added int) {
 	started := false
 	if !t.Player.IsPlaying() {
-		if err := t.Player.PlayNext(t.ChannelID); err != nil {
+		if err := t.Player.PlayNextContext(ctx, t.ChannelID); err != nil {
 			renderStartError(s, e, err)
 			return
 		}
@@ -108,10 +111,22 @@ func renderStartError(s *discordgo.Session, e *discordgo.InteractionCreate, err
 	switch {
 	case errors.Is(err, player.ErrTrackStartFailed):
 		reply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
-			Title:       "🎵 Playback Error",
+			Title:       common.PlaybackErrorTitle(err, "🎵 Playback Error"),
 			Description: common.PlaybackErrorDescription(err),
 			Color:       reply.EmbedColor,
 		})
+	case errors.Is(err, stream.ErrPlaybackStopped):
+		reply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Queue",
+			Description: "Playback was stopped before the track started.",
+			Color:       reply.EmbedColor,
+		})
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		reply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Queue",
+			Description: "The track took too long to start. It is still first in the queue.",
+			Color:       reply.EmbedColor,
+		})
 	case errors.Is(err, player.ErrNoTracksInQueue):
 		reply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
 			Title:       "🎵 Queue",
@@ -0,0 +1,145 @@
+// Package playmessage is the "Play with Melodix" message context menu: it
+// queues whatever a chat message carries — audio and video attachments first,
+// otherwise the first link that resolves.
+package playmessage
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/melodix/internal/command/music/common"
+	"github.com/keshon/melodix/internal/command/music/playback"
+	"github.com/keshon/melodix/internal/discord"
+	"github.com/keshon/melodix/internal/discord/cmdadapter"
+	"github.com/keshon/melodix/internal/discord/reply"
+	"github.com/keshon/melodix/pkg/music/sources"
+)
+
+// maxLinkAttempts bounds how many links are tried before giving up. Each miss
+// costs a resolver round trip, and a message full of unrelated links should
+// fail in seconds, not after every one has been probed.
+const maxLinkAttempts = 5
+
+// errNoAudio is what an attachment or link reports when it resolved without
+// error but to no tracks, so a skip always has a reason to show.
+var errNoAudio = errors.New("no playable audio")
+
+type PlayMessage struct {
+	Bot discord.VoiceAPI
+}
+
+func (c *PlayMessage) Name() string { return "Play with Melodix" }
+func (c *PlayMessage) Description() string {
+	return "Play a message's audio or video attachments, or its first link"
+}
+func (c *PlayMessage) Group() string            { return "music" }
+func (c *PlayMessage) Category() string         { return "🎵 Music" }
+func (c *PlayMessage) UserPermissions() []int64 { return []int64{} }
+
+func (c *PlayMessage) ContextDefinition() *discordgo.ApplicationCommand {
+	// No description: Discord rejects one on context menu commands.
+	return &discordgo.ApplicationCommand{
+		Name: c.Name(),
+		Type: discordgo.MessageApplicationCommand,
+	}
+}
+
+func (c *PlayMessage) Run(ctx interface{}) error {
+	msgCtx, ok := ctx.(*cmdadapter.MessageApplicationCommandContext)
+	if !ok {
+		return nil
+	}
+
+	s := msgCtx.Session
+	e := msgCtx.Event
+	msg := msgCtx.Target
+	if msg == nil {
+		return reply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Error",
+			Description: "Could not read that message.",
+		})
+	}
+
+	var files []*discordgo.MessageAttachment
+	for _, a := range msg.Attachments {
+		if common.PlayableAttachment(a) {
+			files = append(files, a)
+		}
+	}
+	links := common.MessageLinks(msg.Content)
+	if len(files) == 0 && len(links) == 0 {
+		return reply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Error",
+			Description: "That message has no audio, video or link to play.",
+		})
+	}
+
+	if err := s.InteractionRespond(e.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		return fmt.Errorf("failed to send deferred response: %w", err)
+	}
+
+	target, ok := playback.Join(c.Bot, s, e)
+	if !ok {
+		return nil
+	}
+
+	var batch []sources.TrackInfo
+	var skipped int
+	var lastErr error
+	if len(files) > 0 {
+		// Attachments win over links: someone who uploaded a file meant that
+		// file, and a link beside it is usually commentary. One unreadable
+		// file does not cost the others their turn.
+		for _, a := range files {
+			tracks, err := c.Bot.ResolveTracksContext(msgCtx.Context(), target.GuildID, a.URL, sources.Direct, "")
+			if err == nil && len(tracks) == 0 {
+				err = errNoAudio
+			}
+			if err != nil {
+				skipped++
+				lastErr = err
+				continue
+			}
+			batch = append(batch, tracks...)
+		}
+	} else {
+		for i, l := range links {
+			if i == maxLinkAttempts {
+				break
+			}
+			tracks, err := c.Bot.ResolveTracksContext(msgCtx.Context(), target.GuildID, l, "", "")
+			if err == nil && len(tracks) == 0 {
+				err = errNoAudio
+			}
+			if err == nil {
+				batch = tracks
+				break
+			}
+			lastErr = err
+		}
+	}
+
+	if len(batch) == 0 {
+		reply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Error",
+			Description: fmt.Sprintf("Nothing in that message could be played: %v", lastErr),
+		})
+		return nil
+	}
+	if err := target.Player.EnqueueTrackInfos(batch); err != nil {
+		playback.QueueError(s, e, err)
+		return nil
+	}
+
+	playback.StartAndRender(msgCtx.Context(), c.Bot, s, e, msgCtx.AppLog, target, len(batch))
+	if skipped > 0 {
+		reply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Skipped",
+			Description: fmt.Sprintf("%d attachment(s) could not be played: %v", skipped, lastErr),
+		})
+	}
+	return nil
+}
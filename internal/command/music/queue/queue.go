@@ -9,7 +9,7 @@ import (
 	"github.com/keshon/melodix/internal/discord"
 	"github.com/keshon/melodix/internal/discord/cmdadapter"
 	"github.com/keshon/melodix/internal/discord/reply"
-	"github.com/keshon/melodix/pkg/music/sources/youtube"
+	"github.com/keshon/melodix/pkg/music/sources"
 )
 
 type Queue struct {
@@ -68,11 +68,11 @@ func (c *Queue) Run(ctx interface{}) error {
 			noun = "track"
 		}
 		// The per-link cap is named here because this is where someone counts the
-		// tracks and wonders why a 300-track playlist became fewer. It is per
-		// link, not per queue: several playlists still stack up.
+		// entries and wonders why a 300-track playlist is a single row: past the
+		// cap a list is queued as one entry and paged in as it plays.
 		embed.Footer = &discordgo.MessageEmbedFooter{
-			Text: fmt.Sprintf("%d %s queued · up to %d per playlist link · skip with /next",
-				n, noun, youtube.MaxPlaylistItems),
+			Text: fmt.Sprintf("%d %s queued · playlists over %d tracks page in as they play · skip with /next",
+				n, noun, sources.MaxPlaylistItems),
 		}
 	}
 	reply.FollowupEmbedEphemeral(s, e, embed)
@@ -1,8 +1,10 @@
 package search
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 
@@ -12,6 +14,9 @@ import (
 	"github.com/keshon/melodix/internal/discord/cmdadapter"
 	"github.com/keshon/melodix/internal/discord/reply"
 	"github.com/keshon/melodix/pkg/music/sources"
+	"github.com/keshon/melodix/pkg/music/sources/local"
+	"github.com/keshon/melodix/pkg/music/sources/podcast"
+	"github.com/keshon/melodix/pkg/music/sources/radio"
 	"github.com/keshon/melodix/pkg/music/sources/soundcloud"
 	"github.com/keshon/melodix/pkg/music/sources/youtube"
 )
@@ -38,6 +43,9 @@ const componentPrefix = "search"
 const (
 	sourceYouTube    = "yt"
 	sourceSoundCloud = "sc"
+	sourceLocal      = "lc"
+	sourceRadio      = "rb" // Radio Browser station uuid
+	sourcePodcast    = "pc" // "<feed key>.<episode key>"; the feed URL is in storage
 
 	// customIDLimit is Discord's cap on a component id. YouTube ids are fixed
 	// at 11 characters so the budget is never close, but a source whose payload
@@ -55,13 +63,23 @@ func buttonID(source, payload string) (string, bool) {
 // quietly stop responding.
 var _ cmdadapter.ComponentInteractionHandler = (*Search)(nil)
 
+// feedLookup finds a podcast feed by its podcast.FeedKey; *storage.Storage
+// implements it.
+type feedLookup interface {
+	PodcastFeedURL(feedKey string) (string, bool)
+}
+
 // Search offers a pick-one chooser for a query instead of /play's
-// take-the-first-hit. Radio is absent on purpose: a stream has nothing to rank.
+// take-the-first-hit. Radio searches the station directory, not streams; a
+// podcast "query" is the feed link, and the chooser lists its latest episodes.
 type Search struct {
 	Bot discord.VoiceAPI
 
 	yt *youtube.Searcher
 	sc *soundcloud.Searcher
+	lc *local.Source
+	rb *radio.Directory
+	pc *podcast.Source
 }
 
 func (c *Search) Name() string             { return componentPrefix }
@@ -84,10 +102,13 @@ func (c *Search) SlashDefinition() *discordgo.ApplicationCommand {
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
 				Name:        "source",
-				Description: "Where to search (YouTube by default)",
+				Description: "Where to search (YouTube by default; a podcast search takes the feed link)",
 				Choices: []*discordgo.ApplicationCommandOptionChoice{
 					{Name: "YouTube", Value: sources.YouTube},
 					{Name: "SoundCloud", Value: sources.SoundCloud},
+					{Name: "Local library", Value: sources.Local},
+					{Name: "Radio stations", Value: sources.Radio},
+					{Name: "Podcast episodes", Value: sources.Podcast},
 				},
 			},
 		},
@@ -131,7 +152,7 @@ func (c *Search) Run(ctx interface{}) error {
 		return fmt.Errorf("failed to send deferred response: %w", err)
 	}
 
-	hits, err := searcher.Search(query, resultCount)
+	hits, err := sources.SearchContext(slashCtx.Context(), searcher, query, resultCount)
 	if err != nil {
 		reply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
 			Title:       "🔎 Search",
@@ -140,6 +161,20 @@ func (c *Search) Run(ctx interface{}) error {
 		})
 		return nil
 	}
+	if tag == sourcePodcast {
+		// A feed URL is too long for a button id, so the buttons carry its key
+		// and the URL waits in storage; without storage there is no way back.
+		if slashCtx.Storage == nil {
+			reply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+				Title:       "🎵 Error",
+				Description: "Podcast search needs storage, which is not available.",
+			})
+			return nil
+		}
+		if err := slashCtx.Storage.SavePodcastFeed(podcast.FeedKey(query), query, time.Now()); err != nil {
+			return fmt.Errorf("save podcast feed: %w", err)
+		}
+	}
 
 	lines := make([]string, 0, len(hits))
 	buttons := make([]discordgo.MessageComponent, 0, len(hits))
@@ -211,7 +246,11 @@ func (c *Search) Component(compCtx *cmdadapter.ComponentInteractionContext) erro
 		return nil
 	}
 
-	url, err := c.trackURL(source, payload)
+	var feeds feedLookup
+	if compCtx.Storage != nil {
+		feeds = compCtx.Storage
+	}
+	url, title, err := c.trackURL(compCtx.Context(), feeds, source, payload)
 	if err != nil {
 		reply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
 			Title:       "🎵 Error",
@@ -220,7 +259,17 @@ func (c *Search) Component(compCtx *cmdadapter.ComponentInteractionContext) erro
 		return nil
 	}
 
-	tracks, err := c.Bot.ResolveTracks(target.GuildID, url, "", "")
+	// A station's stream would otherwise go through auto-detection, where the
+	// direct-file probe can take a stream that sends a length for a file. An
+	// episode link is known to be a feed, so it skips the probing altogether.
+	var resolveSource string
+	switch source {
+	case sourceRadio:
+		resolveSource = sources.Radio
+	case sourcePodcast:
+		resolveSource = sources.Podcast
+	}
+	tracks, err := c.Bot.ResolveTracksContext(compCtx.Context(), target.GuildID, url, resolveSource, "")
 	if err != nil || len(tracks) == 0 {
 		reply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
 			Title:       "🎵 Error",
@@ -228,12 +277,15 @@ func (c *Search) Component(compCtx *cmdadapter.ComponentInteractionContext) erro
 		})
 		return nil
 	}
+	if tracks[0].Title == "" {
+		tracks[0].Title = title
+	}
 	if err := target.Player.EnqueueTrackInfos(tracks); err != nil {
 		playback.QueueError(s, e, err)
 		return nil
 	}
 
-	playback.StartAndRender(c.Bot, s, e, compCtx.AppLog, target, len(tracks))
+	playback.StartAndRender(compCtx.Context(), c.Bot, s, e, compCtx.AppLog, target, len(tracks))
 	return nil
 }
 
@@ -251,20 +303,48 @@ func parseButtonID(customID string) (source, payload string, ok bool) {
 }
 
 func knownSource(source string) bool {
-	return source == sourceYouTube || source == sourceSoundCloud
+	switch source {
+	case sourceYouTube, sourceSoundCloud, sourceLocal, sourceRadio, sourcePodcast:
+		return true
+	}
+	return false
 }
 
 // trackURL turns a button payload back into a resolvable page URL. YouTube ids
 // rebuild into a watch URL offline; a SoundCloud id has to be looked up, which
-// is the price of a payload that fits in a component id.
-func (c *Search) trackURL(source, payload string) (string, error) {
+// is the price of a payload that fits in a component id. A local id goes back
+// through the resolver as "local:<id>", which confines it again at play time.
+// A station uuid is looked up in the directory too, and that lookup is the
+// only place its name comes from: title is "" for every other source, whose
+// resolvers find their own. An episode is its feed, found in feeds by key, with
+// the episode key as the URL's fragment.
+func (c *Search) trackURL(ctx context.Context, feeds feedLookup, source, payload string) (url, title string, err error) {
 	switch source {
 	case sourceYouTube:
-		return youtube.VideoURL(payload), nil
+		return youtube.VideoURL(payload), "", nil
 	case sourceSoundCloud:
-		return c.soundcloud().PermalinkByID(payload)
+		url, err = c.soundcloud().PermalinkByIDContext(ctx, payload)
+		return url, "", err
+	case sourceLocal:
+		return local.IDPrefix + payload, "", nil
+	case sourceRadio:
+		st, err := c.radio().StationContext(ctx, payload)
+		if err != nil {
+			return "", "", err
+		}
+		return st.StreamURL(), strings.TrimSpace(st.Name), nil
+	case sourcePodcast:
+		feedKey, episodeKey, ok := strings.Cut(payload, ".")
+		if !ok || feeds == nil {
+			return "", "", fmt.Errorf("podcast episode %q cannot be looked up", payload)
+		}
+		feedURL, ok := feeds.PodcastFeedURL(feedKey)
+		if !ok {
+			return "", "", fmt.Errorf("the podcast feed is no longer known")
+		}
+		return podcast.EpisodeURL(feedURL, episodeKey), "", nil
 	default:
-		return "", fmt.Errorf("unknown search source %q", source)
+		return "", "", fmt.Errorf("unknown search source %q", source)
 	}
 }
 
@@ -275,6 +355,12 @@ func (c *Search) pick(wanted string) (sources.Searcher, string, error) {
 		return c.youtube(), sourceYouTube, nil
 	case sources.SoundCloud:
 		return c.soundcloud(), sourceSoundCloud, nil
+	case sources.Local:
+		return c.local(), sourceLocal, nil
+	case sources.Radio:
+		return c.radio(), sourceRadio, nil
+	case sources.Podcast:
+		return c.podcast(), sourcePodcast, nil
 	default:
 		return nil, "", fmt.Errorf("%s cannot be searched", wanted)
 	}
@@ -294,3 +380,24 @@ func (c *Search) soundcloud() *soundcloud.Searcher {
 	}
 	return c.sc
 }
+
+func (c *Search) local() *local.Source {
+	if c.lc == nil {
+		c.lc = local.New()
+	}
+	return c.lc
+}
+
+func (c *Search) radio() *radio.Directory {
+	if c.rb == nil {
+		c.rb = radio.NewDirectory()
+	}
+	return c.rb
+}
+
+func (c *Search) podcast() *podcast.Source {
+	if c.pc == nil {
+		c.pc = podcast.New()
+	}
+	return c.pc
+}
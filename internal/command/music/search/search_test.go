@@ -1,10 +1,16 @@
 package search
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"github.com/keshon/melodix/pkg/music/sources"
+	"github.com/keshon/melodix/pkg/music/sources/podcast"
+	"github.com/keshon/melodix/pkg/music/sources/radio"
 )
 
 // The button id is a wire format: ids handed out today come back from choosers
@@ -28,9 +34,9 @@ func TestButtonIDRoundTrip(t *testing.T) {
 	}
 
 	// YouTube rebuilds offline; reaching the network here would be a bug.
-	url, err := c.trackURL(source, payload)
-	if err != nil || url != "https://www.youtube.com/watch?v=K0HSD_i2DvA" {
-		t.Fatalf("url = %q, err = %v", url, err)
+	url, title, err := c.trackURL(context.Background(), nil, source, payload)
+	if err != nil || url != "https://www.youtube.com/watch?v=K0HSD_i2DvA" || title != "" {
+		t.Fatalf("url = %q, title = %q, err = %v", url, title, err)
 	}
 }
 
@@ -98,7 +104,7 @@ func TestUnknownSourceFailsClosed(t *testing.T) {
 		t.Fatal("unknown source reported as known")
 	}
 	c := &Search{}
-	if _, err := c.trackURL("bandcamp", "123"); err == nil {
+	if _, _, err := c.trackURL(context.Background(), nil, "bandcamp", "123"); err == nil {
 		t.Fatal("an unknown source must not resolve")
 	}
 }
@@ -113,6 +119,9 @@ func TestPickMapsSourceOption(t *testing.T) {
 		{"", sourceYouTube}, // default
 		{sources.YouTube, sourceYouTube},
 		{sources.SoundCloud, sourceSoundCloud},
+		{sources.Local, sourceLocal},
+		{sources.Radio, sourceRadio},
+		{sources.Podcast, sourcePodcast},
 	}
 	for _, tc := range cases {
 		got, tag, err := c.pick(tc.option)
@@ -121,16 +130,79 @@ func TestPickMapsSourceOption(t *testing.T) {
 		}
 	}
 
-	// Radio has nothing to rank, so it is not offered and must be refused.
-	if _, _, err := c.pick(sources.Radio); err == nil {
-		t.Error("radio must not be searchable")
+	// A direct link has nothing to search, so it is not offered and must be refused.
+	if _, _, err := c.pick(sources.Direct); err == nil {
+		t.Error("direct must not be searchable")
+	}
+}
+
+func TestButtonIDRoundTripsRadioStation(t *testing.T) {
+	t.Parallel()
+	const uuid = "96062a7b-0601-11e8-ae97-52543be04c81"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/json/stations/byuuid/"+uuid {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `[{"stationuuid":%q,"name":"Jazz Radio ","url":"http://x/jazz.pls","url_resolved":"http://x/jazz"}]`, uuid)
+	}))
+	t.Cleanup(srv.Close)
+	c := &Search{rb: &radio.Directory{BaseURL: srv.URL, Client: srv.Client()}}
+
+	// A station uuid is 36 characters; the id must still fit with room to spare.
+	id, ok := buttonID(sourceRadio, uuid)
+	if !ok {
+		t.Fatal("a station uuid must fit")
+	}
+	source, payload, ok := parseButtonID(id)
+	if !ok || source != sourceRadio || payload != uuid {
+		t.Fatalf("parse = %q, %q, %v", source, payload, ok)
+	}
+	url, title, err := c.trackURL(context.Background(), nil, source, payload)
+	if err != nil || url != "http://x/jazz" || title != "Jazz Radio" {
+		t.Fatalf("trackURL = %q, %q, %v; want the resolved stream and the station name", url, title, err)
+	}
+}
+
+// feedMap is a feedLookup over a map.
+type feedMap map[string]string
+
+func (m feedMap) PodcastFeedURL(key string) (string, bool) {
+	url, ok := m[key]
+	return url, ok
+}
+
+func TestButtonIDRoundTripsPodcastEpisode(t *testing.T) {
+	t.Parallel()
+	const feedURL = "https://feeds.example.com/a/very/long/path/to/the/show/feed.xml?format=audio&token=0123456789"
+	c := &Search{}
+	feedKey := podcast.FeedKey(feedURL)
+	episodeKey := podcast.Episode{GUID: "tag:example.com,2026:episode-212"}.Key()
+
+	id, ok := buttonID(sourcePodcast, feedKey+"."+episodeKey)
+	if !ok {
+		t.Fatalf("an episode id must fit, got %d chars", len(id))
+	}
+	source, payload, ok := parseButtonID(id)
+	if !ok || source != sourcePodcast {
+		t.Fatalf("parse = %q, %q, %v", source, payload, ok)
+	}
+	url, _, err := c.trackURL(context.Background(), feedMap{feedKey: feedURL}, source, payload)
+	if err != nil || url != podcast.EpisodeURL(feedURL, episodeKey) {
+		t.Fatalf("trackURL = %q, %v", url, err)
+	}
+	if _, _, err := c.trackURL(context.Background(), feedMap{}, source, payload); err == nil {
+		t.Fatal("a feed missing from storage must not resolve")
+	}
+	if _, _, err := c.trackURL(context.Background(), nil, source, payload); err == nil {
+		t.Fatal("without storage an episode must not resolve")
 	}
 }
 
 // The button tags are persisted in live choosers, so they are frozen strings.
 func TestSourceTagsAreStable(t *testing.T) {
 	t.Parallel()
-	if sourceYouTube != "yt" || sourceSoundCloud != "sc" {
+	if sourceYouTube != "yt" || sourceSoundCloud != "sc" || sourceLocal != "lc" || sourceRadio != "rb" || sourcePodcast != "pc" {
 		t.Fatal("source tags are a wire format and must not be renamed")
 	}
 }
@@ -0,0 +1,319 @@
+// Package station implements /station: radio stations a guild saved under
+// names of its own, so a favourite plays by name rather than by a stream URL
+// nobody remembers.
+package station
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/keshon/melodix/internal/command/music/common"
+	"github.com/keshon/melodix/internal/command/music/playback"
+	"github.com/keshon/melodix/internal/discord"
+	"github.com/keshon/melodix/internal/discord/cmdadapter"
+	"github.com/keshon/melodix/internal/discord/reply"
+	"github.com/keshon/melodix/internal/storage"
+	"github.com/keshon/melodix/pkg/music/sources"
+)
+
+// nameLimit keeps a favourite's name within what an autocomplete choice can show.
+const nameLimit = 100
+
+// choiceCount is Discord's cap on autocomplete choices.
+const choiceCount = 25
+
+// linesShown caps the /station list. Station links are long, and every line
+// has to fit in one embed.
+const linesShown = 20
+
+var _ cmdadapter.AutocompleteHandler = (*Station)(nil)
+
+// Station plays, saves, lists and removes the guild's favourite stations.
+type Station struct {
+	Bot discord.VoiceAPI
+}
+
+func (c *Station) Name() string             { return "station" }
+func (c *Station) Description() string      { return "Play and manage favourite radio stations" }
+func (c *Station) Group() string            { return "music" }
+func (c *Station) Category() string         { return "🎵 Music" }
+func (c *Station) UserPermissions() []int64 { return []int64{} }
+
+func (c *Station) SlashDefinition() *discordgo.ApplicationCommand {
+	nameOption := func(description string, autocomplete bool) *discordgo.ApplicationCommandOption {
+		return &discordgo.ApplicationCommandOption{
+			Type:         discordgo.ApplicationCommandOptionString,
+			Name:         "name",
+			Description:  description,
+			Required:     true,
+			MaxLength:    nameLimit,
+			Autocomplete: autocomplete,
+		}
+	}
+	return &discordgo.ApplicationCommand{
+		Name:        c.Name(),
+		Description: c.Description(),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "play",
+				Description: "Play a favourite station",
+				Options:     []*discordgo.ApplicationCommandOption{nameOption("Favourite to play", true)},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "save",
+				Description: "Save a station as a favourite",
+				Options: []*discordgo.ApplicationCommandOption{
+					nameOption("Name to play it by", false),
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "station",
+						Description: "Stream or playlist link, or a directory search (default: the station playing now)",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "remove",
+				Description: "Remove a favourite station",
+				Options:     []*discordgo.ApplicationCommandOption{nameOption("Favourite to remove", true)},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "list",
+				Description: "List the favourite stations",
+			},
+		},
+	}
+}
+
+func (c *Station) Run(ctx interface{}) error {
+	slashCtx, ok := ctx.(*cmdadapter.SlashInteractionContext)
+	if !ok {
+		return nil
+	}
+	s := slashCtx.Session
+	e := slashCtx.Event
+
+	if slashCtx.Storage == nil {
+		return reply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Error",
+			Description: "Station storage is not available.",
+		})
+	}
+	data := e.ApplicationCommandData()
+	if len(data.Options) == 0 {
+		return reply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Description: "No subcommand provided.",
+		})
+	}
+	sub := data.Options[0]
+	var name, input string
+	for _, opt := range sub.Options {
+		switch opt.Name {
+		case "name":
+			name = strings.TrimSpace(opt.StringValue())
+		case "station":
+			input = strings.TrimSpace(opt.StringValue())
+		}
+	}
+
+	switch sub.Name {
+	case "play":
+		return c.play(slashCtx, name)
+	case "save":
+		return c.save(slashCtx, name, input)
+	case "remove":
+		return c.remove(slashCtx, name)
+	case "list":
+		return c.list(slashCtx)
+	default:
+		return reply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Description: fmt.Sprintf("Unknown subcommand: %s", sub.Name),
+		})
+	}
+}
+
+func (c *Station) play(ctx *cmdadapter.SlashInteractionContext, name string) error {
+	s, e := ctx.Session, ctx.Event
+	fav, err := ctx.Storage.FavouriteStation(e.GuildID, name)
+	if err != nil {
+		return reply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "📻 Stations",
+			Description: notFound(err, name),
+		})
+	}
+
+	if err := s.InteractionRespond(e.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		return fmt.Errorf("failed to send deferred response: %w", err)
+	}
+	target, ok := playback.Join(c.Bot, s, e)
+	if !ok {
+		return nil
+	}
+
+	tracks, err := c.Bot.ResolveTracksContext(ctx.Context(), e.GuildID, fav.URL, sources.Radio, "")
+	if err != nil || len(tracks) == 0 {
+		reply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Error",
+			Description: fmt.Sprintf("Failed to resolve %s: %v", fav.Name, err),
+		})
+		return nil
+	}
+	// The guild's own name for it beats whatever the stream calls itself.
+	tracks[0].Title = fav.Name
+	if err := target.Player.EnqueueTrackInfos(tracks); err != nil {
+		playback.QueueError(s, e, err)
+		return nil
+	}
+	playback.StartAndRender(ctx.Context(), c.Bot, s, e, ctx.AppLog, target, len(tracks))
+	return nil
+}
+
+func (c *Station) save(ctx *cmdadapter.SlashInteractionContext, name, input string) error {
+	s, e := ctx.Session, ctx.Event
+	if input == "" {
+		// Saving what is on air is the common case: someone liked it enough to ask.
+		if p := c.Bot.GetOrCreatePlayer(e.GuildID); p != nil {
+			if t := p.CurrentTrack(); t != nil && t.SourceInfo.SourceName == sources.Radio {
+				input = t.URL
+			}
+		}
+		if input == "" {
+			return reply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+				Title:       "📻 Stations",
+				Description: "No station is playing. Give a stream link or a station to search for.",
+			})
+		}
+	}
+
+	// Resolving checks the link is a stream we can play, and turns a directory
+	// search into the station it found, before anything is saved.
+	if err := reply.RespondDeferredEphemeral(s, e); err != nil {
+		return fmt.Errorf("failed to send deferred response: %w", err)
+	}
+	tracks, err := c.Bot.ResolveTracksContext(ctx.Context(), e.GuildID, input, sources.Radio, "")
+	if err != nil || len(tracks) == 0 {
+		reply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Error",
+			Description: fmt.Sprintf("That is not a station I can play: %v", err),
+		})
+		return nil
+	}
+
+	replaced, err := ctx.Storage.SaveFavouriteStation(e.GuildID, name, tracks[0].URL, e.Member.User.ID, time.Now())
+	if err != nil {
+		msg := fmt.Sprintf("Could not save the station: %v", err)
+		if errors.Is(err, storage.ErrFavouriteStationLimit) {
+			msg = "This server has saved as many stations as it can. Remove one first."
+		}
+		reply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "📻 Stations",
+			Description: msg,
+		})
+		return nil
+	}
+	verb := "Saved"
+	if replaced {
+		verb = "Updated"
+	}
+	reply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+		Title:       "📻 Stations",
+		Description: fmt.Sprintf("%s **%s**. Play it with `/station play name:%s`.", verb, name, name),
+		Color:       reply.EmbedColor,
+	})
+	return nil
+}
+
+func (c *Station) remove(ctx *cmdadapter.SlashInteractionContext, name string) error {
+	s, e := ctx.Session, ctx.Event
+	desc := fmt.Sprintf("Removed **%s**.", name)
+	if err := ctx.Storage.RemoveFavouriteStation(e.GuildID, name); err != nil {
+		desc = notFound(err, name)
+	}
+	return reply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+		Title:       "📻 Stations",
+		Description: desc,
+		Color:       reply.EmbedColor,
+	})
+}
+
+func (c *Station) list(ctx *cmdadapter.SlashInteractionContext) error {
+	s, e := ctx.Session, ctx.Event
+	favs, err := ctx.Storage.ListFavouriteStations(e.GuildID)
+	if err != nil {
+		return err
+	}
+	desc := "No favourite stations yet. Save one with `/station save`."
+	if len(favs) > 0 {
+		desc = formatFavourites(favs)
+	}
+	return reply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+		Title:       "📻 Favourite stations",
+		Description: desc,
+		Color:       reply.EmbedColor,
+	})
+}
+
+// formatFavourites renders one numbered line per station, its name linking to
+// what was saved. Past linesShown the rest is a count: the autocomplete on
+// /station play finds any of them by name, which a long list would not help with.
+func formatFavourites(favs []storage.FavouriteStation) string {
+	shown := favs
+	if len(shown) > linesShown {
+		shown = shown[:linesShown]
+	}
+	lines := make([]string, 0, len(shown)+1)
+	for i, f := range shown {
+		lines = append(lines, common.FormatQueueLine(i+1, f.Name, f.URL, 0))
+	}
+	if rest := len(favs) - len(shown); rest > 0 {
+		lines = append(lines, fmt.Sprintf("\n…and %d more", rest))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func notFound(err error, name string) string {
+	if errors.Is(err, storage.ErrFavouriteStationNotFound) {
+		return fmt.Sprintf("No favourite station called %q. `/station list` shows them all.", name)
+	}
+	return fmt.Sprintf("Could not load the station: %v", err)
+}
+
+// Autocomplete suggests the guild's favourites whose name contains what has
+// been typed so far.
+func (c *Station) Autocomplete(ac *cmdadapter.AutocompleteContext) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+	if ac.Storage == nil {
+		return nil, nil
+	}
+	var typed string
+	for _, sub := range ac.Event.ApplicationCommandData().Options {
+		for _, opt := range sub.Options {
+			if opt.Name == "name" {
+				typed = strings.ToLower(strings.TrimSpace(opt.StringValue()))
+			}
+		}
+	}
+	favs, err := ac.Storage.ListFavouriteStations(ac.Event.GuildID)
+	if err != nil {
+		return nil, err
+	}
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, choiceCount)
+	for _, f := range favs {
+		if !strings.Contains(strings.ToLower(f.Name), typed) {
+			continue
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: f.Name, Value: f.Name})
+		if len(choices) == choiceCount {
+			break
+		}
+	}
+	return choices, nil
+}
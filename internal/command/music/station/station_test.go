@@ -0,0 +1,26 @@
+package station
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/keshon/melodix/internal/storage"
+)
+
+func TestFormatFavouritesCapsTheList(t *testing.T) {
+	favs := make([]storage.FavouriteStation, linesShown+3)
+	for i := range favs {
+		favs[i] = storage.FavouriteStation{Name: fmt.Sprintf("Station %d", i), URL: fmt.Sprintf("http://x/%d", i)}
+	}
+	got := formatFavourites(favs)
+	if !strings.HasPrefix(got, "`1` [Station 0](http://x/0)") {
+		t.Fatalf("first line: %q", strings.SplitN(got, "\n", 2)[0])
+	}
+	if !strings.HasSuffix(got, "\n\n…and 3 more") {
+		t.Fatalf("want the overflow counted, got tail %q", got[len(got)-30:])
+	}
+	if strings.Contains(got, "Station 20") {
+		t.Fatal("lines past the cap must not be listed")
+	}
+}
@@ -0,0 +1,113 @@
+package why
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/keshon/melodix/internal/discord"
+	"github.com/keshon/melodix/internal/discord/cmdadapter"
+	"github.com/keshon/melodix/internal/discord/reply"
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+// maxTraceDescription keeps the trace inside Discord's 4096-character embed
+// description, with room for the code fence and the truncation note.
+const maxTraceDescription = 3900
+
+type Why struct {
+	Bot discord.VoiceAPI
+}
+
+func (c *Why) Name() string             { return "why" }
+func (c *Why) Description() string      { return "Show what happened to the last track that failed" }
+func (c *Why) Group() string            { return "music" }
+func (c *Why) Category() string         { return "🎵 Music" }
+func (c *Why) UserPermissions() []int64 { return []int64{} }
+
+func (c *Why) SlashDefinition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        c.Name(),
+		Description: c.Description(),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "current",
+				Description: "Show the track playing now instead of the last failure",
+			},
+		},
+	}
+}
+
+func (c *Why) Run(ctx interface{}) error {
+	slashCtx, ok := ctx.(*cmdadapter.SlashInteractionContext)
+	if !ok {
+		return nil
+	}
+
+	s := slashCtx.Session
+	e := slashCtx.Event
+
+	if err := s.InteractionRespond(e.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		return fmt.Errorf("failed to send deferred response: %w", err)
+	}
+
+	p := c.Bot.GetOrCreatePlayer(e.GuildID)
+	if p == nil {
+		reply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🔍 Error",
+			Description: "Music service is not available.",
+		})
+		return nil
+	}
+
+	current := false
+	for _, opt := range e.ApplicationCommandData().Options {
+		if opt.Name == "current" {
+			current = opt.BoolValue()
+		}
+	}
+
+	// The last failure is what someone asks about after a track went silent;
+	// with nothing failed, the track playing now is the next best answer.
+	var t stream.Trace
+	if !current {
+		t, ok = p.LastFailedTrace()
+	}
+	if current || !ok {
+		t, ok = p.CurrentTrace()
+	}
+	if !ok {
+		reply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🔍 Why",
+			Description: "Nothing has failed recently and nothing is playing.",
+			Color:       reply.EmbedColor,
+		})
+		return nil
+	}
+
+	reply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+		Title:       "🔍 Why: " + string(t.Outcome),
+		Description: "```\n" + fitTrace(t.String()) + "```",
+		Color:       reply.EmbedColor,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Each parser tried, in order, with times from the start of the track",
+		},
+	})
+	return nil
+}
+
+// fitTrace cuts a rendered trace at a line boundary to fit the embed. The
+// outcome and error are in its first lines, so what is lost is the tail of a
+// long recovery, not the reason.
+func fitTrace(s string) string {
+	s = strings.ReplaceAll(s, "```", "'''")
+	if len(s) <= maxTraceDescription {
+		return s
+	}
+	cut := strings.LastIndexByte(s[:maxTraceDescription], '\n')
+	return s[:cut+1] + "…\n"
+}
@@ -6,13 +6,19 @@ import (
 	"github.com/bwmarrin/discordgo"
 
 	"github.com/keshon/melodix/internal/command/core/commands"
+	"github.com/keshon/melodix/internal/discord"
 	"github.com/keshon/melodix/internal/discord/cmdadapter"
 	"github.com/keshon/melodix/internal/discord/reply"
 
 	"github.com/keshon/melodix/internal/storage"
+	"github.com/keshon/melodix/pkg/music/resolve"
 )
 
-type SettingsCommand struct{}
+type SettingsCommand struct {
+	// Bot supplies the resolver whose sources the sources group configures;
+	// nil leaves that group with the built-in set.
+	Bot discord.VoiceAPI
+}
 
 func (c *SettingsCommand) Name() string        { return "settings" }
 func (c *SettingsCommand) Description() string { return "Server settings" }
@@ -33,6 +39,12 @@ func (c *SettingsCommand) SlashDefinition() *discordgo.ApplicationCommand {
 				Description: "Command group management",
 				Options:     commands.CommandsSubcommandOptions(),
 			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+				Name:        "sources",
+				Description: "Search source and link detection order for /play",
+				Options:     sourcesSubcommandOptions(c.resolver()),
+			},
 		},
 	}
 }
@@ -66,6 +78,8 @@ func (c *SettingsCommand) Run(ctx interface{}) error {
 	switch group.Name {
 	case "commands":
 		return runCommandsSettings(s, e, *st, context.Syncer, sub)
+	case "sources":
+		return runSourcesSettings(s, e, st, c.resolver(), sub)
 	default:
 		return reply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
 			Description: fmt.Sprintf("Unknown settings group: %s", group.Name),
@@ -73,6 +87,14 @@ func (c *SettingsCommand) Run(ctx interface{}) error {
 	}
 }
 
+// resolver is the bot's, or nil when the command was built without one.
+func (c *SettingsCommand) resolver() *resolve.Resolver {
+	if c.Bot == nil {
+		return nil
+	}
+	return c.Bot.Resolver()
+}
+
 func runCommandsSettings(s *discordgo.Session, e *discordgo.InteractionCreate, st storage.Storage, syncer cmdadapter.CommandSyncer, sub *discordgo.ApplicationCommandInteractionDataOption) error {
 	switch sub.Name {
 	case "log":
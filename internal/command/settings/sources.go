@@ -0,0 +1,148 @@
+package settings
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/keshon/melodix/internal/command/core/commands"
+	"github.com/keshon/melodix/internal/command/music/common"
+	"github.com/keshon/melodix/internal/discord/reply"
+	"github.com/keshon/melodix/internal/storage"
+	"github.com/keshon/melodix/pkg/music/resolve"
+	"github.com/keshon/melodix/pkg/music/sources"
+)
+
+// sourcesSubcommandOptions returns the slash options for how /play picks a
+// source. The search choices are the resolver's query sources.
+func sourcesSubcommandOptions(r *resolve.Resolver) []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "status",
+			Description: "Show the search source and link detection order",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "search",
+			Description: "Choose where /play looks up a search query",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "source",
+					Description: "Source for search queries",
+					Required:    true,
+					Choices:     common.SourceChoices(r, sources.ResolvesQueries),
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "order",
+			Description: "Choose which sources get first claim on a link",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "sources",
+					Description: "Source names, comma-separated (e.g. soundcloud, youtube); leave out to reset",
+				},
+			},
+		},
+	}
+}
+
+func runSourcesSettings(s *discordgo.Session, e *discordgo.InteractionCreate, st *storage.Storage, r *resolve.Resolver, sub *discordgo.ApplicationCommandInteractionDataOption) error {
+	if r == nil {
+		return reply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Description: "Music is not available on this bot.",
+		})
+	}
+	switch sub.Name {
+	case "status":
+		return reply.RespondEmbedEphemeral(s, e, sourcesStatusEmbed(st, r, e.GuildID))
+	case "search":
+		name := commands.SubOptionString(sub, "source")
+		src, ok := r.Source(name)
+		if !ok || !sources.ResolvesQueries(src) {
+			return reply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+				Description: fmt.Sprintf("`%s` can't take search queries. Pick one of: %s.", name, codeList(r.QuerySources())),
+			})
+		}
+		if err := st.SetSearchSource(e.GuildID, name); err != nil {
+			return reply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+				Description: "Failed to save the search source.",
+			})
+		}
+		return reply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Description: fmt.Sprintf("Search queries now go to %s.", sources.DisplayName(src)),
+			Footer:      &discordgo.MessageEmbedFooter{Text: "Use /settings sources status to review."},
+		})
+	case "order":
+		names, err := parseOrder(commands.SubOptionString(sub, "sources"), r.Precedence(resolve.Prefs{}))
+		if err != nil {
+			return reply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{Description: err.Error()})
+		}
+		if err := st.SetSourcePrecedence(e.GuildID, names); err != nil {
+			return reply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+				Description: "Failed to save the source order.",
+			})
+		}
+		desc := "Links are matched in the default order again."
+		if len(names) > 0 {
+			desc = "Links are now matched in this order: " + codeChain(r.Precedence(resolve.Prefs{Precedence: names})) + "."
+		}
+		return reply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Description: desc,
+			Footer:      &discordgo.MessageEmbedFooter{Text: "Use /settings sources status to review."},
+		})
+	default:
+		return reply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Description: fmt.Sprintf("Unknown subcommand: %s", sub.Name),
+		})
+	}
+}
+
+// parseOrder reads a comma- or space-separated source list, checking each name
+// against the sources link detection asks. An empty list is a reset.
+func parseOrder(raw string, detectable []string) ([]string, error) {
+	var names []string
+	for _, n := range strings.FieldsFunc(strings.ToLower(raw), func(r rune) bool { return r == ',' || r == ' ' }) {
+		if !slices.Contains(detectable, n) {
+			return nil, fmt.Errorf("`%s` is not a source links are matched against. Use: %s.", n, codeList(detectable))
+		}
+		if !slices.Contains(names, n) {
+			names = append(names, n)
+		}
+	}
+	return names, nil
+}
+
+func sourcesStatusEmbed(st *storage.Storage, r *resolve.Resolver, guildID string) *discordgo.MessageEmbed {
+	search, order := st.SourcePrefs(guildID)
+	searchName := sources.YouTube + " (default)"
+	if src, ok := r.Source(search); ok && sources.ResolvesQueries(src) {
+		searchName = src.SourceName()
+	}
+	orderNote := ""
+	if len(order) == 0 {
+		orderNote = " (default)"
+	}
+	return &discordgo.MessageEmbed{
+		Title:       "Sources",
+		Description: "Search queries go to the search source. A link goes to the first source in the order that recognises it, and to radio when none does. Use `/settings sources search` / `/settings sources order` to change.",
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Search source", Value: "`" + searchName + "`"},
+			{Name: "Link order" + orderNote, Value: codeChain(r.Precedence(resolve.Prefs{Precedence: order}))},
+		},
+	}
+}
+
+func codeList(names []string) string {
+	return "`" + strings.Join(names, "`, `") + "`"
+}
+
+func codeChain(names []string) string {
+	return "`" + strings.Join(names, "` → `") + "`"
+}
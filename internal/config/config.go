@@ -58,6 +58,12 @@ type Config struct {
 	// per guild at the default depth — and it does not pre-fill, so raising it
 	// delays nothing. Independent of the cache.
 	BufferAheadMs int `env:"BUFFER_AHEAD_MS" envDefault:"30000"`
+	// HedgeOpenMs hedges slow parser opens: when the preferred parser has not
+	// produced audio within this many ms, the next one is started alongside it
+	// and whichever plays first is kept (0 disables; parsers are then tried one
+	// after another). The hedge costs a second extraction, so set it above a
+	// healthy parser's usual start time — a couple of seconds for YouTube.
+	HedgeOpenMs int `env:"HEDGE_OPEN_MS" envDefault:"0"`
 	// MaxAudioBitrate caps which YouTube audio format the native parser picks, in
 	// bits per second (0 = take the best on offer). The same track is usually
 	// offered near 49, 66 and 137 kbps; a Discord voice channel carries 64 kbps
@@ -65,6 +71,35 @@ type Config struct {
 	// channel will not use. On a slow or lossy link a cap is worth real money,
 	// because a reopened stream is re-fetched from the start.
 	MaxAudioBitrate int `env:"MAX_AUDIO_BITRATE" envDefault:"0"`
+	// YtdlpCookiesFile is a Netscape-format cookies.txt exported from a browser
+	// signed in to YouTube: what gets age-restricted and bot-checked videos
+	// through. yt-dlp is given it with --cookies, and the native parser sends
+	// its youtube.com cookies as well. Empty = no cookies.
+	YtdlpCookiesFile string `env:"YTDLP_COOKIES_FILE"`
+	// YtdlpProxy is a proxy URL for yt-dlp (--proxy). ytdlp-link hands an HTTP
+	// proxy on to ffmpeg too, because a resolved googlevideo URL only plays from
+	// the address that resolved it.
+	YtdlpProxy string `env:"YTDLP_PROXY"`
+	// YtdlpExtractorArgs are extra yt-dlp --extractor-args values
+	// (space-separated), e.g. a PO token with the visitor data it was minted
+	// for: "youtube:po_token=web.gvs+TOKEN;visitor_data=DATA". They override
+	// the built-in player client, and a visitor_data here is presented by the
+	// native parser as well.
+	YtdlpExtractorArgs []string `env:"YTDLP_EXTRACTOR_ARGS" envSeparator:" "`
+	// LocalDirs lists directories whose audio files the local source may play
+	// (comma-separated; empty disables it). Nothing outside them is ever opened,
+	// symlinks included. They are rescanned at every start.
+	LocalDirs []string `env:"LOCAL_DIRS" envSeparator:","`
+	// RadioBrowserURL is the station directory /search source:radio and radio
+	// title search query: any Radio Browser-compatible server (empty = the
+	// public round-robin name).
+	RadioBrowserURL string `env:"RADIO_BROWSER_URL"`
+	// SpotifyEmbedURL and AppleMusicURL are where Spotify and Apple Music
+	// link metadata is fetched from (empty = the services themselves). Point
+	// them at a stand-in serving the same pages when the services are out of
+	// reach.
+	SpotifyEmbedURL string `env:"SPOTIFY_EMBED_URL"`
+	AppleMusicURL   string `env:"APPLE_MUSIC_URL"`
 
 	// Logging (applog / zerolog). LOG_FILE empty = stderr only (pretty console).
 	LogLevel      string `env:"LOG_LEVEL" envDefault:"info"`
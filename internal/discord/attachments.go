@@ -0,0 +1,53 @@
+package discord
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// endpointAttachmentsRefresh signs expired attachment links again. The fork
+// has no wrapper for it, so it goes through the session's raw request path,
+// which still applies the token and the rate limiter.
+var endpointAttachmentsRefresh = discordgo.EndpointAPI + "attachments/refresh-urls"
+
+// refreshAttachmentURLs is the httpfile.Refresher installed by NewBot. It uses
+// whichever session is current, so a renewal during a session restart fails
+// and the open that wanted it is retried by the recovery stream.
+func (b *Bot) refreshAttachmentURLs(urls []string) ([]string, error) {
+	b.mu.RLock()
+	s := b.dg
+	b.mu.RUnlock()
+	if s == nil {
+		return nil, fmt.Errorf("no discord session to refresh attachment links")
+	}
+
+	body, err := s.RequestWithBucketID(http.MethodPost, endpointAttachmentsRefresh,
+		map[string][]string{"attachment_urls": urls}, endpointAttachmentsRefresh)
+	if err != nil {
+		return nil, fmt.Errorf("refresh attachment links: %w", err)
+	}
+	var resp struct {
+		RefreshedURLs []struct {
+			Original  string `json:"original"`
+			Refreshed string `json:"refreshed"`
+		} `json:"refreshed_urls"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("refresh attachment links: %w", err)
+	}
+
+	// Answered by original rather than by position: nothing documents the
+	// order, and a link Discord would not sign is simply left out.
+	refreshed := make(map[string]string, len(resp.RefreshedURLs))
+	for _, r := range resp.RefreshedURLs {
+		refreshed[r.Original] = r.Refreshed
+	}
+	out := make([]string, len(urls))
+	for i, u := range urls {
+		out[i] = refreshed[u]
+	}
+	return out, nil
+}
@@ -1,10 +1,12 @@
 package discord
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/keshon/melodix/pkg/music/player"
+	"github.com/keshon/melodix/pkg/music/resolve"
 	"github.com/keshon/melodix/pkg/music/sources"
 )
 
@@ -19,6 +21,13 @@ type VoiceAPI interface {
 	// Resolve resolves input to tracks using the bot's shared resolver.
 	ResolveTracks(guildID, input, source, parser string) ([]sources.TrackInfo, error)
 
+	// ResolveTracksContext is ResolveTracks with the resolve bound to ctx.
+	ResolveTracksContext(ctx context.Context, guildID, input, source, parser string) ([]sources.TrackInfo, error)
+
+	// Resolver returns the bot's shared resolver, for listing its sources. Nil
+	// when the bot has no voice service.
+	Resolver() *resolve.Resolver
+
 	// UpdatePlaybackStatus creates or edits the guild's music status message so updates work beyond 15 min token expiry.
 	UpdatePlaybackStatus(s *discordgo.Session, i *discordgo.InteractionCreate, guildID string, embed *discordgo.MessageEmbed) error
 
@@ -56,10 +65,25 @@ func (b *Bot) FindUserVoiceState(guildID, userID string) (*UserVoiceState, error
 
 // ResolveTracks resolves input to tracks using the bot's shared resolver (delegates to voice service).
 func (b *Bot) ResolveTracks(guildID, input, source, parser string) ([]sources.TrackInfo, error) {
+	return b.ResolveTracksContext(context.Background(), guildID, input, source, parser)
+}
+
+// ResolveTracksContext is ResolveTracks with the resolve bound to ctx.
+func (b *Bot) ResolveTracksContext(ctx context.Context, guildID, input, source, parser string) ([]sources.TrackInfo, error) {
 	if b.voice == nil {
 		return nil, fmt.Errorf("voice service not available")
 	}
-	return b.voice.ResolveTracks(guildID, input, source, parser)
+	return b.voice.ResolveTracksContext(ctx, guildID, input, source, parser)
+}
+
+// Resolver returns the voice service's shared resolver. It is nil-safe on the
+// receiver too: commands are registered against a nil *Bot when only their
+// definitions are wanted (README generation).
+func (b *Bot) Resolver() *resolve.Resolver {
+	if b == nil || b.voice == nil {
+		return nil
+	}
+	return b.voice.Resolver()
 }
 
 // UpdatePlaybackStatus creates or edits the guild's music status message (delegates to voice service).
@@ -17,7 +17,15 @@ func (a *Adapter) Group() string            { return a.Cmd.Group() }
 func (a *Adapter) Category() string         { return a.Cmd.Category() }
 func (a *Adapter) UserPermissions() []int64 { return a.Cmd.UserPermissions() }
 
+// Run hands the command its invocation data, with ctx — the one left after
+// middleware — set on the interaction contexts that carry one.
 func (a *Adapter) Run(ctx context.Context, inv *command.Invocation) error {
+	switch data := inv.Data.(type) {
+	case *SlashInteractionContext:
+		data.Ctx = ctx
+	case *MessageApplicationCommandContext:
+		data.Ctx = ctx
+	}
 	return a.Cmd.Run(inv.Data)
 }
 
@@ -48,3 +56,10 @@ func (a *Adapter) Component(ctx *ComponentInteractionContext) error {
 	}
 	return nil
 }
+
+func (a *Adapter) Autocomplete(ctx *AutocompleteContext) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+	if ah, ok := a.Cmd.(AutocompleteHandler); ok {
+		return ah.Autocomplete(ctx)
+	}
+	return nil, nil
+}
@@ -1,6 +1,8 @@
 package cmdadapter
 
 import (
+	"context"
+
 	"github.com/bwmarrin/discordgo"
 	"github.com/keshon/melodix/internal/config"
 	"github.com/keshon/melodix/internal/storage"
@@ -21,8 +23,14 @@ type SlashInteractionContext struct {
 	Logger    Logger
 	AppLog    zerolog.Logger
 	Syncer    CommandSyncer
+	// Ctx is the command's context: cancelled on shutdown and at the command
+	// timeout. Read it through Context.
+	Ctx context.Context
 }
 
+// Context is the command's context, or Background when none was set.
+func (c *SlashInteractionContext) Context() context.Context { return orBackground(c.Ctx) }
+
 type ComponentInteractionContext struct {
 	Session   *discordgo.Session
 	Event     *discordgo.InteractionCreate
@@ -31,6 +39,19 @@ type ComponentInteractionContext struct {
 	Responder Responder
 	Logger    Logger
 	AppLog    zerolog.Logger
+	// Ctx is as on SlashInteractionContext.
+	Ctx context.Context
+}
+
+// Context is the interaction's context, or Background when none was set.
+func (c *ComponentInteractionContext) Context() context.Context { return orBackground(c.Ctx) }
+
+type AutocompleteContext struct {
+	Session *discordgo.Session
+	Event   *discordgo.InteractionCreate
+	Storage *storage.Storage
+	Config  *config.Config
+	AppLog  zerolog.Logger
 }
 
 type MessageReactionContext struct {
@@ -50,11 +71,23 @@ type MessageApplicationCommandContext struct {
 	Responder Responder
 	Logger    Logger
 	AppLog    zerolog.Logger
+	// Ctx is as on SlashInteractionContext.
+	Ctx context.Context
 }
 
+// Context is the command's context, or Background when none was set.
+func (c *MessageApplicationCommandContext) Context() context.Context { return orBackground(c.Ctx) }
+
 type MessageContext struct {
 	Session *discordgo.Session
 	Event   *discordgo.MessageCreate
 	Storage *storage.Storage
 	Config  *config.Config
 }
+
+func orBackground(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
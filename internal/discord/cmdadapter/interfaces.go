@@ -37,6 +37,14 @@ type ComponentInteractionHandler interface {
 	Component(*ComponentInteractionContext) error
 }
 
+// AutocompleteHandler is implemented by commands with options declared
+// Autocomplete: true. It returns the suggestions and the dispatcher sends them,
+// because an autocomplete interaction accepts exactly one response type and no
+// deferral — a handler has no other reply to make.
+type AutocompleteHandler interface {
+	Autocomplete(*AutocompleteContext) ([]*discordgo.ApplicationCommandOptionChoice, error)
+}
+
 // Meta is the read-side view of a command's classification, used by consumers
 // that only group/filter commands (readme generation, middleware checks).
 type Meta interface {
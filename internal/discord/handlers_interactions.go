@@ -9,13 +9,16 @@ import (
 	"github.com/keshon/melodix/internal/discord/reply"
 )
 
-// onInteractionCreate dispatches slash commands, context menu commands, and component interactions.
+// onInteractionCreate dispatches slash commands, context menu commands,
+// component interactions and autocomplete requests.
 func (b *Bot) onInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	switch i.Type {
 	case discordgo.InteractionApplicationCommand:
 		b.onApplicationCommand(s, i)
 	case discordgo.InteractionMessageComponent:
 		b.onComponentInteraction(s, i)
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		b.onAutocomplete(s, i)
 	default:
 		b.log.Debug().Int("interaction_type", int(i.Type)).Msg("interaction_unhandled")
 	}
@@ -37,7 +40,7 @@ func (b *Bot) onApplicationCommand(s *discordgo.Session, i *discordgo.Interactio
 	switch i.ApplicationCommandData().CommandType {
 	case discordgo.MessageApplicationCommand:
 		inv = &command.Invocation{Data: &cmdadapter.MessageApplicationCommandContext{
-			Session: s, Event: i, Storage: b.storage, Target: i.Message,
+			Session: s, Event: i, Storage: b.storage, Target: contextTarget(i),
 			Config: b.cfg, Responder: reply.DefaultResponder, Logger: logger,
 			AppLog: b.log,
 		}}
@@ -84,15 +87,68 @@ func (b *Bot) onComponentInteraction(s *discordgo.Session, i *discordgo.Interact
 	b.mu.RUnlock()
 
 	b.runGuardedInteraction(s, i, "component", matched.Name(), func(cmdCtx context.Context) error {
-		_ = cmdCtx
 		return handler.Component(&cmdadapter.ComponentInteractionContext{
 			Session: s, Event: i, Storage: b.storage,
 			Config: b.cfg, Responder: reply.DefaultResponder, Logger: logger,
-			AppLog: b.log,
+			AppLog: b.log, Ctx: cmdCtx,
 		})
 	})
 }
 
+// maxAutocompleteChoices is Discord's cap; a longer list is rejected whole.
+const maxAutocompleteChoices = 25
+
+// onAutocomplete answers an autocomplete request. It bypasses the command guard
+// on purpose: Discord fires one per keystroke and drops any answer slower than
+// three seconds, so queueing behind a busy slot only produces stale lists, and
+// the guard's embed replies are not a valid response to this interaction.
+func (b *Bot) onAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	name := i.ApplicationCommandData().Name
+	c := command.DefaultRegistry.Get(name)
+	if c == nil {
+		return
+	}
+	handler, ok := command.Root(c).(cmdadapter.AutocompleteHandler)
+	if !ok {
+		b.log.Warn().Str("command", name).Msg("autocomplete_handler_missing")
+		return
+	}
+	choices, err := handler.Autocomplete(&cmdadapter.AutocompleteContext{
+		Session: s, Event: i, Storage: b.storage, Config: b.cfg, AppLog: b.log,
+	})
+	if err != nil {
+		// Still answer, with nothing: an unanswered request leaves the client
+		// showing a loading error until the user types again.
+		b.log.Debug().Str("command", name).Err(err).Msg("autocomplete_failed")
+		choices = nil
+	}
+	if len(choices) > maxAutocompleteChoices {
+		choices = choices[:maxAutocompleteChoices]
+	}
+	if choices == nil {
+		choices = []*discordgo.ApplicationCommandOptionChoice{}
+	}
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	}); err != nil {
+		b.log.Debug().Str("command", name).Err(err).Msg("autocomplete_respond_failed")
+	}
+}
+
+// contextTarget is the message a message context menu was opened on. It
+// arrives in the resolved data, keyed by the target id; i.Message belongs to
+// component interactions and is nil here.
+func contextTarget(i *discordgo.InteractionCreate) *discordgo.Message {
+	data := i.ApplicationCommandData()
+	if data.Resolved != nil {
+		if m := data.Resolved.Messages[data.TargetID]; m != nil {
+			return m
+		}
+	}
+	return i.Message
+}
+
 // matchesComponentID reports whether a component customID belongs to a command.
 // CustomIDs follow the convention "commandName", "commandName:...", or "commandName_...".
 func matchesComponentID(customID, commandName string) bool {
@@ -17,23 +17,34 @@ import (
 
 // NowPlayingEmbed builds the guild music status embed for a track that just started:
 // a title/link line plus a line of inline-code "chips" (source · parser, duration or
-// `live` for radio, artist when known). Embeds don't render -# subtext, so code spans
+// `live` for radio, the resume point of a resumed episode, artist when known). Embeds don't render -# subtext, so code spans
 // are the chip look Discord gives us.
+//
+// Only an http(s) URL becomes a link: a library track's "local:<id>" is not
+// one Discord can open.
+//
+// A radio station that announces its songs gets the song as the headline and the
+// station, linked, on the line under it; the embed is re-rendered on every change.
 func NowPlayingEmbed(track *parsers.Track) *discordgo.MessageEmbed {
-	var title, url string
+	var title, url, song string
 	if track != nil {
-		title, url = track.Title, track.URL
+		title, url, song = track.Title, track.URL, track.StreamTitle
 	}
 	var desc string
 	switch {
-	case title != "" && url != "":
-		desc = fmt.Sprintf("🎶 [%s](%s)", title, url)
+	case title != "" && sources.IsURL(url):
+		desc = fmt.Sprintf("[%s](%s)", title, url)
 	case title != "":
-		desc = "🎶 " + title
+		desc = title
 	case url != "":
-		desc = "🎶 " + url
+		desc = url
 	default:
-		desc = "🎶 Unknown track"
+		desc = "Unknown track"
+	}
+	if song != "" {
+		desc = "🎶 " + song + "\n📻 " + desc
+	} else {
+		desc = "🎶 " + desc
 	}
 	if chips := trackChips(track); chips != "" {
 		// Blank line: the only vertical spacing embed markdown offers.
@@ -76,6 +87,10 @@ func trackChips(track *parsers.Track) string {
 	case track.Duration > 0:
 		chips = append(chips, "`"+formatDuration(track.Duration)+"`")
 	}
+	if track.StartAt > 0 {
+		// Picked up where the guild left off: say so, or it sounds like a skip.
+		chips = append(chips, "`resumed at "+formatDuration(track.StartAt)+"`")
+	}
 
 	if track.Artist != "" {
 		chips = append(chips, "`"+track.Artist+"`")
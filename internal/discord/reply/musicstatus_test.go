@@ -57,6 +57,15 @@ func TestNowPlayingEmbedChips(t *testing.T) {
 			track: track(sources.Radio, "ffmpeg-link", "", 0),
 			want:  "🎶 [Song](https://example.com/t)\n\n`radio` `ffmpeg-link` `ffmpeg` `live`",
 		},
+		{
+			name: "radio song headlines over the station",
+			track: func() *parsers.Track {
+				tr := track(sources.Radio, "ffmpeg-link", "", 0)
+				tr.Title, tr.StreamTitle = "Station", "Artist - Tune"
+				return tr
+			}(),
+			want: "🎶 Artist - Tune\n📻 [Station](https://example.com/t)\n\n`radio` `ffmpeg-link` `ffmpeg` `live`",
+		},
 		{
 			name:  "unknown duration omitted",
 			track: track("soundcloud", "scnative-link", "", 0),
@@ -67,6 +76,24 @@ func TestNowPlayingEmbedChips(t *testing.T) {
 			track: passthroughTrack("youtube", "kkdai-pipe", time.Hour+5*time.Minute+7*time.Second),
 			want:  "🎶 [Song](https://example.com/t)\n\n`youtube` `kkdai-pipe` `passthrough` `1:05:07`",
 		},
+		{
+			name: "resumed episode shows where it picked up",
+			track: func() *parsers.Track {
+				tr := track(sources.Podcast, "direct-ffmpeg", "", time.Hour)
+				tr.StartAt = 12*time.Minute + 5*time.Second
+				return tr
+			}(),
+			want: "🎶 [Song](https://example.com/t)\n\n`podcast` `direct-ffmpeg` `ffmpeg` `1:00:00` `resumed at 12:05`",
+		},
+		{
+			name: "library track is not linked",
+			track: func() *parsers.Track {
+				tr := track(sources.Local, "local-passthrough", "", 0)
+				tr.URL = "local:3f2a9c"
+				return tr
+			}(),
+			want: "🎶 Song\n\n`local` `local-passthrough` `ffmpeg`",
+		},
 		{
 			name:  "no metadata means no chip line",
 			track: &parsers.Track{Title: "Song", URL: "https://example.com/t"},
@@ -8,11 +8,16 @@ import (
 	"github.com/keshon/melodix/internal/config"
 	"github.com/keshon/melodix/internal/discord/voice"
 	"github.com/keshon/melodix/internal/storage"
+	"github.com/keshon/melodix/pkg/music/breaker"
+	"github.com/keshon/melodix/pkg/music/httpfile"
+	"github.com/keshon/melodix/pkg/music/parsers/directfile"
 	"github.com/keshon/melodix/pkg/music/parsers/ffmpeg"
 	"github.com/keshon/melodix/pkg/music/parsers/kkdai"
+	"github.com/keshon/melodix/pkg/music/parsers/localfile"
 	"github.com/keshon/melodix/pkg/music/parsers/ytdlp"
 	"github.com/keshon/melodix/pkg/music/parsers/ytnative"
 	"github.com/keshon/melodix/pkg/music/soundcloudapi"
+	"github.com/keshon/melodix/pkg/music/sources/catalog"
 	"github.com/rs/zerolog"
 )
 
@@ -38,6 +43,11 @@ func NewBot(cfg *config.Config, storage *storage.Storage, log zerolog.Logger) *B
 	soundcloudapi.SetLogger(log)
 	ytnative.SetLogger(log)
 	ytdlp.SetLogger(log)
+	localfile.SetLogger(log)
+	directfile.SetLogger(log)
+	breaker.SetLogger(log)
+	catalog.SetLogger(log)
+	httpfile.SetRefresher(b.refreshAttachmentURLs)
 	return b
 }
 
@@ -1,6 +1,7 @@
 package voice
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -56,23 +57,80 @@ func NewVoiceService(getSession SessionGetter, cfg *config.Config, store *storag
 		store:                   store,
 		log:                     log,
 		players:                 make(map[string]*player.Player),
+		resolver:                resolve.New(),
 		sinkProviders:           make(map[string]*sink.DiscordSinkProvider),
 		guildMusicStatus:        make(map[string]guildMusicStatus),
 		guildMusicNotifyChannel: make(map[string]string),
 	}
 }
 
+// playbackRecorder is per guild (one per player), so lastID is the row of the
+// track that guild is playing now — the one a radio title belongs to.
 type playbackRecorder struct {
 	store *storage.Storage
 	log   zerolog.Logger
+
+	mu     sync.Mutex
+	lastID uint64
 }
 
-func (r playbackRecorder) Record(guildID string, playedAt time.Time, track parsers.Track) {
+func (r *playbackRecorder) Record(guildID string, playedAt time.Time, track parsers.Track) {
 	if r.store == nil {
 		return
 	}
-	if _, err := r.store.AppendMusicPlayback(guildID, track, playedAt); err != nil {
+	id, err := r.store.AppendMusicPlayback(guildID, track, playedAt)
+	if err != nil {
 		r.log.Warn().Str("guild_id", guildID).Err(err).Msg("playback_history_append_failed")
+		// Zero, not the previous track's row: a station's titles must not
+		// land on whatever played before it.
+		id = 0
+	}
+	r.mu.Lock()
+	r.lastID = id
+	r.mu.Unlock()
+}
+
+// RecordStreamTitle implements player.StreamTitleRecorder.
+func (r *playbackRecorder) RecordStreamTitle(guildID, title string) {
+	if r.store == nil {
+		return
+	}
+	r.mu.Lock()
+	id := r.lastID
+	r.mu.Unlock()
+	if id == 0 {
+		return // the stream's own row failed to append
+	}
+	if err := r.store.AppendMusicPlaybackHeard(guildID, id, title); err != nil {
+		r.log.Warn().Str("guild_id", guildID).Err(err).Msg("playback_heard_append_failed")
+	}
+}
+
+// ResumePosition implements player.ResumeStore.
+func (r *playbackRecorder) ResumePosition(guildID, url string) time.Duration {
+	if r.store == nil {
+		return 0
+	}
+	return r.store.ResumePosition(guildID, url)
+}
+
+// SaveResumePosition implements player.ResumeStore.
+func (r *playbackRecorder) SaveResumePosition(guildID, url string, pos time.Duration) {
+	if r.store == nil {
+		return
+	}
+	if err := r.store.SaveResumePosition(guildID, url, pos, time.Now()); err != nil {
+		r.log.Warn().Str("guild_id", guildID).Err(err).Msg("resume_position_save_failed")
+	}
+}
+
+// ClearResumePosition implements player.ResumeStore.
+func (r *playbackRecorder) ClearResumePosition(guildID, url string) {
+	if r.store == nil {
+		return
+	}
+	if err := r.store.ClearResumePosition(guildID, url); err != nil {
+		r.log.Warn().Str("guild_id", guildID).Err(err).Msg("resume_position_clear_failed")
 	}
 }
 
@@ -82,9 +140,9 @@ func (s *Service) notifyPlaybackFailed(guildID string, track parsers.Track, err
 	if sess == nil {
 		return
 	}
-	detail := playbackerr.String(err.Error())
+	detail := playbackerr.Describe(err)
 	var desc string
-	if track.Title != "" && track.URL != "" {
+	if track.Title != "" && sources.IsURL(track.URL) {
 		desc = fmt.Sprintf("%s\n\n[%s](%s)", detail, track.Title, track.URL)
 	} else if track.Title != "" {
 		desc = fmt.Sprintf("%s\n\n%s", detail, track.Title)
@@ -92,7 +150,7 @@ func (s *Service) notifyPlaybackFailed(guildID string, track parsers.Track, err
 		desc = detail
 	}
 	s.deliverPlaybackFailureEmbed(sess, guildID, &discordgo.MessageEmbed{
-		Title:       "Playback failed",
+		Title:       playbackerr.Title(err, "Playback failed"),
 		Description: desc,
 		Color:       reply.EmbedColor,
 	})
@@ -146,6 +204,7 @@ func (s *Service) GetOrCreatePlayer(guildID string) *player.Player {
 	if s.resolver == nil {
 		s.resolver = resolve.New()
 	}
+	res := guildResolver{s: s, guildID: guildID}
 	provider, ok := s.sinkProviders[guildID]
 	if !ok {
 		voiceDelay := time.Duration(s.cfg.VoiceReadyDelayMs) * time.Millisecond
@@ -156,7 +215,7 @@ func (s *Service) GetOrCreatePlayer(guildID string) *player.Player {
 	if !ok {
 		s.log.Warn().Str("value", s.cfg.PlayerTransportRecoveryMode).Msg("unknown_transport_recovery_mode_using_hard")
 	}
-	p := player.NewWithOptions(provider, s.resolver, player.Options{
+	p := player.NewWithOptions(provider, res, player.Options{
 		Logger:                s.log,
 		TransportRecoveryMode: recoveryMode,
 		TransportSoftAttempts: s.cfg.PlayerTransportSoftAttempts,
@@ -164,7 +223,7 @@ func (s *Service) GetOrCreatePlayer(guildID string) *player.Player {
 	})
 	p.SetGuildID(guildID)
 	if s.store != nil {
-		p.SetRecorder(playbackRecorder{store: s.store, log: s.log})
+		p.SetRecorder(&playbackRecorder{store: s.store, log: s.log})
 	}
 	s.players[guildID] = p
 	go s.watchPlayerStatus(guildID, p)
@@ -174,8 +233,9 @@ func (s *Service) GetOrCreatePlayer(guildID string) *player.Player {
 // watchPlayerStatus is the single long-lived consumer of the player's status channel
 // (one per guild, for the player's lifetime). Slash handlers render interaction-driven
 // updates synchronously; this watcher covers async transitions only: auto-advance to the
-// next track and natural queue end. On an interaction-driven start both paths render the
-// same "Now Playing" embed — the duplicate edit is invisible to users.
+// next track, a radio station announcing its next song, and natural queue end. On an
+// interaction-driven start both paths render the same "Now Playing" embed — the
+// duplicate edit is invisible to users.
 func (s *Service) watchPlayerStatus(guildID string, p *player.Player) {
 	for status := range p.PlayerStatus {
 		sess := s.getSession()
@@ -188,7 +248,7 @@ func (s *Service) watchPlayerStatus(guildID string, p *player.Player) {
 			continue
 		}
 		switch status {
-		case player.StatusPlaying:
+		case player.StatusPlaying, player.StatusStreamTitle:
 			track := p.CurrentTrack()
 			if track == nil {
 				s.log.Warn().Str("guild_id", guildID).Msg("now_playing_render_skipped_no_track")
@@ -230,15 +290,50 @@ func (s *Service) watchPlayerStatus(guildID string, p *player.Player) {
 	}
 }
 
-// ResolveTracks resolves input to tracks using the service's shared resolver.
+// ResolveTracks resolves input to tracks using the service's shared resolver
+// and the guild's source settings.
 func (s *Service) ResolveTracks(guildID, input, source, parser string) ([]sources.TrackInfo, error) {
+	return s.ResolveTracksContext(context.Background(), guildID, input, source, parser)
+}
+
+// ResolveTracksContext is ResolveTracks with the resolve bound to ctx.
+func (s *Service) ResolveTracksContext(ctx context.Context, guildID, input, source, parser string) ([]sources.TrackInfo, error) {
+	return s.Resolver().ResolveWithContext(ctx, input, source, parser, s.sourcePrefs(guildID))
+}
+
+// Resolver returns the shared resolver, for listing its sources.
+func (s *Service) Resolver() *resolve.Resolver {
 	s.mu.Lock()
+	defer s.mu.Unlock()
 	if s.resolver == nil {
 		s.resolver = resolve.New()
 	}
-	r := s.resolver
-	s.mu.Unlock()
-	return r.Resolve(input, source, parser)
+	return s.resolver
+}
+
+// sourcePrefs reads the guild's search source and URL precedence, which are
+// read per call so a /settings change applies to the next /play.
+func (s *Service) sourcePrefs(guildID string) resolve.Prefs {
+	if s.store == nil {
+		return resolve.Prefs{}
+	}
+	search, order := s.store.SourcePrefs(guildID)
+	return resolve.Prefs{SearchSource: search, Precedence: order}
+}
+
+// guildResolver is the resolver a guild's player enqueues through: the shared
+// one, with that guild's source settings.
+type guildResolver struct {
+	s       *Service
+	guildID string
+}
+
+func (g guildResolver) Resolve(input, source, parser string) ([]sources.TrackInfo, error) {
+	return g.s.ResolveTracks(g.guildID, input, source, parser)
+}
+
+func (g guildResolver) ResolveContext(ctx context.Context, input, source, parser string) ([]sources.TrackInfo, error) {
+	return g.s.ResolveTracksContext(ctx, g.guildID, input, source, parser)
 }
 
 // SetGuildMusicNotifyChannel records the text channel id for guild (slash command channel) so async
@@ -14,6 +14,9 @@ func WithGuildOnly() command.Middleware {
 			if v, ok := inv.Data.(*cmdadapter.SlashInteractionContext); ok && v.Event.GuildID == "" {
 				return nil
 			}
+			if v, ok := inv.Data.(*cmdadapter.MessageApplicationCommandContext); ok && v.Event.GuildID == "" {
+				return nil
+			}
 			if v, ok := inv.Data.(*cmdadapter.MessageContext); ok && v.Event.GuildID == "" {
 				return nil
 			}
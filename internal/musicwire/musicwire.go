@@ -1,24 +1,46 @@
 // Package musicwire installs the optional playback layers — the anti-skip
-// buffer and the global track cache — into the stream engine from config. It is
-// shared by the Discord bot and the CLI so both behave identically.
+// buffer, the global track cache and the local library — into the stream
+// engine from config, along with the radio station directory and the Spotify
+// and Apple Music metadata endpoints and the YouTube session (yt-dlp cookies,
+// proxy and extractor args), after probing for the binaries the parsers need.
+// It is shared by the Discord bot and the CLI so both behave identically.
 package musicwire
 
 import (
+	"context"
+	"net/http"
+
 	"github.com/keshon/melodix/internal/config"
 	"github.com/keshon/melodix/internal/storage"
 	"github.com/keshon/melodix/pkg/music/cache"
+	"github.com/keshon/melodix/pkg/music/library"
+	"github.com/keshon/melodix/pkg/music/parsers/ytdlp"
 	"github.com/keshon/melodix/pkg/music/parsers/ytnative"
+	"github.com/keshon/melodix/pkg/music/sources/catalog"
+	"github.com/keshon/melodix/pkg/music/sources/radio"
 	"github.com/keshon/melodix/pkg/music/stream"
 	"github.com/rs/zerolog"
 )
 
-// Apply sets the anti-skip read-ahead depth and, when CACHE_ENABLED, builds and
-// installs the global track cache. Call once at startup, before any playback.
+// Apply probes the playback dependencies, sets the anti-skip read-ahead depth
+// and, when CACHE_ENABLED, builds and installs the global track cache. Call
+// once at startup, before any playback.
 // A nil store still enables the cache, but its index is in-memory only — that is
 // the CLI's fallback when the bot holds the data directory lock.
 func Apply(cfg *config.Config, store *storage.Storage, log zerolog.Logger) error {
+	applyDependencies(log)
+	if err := applyYouTubeSession(cfg, log); err != nil {
+		return err
+	}
 	stream.SetBufferAhead(cfg.BufferAheadMs)
+	stream.SetHedgeOpen(cfg.HedgeOpenMs)
 	ytnative.SetMaxBitrate(cfg.MaxAudioBitrate)
+	radio.SetDirectoryURL(cfg.RadioBrowserURL)
+	catalog.SetSpotifyURL(cfg.SpotifyEmbedURL)
+	catalog.SetAppleMusicURL(cfg.AppleMusicURL)
+	if err := applyLibrary(cfg, store, log); err != nil {
+		return err
+	}
 	if !cfg.CacheEnabled {
 		// Say so out loud. A cache that is off writes nothing and logs nothing,
 		// which is indistinguishable from a cache that is broken — and the usual
@@ -26,6 +48,7 @@ func Apply(cfg *config.Config, store *storage.Storage, log zerolog.Logger) error
 		// process at all.
 		log.Info().
 			Int("buffer_ahead_ms", cfg.BufferAheadMs).
+			Int("hedge_open_ms", cfg.HedgeOpenMs).
 			Int("max_audio_bitrate", cfg.MaxAudioBitrate).
 			Msg("track_cache_disabled")
 		return nil
@@ -49,7 +72,92 @@ func Apply(cfg *config.Config, store *storage.Storage, log zerolog.Logger) error
 		Bool("persistent", cfg.CachePersistent).
 		Bool("index_persisted", index != nil).
 		Int("buffer_ahead_ms", cfg.BufferAheadMs).
+		Int("hedge_open_ms", cfg.HedgeOpenMs).
 		Int("max_audio_bitrate", cfg.MaxAudioBitrate).
 		Msg("track_cache_enabled")
 	return nil
 }
+
+// applyLibrary installs the local library when LOCAL_DIRS is set. The index
+// loaded from store serves searches at once; the rescan runs in the background
+// because walking a large collection on a network mount takes minutes, and
+// startup should not wait for it.
+func applyLibrary(cfg *config.Config, store *storage.Storage, log zerolog.Logger) error {
+	if len(cfg.LocalDirs) == 0 {
+		return nil
+	}
+	var index library.IndexStore
+	if store != nil {
+		index = store.LocalIndex()
+	}
+	lib, err := library.New(cfg.LocalDirs, index, log)
+	if err != nil {
+		return err
+	}
+	library.SetDefault(lib)
+	log.Info().Strs("dirs", lib.Roots()).Int("tracks", lib.Len()).Msg("local_library_enabled")
+	go func() {
+		if _, _, err := lib.Scan(); err != nil {
+			log.Warn().Err(err).Msg("library_scan_failed")
+		}
+	}()
+	return nil
+}
+
+// applyDependencies runs the dependency probe, logs what it found and takes
+// the parsers that cannot work here out of the fallback order. A missing
+// binary is not fatal: the native parsers need none, and most sources list
+// one of them first.
+func applyDependencies(log zerolog.Logger) {
+	report := stream.ProbeDependencies(context.Background())
+	for _, d := range report.Dependencies {
+		if d.OK() {
+			log.Info().Str("name", d.Name).Str("path", d.Path).Str("version", d.Version).
+				Msg("dependency_found")
+			continue
+		}
+		log.Warn().Str("name", d.Name).Bool("optional", d.Optional).Err(d.Err).
+			Msg("dependency_missing")
+	}
+	if len(report.Disabled) > 0 {
+		log.Warn().Strs("parsers", report.DisabledParsers()).
+			Msg("parsers_disabled_missing_dependency")
+	}
+	stream.DisableMissing(report)
+}
+
+// applyYouTubeSession hands yt-dlp the operator's cookies, proxy and extractor
+// args, and gives the native parser the same cookies and visitor data so both
+// present one identity. An unreadable cookies file fails startup: running on
+// without it would turn every age-restricted video back into a failure with
+// nothing in the log to say why.
+func applyYouTubeSession(cfg *config.Config, log zerolog.Logger) error {
+	opts := ytdlp.Options{
+		CookiesFile:   cfg.YtdlpCookiesFile,
+		Proxy:         cfg.YtdlpProxy,
+		ExtractorArgs: cfg.YtdlpExtractorArgs,
+	}
+	ytdlp.SetOptions(opts)
+
+	var cookies []*http.Cookie
+	if cfg.YtdlpCookiesFile != "" {
+		var err error
+		if cookies, err = ytnative.LoadCookies(cfg.YtdlpCookiesFile); err != nil {
+			return err
+		}
+	}
+	ytnative.SetSession(ytnative.Session{Cookies: cookies, VisitorData: opts.VisitorData()})
+
+	if cfg.YtdlpCookiesFile == "" && cfg.YtdlpProxy == "" && len(cfg.YtdlpExtractorArgs) == 0 {
+		return nil
+	}
+	// The proxy URL is not logged: it may carry credentials.
+	log.Info().
+		Str("cookies_file", cfg.YtdlpCookiesFile).
+		Int("youtube_cookies", len(cookies)).
+		Bool("proxy", cfg.YtdlpProxy != "").
+		Int("extractor_args", len(cfg.YtdlpExtractorArgs)).
+		Bool("visitor_data_pinned", opts.VisitorData() != "").
+		Msg("youtube_session_configured")
+	return nil
+}
@@ -0,0 +1,49 @@
+package playbackerr
+
+import (
+	"errors"
+
+	"github.com/keshon/melodix/pkg/music/parsers"
+)
+
+// Category is a kind of playback failure that has its own explanation, so the
+// listener is told what happened instead of being shown the last parser's
+// error.
+type Category int
+
+const (
+	// Generic failures are shown as their error text.
+	Generic Category = iota
+	// SignInRequired is an age-restricted video, or YouTube's "confirm you're
+	// not a bot" check: nothing plays it without a signed-in session.
+	SignInRequired
+)
+
+const signInRequiredText = "This video is age-restricted, or YouTube wants a signed-in account before it will play it. " +
+	"The bot's host can let it through by giving yt-dlp the cookies of a signed-in account (`YTDLP_COOKIES_FILE`)."
+
+// Classify returns err's category.
+func Classify(err error) Category {
+	if errors.Is(err, parsers.ErrSignInRequired) {
+		return SignInRequired
+	}
+	return Generic
+}
+
+// Title returns the embed title for err: the category's own, or fallback for
+// a generic failure.
+func Title(err error, fallback string) string {
+	if Classify(err) == SignInRequired {
+		return "🔞 Age-restricted / Sign-in Required"
+	}
+	return fallback
+}
+
+// Describe returns the embed description for err: the category's explanation,
+// or the error text, length-capped as String does.
+func Describe(err error) string {
+	if Classify(err) == SignInRequired {
+		return String(signInRequiredText)
+	}
+	return String(err.Error())
+}
@@ -1,4 +1,5 @@
-// Package playbackerr holds shared formatting for user-visible playback errors (Discord embeds, etc.).
+// Package playbackerr holds shared formatting for user-visible playback errors (Discord embeds, etc.),
+// including the failure categories that get their own explanation.
 // Kept separate from internal/command/music/common to avoid import cycles with internal/discord/voice.
 package playbackerr
 
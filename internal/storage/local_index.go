@@ -0,0 +1,25 @@
+package storage
+
+import "github.com/keshon/melodix/pkg/music/library"
+
+// LocalIndex returns a library.IndexStore backed by the local_tracks
+// collection, for wiring into library.New.
+func (s *Storage) LocalIndex() library.IndexStore { return localIndexStore{s} }
+
+type localIndexStore struct{ s *Storage }
+
+func (l localIndexStore) Load() (map[string]library.Entry, error) {
+	out := make(map[string]library.Entry, l.s.localIdx.Len())
+	for e := range l.s.localIdx.All() {
+		out[e.ID] = e.Entry
+	}
+	return out, nil
+}
+
+func (l localIndexStore) Put(e library.Entry) error {
+	return l.s.localIdx.Put(&LocalTrackEntry{Entry: e})
+}
+
+func (l localIndexStore) Delete(id string) error {
+	return l.s.localIdx.Delete(id)
+}
@@ -24,9 +24,19 @@ func playbackFromTrack(id uint64, guildID string, at time.Time, tp parsers.Track
 		CurrentParser:    tp.CurrentParser,
 		AvailableParsers: slices.Clone(tp.SourceInfo.AvailableParsers),
 		SourceName:       tp.SourceInfo.SourceName,
+		Heard:            heardFromTrack(tp),
 	}
 }
 
+// heardFromTrack seeds a live stream's song list with the title it announced
+// before its history row existed.
+func heardFromTrack(tp parsers.Track) []string {
+	if tp.StreamTitle == "" {
+		return nil
+	}
+	return []string{tp.StreamTitle}
+}
+
 // TrackInfoFromMusicPlayback rebuilds resolver metadata for enqueue. Current parser is first in AvailableParsers when possible.
 func TrackInfoFromMusicPlayback(m PlaybackEntry) sources.TrackInfo {
 	parsersList := slices.Clone(m.AvailableParsers)
@@ -65,6 +75,29 @@ func (s *Storage) AppendMusicPlayback(guildID string, track parsers.Track, at ti
 	return id, nil
 }
 
+// AppendMusicPlaybackHeard adds a song a live stream announced to the row that
+// recorded the stream. Only the most recent musicPlaybackHeardLimit titles are
+// kept: a station left on all day announces hundreds.
+func (s *Storage) AppendMusicPlaybackHeard(guildID string, id uint64, title string) error {
+	err := s.db.Update(func(tx *datastore.Tx) error {
+		col := datastore.In(tx, s.playback)
+		row, ok := col.Get(guildRowKey(guildID, id))
+		if !ok {
+			// Trimmed past the retention limit while the stream played on.
+			return ErrMusicPlaybackNotFound
+		}
+		row.Heard = append(row.Heard, title)
+		if n := len(row.Heard); n > musicPlaybackHeardLimit {
+			row.Heard = row.Heard[n-musicPlaybackHeardLimit:]
+		}
+		return col.Put(row)
+	})
+	if err != nil {
+		return fmt.Errorf("persist heard title: %w", err)
+	}
+	return nil
+}
+
 // MusicPlayback returns one row by id.
 func (s *Storage) MusicPlayback(guildID string, id uint64) (PlaybackEntry, error) {
 	row, ok := s.playback.Get(guildRowKey(guildID, id))
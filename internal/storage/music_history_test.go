@@ -2,6 +2,7 @@ package storage
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -217,3 +218,40 @@ func TestStoragePersistsAcrossReopen(t *testing.T) {
 		t.Fatalf("disabled group lost across reopen (%v, %v)", disabled, err)
 	}
 }
+
+func TestAppendMusicPlaybackHeard(t *testing.T) {
+	s := newTestStorage(t)
+
+	guild := "guild1"
+	radio := parsers.Track{
+		URL:         "https://radio.example/stream",
+		Title:       "Station",
+		StreamTitle: "Opening Song",
+		SourceInfo:  sources.TrackInfo{SourceName: sources.Radio},
+	}
+	id, err := s.AppendMusicPlayback(guild, radio, time.Now())
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	for i := 0; i < musicPlaybackHeardLimit; i++ {
+		if err := s.AppendMusicPlaybackHeard(guild, id, fmt.Sprintf("Song %d", i)); err != nil {
+			t.Fatalf("append heard %d: %v", i, err)
+		}
+	}
+
+	got, err := s.MusicPlayback(guild, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Heard) != musicPlaybackHeardLimit {
+		t.Fatalf("heard = %d titles, want the cap %d", len(got.Heard), musicPlaybackHeardLimit)
+	}
+	// The opening title was the oldest, so the cap pushed it out.
+	if got.Heard[0] != "Song 0" || got.Heard[len(got.Heard)-1] != fmt.Sprintf("Song %d", musicPlaybackHeardLimit-1) {
+		t.Fatalf("heard kept the wrong end: first %q, last %q", got.Heard[0], got.Heard[len(got.Heard)-1])
+	}
+
+	if err := s.AppendMusicPlaybackHeard(guild, id+1, "Nowhere"); !errors.Is(err, ErrMusicPlaybackNotFound) {
+		t.Fatalf("unknown row: err = %v, want ErrMusicPlaybackNotFound", err)
+	}
+}
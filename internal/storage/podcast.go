@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/keshon/datastore"
+)
+
+// resumeKey hashes the URL: enclosure links carry long tracking query strings,
+// and the key only has to tell one episode from another within the guild.
+func resumeKey(guildID, url string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(url)))
+	return guildID + ":" + hex.EncodeToString(sum[:16])
+}
+
+// SavePodcastFeed remembers url under feedKey. Saving again refreshes SeenAt.
+func (s *Storage) SavePodcastFeed(feedKey, url string, at time.Time) error {
+	return s.feeds.Put(&PodcastFeed{FeedKey: feedKey, URL: strings.TrimSpace(url), SeenAt: at})
+}
+
+// PodcastFeedURL returns the feed URL saved under feedKey.
+func (s *Storage) PodcastFeedURL(feedKey string) (string, bool) {
+	f, ok := s.feeds.Get(feedKey)
+	if !ok {
+		return "", false
+	}
+	return f.URL, true
+}
+
+// SaveResumePosition records how far the guild got into url. When the guild
+// is at resumePositionLimit, the position updated longest ago makes room.
+func (s *Storage) SaveResumePosition(guildID, url string, pos time.Duration, at time.Time) error {
+	row := &ResumePosition{GuildID: guildID, URL: strings.TrimSpace(url), Position: pos, UpdatedAt: at}
+	err := s.db.Update(func(tx *datastore.Tx) error {
+		col := datastore.In(tx, s.resume)
+		if _, ok := col.Get(row.Key()); !ok {
+			// Keys are hashes, so index order says nothing about age: sort.
+			existing := datastore.InIndex(tx, s.resumeByGuild).Find(guildID)
+			slices.SortFunc(existing, func(a, b *ResumePosition) int {
+				return a.UpdatedAt.Compare(b.UpdatedAt)
+			})
+			if err := trimOldest(col, existing, resumePositionLimit); err != nil {
+				return err
+			}
+		}
+		return col.Put(row)
+	})
+	if err != nil {
+		return fmt.Errorf("save resume position: %w", err)
+	}
+	return nil
+}
+
+// ResumePosition returns where the guild left off in url; zero when it has
+// not played it or finished it.
+func (s *Storage) ResumePosition(guildID, url string) time.Duration {
+	r, ok := s.resume.Get(resumeKey(guildID, url))
+	if !ok {
+		return 0
+	}
+	return r.Position
+}
+
+// ClearResumePosition forgets the guild's position in url. Clearing one that
+// was never saved is not an error.
+func (s *Storage) ClearResumePosition(guildID, url string) error {
+	key := resumeKey(guildID, url)
+	return s.db.Update(func(tx *datastore.Tx) error {
+		col := datastore.In(tx, s.resume)
+		if _, ok := col.Get(key); !ok {
+			return nil
+		}
+		return col.Delete(key)
+	})
+}
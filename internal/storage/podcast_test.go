@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPodcastFeedRoundTrip(t *testing.T) {
+	s := newTestStorage(t)
+	if _, ok := s.PodcastFeedURL("abc"); ok {
+		t.Fatal("unknown key found")
+	}
+	if err := s.SavePodcastFeed("abc", " http://x/feed.xml ", time.Now()); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if url, ok := s.PodcastFeedURL("abc"); !ok || url != "http://x/feed.xml" {
+		t.Fatalf("url = %q, %v", url, ok)
+	}
+}
+
+func TestResumePositionSaveClearAndPerGuild(t *testing.T) {
+	s := newTestStorage(t)
+	at := time.Date(2026, 10, 1, 20, 0, 0, 0, time.UTC)
+
+	if err := s.SaveResumePosition("g1", "http://x/ep1.mp3", 12*time.Minute, at); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := s.SaveResumePosition("g1", "http://x/ep1.mp3", 20*time.Minute, at.Add(time.Hour)); err != nil {
+		t.Fatalf("resave: %v", err)
+	}
+	if got := s.ResumePosition("g1", "http://x/ep1.mp3"); got != 20*time.Minute {
+		t.Fatalf("position = %v, want the later save", got)
+	}
+	if got := s.ResumePosition("g2", "http://x/ep1.mp3"); got != 0 {
+		t.Fatalf("another guild sees %v", got)
+	}
+	if err := s.ClearResumePosition("g1", "http://x/ep1.mp3"); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if got := s.ResumePosition("g1", "http://x/ep1.mp3"); got != 0 {
+		t.Fatalf("after clear = %v", got)
+	}
+	if err := s.ClearResumePosition("g1", "http://x/ep1.mp3"); err != nil {
+		t.Fatalf("clearing twice: %v", err)
+	}
+}
+
+func TestResumePositionLimitDropsLeastRecentlyUpdated(t *testing.T) {
+	old := resumePositionLimit
+	resumePositionLimit = 2
+	t.Cleanup(func() { resumePositionLimit = old })
+	s := newTestStorage(t)
+	at := time.Date(2026, 10, 1, 20, 0, 0, 0, time.UTC)
+
+	_ = s.SaveResumePosition("g1", "http://x/a", time.Minute, at)
+	_ = s.SaveResumePosition("g1", "http://x/b", time.Minute, at.Add(time.Minute))
+	// Touching a keeps it; b is now the stalest.
+	_ = s.SaveResumePosition("g1", "http://x/a", 2*time.Minute, at.Add(2*time.Minute))
+	if err := s.SaveResumePosition("g1", "http://x/c", time.Minute, at.Add(3*time.Minute)); err != nil {
+		t.Fatalf("save over the limit: %v", err)
+	}
+	if s.ResumePosition("g1", "http://x/b") != 0 {
+		t.Fatal("the least recently updated position should have been dropped")
+	}
+	if s.ResumePosition("g1", "http://x/a") != 2*time.Minute || s.ResumePosition("g1", "http://x/c") != time.Minute {
+		t.Fatal("the two recent positions should remain")
+	}
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/keshon/datastore"
+)
+
+var (
+	// ErrFavouriteStationNotFound is returned when the guild has no station saved under the name.
+	ErrFavouriteStationNotFound = errors.New("favourite station not found")
+	// ErrFavouriteStationLimit is returned when saving a new name would exceed favouriteStationLimit.
+	ErrFavouriteStationLimit = errors.New("too many favourite stations")
+	// ErrFavouriteStationName is returned for a blank name.
+	ErrFavouriteStationName = errors.New("favourite station name is empty")
+)
+
+// favouriteStationKey folds the name so "Jazz FM" and "jazz fm" are one
+// favourite. Runs of whitespace count as one space for the same reason.
+func favouriteStationKey(guildID, name string) string {
+	return guildID + ":" + strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// SaveFavouriteStation stores url under name for the guild, replacing the
+// station already saved under that name if there is one; replaced reports
+// which happened. The name is stored as given, so lists show it as typed.
+func (s *Storage) SaveFavouriteStation(guildID, name, url, savedBy string, at time.Time) (replaced bool, err error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" {
+		return false, ErrFavouriteStationName
+	}
+	fav := &FavouriteStation{
+		GuildID: guildID,
+		Name:    name,
+		URL:     strings.TrimSpace(url),
+		SavedBy: savedBy,
+		SavedAt: at,
+	}
+	err = s.db.Update(func(tx *datastore.Tx) error {
+		col := datastore.In(tx, s.stations)
+		_, replaced = col.Get(fav.Key())
+		if !replaced && datastore.InIndex(tx, s.stationsByGuild).Count(guildID) >= favouriteStationLimit {
+			return ErrFavouriteStationLimit
+		}
+		return col.Put(fav)
+	})
+	if err != nil {
+		return false, fmt.Errorf("save favourite station: %w", err)
+	}
+	return replaced, nil
+}
+
+// FavouriteStation returns the station the guild saved under name, matched
+// regardless of case.
+func (s *Storage) FavouriteStation(guildID, name string) (FavouriteStation, error) {
+	fav, ok := s.stations.Get(favouriteStationKey(guildID, name))
+	if !ok {
+		return FavouriteStation{}, ErrFavouriteStationNotFound
+	}
+	return *fav, nil
+}
+
+// ListFavouriteStations returns the guild's saved stations in name order.
+func (s *Storage) ListFavouriteStations(guildID string) ([]FavouriteStation, error) {
+	rows := s.stationsByGuild.Find(guildID)
+	out := make([]FavouriteStation, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, *r)
+	}
+	return out, nil
+}
+
+// RemoveFavouriteStation deletes the station the guild saved under name.
+func (s *Storage) RemoveFavouriteStation(guildID, name string) error {
+	key := favouriteStationKey(guildID, name)
+	return s.db.Update(func(tx *datastore.Tx) error {
+		col := datastore.In(tx, s.stations)
+		if _, ok := col.Get(key); !ok {
+			return ErrFavouriteStationNotFound
+		}
+		return col.Delete(key)
+	})
+}
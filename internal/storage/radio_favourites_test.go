@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFavouriteStationsSaveFindListRemove(t *testing.T) {
+	s := newTestStorage(t)
+	at := time.Date(2026, 10, 1, 20, 0, 0, 0, time.UTC)
+
+	if replaced, err := s.SaveFavouriteStation("g1", "  Jazz   FM ", "http://x/jazz.pls", "u1", at); err != nil || replaced {
+		t.Fatalf("save: replaced=%v err=%v", replaced, err)
+	}
+	if _, err := s.SaveFavouriteStation("g1", "ambient", "http://x/ambient", "u1", at); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if _, err := s.SaveFavouriteStation("g2", "jazz fm", "http://y/other", "u2", at); err != nil {
+		t.Fatalf("save other guild: %v", err)
+	}
+
+	fav, err := s.FavouriteStation("g1", "JAZZ fm")
+	if err != nil || fav.Name != "Jazz FM" || fav.URL != "http://x/jazz.pls" {
+		t.Fatalf("lookup ignoring case = %+v, %v", fav, err)
+	}
+
+	// Saving under the same name again moves it, not duplicates it.
+	replaced, err := s.SaveFavouriteStation("g1", "jazz fm", "http://x/jazz2", "u3", at)
+	if err != nil || !replaced {
+		t.Fatalf("resave: replaced=%v err=%v", replaced, err)
+	}
+	list, err := s.ListFavouriteStations("g1")
+	if err != nil || len(list) != 2 {
+		t.Fatalf("list = %+v, %v", list, err)
+	}
+	if list[0].Name != "ambient" || list[1].URL != "http://x/jazz2" {
+		t.Fatalf("list not in name order or not replaced: %+v", list)
+	}
+
+	if err := s.RemoveFavouriteStation("g1", "Jazz FM"); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if _, err := s.FavouriteStation("g1", "jazz fm"); !errors.Is(err, ErrFavouriteStationNotFound) {
+		t.Fatalf("after remove err = %v", err)
+	}
+	if err := s.RemoveFavouriteStation("g1", "jazz fm"); !errors.Is(err, ErrFavouriteStationNotFound) {
+		t.Fatalf("second remove err = %v", err)
+	}
+	if fav, err := s.FavouriteStation("g2", "Jazz FM"); err != nil || fav.URL != "http://y/other" {
+		t.Fatalf("another guild's favourite was touched: %+v, %v", fav, err)
+	}
+}
+
+func TestFavouriteStationLimitAndBlankName(t *testing.T) {
+	old := favouriteStationLimit
+	favouriteStationLimit = 2
+	t.Cleanup(func() { favouriteStationLimit = old })
+	s := newTestStorage(t)
+	at := time.Now()
+
+	if _, err := s.SaveFavouriteStation("g1", " ", "http://x/a", "u", at); !errors.Is(err, ErrFavouriteStationName) {
+		t.Fatalf("blank name err = %v", err)
+	}
+	for _, name := range []string{"a", "b"} {
+		if _, err := s.SaveFavouriteStation("g1", name, "http://x/"+name, "u", at); err != nil {
+			t.Fatalf("save %s: %v", name, err)
+		}
+	}
+	if _, err := s.SaveFavouriteStation("g1", "c", "http://x/c", "u", at); !errors.Is(err, ErrFavouriteStationLimit) {
+		t.Fatalf("over the limit err = %v", err)
+	}
+	// Replacing an existing name is not a new favourite, so the cap allows it.
+	if _, err := s.SaveFavouriteStation("g1", "A", "http://x/a2", "u", at); err != nil {
+		t.Fatalf("replace at the limit: %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/keshon/melodix/pkg/music/cache"
+	"github.com/keshon/melodix/pkg/music/library"
 )
 
 // Persisted record types. Each satisfies datastore.Entity via Key(), which is
@@ -19,10 +20,16 @@ func guildRowKey(guildID string, id uint64) string {
 	return fmt.Sprintf("%s:%020d", guildID, id)
 }
 
-// GuildSettings holds per-guild configuration (currently disabled command groups).
+// GuildSettings holds per-guild configuration: disabled command groups and
+// how /play picks a source.
 type GuildSettings struct {
 	GuildID          string   `json:"guild_id"`
 	CommandsDisabled []string `json:"commands_disabled"`
+	// SearchSource takes the guild's bare queries; empty means YouTube.
+	SearchSource string `json:"search_source,omitempty"`
+	// SourcePrecedence names the sources URL auto-detect asks first; empty
+	// keeps the built-in order.
+	SourcePrecedence []string `json:"source_precedence,omitempty"`
 }
 
 func (g *GuildSettings) Key() string { return g.GuildID }
@@ -53,10 +60,48 @@ type PlaybackEntry struct {
 	CurrentParser    string    `json:"current_parser"`
 	AvailableParsers []string  `json:"available_parsers"`
 	SourceName       string    `json:"source_name"`
+	// Heard lists the songs a live stream announced while it played, oldest
+	// first; empty for anything that is not radio.
+	Heard []string `json:"heard,omitempty"`
 }
 
 func (p *PlaybackEntry) Key() string { return guildRowKey(p.GuildID, p.ID) }
 
+// FavouriteStation is a radio station a guild saved under a name of its own.
+// It keys on the folded name rather than a numbered id, so a name is unique per
+// guild regardless of case, and an index read lists a guild's stations in
+// alphabetical order.
+type FavouriteStation struct {
+	GuildID string    `json:"guild_id"`
+	Name    string    `json:"name"`
+	URL     string    `json:"url"`
+	SavedBy string    `json:"saved_by"`
+	SavedAt time.Time `json:"saved_at"`
+}
+
+func (f *FavouriteStation) Key() string { return favouriteStationKey(f.GuildID, f.Name) }
+
+// PodcastFeed remembers a feed URL under its podcast.FeedKey, so an episode
+// button built from the short key can find the feed again after a restart.
+type PodcastFeed struct {
+	FeedKey string    `json:"feed_key"`
+	URL     string    `json:"url"`
+	SeenAt  time.Time `json:"seen_at"`
+}
+
+func (p *PodcastFeed) Key() string { return p.FeedKey }
+
+// ResumePosition is how far a guild got into a resumable track (a podcast
+// episode), keyed on the guild and a hash of the track URL.
+type ResumePosition struct {
+	GuildID   string        `json:"guild_id"`
+	URL       string        `json:"url"`
+	Position  time.Duration `json:"position"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+func (r *ResumePosition) Key() string { return resumeKey(r.GuildID, r.URL) }
+
 // CacheEntry persists one track-cache index row. It embeds the cache package's
 // own record so the two never drift; cache.Entry names its content key ID
 // precisely so this Key() method can exist.
@@ -65,3 +110,12 @@ type CacheEntry struct {
 }
 
 func (c *CacheEntry) Key() string { return c.ID }
+
+// LocalTrackEntry persists one local library index row, embedding the library
+// package's record for the same reason CacheEntry embeds cache.Entry. Keeping
+// it means a restart serves search from the last scan while the next one runs.
+type LocalTrackEntry struct {
+	library.Entry
+}
+
+func (l *LocalTrackEntry) Key() string { return l.ID }
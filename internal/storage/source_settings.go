@@ -0,0 +1,32 @@
+package storage
+
+import "strings"
+
+// SetSearchSource makes source the one the guild's bare queries go to (""
+// restores YouTube). The name is not checked here: the resolver ignores one
+// it does not know.
+func (s *Storage) SetSearchSource(guildID, source string) error {
+	g := s.guildSettings(guildID)
+	g.SearchSource = strings.TrimSpace(source)
+	return s.settings.Put(g)
+}
+
+// SetSourcePrecedence stores the sources URL auto-detect asks first for the
+// guild, in order (empty restores the built-in order).
+func (s *Storage) SetSourcePrecedence(guildID string, names []string) error {
+	g := s.guildSettings(guildID)
+	g.SourcePrecedence = nil
+	for _, n := range names {
+		if n = strings.TrimSpace(n); n != "" {
+			g.SourcePrecedence = append(g.SourcePrecedence, n)
+		}
+	}
+	return s.settings.Put(g)
+}
+
+// SourcePrefs returns the guild's search source and URL precedence, both
+// empty for a guild that set neither.
+func (s *Storage) SourcePrefs(guildID string) (searchSource string, precedence []string) {
+	g := s.guildSettings(guildID)
+	return g.SearchSource, g.SourcePrecedence
+}
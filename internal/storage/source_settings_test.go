@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSourcePrefsRoundTrip(t *testing.T) {
+	s := newTestStorage(t)
+	if search, order := s.SourcePrefs("g"); search != "" || order != nil {
+		t.Fatalf("fresh guild prefs = %q, %q; want none", search, order)
+	}
+
+	if err := s.SetSearchSource("g", " soundcloud "); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetSourcePrecedence("g", []string{"soundcloud", " ", "youtube"}); err != nil {
+		t.Fatal(err)
+	}
+	// Settings share a row with the disabled groups; neither may clobber the other.
+	if err := s.DisableGroup("g", "music"); err != nil {
+		t.Fatal(err)
+	}
+	search, order := s.SourcePrefs("g")
+	if search != "soundcloud" || !slices.Equal(order, []string{"soundcloud", "youtube"}) {
+		t.Fatalf("prefs = %q, %q", search, order)
+	}
+	if disabled, _ := s.IsGroupDisabled("g", "music"); !disabled {
+		t.Fatal("setting prefs lost the disabled group")
+	}
+	if other, _ := s.SourcePrefs("h"); other != "" {
+		t.Fatalf("another guild sees %q", other)
+	}
+
+	exp, err := s.ExportGuild("g")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp.SearchSource != "soundcloud" || len(exp.SourcePrecedence) != 2 {
+		t.Fatalf("export = %q, %q", exp.SearchSource, exp.SourcePrecedence)
+	}
+
+	if err := s.SetSearchSource("g", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetSourcePrecedence("g", nil); err != nil {
+		t.Fatal(err)
+	}
+	if search, order := s.SourcePrefs("g"); search != "" || order != nil {
+		t.Fatalf("reset prefs = %q, %q; want none", search, order)
+	}
+}
@@ -1,5 +1,6 @@
-// Package storage persists guild settings, the command log, playback history
-// and the track-cache index in an embedded write-ahead-logged datastore.
+// Package storage persists guild settings, the command log, playback history,
+// the track-cache index, the local library index, radio favourites and podcast
+// resume positions in an embedded write-ahead-logged datastore.
 package storage
 
 import (
@@ -15,6 +16,16 @@ const commandHistoryLimit = 50
 // musicPlaybackHistoryLimit is a var so tests can shrink it.
 var musicPlaybackHistoryLimit = 750
 
+// musicPlaybackHeardLimit caps the songs kept on one radio row.
+const musicPlaybackHeardLimit = 100
+
+// favouriteStationLimit caps a guild's saved stations. A var so tests can shrink it.
+var favouriteStationLimit = 100
+
+// resumePositionLimit caps a guild's remembered positions, dropping the least
+// recently updated. A var so tests can shrink it.
+var resumePositionLimit = 200
+
 // Storage owns the database and the collections declared on it. Every
 // collection and index must be registered before Open, so construction is the
 // only place the schema is described.
@@ -26,9 +37,15 @@ type Storage struct {
 	cmdLog   *datastore.Collection[*CommandLogEntry]
 	playback *datastore.Collection[*PlaybackEntry]
 	cacheIdx *datastore.Collection[*CacheEntry]
+	localIdx *datastore.Collection[*LocalTrackEntry]
+	stations *datastore.Collection[*FavouriteStation]
+	feeds    *datastore.Collection[*PodcastFeed]
+	resume   *datastore.Collection[*ResumePosition]
 
 	cmdLogByGuild   *datastore.Index[*CommandLogEntry]
 	playbackByGuild *datastore.Index[*PlaybackEntry]
+	stationsByGuild *datastore.Index[*FavouriteStation]
+	resumeByGuild   *datastore.Index[*ResumePosition]
 }
 
 // NewStorage opens the database in dir, creating it if needed. The directory is
@@ -42,11 +59,19 @@ func NewStorage(dir string, log zerolog.Logger) (*Storage, error) {
 	s.cmdLog = datastore.Register[*CommandLogEntry](db, "command_log")
 	s.playback = datastore.Register[*PlaybackEntry](db, "playback")
 	s.cacheIdx = datastore.Register[*CacheEntry](db, "cache_entries")
+	s.localIdx = datastore.Register[*LocalTrackEntry](db, "local_tracks")
+	s.stations = datastore.Register[*FavouriteStation](db, "radio_favourites")
+	s.feeds = datastore.Register[*PodcastFeed](db, "podcast_feeds")
+	s.resume = datastore.Register[*ResumePosition](db, "resume_positions")
 
 	s.cmdLogByGuild = datastore.AddIndex(s.cmdLog, "guild",
 		func(c *CommandLogEntry) []string { return []string{c.GuildID} })
 	s.playbackByGuild = datastore.AddIndex(s.playback, "guild",
 		func(p *PlaybackEntry) []string { return []string{p.GuildID} })
+	s.stationsByGuild = datastore.AddIndex(s.stations, "guild",
+		func(f *FavouriteStation) []string { return []string{f.GuildID} })
+	s.resumeByGuild = datastore.AddIndex(s.resume, "guild",
+		func(r *ResumePosition) []string { return []string{r.GuildID} })
 
 	if err := db.Open(); err != nil {
 		return nil, err
@@ -125,10 +150,14 @@ func (s *Storage) CommandHistory(guildID string) ([]CommandLogEntry, error) {
 
 // GuildExport is the shape served by the maintenance database dump.
 type GuildExport struct {
-	GuildID          string            `json:"guild_id"`
-	CommandsDisabled []string          `json:"commands_disabled"`
-	CommandsHistory  []CommandLogEntry `json:"commands_history"`
-	PlaybackHistory  []PlaybackEntry   `json:"playback_history"`
+	GuildID          string             `json:"guild_id"`
+	CommandsDisabled []string           `json:"commands_disabled"`
+	CommandsHistory  []CommandLogEntry  `json:"commands_history"`
+	PlaybackHistory  []PlaybackEntry    `json:"playback_history"`
+	RadioFavourites  []FavouriteStation `json:"radio_favourites"`
+	ResumePositions  []ResumePosition   `json:"resume_positions"`
+	SearchSource     string             `json:"search_source,omitempty"`
+	SourcePrecedence []string           `json:"source_precedence,omitempty"`
 }
 
 // ExportGuild gathers everything stored for one guild.
@@ -141,10 +170,24 @@ func (s *Storage) ExportGuild(guildID string) (GuildExport, error) {
 	if err != nil {
 		return GuildExport{}, err
 	}
+	stations, err := s.ListFavouriteStations(guildID)
+	if err != nil {
+		return GuildExport{}, err
+	}
+	resume := s.resumeByGuild.Find(guildID)
+	positions := make([]ResumePosition, 0, len(resume))
+	for _, r := range resume {
+		positions = append(positions, *r)
+	}
+	settings := s.guildSettings(guildID)
 	return GuildExport{
 		GuildID:          guildID,
-		CommandsDisabled: s.guildSettings(guildID).CommandsDisabled,
+		CommandsDisabled: settings.CommandsDisabled,
 		CommandsHistory:  cmds,
 		PlaybackHistory:  plays,
+		RadioFavourites:  stations,
+		ResumePositions:  positions,
+		SearchSource:     settings.SearchSource,
+		SourcePrecedence: settings.SourcePrecedence,
 	}, nil
 }
@@ -0,0 +1,789 @@
+// Package audiotag reads titles, artists, albums and durations from audio
+// container headers. It serves the local library (files on disk) and the
+// direct source (files behind a URL, read with ranged requests), which is why
+// everything here works on an io.ReaderAt and asks for as few bytes as it can.
+package audiotag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// Tag reading is hand-rolled, like the WebM demuxer in pkg/music/opus: four
+// small readers (ID3, Vorbis comments, MP4 atoms, EBML) cover every format
+// listed below, and each reads only headers — seeking past pictures and audio
+// — so probing a file costs a few KB of I/O, and a remote one a handful of
+// ranged requests. Everything here is best effort: a reader that gives up
+// leaves fields empty, and callers fall back to the file name.
+
+// Format is a container family.
+type Format int
+
+const (
+	MP3  Format = iota + 1
+	Ogg         // Vorbis or Opus; only the header says which
+	Opus        // Ogg Opus by extension
+	FLAC
+	MP4
+	WAV
+	WebM
+)
+
+var byExt = map[string]Format{
+	".mp3":  MP3,
+	".ogg":  Ogg,
+	".oga":  Ogg,
+	".opus": Opus,
+	".flac": FLAC,
+	".m4a":  MP4,
+	".mp4":  MP4,
+	".wav":  WAV,
+	".webm": WebM,
+}
+
+// FormatOf picks the format from a file name or URL path's extension.
+func FormatOf(name string) (Format, bool) {
+	f, ok := byExt[strings.ToLower(path.Ext(name))]
+	return f, ok
+}
+
+// MayHoldOpus reports whether the container can carry Opus the engine
+// forwards without ffmpeg. An Ogg file is as likely to be Vorbis; the demuxer
+// decides at open time, so this is a reason to try, not a promise.
+func (f Format) MayHoldOpus() bool {
+	return f == Opus || f == Ogg || f == WebM
+}
+
+// EstimateDuration guesses a length from the file size at the highest common
+// bitrate for the format, so the guess errs short: 320 kbit/s for the lossy
+// ones, and CD PCM (1411 kbit/s) for WAV and FLAC, which a CD-quality FLAC
+// compresses below. Short is the safe side: RecoveryStream treats an end past
+// 95% of the duration as natural, so an underestimate can only make a real end
+// look natural, while an overestimate would make it look like a dropout and
+// reopen the file. Lossless files usually record their length anyway (WAV's
+// byte rate, FLAC's STREAMINFO), so this is rarely their only source.
+func EstimateDuration(size int64, f Format) time.Duration {
+	bytesPerSec := int64(320 * 1000 / 8)
+	if f == FLAC || f == WAV {
+		bytesPerSec = 44100 * 2 * 2
+	}
+	d := time.Duration(size) * time.Second / time.Duration(bytesPerSec)
+	return max(d, time.Second)
+}
+
+// maxTextField bounds one tag value; anything longer is a picture or junk
+// stored under a text key, not a title.
+const maxTextField = 64 << 10
+
+// Tags is what a file says about itself. Empty fields were not found;
+// a zero Duration means the container did not record one.
+type Tags struct {
+	Title, Artist, Album string
+	Duration             time.Duration
+}
+
+// Read reads the tags of a size-byte file in format f.
+func Read(r io.ReaderAt, size int64, f Format) (Tags, error) {
+	var t tags
+	var err error
+	switch f {
+	case MP3:
+		err = readMP3(r, size, &t)
+	case Ogg, Opus:
+		err = readOgg(r, size, &t)
+	case FLAC:
+		err = readFLAC(r, &t)
+	case MP4:
+		err = readMP4(r, 0, size, 0, &t)
+	case WAV:
+		err = readWAV(r, &t)
+	case WebM:
+		err = readWebM(r, &t)
+	default:
+		err = fmt.Errorf("audiotag: unsupported format %d", f)
+	}
+	return Tags{
+		Title:    strings.TrimSpace(t.title),
+		Artist:   strings.TrimSpace(t.artist),
+		Album:    strings.TrimSpace(t.album),
+		Duration: t.duration,
+	}, err
+}
+
+type tags struct {
+	title, artist, album string
+	duration             time.Duration
+}
+
+// --- ID3 / MP3 ---
+
+func readMP3(f io.ReaderAt, size int64, t *tags) error {
+	var hdr [10]byte
+	audioStart := int64(0)
+	if _, err := f.ReadAt(hdr[:], 0); err == nil && string(hdr[:3]) == "ID3" {
+		tagSize := int64(syncsafe(hdr[6:10]))
+		if err := readID3v2(f, hdr[3], hdr[5], tagSize, t); err != nil {
+			return err
+		}
+		audioStart = 10 + tagSize
+		if hdr[5]&0x10 != 0 {
+			audioStart += 10 // footer
+		}
+	}
+	end := size
+	if v1, ok := readID3v1(f, size); ok {
+		end -= 128
+		if t.title == "" {
+			t.title, t.artist, t.album = v1.title, v1.artist, v1.album
+		}
+	}
+	if t.duration == 0 {
+		t.duration = mp3Duration(f, audioStart, end)
+	}
+	return nil
+}
+
+// readID3v2 reads the text frames out of an ID3v2.2/2.3/2.4 tag, seeking past
+// everything else (attached pictures run to megabytes).
+func readID3v2(f io.ReaderAt, major, flags byte, tagSize int64, t *tags) error {
+	end := 10 + tagSize
+	pos := int64(10)
+	if flags&0x40 != 0 && major >= 3 { // extended header
+		var b [4]byte
+		if _, err := f.ReadAt(b[:], pos); err != nil {
+			return err
+		}
+		n := int64(binary.BigEndian.Uint32(b[:]))
+		if major == 3 {
+			n += 4 // v2.3 excludes its own size field
+		} else {
+			n = int64(syncsafe(b[:]))
+		}
+		pos += n
+	}
+	idLen, hdrLen := 4, 10
+	if major == 2 {
+		idLen, hdrLen = 3, 6
+	}
+	// v2.3 unsynchronises the whole tag; v2.4 flags it per frame.
+	tagUnsync := flags&0x80 != 0 && major < 4
+	for pos+int64(hdrLen) <= end {
+		fh := make([]byte, hdrLen)
+		if _, err := f.ReadAt(fh, pos); err != nil {
+			return err
+		}
+		if fh[0] == 0 {
+			break // padding
+		}
+		id := string(fh[:idLen])
+		var n int64
+		var frameUnsync bool
+		switch major {
+		case 2:
+			n = int64(fh[3])<<16 | int64(fh[4])<<8 | int64(fh[5])
+		case 3:
+			n = int64(binary.BigEndian.Uint32(fh[4:8]))
+		default:
+			n = int64(syncsafe(fh[4:8]))
+			frameUnsync = fh[9]&0x02 != 0
+		}
+		pos += int64(hdrLen)
+		if n <= 0 || pos+n > end {
+			break
+		}
+		field := id3Field(id)
+		if field != nil && n <= maxTextField {
+			body := make([]byte, n)
+			if _, err := f.ReadAt(body, pos); err != nil {
+				return err
+			}
+			if tagUnsync || frameUnsync {
+				body = bytes.ReplaceAll(body, []byte{0xFF, 0x00}, []byte{0xFF})
+			}
+			field(t, decodeID3Text(body))
+		}
+		pos += n
+	}
+	return nil
+}
+
+// id3Field maps a frame id to the tag field it fills, or nil to skip it.
+func id3Field(id string) func(*tags, string) {
+	switch id {
+	case "TIT2", "TT2":
+		return func(t *tags, v string) { t.title = v }
+	case "TPE1", "TP1":
+		return func(t *tags, v string) { t.artist = v }
+	case "TALB", "TAL":
+		return func(t *tags, v string) { t.album = v }
+	case "TLEN", "TLE":
+		return func(t *tags, v string) {
+			var ms int64
+			if _, err := fmt.Sscan(v, &ms); err == nil && ms > 0 {
+				t.duration = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	return nil
+}
+
+// decodeID3Text decodes a text frame body: an encoding byte, then the text.
+// Multi-value frames (v2.4 NUL-separated) keep their first value.
+func decodeID3Text(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	enc, b := b[0], b[1:]
+	switch enc {
+	case 1, 2: // UTF-16 with BOM, UTF-16BE
+		bigEndian := enc == 2
+		if len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF {
+			bigEndian, b = true, b[2:]
+		} else if len(b) >= 2 && b[0] == 0xFF && b[1] == 0xFE {
+			bigEndian, b = false, b[2:]
+		}
+		u := make([]uint16, 0, len(b)/2)
+		for i := 0; i+1 < len(b); i += 2 {
+			var c uint16
+			if bigEndian {
+				c = binary.BigEndian.Uint16(b[i:])
+			} else {
+				c = binary.LittleEndian.Uint16(b[i:])
+			}
+			if c == 0 {
+				break
+			}
+			u = append(u, c)
+		}
+		return string(utf16.Decode(u))
+	case 3: // UTF-8
+		if i := bytes.IndexByte(b, 0); i >= 0 {
+			b = b[:i]
+		}
+		return string(b)
+	default: // ISO-8859-1
+		if i := bytes.IndexByte(b, 0); i >= 0 {
+			b = b[:i]
+		}
+		return latin1(b)
+	}
+}
+
+func readID3v1(f io.ReaderAt, size int64) (tags, bool) {
+	if size < 128 {
+		return tags{}, false
+	}
+	b := make([]byte, 128)
+	if _, err := f.ReadAt(b, size-128); err != nil || string(b[:3]) != "TAG" {
+		return tags{}, false
+	}
+	field := func(b []byte) string {
+		if i := bytes.IndexByte(b, 0); i >= 0 {
+			b = b[:i]
+		}
+		return strings.TrimSpace(latin1(b))
+	}
+	return tags{title: field(b[3:33]), artist: field(b[33:63]), album: field(b[63:93])}, true
+}
+
+// mp3Duration reads the first MPEG audio frame: a Xing/Info header gives the
+// exact frame count (VBR files carry one); otherwise the first frame's bitrate
+// is taken as constant. Only Layer III is understood; anything else returns 0
+// and the caller falls back to the size estimate.
+func mp3Duration(f io.ReaderAt, start, end int64) time.Duration {
+	buf := make([]byte, 4096)
+	n, _ := f.ReadAt(buf, start)
+	buf = buf[:n]
+	i := 0
+	for ; i+4 <= len(buf); i++ {
+		if buf[i] == 0xFF && buf[i+1]&0xE0 == 0xE0 {
+			break
+		}
+	}
+	if i+4 > len(buf) {
+		return 0
+	}
+	h := buf[i:]
+	version := (h[1] >> 3) & 0x03 // 3 = MPEG1, 2 = MPEG2, 0 = MPEG2.5
+	layer := (h[1] >> 1) & 0x03   // 1 = Layer III
+	if layer != 1 || version == 1 {
+		return 0
+	}
+	brIdx, srIdx := int(h[2]>>4), int((h[2]>>2)&0x03)
+	if brIdx == 0 || brIdx == 15 || srIdx == 3 {
+		return 0
+	}
+	mpeg1 := version == 3
+	rate := [3]int{44100, 48000, 32000}[srIdx]
+	samplesPerFrame := 1152
+	bitrates := [15]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320}
+	if !mpeg1 {
+		rate /= 2
+		if version == 0 {
+			rate /= 2
+		}
+		samplesPerFrame = 576
+		bitrates = [15]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160}
+	}
+	mono := h[3]>>6 == 3
+	side := 32
+	switch {
+	case mpeg1 && mono, !mpeg1 && !mono:
+		side = 17
+	case !mpeg1 && mono:
+		side = 9
+	}
+	if x := 4 + side; len(h) >= x+12 {
+		tag := string(h[x : x+4])
+		if (tag == "Xing" || tag == "Info") && binary.BigEndian.Uint32(h[x+4:])&0x01 != 0 {
+			frames := int64(binary.BigEndian.Uint32(h[x+8:]))
+			return time.Duration(frames*int64(samplesPerFrame)) * time.Second / time.Duration(rate)
+		}
+	}
+	bytesPerSec := int64(bitrates[brIdx]) * 1000 / 8
+	audio := end - start - int64(i)
+	if audio <= 0 {
+		return 0
+	}
+	return time.Duration(audio) * time.Second / time.Duration(bytesPerSec)
+}
+
+func syncsafe(b []byte) uint32 {
+	return uint32(b[0]&0x7F)<<21 | uint32(b[1]&0x7F)<<14 | uint32(b[2]&0x7F)<<7 | uint32(b[3]&0x7F)
+}
+
+func latin1(b []byte) string {
+	r := make([]rune, len(b))
+	for i, c := range b {
+		r[i] = rune(c)
+	}
+	return string(r)
+}
+
+// --- Vorbis comments (Ogg, FLAC) ---
+
+// parseVorbisComment reads a comment block (after any codec magic). It
+// tolerates truncation, since the caller may have cut a block that carries
+// cover art; fields before the cut are kept.
+func parseVorbisComment(b []byte, t *tags) {
+	u32 := func() (uint32, bool) {
+		if len(b) < 4 {
+			return 0, false
+		}
+		v := binary.LittleEndian.Uint32(b)
+		b = b[4:]
+		return v, true
+	}
+	vendor, ok := u32()
+	if !ok || int64(vendor) > int64(len(b)) {
+		return
+	}
+	b = b[vendor:]
+	count, ok := u32()
+	if !ok {
+		return
+	}
+	for range count {
+		n, ok := u32()
+		if !ok || int64(n) > int64(len(b)) {
+			return
+		}
+		kv := string(b[:n])
+		b = b[n:]
+		k, v, found := strings.Cut(kv, "=")
+		if !found {
+			continue
+		}
+		switch strings.ToUpper(k) {
+		case "TITLE":
+			t.title = v
+		case "ARTIST":
+			if t.artist == "" {
+				t.artist = v
+			}
+		case "ALBUM":
+			t.album = v
+		}
+	}
+}
+
+// maxOggHeaderBytes bounds how far into an Ogg file the header packets are
+// looked for. The comment packet can embed cover art, and a title is worth
+// reading a few MB for, but not an unbounded amount.
+const maxOggHeaderBytes = 4 << 20
+
+func readOgg(f io.ReaderAt, size int64, t *tags) error {
+	var packets [][]byte
+	var cur []byte
+	pos := int64(0)
+	for len(packets) < 2 && pos < min(size, maxOggHeaderBytes) {
+		segs, bodyLen, err := oggPageHeader(f, pos)
+		if err != nil {
+			break
+		}
+		body := make([]byte, bodyLen)
+		if _, err := f.ReadAt(body, pos+27+int64(len(segs))); err != nil {
+			break
+		}
+		for _, s := range segs {
+			cur = append(cur, body[:s]...)
+			body = body[s:]
+			if s < 255 {
+				packets = append(packets, cur)
+				cur = nil
+			}
+		}
+		pos += 27 + int64(len(segs)) + int64(bodyLen)
+	}
+	if len(cur) > 0 && len(packets) < 2 {
+		packets = append(packets, cur) // a truncated comment packet still has its head
+	}
+	if len(packets) == 0 {
+		return errors.New("audiotag: no ogg packets")
+	}
+
+	var rate, preSkip int64
+	head := packets[0]
+	switch {
+	case bytes.HasPrefix(head, []byte("OpusHead")) && len(head) >= 19:
+		rate, preSkip = 48000, int64(binary.LittleEndian.Uint16(head[10:]))
+		if len(packets) > 1 && bytes.HasPrefix(packets[1], []byte("OpusTags")) {
+			parseVorbisComment(packets[1][8:], t)
+		}
+	case bytes.HasPrefix(head, []byte("\x01vorbis")) && len(head) >= 16:
+		rate = int64(binary.LittleEndian.Uint32(head[12:]))
+		if len(packets) > 1 && bytes.HasPrefix(packets[1], []byte("\x03vorbis")) {
+			parseVorbisComment(packets[1][7:], t)
+		}
+	default:
+		return errors.New("audiotag: unknown ogg codec")
+	}
+	if g := lastOggGranule(f, size); g > preSkip && rate > 0 {
+		t.duration = time.Duration(g-preSkip) * time.Second / time.Duration(rate)
+	}
+	return nil
+}
+
+func oggPageHeader(f io.ReaderAt, pos int64) (segs []byte, bodyLen int, err error) {
+	var hdr [27]byte
+	if _, err := f.ReadAt(hdr[:], pos); err != nil {
+		return nil, 0, err
+	}
+	if string(hdr[:4]) != "OggS" {
+		return nil, 0, errors.New("audiotag: bad ogg page")
+	}
+	segs = make([]byte, hdr[26])
+	if _, err := f.ReadAt(segs, pos+27); err != nil {
+		return nil, 0, err
+	}
+	for _, s := range segs {
+		bodyLen += int(s)
+	}
+	return segs, bodyLen, nil
+}
+
+// lastOggGranule finds the last page's granule position in the file's tail.
+// A page is at most ~64 KB, so the final one starts within that distance.
+func lastOggGranule(f io.ReaderAt, size int64) int64 {
+	n := min(size, 65536+27)
+	tail := make([]byte, n)
+	if _, err := f.ReadAt(tail, size-n); err != nil {
+		return 0
+	}
+	for i := bytes.LastIndex(tail, []byte("OggS")); i >= 0; i = bytes.LastIndex(tail[:i], []byte("OggS")) {
+		if i+14 <= len(tail) {
+			if g := int64(binary.LittleEndian.Uint64(tail[i+6:])); g > 0 {
+				return g
+			}
+		}
+	}
+	return 0
+}
+
+func readFLAC(f io.ReaderAt, t *tags) error {
+	var magic [4]byte
+	if _, err := f.ReadAt(magic[:], 0); err != nil || string(magic[:]) != "fLaC" {
+		return errors.New("audiotag: not a flac file")
+	}
+	pos := int64(4)
+	for {
+		var bh [4]byte
+		if _, err := f.ReadAt(bh[:], pos); err != nil {
+			return err
+		}
+		last, typ := bh[0]&0x80 != 0, bh[0]&0x7F
+		n := int64(bh[1])<<16 | int64(bh[2])<<8 | int64(bh[3])
+		pos += 4
+		switch typ {
+		case 0: // STREAMINFO
+			if n >= 18 {
+				si := make([]byte, 18)
+				if _, err := f.ReadAt(si, pos); err != nil {
+					return err
+				}
+				rate := int64(si[10])<<12 | int64(si[11])<<4 | int64(si[12])>>4
+				total := int64(si[13]&0x0F)<<32 | int64(binary.BigEndian.Uint32(si[14:18]))
+				if rate > 0 && total > 0 {
+					t.duration = time.Duration(total) * time.Second / time.Duration(rate)
+				}
+			}
+		case 4: // VORBIS_COMMENT
+			b := make([]byte, min(n, maxOggHeaderBytes))
+			if _, err := f.ReadAt(b, pos); err != nil {
+				return err
+			}
+			parseVorbisComment(b, t)
+		}
+		pos += n
+		if last {
+			return nil
+		}
+	}
+}
+
+// --- MP4 atoms ---
+
+// readMP4 walks the atom tree between start and end, descending only into the
+// containers that lead to metadata (moov/udta/meta/ilst) and the movie header.
+func readMP4(f io.ReaderAt, start, end int64, depth int, t *tags) error {
+	if depth > 6 {
+		return nil
+	}
+	pos := start
+	for pos+8 <= end {
+		var h [8]byte
+		if _, err := f.ReadAt(h[:], pos); err != nil {
+			return err
+		}
+		size := int64(binary.BigEndian.Uint32(h[:4]))
+		typ := string(h[4:8])
+		hdr := int64(8)
+		switch size {
+		case 0:
+			size = end - pos
+		case 1:
+			var ext [8]byte
+			if _, err := f.ReadAt(ext[:], pos+8); err != nil {
+				return err
+			}
+			size, hdr = int64(binary.BigEndian.Uint64(ext[:])), 16
+		}
+		if size < hdr || pos+size > end {
+			return nil
+		}
+		body, bodyEnd := pos+hdr, pos+size
+		switch typ {
+		case "moov", "udta", "ilst":
+			if err := readMP4(f, body, bodyEnd, depth+1, t); err != nil {
+				return err
+			}
+		case "meta":
+			// A full box: version and flags precede the children.
+			if err := readMP4(f, body+4, bodyEnd, depth+1, t); err != nil {
+				return err
+			}
+		case "mvhd":
+			readMVHD(f, body, t)
+		case "\xa9nam", "\xa9ART", "aART", "\xa9alb":
+			v := mp4Data(f, body, bodyEnd)
+			switch typ {
+			case "\xa9nam":
+				t.title = v
+			case "\xa9ART":
+				t.artist = v
+			case "aART":
+				if t.artist == "" {
+					t.artist = v
+				}
+			case "\xa9alb":
+				t.album = v
+			}
+		}
+		pos += size
+	}
+	return nil
+}
+
+func readMVHD(f io.ReaderAt, pos int64, t *tags) {
+	var b [32]byte
+	if _, err := f.ReadAt(b[:], pos); err != nil {
+		return
+	}
+	var scale, dur int64
+	if b[0] == 1 {
+		scale = int64(binary.BigEndian.Uint32(b[20:24]))
+		dur = int64(binary.BigEndian.Uint64(b[24:32]))
+	} else {
+		scale = int64(binary.BigEndian.Uint32(b[12:16]))
+		dur = int64(binary.BigEndian.Uint32(b[16:20]))
+	}
+	if scale > 0 && dur > 0 {
+		t.duration = time.Duration(dur) * time.Second / time.Duration(scale)
+	}
+}
+
+// mp4Data returns the UTF-8 value of the "data" child of an ilst item.
+func mp4Data(f io.ReaderAt, start, end int64) string {
+	if end-start < 16 || end-start > maxTextField {
+		return ""
+	}
+	b := make([]byte, end-start)
+	if _, err := f.ReadAt(b, start); err != nil {
+		return ""
+	}
+	size := int(binary.BigEndian.Uint32(b[:4]))
+	if string(b[4:8]) != "data" || size < 16 || size > len(b) {
+		return ""
+	}
+	return string(b[16:size]) // 8 header + 4 type + 4 locale
+}
+
+// --- WAV ---
+
+func readWAV(f io.ReaderAt, t *tags) error {
+	var h [12]byte
+	if _, err := f.ReadAt(h[:], 0); err != nil || string(h[:4]) != "RIFF" || string(h[8:12]) != "WAVE" {
+		return errors.New("audiotag: not a wav file")
+	}
+	var byteRate int64
+	pos := int64(12)
+	for {
+		var ch [8]byte
+		if _, err := f.ReadAt(ch[:], pos); err != nil {
+			return nil
+		}
+		n := int64(binary.LittleEndian.Uint32(ch[4:]))
+		switch string(ch[:4]) {
+		case "fmt ":
+			var fm [12]byte
+			if _, err := f.ReadAt(fm[:], pos+8); err == nil {
+				byteRate = int64(binary.LittleEndian.Uint32(fm[8:12]))
+			}
+		case "data":
+			if byteRate > 0 {
+				t.duration = time.Duration(n) * time.Second / time.Duration(byteRate)
+			}
+			return nil
+		}
+		pos += 8 + n + n%2 // chunks are word-aligned
+	}
+}
+
+// --- WebM (EBML) ---
+
+// readWebM walks Segment → Info for the duration and title, stopping at the
+// first Cluster: everything the library wants precedes the audio.
+func readWebM(f io.ReaderAt, t *tags) error {
+	const (
+		idSegment  = 0x18538067
+		idInfo     = 0x1549A966
+		idCluster  = 0x1F43B675
+		idScale    = 0x2AD7B1
+		idDuration = 0x4489
+		idTitle    = 0x7BA9
+	)
+	scale := int64(1000000)
+	var duration float64
+	pos := int64(0)
+	end := int64(1 << 20)
+	for pos < end {
+		id, idLen, err := readVint(f, pos, true)
+		if err != nil {
+			break
+		}
+		size, sizeLen, err := readVint(f, pos+int64(idLen), false)
+		if err != nil {
+			break
+		}
+		body := pos + int64(idLen) + int64(sizeLen)
+		switch id {
+		case idSegment, idInfo:
+			pos = body // descend
+			continue
+		case idCluster:
+			pos = end
+			continue
+		case idScale:
+			scale = int64(readUintAt(f, body, int(size)))
+		case idDuration:
+			b := make([]byte, size)
+			if _, err := f.ReadAt(b, body); err == nil {
+				switch size {
+				case 4:
+					duration = float64(math.Float32frombits(binary.BigEndian.Uint32(b)))
+				case 8:
+					duration = math.Float64frombits(binary.BigEndian.Uint64(b))
+				}
+			}
+		case idTitle:
+			if size <= maxTextField {
+				b := make([]byte, size)
+				if _, err := f.ReadAt(b, body); err == nil {
+					t.title = string(b)
+				}
+			}
+		}
+		if uint64(size) == (uint64(1)<<(7*sizeLen))-1 {
+			pos = body // unknown size: only masters use it, so descend
+			continue
+		}
+		pos = body + int64(size)
+	}
+	if duration > 0 {
+		t.duration = time.Duration(duration * float64(scale))
+	}
+	return nil
+}
+
+func readVint(f io.ReaderAt, pos int64, keepMarker bool) (uint64, int, error) {
+	var b [8]byte
+	if _, err := f.ReadAt(b[:1], pos); err != nil {
+		return 0, 0, err
+	}
+	first := b[0]
+	mask := byte(0x80)
+	length := 1
+	for length <= 8 && first&mask == 0 {
+		mask >>= 1
+		length++
+	}
+	if length > 8 {
+		return 0, 0, errors.New("audiotag: bad ebml vint")
+	}
+	if length > 1 {
+		if _, err := f.ReadAt(b[1:length], pos+1); err != nil {
+			return 0, 0, err
+		}
+	}
+	v := uint64(first)
+	if !keepMarker {
+		v = uint64(first & (mask - 1))
+	}
+	for i := 1; i < length; i++ {
+		v = v<<8 | uint64(b[i])
+	}
+	return v, length, nil
+}
+
+func readUintAt(f io.ReaderAt, pos int64, n int) uint64 {
+	if n <= 0 || n > 8 {
+		return 0
+	}
+	b := make([]byte, n)
+	if _, err := f.ReadAt(b, pos); err != nil {
+		return 0
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
@@ -0,0 +1,120 @@
+package audiotag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/opus"
+)
+
+func oggOpusFile(t *testing.T, packets int) []byte {
+	t.Helper()
+	pkt := append([]byte{31 << 3}, bytes.Repeat([]byte{0x11}, 40)...)
+	var buf bytes.Buffer
+	w := opus.NewOggWriter(&buf)
+	for range packets {
+		if err := w.WritePacket(pkt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// id3Frame builds an ID3v2.3 text frame with ISO-8859-1 encoding.
+func id3Frame(id, text string) []byte {
+	body := append([]byte{0}, text...)
+	f := append([]byte(id), 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(f[4:], uint32(len(body)))
+	return append(f, body...)
+}
+
+// mp3File is an ID3v2.3 tag followed by seconds of 128 kbit/s CBR frames
+// (only the first header is real; the duration reader needs no more).
+func mp3File(title, artist string, seconds int) []byte {
+	frames := append(id3Frame("TIT2", title), id3Frame("TPE1", artist)...)
+	n := len(frames)
+	hdr := []byte{'I', 'D', '3', 3, 0, 0,
+		byte(n >> 21 & 0x7f), byte(n >> 14 & 0x7f), byte(n >> 7 & 0x7f), byte(n & 0x7f)}
+	audio := make([]byte, seconds*128000/8)
+	copy(audio, []byte{0xFF, 0xFB, 0x90, 0x00})
+	return append(append(hdr, frames...), audio...)
+}
+
+func flacFile(title string, rate, samples int) []byte {
+	b := []byte("fLaC")
+	si := make([]byte, 18)
+	si[10] = byte(rate >> 12)
+	si[11] = byte(rate >> 4)
+	si[12] = byte(rate<<4) | 0x02 // low rate bits, then channels-1 = 1
+	binary.BigEndian.PutUint32(si[14:], uint32(samples))
+	b = append(b, 0, 0, 0, byte(len(si)))
+	b = append(b, si...)
+
+	var vc []byte
+	vc = binary.LittleEndian.AppendUint32(vc, 4)
+	vc = append(vc, "test"...)
+	vc = binary.LittleEndian.AppendUint32(vc, 1)
+	c := "TITLE=" + title
+	vc = binary.LittleEndian.AppendUint32(vc, uint32(len(c)))
+	vc = append(vc, c...)
+	b = append(b, 0x80|4, 0, 0, byte(len(vc)))
+	return append(b, vc...)
+}
+
+func TestReadTags(t *testing.T) {
+	cases := []struct {
+		file          string
+		data          []byte
+		title, artist string
+		duration      time.Duration
+	}{
+		{"a.mp3", mp3File("Song A", "Band", 3), "Song A", "Band", 3 * time.Second},
+		{"b.flac", flacFile("Song B", 44100, 441000), "Song B", "", 10 * time.Second},
+		// 250 packets of 960 samples, less OggWriter's 312-sample pre-skip.
+		{"c.opus", oggOpusFile(t, 250), "", "", time.Duration(250*960-312) * time.Second / 48000},
+	}
+	for _, tc := range cases {
+		f, ok := FormatOf(tc.file)
+		if !ok {
+			t.Fatalf("FormatOf(%q) not recognised", tc.file)
+		}
+		got, err := Read(bytes.NewReader(tc.data), int64(len(tc.data)), f)
+		if err != nil {
+			t.Errorf("%s: readTags: %v", tc.file, err)
+			continue
+		}
+		if got.Title != tc.title || got.Artist != tc.artist {
+			t.Errorf("%s: tags = %q/%q, want %q/%q", tc.file, got.Title, got.Artist, tc.title, tc.artist)
+		}
+		if d := got.Duration - tc.duration; d < -10*time.Millisecond || d > 10*time.Millisecond {
+			t.Errorf("%s: duration = %v, want %v", tc.file, got.Duration, tc.duration)
+		}
+	}
+}
+
+func TestEstimateDurationErrsShort(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		f    Format
+		// bytesPerSec is a typical real rate for the format, at or below the
+		// one the estimate assumes.
+		bytesPerSec int64
+	}{
+		{"mp3 320k", MP3, 320 * 1000 / 8},
+		{"mp3 128k", MP3, 128 * 1000 / 8},
+		{"flac cd", FLAC, 100 * 1000},
+		{"wav cd", WAV, 44100 * 2 * 2},
+	} {
+		length := 4 * time.Minute
+		size := tc.bytesPerSec * int64(length/time.Second)
+		got := EstimateDuration(size, tc.f)
+		if got > length || got < length/3 {
+			t.Errorf("%s: estimate %v for a %v file, want at most it and within 3x", tc.name, got, length)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+// Package bandcampapi reads Bandcamp track and album pages. Bandcamp has no
+// public API; every page embeds its tracklist as JSON in a data-tralbum
+// attribute for the page's own player, and that is what this package parses:
+// titles, durations, track page links and the mp3-128 stream URLs. Plain
+// net/http + encoding/json, nothing else.
+package bandcampapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxPageBytes bounds a page read. Album pages with long descriptions and
+// many tracks stay well under a megabyte.
+const maxPageBytes = 4 << 20
+
+// userAgent is a browser string: Bandcamp serves a stripped page to clients
+// it does not recognise.
+const userAgent = "Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0"
+
+var (
+	// ErrNoTralbum means the page carries no data-tralbum player data: not a
+	// track or album page, or a layout this package does not know.
+	ErrNoTralbum = errors.New("bandcamp api: page has no tracklist")
+	// ErrNotStreamable means no track on the page can be streamed; Bandcamp
+	// leaves the stream out for purchase-only tracks.
+	ErrNotStreamable = errors.New("bandcamp api: no streamable tracks")
+)
+
+// Client fetches Bandcamp pages. Use Default() to share one client, or New()
+// for an isolated instance (tests).
+type Client struct {
+	// HTTP is overridable for tests.
+	HTTP *http.Client
+}
+
+// New creates a Client with production defaults.
+func New() *Client {
+	return &Client{HTTP: &http.Client{Timeout: 10 * time.Second}}
+}
+
+var defaultClient = sync.OnceValue(New)
+
+// Default returns the process-wide shared client, used by both the bandcamp
+// source and the bcnative parser.
+func Default() *Client { return defaultClient() }
+
+// Release is a track or album page: its tracks in album order. A track page
+// is a release of one.
+type Release struct {
+	// Type is "track" or "album".
+	Type   string
+	Title  string
+	Artist string
+	// URL is the page's canonical address.
+	URL    string
+	Tracks []Track
+}
+
+// Track is one entry of a release's tracklist.
+type Track struct {
+	ID     int64
+	Num    int
+	Title  string
+	Artist string
+	// Page is the track's own page: stable, so it is what gets queued, cached
+	// and kept in history.
+	Page     string
+	Duration time.Duration
+	// Stream is the mp3-128 URL. It is signed and expires within hours, so it
+	// is read fresh from the page each time a track is opened and never stored.
+	// Empty for a track that cannot be streamed.
+	Stream string
+}
+
+// tralbum is the data-tralbum JSON (only the fields Melodix needs).
+type tralbum struct {
+	Artist   string `json:"artist"`
+	ItemType string `json:"item_type"`
+	URL      string `json:"url"`
+	Current  struct {
+		Title string `json:"title"`
+	} `json:"current"`
+	TrackInfo []struct {
+		ID        int64             `json:"id"`
+		TrackID   int64             `json:"track_id"`
+		TrackNum  int               `json:"track_num"`
+		Title     string            `json:"title"`
+		Artist    string            `json:"artist"`
+		Duration  float64           `json:"duration"`
+		TitleLink string            `json:"title_link"`
+		File      map[string]string `json:"file"`
+	} `json:"trackinfo"`
+}
+
+var tralbumRe = regexp.MustCompile(`data-tralbum="([^"]+)"`)
+
+// Release fetches and parses a track or album page.
+func (c *Client) Release(pageURL string) (*Release, error) {
+	return c.ReleaseContext(context.Background(), pageURL)
+}
+
+// ReleaseContext is Release with the page fetch bound to ctx.
+func (c *Client) ReleaseContext(ctx context.Context, pageURL string) (*Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("bandcamp api: request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("bandcamp api: fetch %s: %w", pageURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bandcamp api: fetch %s: %s", pageURL, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPageBytes))
+	if err != nil {
+		return nil, fmt.Errorf("bandcamp api: read page: %w", err)
+	}
+	return ParsePage(body, pageURL)
+}
+
+// ParsePage extracts the release from a page's HTML. pageURL resolves the
+// relative track links when the data carries no URL of its own.
+func ParsePage(body []byte, pageURL string) (*Release, error) {
+	m := tralbumRe.FindSubmatch(body)
+	if m == nil {
+		return nil, ErrNoTralbum
+	}
+	var data tralbum
+	if err := json.Unmarshal([]byte(html.UnescapeString(string(m[1]))), &data); err != nil {
+		return nil, fmt.Errorf("bandcamp api: decode tracklist: %w", err)
+	}
+	if data.URL != "" {
+		pageURL = data.URL
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, fmt.Errorf("bandcamp api: page url: %w", err)
+	}
+
+	rel := &Release{
+		Type:   data.ItemType,
+		Title:  strings.TrimSpace(data.Current.Title),
+		Artist: strings.TrimSpace(data.Artist),
+		URL:    pageURL,
+	}
+	for _, ti := range data.TrackInfo {
+		t := Track{
+			ID:       ti.TrackID,
+			Num:      ti.TrackNum,
+			Title:    strings.TrimSpace(ti.Title),
+			Artist:   strings.TrimSpace(ti.Artist),
+			Duration: time.Duration(ti.Duration * float64(time.Second)),
+			Stream:   ti.File["mp3-128"],
+		}
+		if t.ID == 0 {
+			t.ID = ti.ID
+		}
+		if t.Artist == "" {
+			t.Artist = rel.Artist
+		}
+		switch {
+		case ti.TitleLink != "":
+			if ref, err := base.Parse(ti.TitleLink); err == nil {
+				t.Page = ref.String()
+			}
+		case data.ItemType == "track":
+			t.Page = pageURL
+		}
+		rel.Tracks = append(rel.Tracks, t)
+	}
+	if len(rel.Tracks) == 0 {
+		return nil, ErrNoTralbum
+	}
+	return rel, nil
+}
+
+// Streamable returns the tracks that have both a page and a stream, in order.
+func (r *Release) Streamable() []Track {
+	out := make([]Track, 0, len(r.Tracks))
+	for _, t := range r.Tracks {
+		if t.Page != "" && t.Stream != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// Label is how a track reads in a queue: "Artist - Title".
+func (t Track) Label() string {
+	if t.Artist == "" {
+		return t.Title
+	}
+	return t.Artist + " - " + t.Title
+}
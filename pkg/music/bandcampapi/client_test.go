@@ -0,0 +1,94 @@
+package bandcampapi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func fixture(t *testing.T, name string) []byte {
+	t.Helper()
+	b, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestParseAlbumPage(t *testing.T) {
+	rel, err := ParsePage(fixture(t, "album.html"), "https://harbourlights.bandcamp.com/album/night-lines?from=embed")
+	if err != nil {
+		t.Fatalf("ParsePage: %v", err)
+	}
+	if rel.Type != "album" || rel.Title != "Night Lines" || rel.Artist != "Harbour Lights" || len(rel.Tracks) != 3 {
+		t.Fatalf("release = %+v", rel)
+	}
+	first := rel.Tracks[0]
+	if first.Page != "https://harbourlights.bandcamp.com/track/low-tide" || first.ID != 111001 || first.Num != 1 {
+		t.Fatalf("first = %+v", first)
+	}
+	if first.Duration != 212440*time.Millisecond {
+		t.Fatalf("duration = %v", first.Duration)
+	}
+	if first.Stream != "https://t4.bcbits.com/stream/aaa/mp3-128/111001?p=0&ts=1760000000&t=abc&token=1760000000_def" {
+		t.Fatalf("stream = %q; the attribute's entities must be decoded", first.Stream)
+	}
+	if first.Label() != "Harbour Lights - Low Tide" || rel.Tracks[1].Label() != "Harbour Lights & Mira - Signal Fires" {
+		t.Fatalf("labels = %q, %q", first.Label(), rel.Tracks[1].Label())
+	}
+
+	streamable := rel.Streamable()
+	if len(streamable) != 2 || streamable[1].Title != "Signal Fires" {
+		t.Fatalf("streamable = %+v; the purchase-only track must be left out", streamable)
+	}
+}
+
+func TestParseTrackPage(t *testing.T) {
+	rel, err := ParsePage(fixture(t, "track.html"), "https://harbourlights.bandcamp.com/track/low-tide")
+	if err != nil {
+		t.Fatalf("ParsePage: %v", err)
+	}
+	if rel.Type != "track" || len(rel.Tracks) != 1 {
+		t.Fatalf("release = %+v", rel)
+	}
+	tr := rel.Tracks[0]
+	if tr.Page != "https://harbourlights.bandcamp.com/track/low-tide" || tr.Stream == "" || tr.Artist != "Harbour Lights" {
+		t.Fatalf("track = %+v; a track page is its own track", tr)
+	}
+}
+
+func TestParsePageWithoutTracklist(t *testing.T) {
+	_, err := ParsePage([]byte(`<html><body>Artist front page</body></html>`), "https://x.bandcamp.com/")
+	if !errors.Is(err, ErrNoTralbum) {
+		t.Fatalf("err = %v, want ErrNoTralbum", err)
+	}
+}
+
+func TestReleaseFetchesPage(t *testing.T) {
+	page := fixture(t, "track.html")
+	var ua string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/track/low-tide" {
+			http.NotFound(w, r)
+			return
+		}
+		ua = r.Header.Get("User-Agent")
+		_, _ = w.Write(page)
+	}))
+	t.Cleanup(srv.Close)
+	c := &Client{HTTP: srv.Client()}
+
+	rel, err := c.Release(srv.URL + "/track/low-tide")
+	if err != nil || rel.Tracks[0].Title != "Low Tide" {
+		t.Fatalf("Release = %+v, %v", rel, err)
+	}
+	if ua != userAgent {
+		t.Fatalf("User-Agent = %q", ua)
+	}
+	if _, err := c.Release(srv.URL + "/track/gone"); err == nil {
+		t.Fatal("a 404 must fail")
+	}
+}
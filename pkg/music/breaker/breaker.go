@@ -0,0 +1,226 @@
+// Package breaker is a circuit breaker for the external paths playback depends
+// on — a parser's tool or site, an API's credentials. A path that keeps
+// failing is skipped for a cooldown instead of being paid for again by every
+// track (a process spawn, an HTTP round trip), then tried with a single probe.
+package breaker
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned by Allow while the breaker is open, in an error that
+// names the breaker and the failure that tripped it.
+var ErrOpen = errors.New("breaker: circuit open")
+
+const (
+	// DefaultThreshold is how many failures in a row trip a breaker. A success
+	// in between resets the count, so a path that works for most tracks never
+	// trips.
+	DefaultThreshold = 5
+	// DefaultCooldown is how long a tripped breaker stays open before letting
+	// a probe through. Each failed probe doubles it, up to MaxCooldown.
+	DefaultCooldown = 30 * time.Second
+	// MaxCooldown caps the backed-off cooldown: a path fixed while the bot
+	// runs (yt-dlp installed, the site back up) is noticed within this long.
+	MaxCooldown = 10 * time.Minute
+)
+
+// State is a breaker's position.
+type State int
+
+const (
+	// Closed lets every call through.
+	Closed State = iota
+	// Open fails every call until the cooldown is over.
+	Open
+	// HalfOpen has let one probe through and waits for its outcome.
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// Breaker guards one external path. Callers ask Allow before using the path
+// and report the outcome with Success, Failure or Skip. Safe for concurrent
+// use.
+type Breaker struct {
+	name      string
+	threshold int
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int           // in a row, while closed
+	cooldown time.Duration // current; doubles per failed probe
+	openedAt time.Time     // when the breaker last tripped or re-opened
+	lastErr  error         // the failure that tripped it
+	trips    int           // lifetime count, for display
+}
+
+// New creates a closed breaker with the default threshold and cooldown.
+func New(name string) *Breaker {
+	return &Breaker{name: name, threshold: DefaultThreshold, cooldown: DefaultCooldown, now: time.Now}
+}
+
+// Name returns the breaker's name.
+func (b *Breaker) Name() string { return b.name }
+
+// Allow reports whether the path may be used now: nil while closed, and for
+// the one probe let through once an open breaker's cooldown is over. Every
+// other call gets an error wrapping ErrOpen. A nil return must be followed by
+// exactly one of Success, Failure or Skip.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case Closed:
+		return nil
+	case Open:
+		if b.now().Sub(b.openedAt) >= b.cooldown {
+			b.state = HalfOpen
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s (%v)", ErrOpen, b.name, b.lastErr)
+}
+
+// Success reports that the path worked, closing the breaker.
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state != Closed {
+		l := logger()
+		l.Info().Str("breaker", b.name).Dur("was_open", b.now().Sub(b.openedAt)).Msg("breaker_closed")
+	}
+	b.state = Closed
+	b.failures = 0
+	b.cooldown = DefaultCooldown
+}
+
+// Failure reports that the path failed with err. The threshold-th failure in a
+// row trips a closed breaker; a failed probe re-opens it for twice as long.
+func (b *Breaker) Failure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case Closed:
+		b.failures++
+		if b.failures < b.threshold {
+			return
+		}
+		b.trips++
+	case HalfOpen:
+		b.cooldown = min(2*b.cooldown, MaxCooldown)
+	case Open:
+		// A call that was allowed before the trip, reporting late.
+		return
+	}
+	b.state = Open
+	b.openedAt = b.now()
+	b.lastErr = err
+	l := logger()
+	l.Warn().Str("breaker", b.name).Int("failures", b.failures).
+		Dur("cooldown", b.cooldown).Err(err).Msg("breaker_open")
+}
+
+// Skip reports a call that proved nothing about the path either way — it
+// failed for reasons of its own input. A probe that ends this way is given
+// back, so the next call probes again.
+func (b *Breaker) Skip() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == HalfOpen {
+		b.state = Open
+		b.openedAt = b.now().Add(-b.cooldown)
+	}
+}
+
+// Status is a breaker's state as Snapshot reports it.
+type Status struct {
+	Name  string
+	State State
+	// Since is when the breaker last opened; zero if it never has.
+	Since time.Time
+	// Cooldown is the current wait between probes.
+	Cooldown time.Duration
+	// LastErr is the failure that last opened the breaker.
+	LastErr error
+	// Trips counts how often the breaker has tripped from closed.
+	Trips int
+}
+
+// Status returns the breaker's current state.
+func (b *Breaker) Status() Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return Status{
+		Name:     b.name,
+		State:    b.state,
+		Since:    b.openedAt,
+		Cooldown: b.cooldown,
+		LastErr:  b.lastErr,
+		Trips:    b.trips,
+	}
+}
+
+// Set is a named collection of breakers, created on first use. Safe for
+// concurrent use.
+type Set struct {
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+// NewSet creates an empty set.
+func NewSet() *Set { return &Set{breakers: make(map[string]*Breaker)} }
+
+// Get returns the set's breaker for name, creating a closed one if needed.
+func (s *Set) Get(name string) *Breaker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.breakers[name]
+	if !ok {
+		b = New(name)
+		s.breakers[name] = b
+	}
+	return b
+}
+
+// Snapshot returns the status of every breaker in the set, by name.
+func (s *Set) Snapshot() []Status {
+	s.mu.Lock()
+	list := make([]*Breaker, 0, len(s.breakers))
+	for _, b := range s.breakers {
+		list = append(list, b)
+	}
+	s.mu.Unlock()
+
+	out := make([]Status, 0, len(list))
+	for _, b := range list {
+		out = append(out, b.Status())
+	}
+	slices.SortFunc(out, func(a, b Status) int { return strings.Compare(a.Name, b.Name) })
+	return out
+}
+
+// Degraded returns the breakers in the set that are not closed, by name.
+func (s *Set) Degraded() []Status {
+	return slices.DeleteFunc(s.Snapshot(), func(st Status) bool { return st.State == Closed })
+}
+
+var defaultSet = NewSet()
+
+// Default returns the process-wide set the parsers and API clients use.
+func Default() *Set { return defaultSet }
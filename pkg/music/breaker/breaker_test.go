@@ -0,0 +1,122 @@
+package breaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestBreaker() (*Breaker, *time.Time) {
+	clock := time.Unix(1_700_000_000, 0)
+	b := New("test")
+	b.now = func() time.Time { return clock }
+	return b, &clock
+}
+
+func fail(t *testing.T, b *Breaker, n int) {
+	t.Helper()
+	for range n {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("Allow before the trip: %v", err)
+		}
+		b.Failure(errors.New("exec: \"yt-dlp\": executable file not found"))
+	}
+}
+
+func TestBreaker_TripsAfterThresholdInARow(t *testing.T) {
+	b, _ := newTestBreaker()
+	fail(t, b, DefaultThreshold-1)
+	b.Success() // a success in between starts the count over
+	fail(t, b, DefaultThreshold-1)
+	if st := b.Status(); st.State != Closed {
+		t.Fatalf("state = %v before the threshold, want closed", st.State)
+	}
+
+	fail(t, b, 1)
+	err := b.Allow()
+	if !errors.Is(err, ErrOpen) {
+		t.Fatalf("Allow on a tripped breaker = %v, want ErrOpen", err)
+	}
+	if st := b.Status(); st.State != Open || st.Trips != 1 || st.LastErr == nil {
+		t.Fatalf("status = %+v", st)
+	}
+}
+
+func TestBreaker_HalfOpenProbe(t *testing.T) {
+	b, clock := newTestBreaker()
+	fail(t, b, DefaultThreshold)
+
+	*clock = clock.Add(DefaultCooldown)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe after the cooldown: %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("second call during the probe = %v, want ErrOpen", err)
+	}
+
+	// A failed probe re-opens for twice as long.
+	b.Failure(errors.New("still broken"))
+	*clock = clock.Add(DefaultCooldown)
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("Allow within the doubled cooldown = %v, want ErrOpen", err)
+	}
+	*clock = clock.Add(DefaultCooldown)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe after the doubled cooldown: %v", err)
+	}
+
+	b.Success()
+	if st := b.Status(); st.State != Closed || st.Cooldown != DefaultCooldown {
+		t.Fatalf("status after a good probe = %+v", st)
+	}
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow after closing: %v", err)
+	}
+}
+
+func TestBreaker_SkippedProbeIsGivenBack(t *testing.T) {
+	b, clock := newTestBreaker()
+	fail(t, b, DefaultThreshold)
+	*clock = clock.Add(DefaultCooldown)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	b.Skip()
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow after a skipped probe = %v, want a new probe", err)
+	}
+}
+
+func TestBreaker_CooldownIsCapped(t *testing.T) {
+	b, clock := newTestBreaker()
+	fail(t, b, DefaultThreshold)
+	for range 10 {
+		*clock = clock.Add(MaxCooldown)
+		if err := b.Allow(); err != nil {
+			t.Fatalf("probe: %v", err)
+		}
+		b.Failure(errors.New("still broken"))
+	}
+	if st := b.Status(); st.Cooldown != MaxCooldown || st.Trips != 1 {
+		t.Fatalf("status = %+v", st)
+	}
+}
+
+func TestSet_Degraded(t *testing.T) {
+	s := NewSet()
+	s.Get("parser b")
+	a := s.Get("parser a")
+	if s.Get("parser a") != a {
+		t.Fatal("Get created a second breaker for the same name")
+	}
+	for range DefaultThreshold {
+		a.Failure(errors.New("boom"))
+	}
+	snap := s.Snapshot()
+	if len(snap) != 2 || snap[0].Name != "parser a" || snap[1].Name != "parser b" {
+		t.Fatalf("snapshot = %+v", snap)
+	}
+	if d := s.Degraded(); len(d) != 1 || d[0].Name != "parser a" || d[0].State != Open {
+		t.Fatalf("degraded = %+v", d)
+	}
+}
@@ -2,6 +2,8 @@ package cache
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"io"
 	"os"
@@ -14,6 +16,11 @@ import (
 	"github.com/rs/zerolog"
 )
 
+func directKey(normalized string) string {
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:16])
+}
+
 func TestKeyCanonicalization(t *testing.T) {
 	const yt = "youtube:dQw4w9WgXcQ"
 	cases := []struct {
@@ -25,7 +32,17 @@ func TestKeyCanonicalization(t *testing.T) {
 		{sources.YouTube, "https://music.youtube.com/watch?v=dQw4w9WgXcQ", yt, true},
 		{sources.YouTube, "https://www.youtube.com/shorts/dQw4w9WgXcQ", yt, true},
 		{sources.SoundCloud, "https://soundcloud.com/artist/track?in=x/sets/y", "soundcloud:soundcloud.com/artist/track", true},
+		{sources.Bandcamp, "https://Artist.bandcamp.com/track/song/?from=embed", "bandcamp:artist.bandcamp.com/track/song", true},
+		// An album is never a cache entry: it is queued as its tracks.
+		{sources.Bandcamp, "https://artist.bandcamp.com/album/record", "", false},
 		{sources.Radio, "http://stream.example/live", "", false},
+		{sources.Direct, "https://example.com/a.mp3?v=2", "direct:" + directKey("https://example.com/a.mp3?v=2"), true},
+		// A refreshed attachment link is still the same file.
+		{sources.Direct, "https://media.discordapp.net/attachments/1/2/a.mp3?ex=1&hm=x",
+			"direct:" + directKey("cdn.discordapp.com/attachments/1/2/a.mp3"), true},
+		{sources.Direct, "https://cdn.discordapp.com/attachments/1/2/a.mp3?ex=9&hm=y",
+			"direct:" + directKey("cdn.discordapp.com/attachments/1/2/a.mp3"), true},
+		{sources.Podcast, "https://cdn.example/ep212.mp3", "podcast:" + directKey("https://cdn.example/ep212.mp3"), true},
 		{sources.YouTube, "not a url at all", "", false},
 	}
 	for _, c := range cases {
@@ -5,6 +5,8 @@
 package cache
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"net/url"
 	"strings"
 
@@ -35,8 +37,21 @@ func KeyFrom(sourceName, rawURL string) (string, bool) {
 		if u := normalizeSoundCloudURL(rawURL); u != "" {
 			return "soundcloud:" + u, true
 		}
+	case sources.Bandcamp:
+		if u := normalizeBandcampURL(rawURL); u != "" {
+			return "bandcamp:" + u, true
+		}
+	case sources.Direct, sources.Podcast:
+		// A podcast episode is a direct file under another name; the prefix
+		// keeps the two apart in the index, where the source is what an
+		// operator sorts by.
+		if u := normalizeDirectURL(rawURL); u != "" {
+			sum := sha256.Sum256([]byte(u))
+			return sourceName + ":" + hex.EncodeToString(sum[:16]), true
+		}
 	}
-	// Radio (live), auto, and unrecognized URLs are uncacheable.
+	// Radio (live), local files (already on disk), auto, and unrecognized URLs
+	// are uncacheable.
 	return "", false
 }
 
@@ -80,3 +95,44 @@ func normalizeSoundCloudURL(raw string) string {
 	}
 	return host + p
 }
+
+// normalizeBandcampURL reduces a Bandcamp track page to host+path, or "" if it
+// isn't one. The page, not the stream: stream URLs are signed and change on
+// every fetch, while /track/<slug> names the track for good.
+func normalizeBandcampURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	if !strings.HasSuffix(host, ".bandcamp.com") || !strings.HasPrefix(u.Path, "/track/") {
+		return ""
+	}
+	return host + strings.TrimRight(u.Path, "/")
+}
+
+// discordCDNHosts serve attachments under signed URLs whose query (ex, is, hm)
+// expires within a day and changes on every refresh, while the path names the
+// file for good.
+var discordCDNHosts = map[string]bool{
+	"cdn.discordapp.com":   true,
+	"media.discordapp.net": true,
+}
+
+// normalizeDirectURL reduces a direct file link to what identifies the file.
+// Elsewhere the query is kept: on an arbitrary server it may well be what
+// selects the file ("download.php?id=7"). The key is a hash because such URLs
+// are long and can carry tokens nobody wants in the cache index.
+func normalizeDirectURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	if discordCDNHosts[host] {
+		return "cdn.discordapp.com" + u.Path
+	}
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	return u.String()
+}
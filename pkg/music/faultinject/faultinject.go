@@ -0,0 +1,256 @@
+// Package faultinject provides a parser and an audio sink that misbehave on
+// cue, for testing the recovery paths: RecoveryStream's parser switch,
+// early-EOF reopen and live backoff, and the player's transport recovery.
+//
+// A Streamer goes into stream.SetRegistry under a parser key and follows a
+// script, one Open per time it is opened: slow or failed opens, streams that
+// end early or with a transport error, stalls, malformed packets. A Sink
+// plays whatever it is given and fails the voice transport when told to.
+//
+// Every packet a Streamer produces is tagged with the parser and the track it
+// came from (see Origin), so a test can tell from what the Sink heard whether
+// the right parser was playing and whether two tracks ever overlapped.
+//
+// Like httpreplay, it is meant for tests and does not import the testing
+// package.
+package faultinject
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/opus"
+	"github.com/keshon/melodix/pkg/music/parsers"
+)
+
+// Forever is a Stall that lasts until the stream is cleaned up, as a read
+// from a CDN that stopped sending without closing the connection does.
+const Forever = time.Duration(math.MaxInt64)
+
+// ErrClosed is what a read returns once the stream's cleanup has run, as a
+// read on a closed connection does.
+var ErrClosed = errors.New("faultinject: stream closed")
+
+// Open is what one open of a Streamer does. The zero Open opens at once and
+// yields no packets before a clean EOF.
+type Open struct {
+	// Delay holds the open back, as a slow extraction does. OpenContext gives
+	// up when its context is done; Open sits the delay out.
+	Delay time.Duration
+	// Err fails the open once Delay has passed.
+	Err error
+	// Packets is how many packets the stream yields before End.
+	Packets int
+	// End is what reads return after the last packet; nil means io.EOF. With
+	// Packets 0 it is a failure on the very first read.
+	End error
+	// Stall blocks the read of packet StallAt (0-based) for this long, or until
+	// the stream is cleaned up, whichever comes first. Zero means no stall.
+	Stall   time.Duration
+	StallAt int
+	// Corrupt lists the packets (0-based) replaced by CorruptPacket.
+	Corrupt []int
+}
+
+// Streamer is a parsers.ContextStreamer that follows Script: its first open
+// does what Script[0] says, the second Script[1], and every open past the end
+// of the script repeats the last entry.
+type Streamer struct {
+	// Name tags the packets; use the registry key the Streamer is installed
+	// under.
+	Name   string
+	Script []Open
+	// Duration is written into the track on every open, as the parsers fill in
+	// what the site reports. Zero leaves the track's own, which for a track
+	// with none makes it unknown or, with Caps.Live, live.
+	Duration time.Duration
+	Caps     parsers.Capabilities
+
+	mu      sync.Mutex
+	opens   int
+	live    int
+	maxLive int
+	stalled chan struct{}
+}
+
+// New returns a seekable Streamer named name that follows script.
+func New(name string, script ...Open) *Streamer {
+	return &Streamer{Name: name, Script: script, Caps: parsers.Capabilities{Seekable: true}}
+}
+
+// Capabilities implements parsers.Capable.
+func (s *Streamer) Capabilities() parsers.Capabilities { return s.Caps }
+
+// Open implements parsers.Streamer.
+func (s *Streamer) Open(track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
+	return s.OpenContext(context.Background(), track, seekSec)
+}
+
+// OpenContext implements parsers.ContextStreamer. The stream it returns is
+// closed by the cleanup and by its own Close alike.
+func (s *Streamer) OpenContext(ctx context.Context, track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
+	s.mu.Lock()
+	var step Open
+	if n := len(s.Script); n > 0 {
+		step = s.Script[min(s.opens, n-1)]
+	}
+	s.opens++
+	s.mu.Unlock()
+
+	if step.Delay > 0 {
+		t := time.NewTimer(step.Delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return nil, nil, ctx.Err()
+		}
+	}
+	if step.Err != nil {
+		return nil, nil, step.Err
+	}
+	if s.Duration > 0 {
+		track.Duration = s.Duration
+	}
+
+	s.mu.Lock()
+	s.live++
+	s.maxLive = max(s.maxLive, s.live)
+	s.mu.Unlock()
+	r := &reader{s: s, step: step, tag: tag(s.Name, track.Title), done: make(chan struct{})}
+	return r, func() { _ = r.Close() }, nil
+}
+
+// Opens is how many times the Streamer has been opened, failed opens included.
+func (s *Streamer) Opens() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.opens
+}
+
+// Live is how many streams the Streamer opened that have not been cleaned up.
+func (s *Streamer) Live() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.live
+}
+
+// MaxLive is the most streams the Streamer has had open at once.
+func (s *Streamer) MaxLive() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.maxLive
+}
+
+// Stalled receives once for every read that starts a stall; a stall nobody
+// waits for is not held up by it.
+func (s *Streamer) Stalled() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stalled == nil {
+		s.stalled = make(chan struct{}, 16)
+	}
+	return s.stalled
+}
+
+func (s *Streamer) noteStall() {
+	s.mu.Lock()
+	ch := s.stalled
+	s.mu.Unlock()
+	if ch == nil {
+		return
+	}
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// reader is one opened stream. Its reads belong to one goroutine; Close may
+// come from any, and unblocks a stalled read.
+type reader struct {
+	s       *Streamer
+	step    Open
+	tag     []byte
+	n       int
+	stalled bool
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func (r *reader) ReadPacket() ([]byte, error) {
+	if r.step.Stall > 0 && r.n == r.step.StallAt && !r.stalled {
+		r.stalled = true
+		r.s.noteStall()
+		t := time.NewTimer(r.step.Stall)
+		select {
+		case <-t.C:
+		case <-r.done:
+			t.Stop()
+		}
+	}
+	select {
+	case <-r.done:
+		return nil, ErrClosed
+	default:
+	}
+	if r.n >= r.step.Packets {
+		if r.step.End != nil {
+			return nil, r.step.End
+		}
+		return nil, io.EOF
+	}
+	i := r.n
+	r.n++
+	if slices.Contains(r.step.Corrupt, i) {
+		return slices.Clone(CorruptPacket), nil
+	}
+	return slices.Clone(r.tag), nil
+}
+
+func (r *reader) Close() error {
+	r.closeOnce.Do(func() {
+		close(r.done)
+		r.s.mu.Lock()
+		r.s.live--
+		r.s.mu.Unlock()
+	})
+	return nil
+}
+
+// CorruptPacket is a malformed Opus packet: its TOC byte announces a frame
+// count (code 3) that the packet is too short to carry.
+var CorruptPacket = []byte{0xFB}
+
+// tocCELT20ms is the TOC byte of a single 20ms CELT fullband stereo frame, so
+// a tagged packet measures as one frame (opus.IsSingle20ms) wherever a sink
+// checks. Only the TOC is real; the payload is the tag.
+const tocCELT20ms = 0xFC
+
+// tag is the packet a stream of parser playing title yields.
+func tag(parser, title string) []byte {
+	b := []byte{tocCELT20ms}
+	b = append(b, parser...)
+	b = append(b, 0)
+	return append(b, title...)
+}
+
+// Origin reports which parser and track produced pkt; ok is false for a packet
+// no Streamer tagged, CorruptPacket included.
+func Origin(pkt []byte) (parser, title string, ok bool) {
+	if len(pkt) < 2 || pkt[0] != tocCELT20ms {
+		return "", "", false
+	}
+	p, t, ok := bytes.Cut(pkt[1:], []byte{0})
+	if !ok {
+		return "", "", false
+	}
+	return string(p), string(t), true
+}
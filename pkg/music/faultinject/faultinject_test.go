@@ -0,0 +1,132 @@
+package faultinject
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/opus"
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+// drain reads r to its end; it may run on a goroutine of its own.
+func drain(t *testing.T, r opus.Reader) (n int, err error) {
+	t.Helper()
+	for {
+		pkt, err := r.ReadPacket()
+		if err != nil {
+			return n, err
+		}
+		if !opus.IsSingle20ms(pkt) && string(pkt) != string(CorruptPacket) {
+			t.Errorf("packet %d is neither tagged nor CorruptPacket: %x", n, pkt)
+		}
+		n++
+	}
+}
+
+// Each open follows the next entry of the script, and the last one repeats.
+func TestStreamerFollowsTheScript(t *testing.T) {
+	errCut := errors.New("cut")
+	s := New("p", Open{Err: errCut}, Open{Packets: 3, End: errCut}, Open{Packets: 2})
+	track := &parsers.Track{Title: "song"}
+
+	if _, _, err := s.Open(track, 0); !errors.Is(err, errCut) {
+		t.Fatalf("open 1: err = %v, want the scripted failure", err)
+	}
+	want := []struct {
+		n   int
+		err error
+	}{{3, errCut}, {2, io.EOF}, {2, io.EOF}}
+	for i, w := range want {
+		r, cleanup, err := s.Open(track, 0)
+		if err != nil {
+			t.Fatalf("open %d: %v", i+2, err)
+		}
+		n, err := drain(t, r)
+		cleanup()
+		if n != w.n || !errors.Is(err, w.err) {
+			t.Errorf("open %d: %d packets then %v, want %d then %v", i+2, n, err, w.n, w.err)
+		}
+	}
+	if s.Opens() != 4 || s.Live() != 0 {
+		t.Errorf("opens %d, live %d; want 4 and 0", s.Opens(), s.Live())
+	}
+}
+
+// Packets say where they came from; a corrupt one does not.
+func TestOriginAndCorruptPackets(t *testing.T) {
+	s := New("ytdlp", Open{Packets: 3, Corrupt: []int{1}})
+	r, cleanup, err := s.Open(&parsers.Track{Title: "song"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	for i := 0; i < 3; i++ {
+		pkt, err := r.ReadPacket()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parser, title, ok := Origin(pkt)
+		if i == 1 {
+			if ok || opus.PacketDurationMs(pkt) != 0 {
+				t.Errorf("packet 1 should be malformed, got %q/%q", parser, title)
+			}
+			continue
+		}
+		if !ok || parser != "ytdlp" || title != "song" {
+			t.Errorf("packet %d: Origin = %q, %q, %v", i, parser, title, ok)
+		}
+	}
+}
+
+// A stall lasts until cleanup when that comes first.
+func TestStallEndsAtCleanup(t *testing.T) {
+	s := New("p", Open{Packets: 5, StallAt: 2, Stall: Forever})
+	r, cleanup, err := s.Open(&parsers.Track{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := drain(t, r)
+		done <- err
+	}()
+	select {
+	case <-s.Stalled():
+	case <-time.After(2 * time.Second):
+		t.Fatal("never stalled")
+	}
+	cleanup()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrClosed) {
+			t.Errorf("stalled read ended with %v, want ErrClosed", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("cleanup did not end the stall")
+	}
+}
+
+// The sink fails the transport on cue and plays through after that.
+func TestSinkFailsTheTransportOnCue(t *testing.T) {
+	s := New("p", Open{Packets: 10})
+	sk := &Sink{FailAfter: []int{4}}
+	stop := make(chan struct{})
+
+	r, cleanup, _ := s.Open(&parsers.Track{Title: "song"}, 0)
+	defer cleanup()
+	if err := sk.Stream(r, stop); !errors.Is(err, stream.ErrVoiceTransport) {
+		t.Fatalf("first call: %v, want ErrVoiceTransport", err)
+	}
+	if err := sk.Stream(r, stop); err != nil {
+		t.Fatalf("second call: %v, want a clean end", err)
+	}
+	if n, _ := sk.Packets(); n != 10 {
+		t.Errorf("played %d packets, want 10", n)
+	}
+	if h := sk.Heard(); len(h) != 1 || h[0] != (Heard{Parser: "p", Title: "song"}) {
+		t.Errorf("heard %v", h)
+	}
+}
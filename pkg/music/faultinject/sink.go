@@ -0,0 +1,159 @@
+package faultinject
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/keshon/melodix/pkg/music/opus"
+	"github.com/keshon/melodix/pkg/music/sink"
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+// Heard is a run of packets the Sink played from one parser and track.
+type Heard struct {
+	Parser string
+	Title  string
+}
+
+// Sink is a sink.AudioSink that plays as fast as it is fed and keeps what it
+// heard. Its Stream calls can be told to fail the voice transport.
+type Sink struct {
+	// FailAfter makes Stream call i fail with stream.ErrVoiceTransport once
+	// it has played FailAfter[i] packets; calls past the end play through.
+	FailAfter []int
+	// OnPacket, when set, sees every packet as it is played, from the
+	// goroutine calling Stream.
+	OnPacket func(pkt []byte)
+
+	mu        sync.Mutex
+	calls     int
+	active    int
+	maxActive int
+	packets   int
+	corrupt   int
+	heard     []Heard
+}
+
+// Stream implements sink.AudioSink. It returns nil at EOF, the read error for
+// any other, and stream.ErrPlaybackStopped once stop is closed.
+func (s *Sink) Stream(r opus.Reader, stop <-chan struct{}) error {
+	s.mu.Lock()
+	failAfter := -1
+	if s.calls < len(s.FailAfter) {
+		failAfter = s.FailAfter[s.calls]
+	}
+	s.calls++
+	s.active++
+	s.maxActive = max(s.maxActive, s.active)
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.active--
+		s.mu.Unlock()
+	}()
+
+	for played := 0; ; played++ {
+		select {
+		case <-stop:
+			return stream.ErrPlaybackStopped
+		default:
+		}
+		if played == failAfter {
+			return stream.ErrVoiceTransport
+		}
+		pkt, err := r.ReadPacket()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		s.played(pkt)
+		if s.OnPacket != nil {
+			s.OnPacket(pkt)
+		}
+	}
+}
+
+func (s *Sink) played(pkt []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.packets++
+	parser, title, ok := Origin(pkt)
+	if !ok {
+		s.corrupt++
+		return
+	}
+	h := Heard{Parser: parser, Title: title}
+	if n := len(s.heard); n == 0 || s.heard[n-1] != h {
+		s.heard = append(s.heard, h)
+	}
+}
+
+// Heard returns what the Sink played, one entry per run of packets from the
+// same parser and track.
+func (s *Sink) Heard() []Heard {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Heard(nil), s.heard...)
+}
+
+// Packets is how many packets the Sink played; Corrupt how many of them no
+// Streamer tagged.
+func (s *Sink) Packets() (packets, corrupt int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.packets, s.corrupt
+}
+
+// Calls is how many times Stream was called; MaxActive the most calls that
+// were streaming at once, which is more than one only if two playbacks
+// overlapped.
+func (s *Sink) Calls() (calls, maxActive int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls, s.maxActive
+}
+
+// Provider hands every target the same Sink and counts what the player asks
+// of it.
+type Provider struct {
+	sink *Sink
+
+	mu            sync.Mutex
+	invalidations int
+	released      chan struct{}
+}
+
+// NewProvider returns a Provider for s.
+func NewProvider(s *Sink) *Provider {
+	return &Provider{sink: s, released: make(chan struct{}, 8)}
+}
+
+func (p *Provider) Sink(target string) (sink.AudioSink, error) { return p.sink, nil }
+
+func (p *Provider) ReleaseSink(target string) {
+	select {
+	case p.released <- struct{}{}:
+	default:
+	}
+}
+
+func (p *Provider) InvalidateSink() {
+	p.mu.Lock()
+	p.invalidations++
+	p.mu.Unlock()
+}
+
+// Released receives once per ReleaseSink, which the player calls when the
+// queue has run out or it is stopped with a disconnect.
+func (p *Provider) Released() <-chan struct{} { return p.released }
+
+// Invalidations is how many times the player dropped the sink after a
+// transport failure.
+func (p *Provider) Invalidations() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.invalidations
+}
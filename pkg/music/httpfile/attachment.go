@@ -0,0 +1,100 @@
+package httpfile
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Discord attachment links are signed: the query carries ex, is and hm, where
+// ex is the expiry in hex Unix seconds. A link copied out of a message stops
+// working within a day, and one replayed from /history long before that. Only
+// Discord's API can sign a link again, and this package must not know about
+// Discord, so the bot installs a Refresher at startup.
+
+// ErrAttachmentExpired is returned for an expired attachment link when no
+// Refresher is installed — the CLI, say, which has no Discord session.
+var ErrAttachmentExpired = errors.New("httpfile: attachment link expired")
+
+// Refresher returns freshly signed URLs for rawURLs, in the same order.
+type Refresher func(rawURLs []string) ([]string, error)
+
+var refresher atomic.Pointer[Refresher]
+
+// SetRefresher installs the function that renews attachment links. Safe for
+// concurrent use; nil removes it.
+func SetRefresher(r Refresher) {
+	if r == nil {
+		refresher.Store(nil)
+		return
+	}
+	refresher.Store(&r)
+}
+
+// attachmentHosts serve Discord attachments. Both sign their links the same
+// way and both are accepted by the refresh endpoint.
+var attachmentHosts = map[string]bool{
+	"cdn.discordapp.com":   true,
+	"media.discordapp.net": true,
+}
+
+// renewMargin renews a link this long before it expires. A link is not only
+// fetched at open: ffmpeg issues a fresh ranged request on every seek and the
+// recovery stream reopens after a drop, both possibly an hour into a track.
+const renewMargin = time.Hour
+
+// IsDiscordAttachment reports whether rawURL points at a Discord attachment.
+func IsDiscordAttachment(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return attachmentHosts[strings.ToLower(u.Hostname())] &&
+		strings.HasPrefix(u.Path, "/attachments/")
+}
+
+// attachmentExpiry reads the ex parameter. A link without one — stripped by
+// whoever pasted it — is reported as already expired, which is what it is.
+func attachmentExpiry(rawURL string) time.Time {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return time.Time{}
+	}
+	ex, err := strconv.ParseInt(u.Query().Get("ex"), 16, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(ex, 0)
+}
+
+// Fresh returns rawURL ready to fetch: a Discord attachment whose signature
+// has expired, or is about to, comes back renewed. Any other URL is returned
+// as given.
+func Fresh(rawURL string) (string, error) {
+	if !IsDiscordAttachment(rawURL) || time.Until(attachmentExpiry(rawURL)) > renewMargin {
+		return rawURL, nil
+	}
+	return Renew(rawURL)
+}
+
+// Renew signs an attachment link again regardless of its expiry — for a link
+// the CDN refused although its ex said it was valid, after a clock skew or a
+// revoked signature.
+func Renew(rawURL string) (string, error) {
+	rp := refresher.Load()
+	if rp == nil {
+		return "", ErrAttachmentExpired
+	}
+	urls, err := (*rp)([]string{rawURL})
+	if err != nil {
+		return "", fmt.Errorf("httpfile: renew attachment link: %w", err)
+	}
+	if len(urls) != 1 || urls[0] == "" {
+		return "", ErrAttachmentExpired
+	}
+	return urls[0], nil
+}
@@ -0,0 +1,215 @@
+// Package httpfile probes audio files behind plain HTTP URLs — a link to an
+// .mp3, a Discord attachment — for the direct source and its parser. What
+// separates such a file from an internet radio stream is that it ends: the
+// server states a length, and answers ranged requests for parts of it.
+package httpfile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/audiotag"
+)
+
+// ErrNotAFile is returned for a URL that does not look like a finite audio
+// file: no stated length, ICY headers, or a type nothing here can play.
+var ErrNotAFile = errors.New("httpfile: not a finite audio file")
+
+// Client is used for every request this package makes. Probes are small, so
+// the timeout covers a whole response; streaming bodies are the parser's
+// business and use their own client.
+var Client = &http.Client{Timeout: 10 * time.Second}
+
+// userAgent is a browser string: some file hosts refuse Go's default.
+const userAgent = "Mozilla/5.0"
+
+// Info is what a probe learned about a file.
+type Info struct {
+	URL         string // after redirects
+	Size        int64
+	ContentType string
+	// Ranges is true when the server honours Range requests. Without them tags
+	// at the end of a file (Ogg duration, a trailing MP4 index) are out of reach,
+	// and a seek has to download everything before the position.
+	Ranges bool
+	Format audiotag.Format
+	Tags   audiotag.Tags
+}
+
+// Duration is the tagged duration, or an estimate from the size — never zero,
+// because a zero duration is how RecoveryStream recognises a live stream.
+func (i Info) Duration() time.Duration {
+	if i.Tags.Duration > 0 {
+		return i.Tags.Duration
+	}
+	return audiotag.EstimateDuration(i.Size, i.Format)
+}
+
+// contentFormats maps Content-Type to a format for URLs whose path has no
+// usable extension. "application/octet-stream" is deliberately absent: it
+// says nothing about the bytes, and radio servers send it too.
+var contentFormats = map[string]audiotag.Format{
+	"audio/mpeg":      audiotag.MP3,
+	"audio/mp3":       audiotag.MP3,
+	"audio/ogg":       audiotag.Ogg,
+	"application/ogg": audiotag.Ogg,
+	"audio/opus":      audiotag.Opus,
+	"audio/flac":      audiotag.FLAC,
+	"audio/x-flac":    audiotag.FLAC,
+	"audio/mp4":       audiotag.MP4,
+	"audio/x-m4a":     audiotag.MP4,
+	"audio/aac":       audiotag.MP4,
+	"audio/wav":       audiotag.WAV,
+	"audio/x-wav":     audiotag.WAV,
+	"audio/wave":      audiotag.WAV,
+	"audio/webm":      audiotag.WebM,
+}
+
+// Stat checks rawURL with headers only — a HEAD, or a one-byte ranged GET for
+// servers that refuse HEAD — and reports ErrNotAFile for anything that is not
+// a finite audio file.
+func Stat(rawURL string) (Info, error) {
+	return StatContext(context.Background(), rawURL)
+}
+
+// StatContext is Stat with its requests bound to ctx.
+func StatContext(ctx context.Context, rawURL string) (Info, error) {
+	resp, err := do(ctx, http.MethodHead, rawURL, "")
+	if err == nil && (resp.StatusCode >= 400 || resp.ContentLength <= 0) {
+		_ = resp.Body.Close()
+		err = fmt.Errorf("httpfile: head %s", resp.Status)
+	}
+	if err != nil {
+		resp, err = do(ctx, http.MethodGet, rawURL, "bytes=0-0")
+		if err != nil {
+			return Info{}, err
+		}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return Info{}, fmt.Errorf("httpfile: %s", resp.Status)
+	}
+
+	// Shoutcast and Icecast announce themselves; a server that does is
+	// streaming radio whatever the URL's extension says.
+	if resp.Header.Get("Icy-Metaint") != "" || resp.Header.Get("Icy-Name") != "" {
+		return Info{}, ErrNotAFile
+	}
+	info := Info{
+		URL:         resp.Request.URL.String(),
+		ContentType: resp.Header.Get("Content-Type"),
+		Size:        resp.ContentLength,
+		Ranges:      resp.StatusCode == http.StatusPartialContent || strings.EqualFold(resp.Header.Get("Accept-Ranges"), "bytes"),
+	}
+	if resp.StatusCode == http.StatusPartialContent {
+		info.Size = totalFromContentRange(resp.Header.Get("Content-Range"))
+	}
+	if info.Size <= 0 {
+		return Info{}, ErrNotAFile
+	}
+	f, ok := formatOf(info.URL, info.ContentType)
+	if !ok {
+		return Info{}, ErrNotAFile
+	}
+	info.Format = f
+	return info, nil
+}
+
+// Probe is Stat plus the file's tags, read with ranged requests. A file whose
+// tags cannot be read is still returned; the tags are simply empty.
+func Probe(rawURL string) (Info, error) {
+	return ProbeContext(context.Background(), rawURL)
+}
+
+// ProbeContext is Probe with its requests bound to ctx.
+func ProbeContext(ctx context.Context, rawURL string) (Info, error) {
+	info, err := StatContext(ctx, rawURL)
+	if err != nil {
+		return Info{}, err
+	}
+	var r io.ReaderAt
+	if info.Ranges {
+		r = newRangeReader(ctx, info.URL, info.Size)
+	} else {
+		p, err := readPrefix(ctx, info.URL)
+		if err != nil {
+			return info, nil
+		}
+		r = p
+	}
+	info.Tags, _ = audiotag.Read(r, info.Size, info.Format)
+	return info, nil
+}
+
+// formatOf prefers the URL's extension: file hosts often label everything
+// application/octet-stream, while the name in the path is usually right.
+func formatOf(rawURL, contentType string) (audiotag.Format, bool) {
+	if u, err := url.Parse(rawURL); err == nil {
+		if f, ok := audiotag.FormatOf(u.Path); ok {
+			return f, true
+		}
+	}
+	return ContentFormat(contentType)
+}
+
+// ContentFormat picks the format a Content-Type names, if it is one the
+// engine plays.
+func ContentFormat(contentType string) (audiotag.Format, bool) {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return 0, false
+	}
+	f, ok := contentFormats[strings.ToLower(mt)]
+	return f, ok
+}
+
+func totalFromContentRange(v string) int64 {
+	_, total, ok := strings.Cut(v, "/")
+	if !ok {
+		return 0
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(total), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+func do(ctx context.Context, method, rawURL, byteRange string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if byteRange != "" {
+		req.Header.Set("Range", byteRange)
+	}
+	return Client.Do(req)
+}
+
+// Open starts a GET of the whole file with client. The caller closes the
+// body.
+func Open(rawURL string, client *http.Client) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("httpfile: %s", resp.Status)
+	}
+	return resp.Body, nil
+}
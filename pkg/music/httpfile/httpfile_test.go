@@ -0,0 +1,187 @@
+package httpfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/audiotag"
+)
+
+// mp3File is an ID3v2.3 tag carrying a title, then seconds of 128 kbit/s CBR
+// audio (one real frame header; the duration reader needs no more).
+func mp3File(title string, seconds int) []byte {
+	body := append([]byte{0}, title...)
+	frame := append([]byte("TIT2"), 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(frame[4:], uint32(len(body)))
+	frame = append(frame, body...)
+	n := len(frame)
+	b := []byte{'I', 'D', '3', 3, 0, 0, 0, 0, byte(n >> 7 & 0x7f), byte(n & 0x7f)}
+	b = append(b, frame...)
+	audio := make([]byte, seconds*128000/8)
+	copy(audio, []byte{0xFF, 0xFB, 0x90, 0x00})
+	return append(b, audio...)
+}
+
+// fileServer serves data like a static file host: ranges, HEAD, a length.
+func fileServer(t *testing.T, data []byte, requests *int) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests != nil {
+			*requests++
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestProbeReadsTagsOverRanges(t *testing.T) {
+	data := mp3File("Remote Song", 4)
+	var requests int
+	srv := fileServer(t, data, &requests)
+
+	info, err := Probe(srv.URL + "/music/song.mp3?token=abc")
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	if info.Format != audiotag.MP3 || info.Size != int64(len(data)) || !info.Ranges {
+		t.Fatalf("info = %+v", info)
+	}
+	if info.Tags.Title != "Remote Song" {
+		t.Errorf("title = %q", info.Tags.Title)
+	}
+	if d := info.Duration(); d < 3900*time.Millisecond || d > 4100*time.Millisecond {
+		t.Errorf("duration = %v, want about 4s", d)
+	}
+	// A HEAD plus one block: small reads must share cached blocks.
+	if requests > 3 {
+		t.Errorf("probe made %d requests, want at most 3", requests)
+	}
+}
+
+// A server that answers ranges with less than it promised fails the read
+// rather than handing the tag readers a short block, and the short block is
+// not cached: the next read asks again.
+func TestRangeReaderRejectsTruncatedPartialContent(t *testing.T) {
+	data := make([]byte, 2*blockSize)
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes 0-%d/%d", blockSize-1, len(data)))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(data[:100])
+	}))
+	defer srv.Close()
+
+	r := newRangeReader(t.Context(), srv.URL, int64(len(data)))
+	for i := 0; i < 2; i++ {
+		p := make([]byte, 16)
+		if _, err := r.ReadAt(p, 200); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("read %d: err = %v, want io.ErrUnexpectedEOF", i, err)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("made %d requests, want 2: a short block must not be cached", requests)
+	}
+}
+
+func TestStatRefusesStreams(t *testing.T) {
+	// Icecast names an .mp3 mount like a file but never ends.
+	icy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Header().Set("Icy-Name", "Some Station")
+		w.Header().Set("Content-Length", "1000")
+	}))
+	defer icy.Close()
+	// No length at all: a chunked stream.
+	chunked := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.(http.Flusher).Flush()
+	}))
+	defer chunked.Close()
+	// A web page, whatever its path says.
+	page := fileServer(t, []byte("<html></html>"), nil)
+
+	for name, u := range map[string]string{
+		"icy":     icy.URL + "/live.mp3",
+		"chunked": chunked.URL + "/stream",
+		"page":    page.URL + "/watch",
+	} {
+		if _, err := Stat(u); !errors.Is(err, ErrNotAFile) {
+			t.Errorf("%s: Stat = %v, want ErrNotAFile", name, err)
+		}
+	}
+}
+
+func TestStatUsesContentTypeWithoutExtension(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "audio/flac")
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(make([]byte, 5000)))
+	}))
+	defer srv.Close()
+
+	info, err := Stat(srv.URL + "/download?id=7")
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.Format != audiotag.FLAC {
+		t.Errorf("format = %v, want FLAC", info.Format)
+	}
+}
+
+// withRefresher installs r for the test and removes it afterwards.
+func withRefresher(t *testing.T, r Refresher) {
+	t.Helper()
+	SetRefresher(r)
+	t.Cleanup(func() { SetRefresher(nil) })
+}
+
+func attachmentURL(expiry time.Time) string {
+	return fmt.Sprintf("https://cdn.discordapp.com/attachments/1/2/song.mp3?ex=%x&is=0&hm=abc", expiry.Unix())
+}
+
+func TestFreshRenewsOnlyExpiringAttachmentLinks(t *testing.T) {
+	calls := 0
+	withRefresher(t, func(urls []string) ([]string, error) {
+		calls++
+		return []string{"https://cdn.discordapp.com/attachments/1/2/song.mp3?ex=fresh"}, nil
+	})
+
+	valid := attachmentURL(time.Now().Add(20 * time.Hour))
+	if got, err := Fresh(valid); err != nil || got != valid {
+		t.Fatalf("Fresh(valid) = %q, %v; want it untouched", got, err)
+	}
+	other := "https://example.com/song.mp3?ex=1"
+	if got, err := Fresh(other); err != nil || got != other {
+		t.Fatalf("Fresh(non-attachment) = %q, %v; want it untouched", got, err)
+	}
+	if calls != 0 {
+		t.Fatalf("refresher called %d times for links that needed nothing", calls)
+	}
+
+	for _, stale := range []string{
+		attachmentURL(time.Now().Add(-time.Minute)),
+		attachmentURL(time.Now().Add(10 * time.Minute)), // inside the margin
+		"https://media.discordapp.net/attachments/1/2/song.mp3",
+	} {
+		got, err := Fresh(stale)
+		if err != nil || got != "https://cdn.discordapp.com/attachments/1/2/song.mp3?ex=fresh" {
+			t.Fatalf("Fresh(%q) = %q, %v; want the renewed link", stale, got, err)
+		}
+	}
+}
+
+func TestFreshWithoutRefresherReportsExpiry(t *testing.T) {
+	SetRefresher(nil)
+	_, err := Fresh(attachmentURL(time.Now().Add(-time.Hour)))
+	if !errors.Is(err, ErrAttachmentExpired) {
+		t.Fatalf("want ErrAttachmentExpired, got %v", err)
+	}
+}
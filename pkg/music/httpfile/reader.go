@@ -0,0 +1,104 @@
+package httpfile
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Tag readers make many small reads — an ID3 frame header, then its body, then
+// the next — so each is served from a cached block rather than a request of
+// its own. 64 KB covers a typical ID3 tag or Ogg header page in one fetch.
+const (
+	blockSize = 64 << 10
+	maxBlocks = 64 // 4 MB, the most audiotag reads into an Ogg comment packet
+)
+
+// rangeReader is an io.ReaderAt over a remote file, fetched in blocks by
+// ranged GETs.
+type rangeReader struct {
+	ctx  context.Context
+	url  string
+	size int64
+
+	mu     sync.Mutex
+	blocks map[int64][]byte
+}
+
+func newRangeReader(ctx context.Context, url string, size int64) *rangeReader {
+	return &rangeReader{ctx: ctx, url: url, size: size, blocks: map[int64][]byte{}}
+}
+
+func (r *rangeReader) ReadAt(p []byte, off int64) (int, error) {
+	n := 0
+	for n < len(p) {
+		pos := off + int64(n)
+		if pos >= r.size {
+			return n, io.EOF
+		}
+		b, err := r.block(pos / blockSize)
+		if err != nil {
+			return n, err
+		}
+		if pos%blockSize >= int64(len(b)) {
+			return n, io.ErrUnexpectedEOF
+		}
+		n += copy(p[n:], b[pos%blockSize:])
+	}
+	return n, nil
+}
+
+func (r *rangeReader) block(i int64) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if b, ok := r.blocks[i]; ok {
+		return b, nil
+	}
+	start := i * blockSize
+	end := min(start+blockSize, r.size) - 1
+	resp, err := do(r.ctx, http.MethodGet, r.url, fmt.Sprintf("bytes=%d-%d", start, end))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		return nil, fmt.Errorf("httpfile: range request: %s", resp.Status)
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, end-start+1))
+	if err != nil {
+		return nil, err
+	}
+	// A 206 cut short, by a server or a dropped connection, is not the block
+	// asked for; caching it would turn one bad response into a bad file.
+	if int64(len(b)) != end-start+1 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if len(r.blocks) >= maxBlocks {
+		clear(r.blocks) // a probe never revisits much; starting over is fine
+	}
+	r.blocks[i] = b
+	return b, nil
+}
+
+// prefixLimit is how much of a file is read when the server ignores ranges:
+// enough for ID3 and most container headers, not enough to matter if the
+// file turns out to be large.
+const prefixLimit = 256 << 10
+
+// readPrefix fetches the head of a file for a server without range support.
+// Reads past it fail, which the tag readers treat as "not found".
+func readPrefix(ctx context.Context, url string) (io.ReaderAt, error) {
+	resp, err := do(ctx, http.MethodGet, url, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(resp.Body, prefixLimit))
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(b), nil
+}
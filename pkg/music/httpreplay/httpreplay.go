@@ -0,0 +1,304 @@
+// Package httpreplay records HTTP exchanges as fixture files and serves them
+// back offline, so the tests that would otherwise need YouTube or SoundCloud —
+// resolve, open and demux included — run in CI without a network.
+//
+// The clients that talk to those sites build their transport with Wrap. Wrap
+// costs one atomic load per request while nothing is installed; a test
+// installs a Transport with Install, and every wrapped client, including the
+// package-level ones built before the test started, goes through it until the
+// test restores the previous one.
+//
+// A fixture is matched on the request's method, URL and Range header, and a
+// digest of its body. The parts of a request that change between runs without
+// changing the answer are left out of the match: the query parameters in
+// IgnoreQuery, and the top-level JSON body keys in IgnoreJSON. New's defaults
+// cover this module's clients — SoundCloud's rotating client_id, and the
+// InnerTube "context" block, which carries the client version and visitor
+// session rather than what was asked.
+package httpreplay
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// Mode selects what a Transport does with a request.
+type Mode string
+
+const (
+	// ModeRecord sends the request to the network and saves the response as a
+	// fixture, replacing any earlier one for the same request.
+	ModeRecord Mode = "record"
+	// ModeReplay serves the saved response and never touches the network. A
+	// request with no fixture fails with ErrNoFixture.
+	ModeReplay Mode = "replay"
+)
+
+// ErrNoFixture means a replayed request was never recorded.
+var ErrNoFixture = errors.New("httpreplay: no fixture")
+
+// Transport is an http.RoundTripper that records to or replays from Dir.
+type Transport struct {
+	Mode Mode
+	// Dir holds one JSON file per recorded request.
+	Dir string
+	// Base carries recorded requests when the Transport is used directly as a
+	// RoundTripper; nil means http.DefaultTransport. Through Wrap, the wrapped
+	// client's own transport is used instead.
+	Base http.RoundTripper
+	// MaxMediaBody caps how much of an audio or video response body is
+	// recorded (0 = all of it). A few seconds of a track exercise the demuxer
+	// as well as the whole of it, at a fraction of the repository. Other
+	// bodies are always kept whole: a page cut short may lose what a scrape
+	// was looking for.
+	MaxMediaBody int64
+	// IgnoreQuery names query parameters left out of the match. They are
+	// left out of the stored URL too: a fixture is committed, and one of them
+	// is SoundCloud's client_id.
+	IgnoreQuery []string
+	// IgnoreJSON names top-level keys of a JSON request body left out of the
+	// match.
+	IgnoreJSON []string
+}
+
+// New returns a Transport in mode over dir, with the match exclusions this
+// module's clients need and recorded media capped at 1 MiB.
+func New(mode Mode, dir string) *Transport {
+	return &Transport{
+		Mode:         mode,
+		Dir:          dir,
+		MaxMediaBody: 1 << 20,
+		IgnoreQuery:  []string{"client_id"},
+		IgnoreJSON:   []string{"context"},
+	}
+}
+
+// fixture is the file a recorded exchange is kept in. The request is stored
+// for whoever reads the file, not for matching: the file name is the match.
+type fixture struct {
+	Request struct {
+		Method string `json:"method"`
+		URL    string `json:"url"`
+		Range  string `json:"range,omitempty"`
+		Body   string `json:"body,omitempty"`
+	} `json:"request"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// RoundTrip records or replays req according to t.Mode.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.roundTrip(req, t.Base)
+}
+
+func (t *Transport) roundTrip(req *http.Request, base http.RoundTripper) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("httpreplay: read request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	path := filepath.Join(t.Dir, t.fileName(req, body))
+
+	switch t.Mode {
+	case ModeReplay:
+		return t.replay(req, path)
+	case ModeRecord:
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		return t.record(req, body, path, base)
+	default:
+		return nil, fmt.Errorf("httpreplay: unknown mode %q", t.Mode)
+	}
+}
+
+func (t *Transport) replay(req *http.Request, path string) (*http.Response, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w for %s %s (%s)", ErrNoFixture, req.Method, req.URL, filepath.Base(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("httpreplay: %w", err)
+	}
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("httpreplay: %s: %w", path, err)
+	}
+	return f.response(req), nil
+}
+
+func (t *Transport) record(req *http.Request, body []byte, path string, base http.RoundTripper) (*http.Response, error) {
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var src io.Reader = resp.Body
+	if ct := resp.Header.Get("Content-Type"); t.MaxMediaBody > 0 &&
+		(strings.HasPrefix(ct, "audio/") || strings.HasPrefix(ct, "video/")) {
+		src = io.LimitReader(resp.Body, t.MaxMediaBody)
+	}
+	respBody, err := io.ReadAll(src)
+	if err != nil {
+		return nil, fmt.Errorf("httpreplay: record %s: %w", req.URL, err)
+	}
+
+	var f fixture
+	f.Request.Method = req.Method
+	f.Request.URL = t.matchURL(req).String()
+	f.Request.Range = req.Header.Get("Range")
+	f.Request.Body = string(body)
+	f.Status = resp.StatusCode
+	f.Header = resp.Header.Clone()
+	// The body is stored decoded and possibly cut short, so headers describing
+	// the bytes on the wire no longer do.
+	f.Header.Del("Content-Length")
+	f.Header.Del("Content-Encoding")
+	// A session cookie (or one set for the configured yt-dlp cookies' account)
+	// has no business in a committed file, and no client here reads it back.
+	f.Header.Del("Set-Cookie")
+	f.Body = respBody
+
+	data, err := json.MarshalIndent(&f, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(t.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("httpreplay: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return nil, fmt.Errorf("httpreplay: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, fmt.Errorf("httpreplay: %w", err)
+	}
+	return f.response(req), nil
+}
+
+func (f *fixture) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        f.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(f.Body)),
+		ContentLength: int64(len(f.Body)),
+		Request:       req,
+	}
+}
+
+// fileName is the fixture file for a request: readable enough to find by eye
+// in a directory listing, with a digest of everything matched on.
+func (t *Transport) fileName(req *http.Request, body []byte) string {
+	u := t.matchURL(req)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n", req.Method, u.String(), req.Header.Get("Range"))
+	h.Write(t.matchBody(body))
+	sum := hex.EncodeToString(h.Sum(nil))[:16]
+
+	name := strings.Trim(strings.NewReplacer("/", "_", ".", "_").Replace(u.Host+u.Path), "_")
+	if len(name) > 60 {
+		name = name[:60]
+	}
+	return fmt.Sprintf("%s-%s-%s.json", req.Method, name, sum)
+}
+
+// matchURL is req's URL as matched and stored: IgnoreQuery parameters and the
+// fragment removed.
+func (t *Transport) matchURL(req *http.Request) *url.URL {
+	u := *req.URL
+	q := u.Query()
+	for _, k := range t.IgnoreQuery {
+		q.Del(k)
+	}
+	u.RawQuery = q.Encode() // Encode sorts, so parameter order does not matter
+	u.Fragment = ""
+	return &u
+}
+
+// matchBody is the part of a request body the match goes by: a JSON object
+// without its IgnoreJSON keys, re-encoded with sorted keys, or the body as it
+// is when it is not a JSON object.
+func (t *Transport) matchBody(body []byte) []byte {
+	if len(t.IgnoreJSON) == 0 || len(body) == 0 {
+		return body
+	}
+	var obj map[string]json.RawMessage
+	if json.Unmarshal(body, &obj) != nil {
+		return body
+	}
+	for _, k := range t.IgnoreJSON {
+		delete(obj, k)
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b bytes.Buffer
+	for _, k := range keys {
+		var v any
+		_ = json.Unmarshal(obj[k], &v)
+		canon, _ := json.Marshal(v) // maps marshal with sorted keys
+		fmt.Fprintf(&b, "%q:%s\n", k, canon)
+	}
+	return b.Bytes()
+}
+
+var installed atomic.Pointer[Transport]
+
+// Install routes every Wrap-ped transport through t, and returns the function
+// that puts back whatever was installed before. Passing nil uninstalls.
+func Install(t *Transport) (restore func()) {
+	prev := installed.Swap(t)
+	return func() { installed.Store(prev) }
+}
+
+// Wrap returns base (nil meaning http.DefaultTransport) routed through the
+// installed Transport whenever one is installed.
+func Wrap(base http.RoundTripper) http.RoundTripper {
+	return hook{base: base}
+}
+
+type hook struct{ base http.RoundTripper }
+
+func (h hook) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t := installed.Load(); t != nil {
+		return t.roundTrip(req, h.base)
+	}
+	if h.base != nil {
+		return h.base.RoundTrip(req)
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// HasFixtures reports whether dir holds any recorded exchange.
+func HasFixtures(dir string) bool {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	return len(matches) > 0
+}
@@ -0,0 +1,138 @@
+package httpreplay
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// get sends a request through c and returns the status and body.
+func get(t *testing.T, c *http.Client, method, url, body string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(b)
+}
+
+// What is recorded against a server is served back once it is gone, matched
+// on what was asked rather than on the volatile parts of the request.
+func TestRecordThenReplayOffline(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		b, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"path":%q,"q":%q,"body":%d}`, r.URL.Path, r.URL.Query().Get("q"), len(b))
+	}))
+	dir := t.TempDir()
+	c := &http.Client{Transport: Wrap(nil)}
+
+	restore := Install(New(ModeRecord, dir))
+	_, first := get(t, c, http.MethodPost, srv.URL+"/search?q=a&client_id=old",
+		`{"context":{"client":{"visitorData":"one"}},"query":"a"}`)
+	_, other := get(t, c, http.MethodGet, srv.URL+"/other", "")
+	restore()
+	srv.Close()
+
+	Install(New(ModeReplay, dir))
+	t.Cleanup(func() { Install(nil) })
+
+	status, got := get(t, c, http.MethodPost, srv.URL+"/search?client_id=new&q=a",
+		`{"query":"a","context":{"client":{"visitorData":"two"}}}`)
+	if status != http.StatusOK || got != first {
+		t.Errorf("replayed %d %q, want the recorded %q", status, got, first)
+	}
+	if _, got := get(t, c, http.MethodGet, srv.URL+"/other", ""); got != other {
+		t.Errorf("replayed %q, want %q", got, other)
+	}
+	if hits != 2 {
+		t.Errorf("server saw %d requests, want only the 2 recorded", hits)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/search?q=b", strings.NewReader(`{"query":"b"}`))
+	if _, err := c.Do(req); !errors.Is(err, ErrNoFixture) {
+		t.Errorf("unrecorded request: err = %v, want ErrNoFixture", err)
+	}
+}
+
+// Fixtures are committed, so the credentials a request or response carries
+// stay out of them.
+func TestRecordScrubsCredentials(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "SID", Value: "secret-session"})
+		fmt.Fprint(w, "ok")
+	}))
+	defer srv.Close()
+	dir := t.TempDir()
+	c := &http.Client{Transport: Wrap(nil)}
+
+	restore := Install(New(ModeRecord, dir))
+	get(t, c, http.MethodGet, srv.URL+"/tracks?q=a&client_id=secret-id", "")
+	restore()
+
+	files, err := os.ReadDir(dir)
+	if err != nil || len(files) != 1 {
+		t.Fatalf("fixtures: %v, %v", files, err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"secret-id", "secret-session", "Set-Cookie"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("fixture contains %q:\n%s", secret, data)
+		}
+	}
+}
+
+// Media is recorded only up to the cap; everything else whole.
+func TestRecordCapsMediaBodies(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/audio" {
+			w.Header().Set("Content-Type", "audio/webm")
+		} else {
+			w.Header().Set("Content-Type", "text/html")
+		}
+		fmt.Fprint(w, strings.Repeat("x", 100))
+	}))
+	defer srv.Close()
+	tr := New(ModeRecord, t.TempDir())
+	tr.MaxMediaBody = 10
+	c := &http.Client{Transport: tr}
+
+	if _, got := get(t, c, http.MethodGet, srv.URL+"/audio", ""); len(got) != 10 {
+		t.Errorf("media body recorded at %d bytes, want 10", len(got))
+	}
+	if _, got := get(t, c, http.MethodGet, srv.URL+"/page", ""); len(got) != 100 {
+		t.Errorf("page body recorded at %d bytes, want all 100", len(got))
+	}
+	if !HasFixtures(tr.Dir) {
+		t.Error("HasFixtures = false after recording")
+	}
+}
+
+// A wrapped client with nothing installed goes to the network as before.
+func TestWrapPassesThroughWhenNothingIsInstalled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "live")
+	}))
+	defer srv.Close()
+	c := &http.Client{Transport: Wrap(nil)}
+	if _, got := get(t, c, http.MethodGet, srv.URL, ""); got != "live" {
+		t.Errorf("got %q, want the live answer", got)
+	}
+}
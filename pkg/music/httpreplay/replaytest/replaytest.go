@@ -0,0 +1,35 @@
+// Package replaytest wires httpreplay into a test, choosing the mode from the
+// environment the same way for every package:
+//
+//   - MELODIX_RECORD_FIXTURES=1 hits the network and records into the
+//     fixture directory;
+//   - MELODIX_LIVE_TESTS=1 hits the network and records nothing, the
+//     canaries' old behaviour;
+//   - otherwise the test replays its fixtures, and is skipped when it has none.
+//
+// It is a package of its own so that importing httpreplay does not link the
+// testing package into the binaries.
+package replaytest
+
+import (
+	"os"
+	"testing"
+
+	"github.com/keshon/melodix/pkg/music/httpreplay"
+)
+
+// Start installs the fixtures in dir for the rest of t, or skips t when there
+// is nothing to replay and no network was asked for.
+func Start(t testing.TB, dir string) {
+	t.Helper()
+	switch {
+	case os.Getenv("MELODIX_RECORD_FIXTURES") != "":
+		t.Cleanup(httpreplay.Install(httpreplay.New(httpreplay.ModeRecord, dir)))
+	case os.Getenv("MELODIX_LIVE_TESTS") != "":
+		// Live: leave whatever is installed, normally nothing, in place.
+	case !httpreplay.HasFixtures(dir):
+		t.Skipf("no fixtures in %s: record them with MELODIX_RECORD_FIXTURES=1", dir)
+	default:
+		t.Cleanup(httpreplay.Install(httpreplay.New(httpreplay.ModeReplay, dir)))
+	}
+}
@@ -0,0 +1,126 @@
+// Package icy reads the in-band metadata SHOUTcast and Icecast servers
+// interleave with a stream's audio when a client asks for it.
+//
+// A client opts in with the request header "Icy-MetaData: 1". A server that
+// supports it answers with "icy-metaint: N", and from then on every N audio
+// bytes are followed by one metadata block: a length byte L, then L×16 bytes of
+// text padded with NULs, e.g. "StreamTitle='Artist - Song';". L is 0 when
+// nothing changed, which is most blocks. Left in, those blocks are garbage to
+// a decoder, so a reader that asked for them must strip them.
+package icy
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// RequestHeader is the header, set to "1", that asks a server for metadata.
+const RequestHeader = "Icy-MetaData"
+
+// maxBlock is the largest block the length byte can describe (255×16).
+const maxBlock = 255 * 16
+
+// ErrBadMetaInt means the server advertised an unusable metadata interval.
+var ErrBadMetaInt = errors.New("icy: invalid icy-metaint")
+
+// MetaInt reads the metadata interval from a response. ok is false when the
+// server sent none, i.e. the stream carries no interleaved metadata and its
+// body is plain audio.
+func MetaInt(h http.Header) (n int, ok bool, err error) {
+	v := strings.TrimSpace(h.Get("icy-metaint"))
+	if v == "" {
+		return 0, false, nil
+	}
+	n, err = strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0, false, ErrBadMetaInt
+	}
+	return n, true, nil
+}
+
+// Reader strips metadata blocks from an ICY stream, leaving the audio, and
+// reports each StreamTitle it finds. Titles are reported as they arrive, with
+// no deduplication: a server repeats the current title from time to time, and
+// telling a repeat from a change is the caller's business.
+type Reader struct {
+	r       io.Reader
+	metaInt int
+	// left is how many audio bytes remain before the next metadata block.
+	left    int
+	onTitle func(title string)
+	block   [maxBlock]byte
+}
+
+// NewReader wraps the body of a response that advertised metaInt. onTitle may
+// be nil; it runs on the reading goroutine and must not block.
+func NewReader(r io.Reader, metaInt int, onTitle func(title string)) *Reader {
+	return &Reader{r: r, metaInt: metaInt, left: metaInt, onTitle: onTitle}
+}
+
+// Read returns audio bytes only. A metadata block is consumed whole when the
+// audio before it runs out, so a Read never returns part of one.
+func (r *Reader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if r.left == 0 {
+		if err := r.readBlock(); err != nil {
+			return 0, err
+		}
+		r.left = r.metaInt
+	}
+	if len(p) > r.left {
+		p = p[:r.left]
+	}
+	n, err := r.r.Read(p)
+	r.left -= n
+	return n, err
+}
+
+func (r *Reader) readBlock() error {
+	var size [1]byte
+	if _, err := io.ReadFull(r.r, size[:]); err != nil {
+		return err
+	}
+	n := int(size[0]) * 16
+	if n == 0 {
+		return nil
+	}
+	if _, err := io.ReadFull(r.r, r.block[:n]); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	if title, ok := StreamTitle(string(r.block[:n])); ok && r.onTitle != nil {
+		r.onTitle(title)
+	}
+	return nil
+}
+
+// StreamTitle extracts StreamTitle from one metadata block. ok is false when
+// the block has no such field; an empty title with ok true is a station
+// clearing it, which usually means an ad or a jingle.
+//
+// Titles routinely contain apostrophes ("Don't Stop"), and servers do not
+// escape them, so the value runs to the "';" that ends the field rather than
+// to the next quote.
+func StreamTitle(block string) (title string, ok bool) {
+	block = strings.TrimRight(block, "\x00")
+	const key = "StreamTitle='"
+	i := strings.Index(block, key)
+	if i < 0 {
+		return "", false
+	}
+	rest := block[i+len(key):]
+	if end := strings.Index(rest, "';"); end >= 0 {
+		rest = rest[:end]
+	} else {
+		// The last field may end the block without its semicolon.
+		rest = strings.TrimSuffix(rest, "'")
+	}
+	return strings.TrimSpace(rest), true
+}
@@ -0,0 +1,87 @@
+package icy
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// metaBlock encodes text as one metadata block: a length byte in 16-byte units,
+// then the text padded with NULs.
+func metaBlock(text string) []byte {
+	n := (len(text) + 15) / 16
+	out := make([]byte, 1+n*16)
+	out[0] = byte(n)
+	copy(out[1:], text)
+	return out
+}
+
+func TestReaderStripsMetadata(t *testing.T) {
+	var stream bytes.Buffer
+	stream.WriteString("aaaa")
+	stream.Write(metaBlock("StreamTitle='First';StreamUrl='';"))
+	stream.WriteString("bbbb")
+	stream.WriteByte(0) // nothing changed
+	stream.WriteString("cccc")
+	stream.Write(metaBlock("StreamTitle='Don't Stop';"))
+	stream.WriteString("dd")
+
+	var titles []string
+	// One byte per underlying Read, so every block straddles many reads.
+	r := NewReader(iotest.OneByteReader(&stream), 4, func(title string) { titles = append(titles, title) })
+	audio, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(audio) != "aaaabbbbccccdd" {
+		t.Fatalf("audio = %q", audio)
+	}
+	if len(titles) != 2 || titles[0] != "First" || titles[1] != "Don't Stop" {
+		t.Fatalf("titles = %q", titles)
+	}
+}
+
+func TestReaderTruncatedBlock(t *testing.T) {
+	block := metaBlock("StreamTitle='Cut';")
+	r := NewReader(bytes.NewReader(append([]byte("aaaa"), block[:5]...)), 4, nil)
+	if _, err := io.ReadAll(r); err != io.ErrUnexpectedEOF {
+		t.Fatalf("err = %v, want io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestStreamTitle(t *testing.T) {
+	cases := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"StreamTitle='Artist - Song';StreamUrl='http://x';", "Artist - Song", true},
+		{"StreamTitle='It's Over';", "It's Over", true},
+		{"StreamTitle='No Semicolon'", "No Semicolon", true},
+		{"StreamTitle='';\x00\x00\x00", "", true},
+		{"StreamUrl='http://x';", "", false},
+	}
+	for _, tc := range cases {
+		got, ok := StreamTitle(tc.in)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("StreamTitle(%q) = %q, %v; want %q, %v", tc.in, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestMetaInt(t *testing.T) {
+	h := http.Header{}
+	if _, ok, err := MetaInt(h); ok || err != nil {
+		t.Fatalf("no header: ok=%v err=%v", ok, err)
+	}
+	h.Set("icy-metaint", " 16000 ")
+	if n, ok, err := MetaInt(h); n != 16000 || !ok || err != nil {
+		t.Fatalf("got %d, %v, %v", n, ok, err)
+	}
+	h.Set("icy-metaint", "0")
+	if _, _, err := MetaInt(h); err == nil || !strings.Contains(err.Error(), "metaint") {
+		t.Fatalf("zero interval should be rejected, got %v", err)
+	}
+}
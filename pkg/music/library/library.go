@@ -0,0 +1,372 @@
+// Package library indexes audio files under configured directories for the
+// local source and confines every file the engine opens to those directories.
+// It is shared by the local source (search, resolve) and the localfile parser
+// (open), the same way soundcloudapi is shared by its source and parser.
+//
+// Confinement is the point of the package, not a detail of it. A track's URL
+// names a filesystem path, and paths come back from places nobody validated —
+// a /play argument, a replayed history row written by an older version, a
+// crafted "local:" id. Every open therefore goes through Confine, which
+// resolves symlinks before checking the root, so a link planted inside a root
+// cannot reach /etc. Do not hand a path to ffmpeg or os.Open without it.
+package library
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/keshon/melodix/pkg/music/audiotag"
+)
+
+// ErrOutsideRoots is returned for a path that is not under any configured
+// directory once symlinks are resolved.
+var ErrOutsideRoots = errors.New("library: path is outside the library directories")
+
+// ErrNotFound is returned for an id the index does not know.
+var ErrNotFound = errors.New("library: track not found")
+
+// ErrNoLibrary is what the local source and the localfile parser wrap when
+// Default is nil: no library directories are configured. A history row
+// replayed on an instance without LOCAL_DIRS gets it, typically.
+var ErrNoLibrary = errors.New("library: no library directories configured")
+
+// Entry is one indexed file.
+type Entry struct {
+	// ID is a short hash of Path. It is what search results and autocomplete
+	// carry, because a path does not fit in a Discord component id.
+	ID     string `json:"id"`
+	Path   string `json:"path"` // symlink-free absolute path
+	Title  string `json:"title"`
+	Artist string `json:"artist"`
+	Album  string `json:"album"`
+	// DurationMs is read from the container where it can be, and otherwise an
+	// underestimate from the file size (see audiotag.EstimateDuration). Never
+	// zero for a playable file: RecoveryStream reads a zero duration as a live stream and
+	// would replay the file from the top when it ends.
+	DurationMs int64 `json:"duration_ms"`
+	Size       int64 `json:"size"`
+	ModTime    int64 `json:"mod_time"` // unix nanos; with Size, decides whether a rescan re-reads tags
+}
+
+// Duration returns DurationMs as a time.Duration.
+func (e Entry) Duration() time.Duration { return time.Duration(e.DurationMs) * time.Millisecond }
+
+// IndexStore persists the index one entry at a time, so a rescan that finds
+// one new file writes one record. A nil IndexStore keeps the index in memory.
+type IndexStore interface {
+	Load() (map[string]Entry, error)
+	Put(Entry) error
+	Delete(id string) error
+}
+
+// Library is the index over a fixed set of root directories.
+type Library struct {
+	roots []string // symlink-free absolute paths
+	idx   IndexStore
+	log   zerolog.Logger
+
+	// scanMu keeps two scans from interleaving their Put/Delete calls. mu
+	// guards byID and is never held across file I/O.
+	scanMu sync.Mutex
+	mu     sync.RWMutex
+	byID   map[string]Entry
+}
+
+// New resolves roots and loads the persisted index. Roots that do not exist
+// are logged and left out rather than failing startup: a music drive that is
+// not mounted yet should cost its tracks, not the bot.
+func New(roots []string, idx IndexStore, log zerolog.Logger) (*Library, error) {
+	l := &Library{idx: idx, log: log, byID: map[string]Entry{}}
+	for _, r := range roots {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		real, err := realPath(r)
+		if err != nil {
+			log.Warn().Str("dir", r).Err(err).Msg("library_root_unavailable")
+			continue
+		}
+		l.roots = append(l.roots, real)
+	}
+	if idx != nil {
+		m, err := idx.Load()
+		if err != nil {
+			return nil, err
+		}
+		for id, e := range m {
+			// An index written with other roots configured must not keep
+			// offering files the current config no longer covers.
+			if l.within(e.Path) {
+				l.byID[id] = e
+			}
+		}
+	}
+	return l, nil
+}
+
+// Roots returns the resolved library directories.
+func (l *Library) Roots() []string { return slices.Clone(l.roots) }
+
+// Len returns the number of indexed files.
+func (l *Library) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.byID)
+}
+
+// Entries returns a snapshot of the index, in no particular order.
+func (l *Library) Entries() []Entry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	out := make([]Entry, 0, len(l.byID))
+	for _, e := range l.byID {
+		out = append(out, e)
+	}
+	return out
+}
+
+// Confine resolves path (symlinks included) and returns it if it lies under a
+// root, or ErrOutsideRoots. This is the only way a path should reach the disk.
+//
+// It checks the path, not an open file, so a symlink swapped between this
+// call and the open could still escape. Doing that requires write access to a
+// library directory, which is already more than the bot's own privileges.
+func (l *Library) Confine(path string) (string, error) {
+	real, err := realPath(path)
+	if err != nil {
+		return "", err
+	}
+	if !l.within(real) {
+		return "", ErrOutsideRoots
+	}
+	return real, nil
+}
+
+func (l *Library) within(path string) bool {
+	for _, root := range l.roots {
+		rel, err := filepath.Rel(root, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// IDPrefix marks a track URL, and a /play input, as a library id. Tracks
+// carry the id rather than their path: the URL is shown and linked in
+// embeds, and the path is the server's directory layout.
+const IDPrefix = "local:"
+
+// Find returns the entry a track URL names: a "local:<id>" reference or, for
+// history rows written before tracks carried ids, a path. The path is
+// confined either way.
+func (l *Library) Find(ref string) (Entry, error) {
+	if id, ok := strings.CutPrefix(ref, IDPrefix); ok {
+		e, err := l.Lookup(id)
+		if err != nil {
+			return Entry{}, err
+		}
+		ref = e.Path
+	}
+	return l.ByPath(ref)
+}
+
+// Lookup returns the entry with the given id.
+func (l *Library) Lookup(id string) (Entry, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if e, ok := l.byID[id]; ok {
+		return e, nil
+	}
+	return Entry{}, ErrNotFound
+}
+
+// ByPath returns the indexed entry for a confined path, reading the file on
+// the spot when the index has not caught up with it yet.
+func (l *Library) ByPath(path string) (Entry, error) {
+	real, err := l.Confine(path)
+	if err != nil {
+		return Entry{}, err
+	}
+	if e, err := l.Lookup(idFor(real)); err == nil {
+		return e, nil
+	}
+	if !Supported(real) {
+		return Entry{}, ErrNotFound
+	}
+	fi, err := os.Stat(real)
+	if err != nil {
+		return Entry{}, err
+	}
+	return l.probe(real, fi), nil
+}
+
+// Scan walks every root, reads tags from files that are new or changed since
+// the last scan, and drops entries whose file is gone. Symlinks are not
+// followed during the walk: one pointing out of the root would otherwise be
+// indexed and then refused at play time.
+func (l *Library) Scan() (added, removed int, err error) {
+	l.scanMu.Lock()
+	defer l.scanMu.Unlock()
+	start := time.Now()
+
+	l.mu.RLock()
+	old := make(map[string]Entry, len(l.byID))
+	for id, e := range l.byID {
+		old[id] = e
+	}
+	l.mu.RUnlock()
+
+	seen := make(map[string]bool, len(old))
+	for _, root := range l.roots {
+		walkErr := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				// An unreadable subdirectory costs its own files, not the scan.
+				l.log.Debug().Str("path", path).Err(err).Msg("library_walk_skipped")
+				if d != nil && d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() || !d.Type().IsRegular() || !Supported(path) {
+				return nil
+			}
+			fi, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			id := idFor(path)
+			seen[id] = true
+			if e, ok := old[id]; ok && e.Size == fi.Size() && e.ModTime == fi.ModTime().UnixNano() {
+				return nil
+			}
+			e := l.probe(path, fi)
+			l.put(e)
+			if _, existed := old[id]; !existed {
+				added++
+			}
+			return nil
+		})
+		if walkErr != nil {
+			err = errors.Join(err, walkErr)
+		}
+	}
+	for id := range old {
+		if !seen[id] {
+			l.remove(id)
+			removed++
+		}
+	}
+	l.log.Info().Int("tracks", l.Len()).Int("added", added).Int("removed", removed).
+		Dur("took", time.Since(start)).Msg("library_scan_finished")
+	return added, removed, err
+}
+
+func (l *Library) probe(path string, fi fs.FileInfo) Entry {
+	f, _ := audiotag.FormatOf(path)
+	t, err := readTags(path, fi.Size(), f)
+	if err != nil {
+		l.log.Debug().Str("path", path).Err(err).Msg("library_tags_unreadable")
+	}
+	e := Entry{
+		ID:         idFor(path),
+		Path:       path,
+		Title:      t.Title,
+		Artist:     t.Artist,
+		Album:      t.Album,
+		DurationMs: t.Duration.Milliseconds(),
+		Size:       fi.Size(),
+		ModTime:    fi.ModTime().UnixNano(),
+	}
+	if e.Title == "" {
+		e.Title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if e.DurationMs <= 0 {
+		e.DurationMs = audiotag.EstimateDuration(fi.Size(), f).Milliseconds()
+	}
+	return e
+}
+
+func (l *Library) put(e Entry) {
+	l.mu.Lock()
+	l.byID[e.ID] = e
+	l.mu.Unlock()
+	if l.idx != nil {
+		if err := l.idx.Put(e); err != nil {
+			l.log.Warn().Str("path", e.Path).Err(err).Msg("library_index_put_failed")
+		}
+	}
+}
+
+func (l *Library) remove(id string) {
+	l.mu.Lock()
+	delete(l.byID, id)
+	l.mu.Unlock()
+	if l.idx != nil {
+		if err := l.idx.Delete(id); err != nil {
+			l.log.Warn().Str("id", id).Err(err).Msg("library_index_delete_failed")
+		}
+	}
+}
+
+func readTags(path string, size int64, f audiotag.Format) (audiotag.Tags, error) {
+	fh, err := os.Open(path)
+	if err != nil {
+		return audiotag.Tags{}, err
+	}
+	defer fh.Close()
+	return audiotag.Read(fh, size, f)
+}
+
+// idFor is 12 hex characters of the path's SHA-1: short enough for a component
+// id next to its prefix, and collisions within one library are not a practical
+// concern at that length.
+func idFor(path string) string {
+	sum := sha1.Sum([]byte(path))
+	return hex.EncodeToString(sum[:6])
+}
+
+func realPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(abs)
+}
+
+// Supported reports whether path has an extension the library indexes.
+func Supported(path string) bool {
+	_, ok := audiotag.FormatOf(path)
+	return ok
+}
+
+// Passthrough reports whether the file may hold Opus the engine can forward
+// without ffmpeg (.opus, .ogg and .webm). An .ogg is as likely to be Vorbis —
+// the demuxer checks the codec and framing at open time and refuses anything
+// else, so this is a preference, not a promise.
+func Passthrough(path string) bool {
+	f, ok := audiotag.FormatOf(path)
+	return ok && f.MayHoldOpus()
+}
+
+// defaultLib is installed once at startup (see SetDefault) and never swapped
+// after, so a plain variable is safe — like stream.SetCache.
+var defaultLib *Library
+
+// SetDefault installs the process-wide library. Call once at startup, before
+// playback; nil (the default) means no directories are configured.
+func SetDefault(l *Library) { defaultLib = l }
+
+// Default returns the process-wide library, or nil when none is configured.
+func Default() *Library { return defaultLib }
@@ -0,0 +1,155 @@
+package library
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/keshon/melodix/pkg/music/opus"
+)
+
+// silentPacket is a 20ms CELT TOC byte and a body; the library never decodes
+// audio, so its shape is all that matters.
+var silentPacket = append([]byte{31 << 3}, bytes.Repeat([]byte{0x11}, 40)...)
+
+func writeOggOpus(t *testing.T, path string, packets int) {
+	t.Helper()
+	var buf bytes.Buffer
+	w := opus.NewOggWriter(&buf)
+	for range packets {
+		if err := w.WritePacket(silentPacket); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, path, buf.Bytes())
+}
+
+func writeFile(t *testing.T, path string, b []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestLibrary(t *testing.T, idx IndexStore, roots ...string) *Library {
+	t.Helper()
+	l, err := New(roots, idx, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return l
+}
+
+func TestScanTracksChanges(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "x", "one.mp3"), []byte("not really an mp3"))
+	writeOggOpus(t, filepath.Join(root, "two.opus"), 50)
+	writeFile(t, filepath.Join(root, "notes.txt"), []byte("not audio"))
+
+	l := newTestLibrary(t, nil, root)
+	added, removed, err := l.Scan()
+	if err != nil || added != 2 || removed != 0 {
+		t.Fatalf("first Scan = %d, %d, %v; want 2, 0, nil", added, removed, err)
+	}
+
+	two, err := l.ByPath(filepath.Join(root, "two.opus"))
+	if err != nil {
+		t.Fatalf("ByPath: %v", err)
+	}
+	if two.Title != "two" {
+		t.Errorf("untagged title = %q, want the file name", two.Title)
+	}
+	if two.DurationMs <= 0 {
+		t.Error("an indexed file must never have a zero duration")
+	}
+
+	if err := os.Remove(filepath.Join(root, "x", "one.mp3")); err != nil {
+		t.Fatal(err)
+	}
+	added, removed, err = l.Scan()
+	if err != nil || added != 0 || removed != 1 {
+		t.Fatalf("rescan = %d, %d, %v; want 0, 1, nil", added, removed, err)
+	}
+	if l.Len() != 1 {
+		t.Fatalf("Len = %d, want 1", l.Len())
+	}
+}
+
+// Confinement is the security boundary of the local source: nothing outside
+// the roots may be opened, whether named directly, through "..", or through a
+// symlink planted inside a root.
+func TestConfineRejectsEscapes(t *testing.T) {
+	root, outside := t.TempDir(), t.TempDir()
+	secret := filepath.Join(outside, "secret.mp3")
+	writeFile(t, secret, []byte("secret"))
+	writeFile(t, filepath.Join(root, "ok.mp3"), []byte("ok"))
+	link := filepath.Join(root, "link.mp3")
+	if err := os.Symlink(secret, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	l := newTestLibrary(t, nil, root)
+	for _, p := range []string{
+		secret,
+		filepath.Join(root, "..", filepath.Base(outside), "secret.mp3"),
+		link,
+	} {
+		if _, err := l.Confine(p); !errors.Is(err, ErrOutsideRoots) {
+			t.Errorf("Confine(%q) = %v, want ErrOutsideRoots", p, err)
+		}
+		if _, err := l.ByPath(p); !errors.Is(err, ErrOutsideRoots) {
+			t.Errorf("ByPath(%q) = %v, want ErrOutsideRoots", p, err)
+		}
+	}
+	if _, err := l.Confine(filepath.Join(root, "ok.mp3")); err != nil {
+		t.Errorf("Confine inside the root: %v", err)
+	}
+
+	if _, _, err := l.Scan(); err != nil {
+		t.Fatal(err)
+	}
+	if l.Len() != 1 {
+		t.Errorf("Scan indexed %d files, want 1: the symlink must be skipped", l.Len())
+	}
+}
+
+type memIndex map[string]Entry
+
+func (m memIndex) Load() (map[string]Entry, error) { return m, nil }
+func (m memIndex) Put(e Entry) error               { m[e.ID] = e; return nil }
+func (m memIndex) Delete(id string) error          { delete(m, id); return nil }
+
+func TestNewDropsEntriesOutsideRoots(t *testing.T) {
+	root, old := t.TempDir(), t.TempDir()
+	inside := filepath.Join(root, "in.mp3")
+	writeFile(t, inside, []byte("in"))
+	realInside, err := filepath.EvalSymlinks(inside)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx := memIndex{
+		"in":  {ID: "in", Path: realInside, Title: "In", DurationMs: 1000},
+		"old": {ID: "old", Path: filepath.Join(old, "gone.mp3"), Title: "Old", DurationMs: 1000},
+	}
+
+	l := newTestLibrary(t, idx, root, filepath.Join(root, "missing"))
+	if _, err := l.Lookup("in"); err != nil {
+		t.Errorf("entry inside the roots was dropped: %v", err)
+	}
+	if _, err := l.Lookup("old"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("entry from a no-longer-configured root was kept: %v", err)
+	}
+	if len(l.Roots()) != 1 {
+		t.Errorf("Roots = %v, want the missing directory left out", l.Roots())
+	}
+}
@@ -25,6 +25,17 @@ func Encode(pcm io.ReadCloser) Reader {
 	}
 }
 
+// CodecVersion reports the version of the built-in Opus encoder, after
+// checking that one can be created. The encoder is compiled to WebAssembly and
+// has no system dependency, so an error here means a broken build or a
+// runtime that cannot host it, and every transcoding parser fails with it.
+func CodecVersion() (string, error) {
+	if _, err := gopus.NewEncoder(SampleRate, Channels, gopus.AppAudio); err != nil {
+		return "", fmt.Errorf("opus: create encoder: %w", err)
+	}
+	return gopus.Version(), nil
+}
+
 type encodeReader struct {
 	src     io.ReadCloser
 	enc     *gopus.Encoder
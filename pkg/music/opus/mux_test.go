@@ -0,0 +1,230 @@
+package opus
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWebMWriterRoundTrip(t *testing.T) {
+	// 400 packets = 8s, so the stream spans more than one cluster.
+	pkts := encodeFrames(t, 400)
+	var buf bytes.Buffer
+	w := NewWebMWriter(&buf)
+	for i, p := range pkts {
+		if err := w.WritePacket(p); err != nil {
+			t.Fatalf("WritePacket %d: %v", i, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := w.WritePacket(pkts[0]); !errors.Is(err, ErrWriterClosed) {
+		t.Fatalf("WritePacket after Close = %v, want ErrWriterClosed", err)
+	}
+
+	d := Demux(io.NopCloser(bytes.NewReader(buf.Bytes())))
+	for i := range pkts {
+		got, err := d.ReadPacket()
+		if err != nil {
+			t.Fatalf("ReadPacket %d: %v", i, err)
+		}
+		if !bytes.Equal(got, pkts[i]) {
+			t.Fatalf("packet %d differs after mux/demux", i)
+		}
+	}
+	if _, err := d.ReadPacket(); !errors.Is(err, io.EOF) {
+		t.Fatalf("trailing ReadPacket = %v, want EOF", err)
+	}
+}
+
+// oggPage is one parsed page, checked against its CRC.
+type oggPage struct {
+	flags   byte
+	granule uint64
+	seq     uint32
+	packets [][]byte // complete packets (no packet spans pages in our output)
+}
+
+func parseOggPages(t *testing.T, b []byte) []oggPage {
+	t.Helper()
+	var pages []oggPage
+	for len(b) > 0 {
+		if len(b) < 27 || string(b[:4]) != "OggS" {
+			t.Fatalf("bad capture pattern at page %d", len(pages))
+		}
+		nseg := int(b[26])
+		segs := b[27 : 27+nseg]
+		size := 27 + nseg
+		for _, s := range segs {
+			size += int(s)
+		}
+		page := append([]byte(nil), b[:size]...)
+		want := binary.LittleEndian.Uint32(page[22:])
+		binary.LittleEndian.PutUint32(page[22:], 0)
+		if got := oggCRC(page); got != want {
+			t.Fatalf("page %d crc = %08x, header says %08x", len(pages), got, want)
+		}
+		p := oggPage{
+			flags:   b[5],
+			granule: binary.LittleEndian.Uint64(b[6:]),
+			seq:     binary.LittleEndian.Uint32(b[18:]),
+		}
+		body := b[27+nseg : size]
+		var cur []byte
+		for _, s := range segs {
+			cur = append(cur, body[:s]...)
+			body = body[s:]
+			if s < 255 {
+				p.packets = append(p.packets, cur)
+				cur = nil
+			}
+		}
+		pages = append(pages, p)
+		b = b[size:]
+	}
+	return pages
+}
+
+func TestOggWriterPages(t *testing.T) {
+	pkts := encodeFrames(t, 120)
+	// A packet longer than 255 bytes exercises multi-segment lacing.
+	big := append([]byte{31 << 3}, bytes.Repeat([]byte{0x55}, 600)...)
+	pkts = append(pkts, big)
+
+	var buf bytes.Buffer
+	w := NewOggWriter(&buf)
+	for i, p := range pkts {
+		if err := w.WritePacket(p); err != nil {
+			t.Fatalf("WritePacket %d: %v", i, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	pages := parseOggPages(t, buf.Bytes())
+	if len(pages) < 4 {
+		t.Fatalf("got %d pages, want headers plus several audio pages", len(pages))
+	}
+	if pages[0].flags != 0x02 || string(pages[0].packets[0][:8]) != "OpusHead" {
+		t.Fatalf("first page is not a BOS OpusHead page")
+	}
+	if string(pages[1].packets[0][:8]) != "OpusTags" {
+		t.Fatalf("second page is not OpusTags")
+	}
+	last := pages[len(pages)-1]
+	if last.flags&0x04 == 0 {
+		t.Fatalf("last page lacks the EOS flag")
+	}
+	if want := uint64(len(pkts) * FrameSize); last.granule != want {
+		t.Fatalf("final granule = %d, want %d", last.granule, want)
+	}
+
+	var got [][]byte
+	for i, p := range pages {
+		if p.seq != uint32(i) {
+			t.Fatalf("page %d has sequence %d", i, p.seq)
+		}
+		if i >= 2 {
+			got = append(got, p.packets...)
+		}
+	}
+	if len(got) != len(pkts) {
+		t.Fatalf("reassembled %d packets, want %d", len(got), len(pkts))
+	}
+	for i := range got {
+		if !bytes.Equal(got[i], pkts[i]) {
+			t.Fatalf("packet %d differs after muxing", i)
+		}
+	}
+}
+
+func TestWAVWriter(t *testing.T) {
+	frames := 25
+	f, err := os.Create(filepath.Join(t.TempDir(), "out.wav"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	w := NewWAVWriter(f)
+	if _, err := io.Copy(w, DecodeReader(&sliceReader{pkts: encodeFrames(t, frames)})); err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	b, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := frames * PCMFrameBytes
+	if len(b) != wavHeaderBytes+data {
+		t.Fatalf("file is %d bytes, want %d", len(b), wavHeaderBytes+data)
+	}
+	if string(b[:4]) != "RIFF" || string(b[8:16]) != "WAVEfmt " || string(b[36:40]) != "data" {
+		t.Fatalf("malformed header % x", b[:wavHeaderBytes])
+	}
+	if got := binary.LittleEndian.Uint32(b[4:]); got != uint32(len(b)-8) {
+		t.Fatalf("RIFF size = %d, want %d", got, len(b)-8)
+	}
+	if got := binary.LittleEndian.Uint32(b[40:]); got != uint32(data) {
+		t.Fatalf("data size = %d, want %d", got, data)
+	}
+}
+
+// What OggWriter writes, DemuxOgg must read back packet for packet — the two
+// meet whenever a recording is played from the local library.
+func TestOggRoundTrip(t *testing.T) {
+	pkts := encodeFrames(t, 130)
+	var buf bytes.Buffer
+	w := NewOggWriter(&buf)
+	for _, p := range pkts {
+		if err := w.WritePacket(p); err != nil {
+			t.Fatalf("WritePacket: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	r, err := PassthroughOgg(io.NopCloser(bytes.NewReader(buf.Bytes())), 5)
+	if err != nil {
+		t.Fatalf("PassthroughOgg: %v", err)
+	}
+	defer r.Close()
+	for i := 5; i < len(pkts); i++ {
+		got, err := r.ReadPacket()
+		if err != nil {
+			t.Fatalf("ReadPacket %d: %v", i, err)
+		}
+		if !bytes.Equal(got, pkts[i]) {
+			t.Fatalf("packet %d differs after ogg round trip", i)
+		}
+	}
+	if _, err := r.ReadPacket(); !errors.Is(err, io.EOF) {
+		t.Fatalf("trailing ReadPacket = %v, want EOF", err)
+	}
+}
+
+func TestDemuxOggRejectsOtherCodecs(t *testing.T) {
+	// A Vorbis identification header where OpusHead belongs.
+	var page []byte
+	page = append(page, "OggS"...)
+	page = append(page, 0, 0x02)
+	page = append(page, make([]byte, 20)...)
+	ident := append([]byte{0x01}, "vorbis"...)
+	page = append(page, 1, byte(len(ident)))
+	page = append(page, ident...)
+
+	d := DemuxOgg(io.NopCloser(bytes.NewReader(page)))
+	if _, err := d.ReadPacket(); !errors.Is(err, ErrNotOggOpus) {
+		t.Fatalf("err = %v, want ErrNotOggOpus", err)
+	}
+}
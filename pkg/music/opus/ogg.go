@@ -0,0 +1,185 @@
+package opus
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Ogg Opus muxing (RFC 3533 framing, RFC 7845 mapping). The packets go in
+// untouched — this is a container writer, not a re-encode — so a recording of a
+// passthrough track is bit-identical to what the CDN served.
+//
+// Pages are cut every oggPagePackets packets (one second of 20ms audio), or
+// earlier when the 255-entry segment table would overflow. Shorter pages only
+// cost overhead; longer ones make a truncated file (process killed mid-export)
+// lose more audio, because a player drops the whole unfinished last page.
+
+// oggPreSkip is the OpusHead pre-skip in 48kHz samples. 312 is libopus's
+// encoder lookahead at 48kHz, which covers both our own Encode path and the
+// YouTube/SoundCloud streams passthrough copies — assumed from libopus's
+// default, not read out of each stream, since WebM carries it only in
+// CodecPrivate and the demuxer does not keep that.
+const oggPreSkip = 312
+
+const (
+	oggPagePackets = 50
+	oggMaxSegments = 255
+)
+
+// ErrWriterClosed is returned by a muxer's WritePacket after Close.
+var ErrWriterClosed = errors.New("opus: writer closed")
+
+// OggWriter muxes Opus packets into an Ogg Opus stream (.ogg / .opus). Not safe
+// for concurrent use; the caller owns w and closes it after Close.
+type OggWriter struct {
+	w       io.Writer
+	seq     uint32
+	granule uint64
+	started bool
+	closed  bool
+
+	segs    []byte // pending page's segment table
+	body    []byte // pending page's packet data
+	packets int    // packets in the pending page
+}
+
+// oggSerial identifies the one logical stream in the file. It only has to be
+// unique among streams chained into the same physical file, which never happens
+// here, so a fixed value keeps recordings reproducible byte for byte.
+const oggSerial = 0x6d6c6478
+
+// NewOggWriter returns a writer that emits the OpusHead and OpusTags pages
+// before the first packet.
+func NewOggWriter(w io.Writer) *OggWriter {
+	return &OggWriter{w: w}
+}
+
+// WritePacket appends one Opus packet. Empty packets are skipped: Ogg has no
+// way to carry one that a decoder would not read as packet loss.
+func (o *OggWriter) WritePacket(pkt []byte) error {
+	if o.closed {
+		return ErrWriterClosed
+	}
+	if len(pkt) == 0 {
+		return nil
+	}
+	if !o.started {
+		if err := o.writeHeaders(); err != nil {
+			return err
+		}
+		o.started = true
+	}
+	need := len(pkt)/255 + 1
+	if len(o.segs)+need > oggMaxSegments {
+		if err := o.flush(false); err != nil {
+			return err
+		}
+	}
+	for n := len(pkt); ; n -= 255 {
+		if n < 255 {
+			o.segs = append(o.segs, byte(n))
+			break
+		}
+		o.segs = append(o.segs, 255)
+	}
+	o.body = append(o.body, pkt...)
+	o.granule += uint64(PacketDurationMs(pkt) * SampleRate / 1000)
+	o.packets++
+	if o.packets >= oggPagePackets {
+		return o.flush(false)
+	}
+	return nil
+}
+
+// Close flushes the last page with the end-of-stream flag. A stream that never
+// received a packet still gets its headers, so the file is a valid empty Ogg
+// Opus stream rather than zero bytes.
+func (o *OggWriter) Close() error {
+	if o.closed {
+		return nil
+	}
+	o.closed = true
+	if !o.started {
+		if err := o.writeHeaders(); err != nil {
+			return err
+		}
+	}
+	return o.flush(true)
+}
+
+func (o *OggWriter) writeHeaders() error {
+	head := opusHead()
+	if err := o.writePage(0x02, 0, []byte{byte(len(head))}, head); err != nil {
+		return err
+	}
+
+	vendor := "melodix"
+	tags := make([]byte, 0, 8+4+len(vendor)+4)
+	tags = append(tags, "OpusTags"...)
+	tags = binary.LittleEndian.AppendUint32(tags, uint32(len(vendor)))
+	tags = append(tags, vendor...)
+	tags = binary.LittleEndian.AppendUint32(tags, 0) // no user comments
+	return o.writePage(0, 0, []byte{byte(len(tags))}, tags)
+}
+
+func (o *OggWriter) flush(eos bool) error {
+	if len(o.segs) == 0 && !eos {
+		return nil
+	}
+	var flags byte
+	if eos {
+		flags = 0x04
+	}
+	err := o.writePage(flags, o.granule, o.segs, o.body)
+	o.segs, o.body, o.packets = o.segs[:0], o.body[:0], 0
+	return err
+}
+
+func (o *OggWriter) writePage(flags byte, granule uint64, segs, body []byte) error {
+	page := make([]byte, 27+len(segs)+len(body))
+	copy(page, "OggS")
+	page[4] = 0 // stream structure version
+	page[5] = flags
+	binary.LittleEndian.PutUint64(page[6:], granule)
+	binary.LittleEndian.PutUint32(page[14:], oggSerial)
+	binary.LittleEndian.PutUint32(page[18:], o.seq)
+	page[26] = byte(len(segs))
+	copy(page[27:], segs)
+	copy(page[27+len(segs):], body)
+	binary.LittleEndian.PutUint32(page[22:], oggCRC(page))
+	o.seq++
+	if _, err := o.w.Write(page); err != nil {
+		return fmt.Errorf("opus: write ogg page: %w", err)
+	}
+	return nil
+}
+
+// oggCRCTable is the Ogg page checksum: CRC-32 with polynomial 0x04c11db7,
+// unreflected and with a zero initial value — not hash/crc32's IEEE variant,
+// which is reflected and would make every page fail verification.
+var oggCRCTable = func() [256]uint32 {
+	var t [256]uint32
+	for i := range t {
+		r := uint32(i) << 24
+		for range 8 {
+			if r&0x80000000 != 0 {
+				r = r<<1 ^ 0x04c11db7
+			} else {
+				r <<= 1
+			}
+		}
+		t[i] = r
+	}
+	return t
+}()
+
+// oggCRC checksums a page whose CRC field is still zero.
+func oggCRC(page []byte) uint32 {
+	var crc uint32
+	for _, b := range page {
+		crc = crc<<8 ^ oggCRCTable[byte(crc>>24)^b]
+	}
+	return crc
+}
@@ -0,0 +1,108 @@
+package opus
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrNotOggOpus is returned when an Ogg stream's first packet is not an
+// OpusHead — Ogg Vorbis or FLAC in a .ogg file, typically. Such a file still
+// plays through ffmpeg; it just cannot be passed through.
+var ErrNotOggOpus = errors.New("opus: ogg stream is not opus")
+
+// DemuxOgg reads an Ogg Opus stream (a .opus file, or .ogg holding Opus) and
+// yields its audio packets, with the OpusHead and OpusTags header packets
+// consumed. Only the first logical stream is followed; pages of any other
+// serial are skipped, which is what a chained or multiplexed file needs to
+// not interleave garbage into the audio.
+//
+// The header's pre-skip is not applied: it drops the first few milliseconds
+// of encoder warm-up, which a decoder fed these packets renders as a short
+// near-silent lead-in rather than anything audible.
+func DemuxOgg(src io.ReadCloser) Reader {
+	return &oggDemuxer{src: src, r: bufio.NewReaderSize(src, 1<<16)}
+}
+
+type oggDemuxer struct {
+	src     io.ReadCloser
+	r       *bufio.Reader
+	serial  uint32
+	started bool
+	headers int      // header packets seen (2 once OpusHead and OpusTags are past)
+	pending [][]byte // complete packets from the current page
+	partial []byte   // a packet continued onto the next page
+}
+
+func (d *oggDemuxer) Close() error { return d.src.Close() }
+
+func (d *oggDemuxer) ReadPacket() ([]byte, error) {
+	for {
+		for len(d.pending) > 0 {
+			pkt := d.pending[0]
+			d.pending = d.pending[1:]
+			if d.headers < 2 {
+				if d.headers == 0 && !bytes.HasPrefix(pkt, []byte("OpusHead")) {
+					return nil, ErrNotOggOpus
+				}
+				d.headers++
+				continue
+			}
+			if len(pkt) == 0 {
+				continue
+			}
+			return pkt, nil
+		}
+		if err := d.readPage(); err != nil {
+			return nil, mapEOF(err)
+		}
+	}
+}
+
+// readPage parses one page into pending. The CRC is not checked: the sources
+// this reads are local files and CDN bodies already covered by TCP and TLS,
+// and a corrupt packet costs one 20ms glitch in the decoder, not a crash.
+func (d *oggDemuxer) readPage() error {
+	var hdr [27]byte
+	if _, err := io.ReadFull(d.r, hdr[:]); err != nil {
+		return err
+	}
+	if string(hdr[:4]) != "OggS" {
+		return fmt.Errorf("opus: bad ogg capture pattern")
+	}
+	serial := binary.LittleEndian.Uint32(hdr[14:])
+	segs := make([]byte, hdr[26])
+	if _, err := io.ReadFull(d.r, segs); err != nil {
+		return err
+	}
+	size := 0
+	for _, s := range segs {
+		size += int(s)
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(d.r, body); err != nil {
+		return err
+	}
+	if !d.started {
+		d.serial, d.started = serial, true
+	}
+	if serial != d.serial {
+		return nil
+	}
+	continued := hdr[5]&0x01 != 0
+	if !continued {
+		d.partial = nil // a lost continuation: drop the fragment rather than splice it
+	}
+	for _, s := range segs {
+		d.partial = append(d.partial, body[:s]...)
+		body = body[s:]
+		if s < 255 {
+			d.pending = append(d.pending, d.partial)
+			d.partial = nil
+		}
+	}
+	return nil
+}
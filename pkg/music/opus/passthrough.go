@@ -23,7 +23,17 @@ func SeekPackets(seekSec float64) int {
 // Discord's sender requires that. On any error it closes body and returns the
 // error (ErrNotPassthrough for a framing mismatch); on success the Reader owns body.
 func Passthrough(body io.ReadCloser, seekPackets int) (Reader, error) {
-	dem := Demux(body)
+	return passthrough(Demux(body), seekPackets)
+}
+
+// PassthroughOgg is Passthrough for an Ogg Opus stream (see DemuxOgg). Files
+// encoded with opusenc's defaults are 20ms and pass; anything else comes back
+// as ErrNotPassthrough, or ErrNotOggOpus for an Ogg stream of another codec.
+func PassthroughOgg(body io.ReadCloser, seekPackets int) (Reader, error) {
+	return passthrough(DemuxOgg(body), seekPackets)
+}
+
+func passthrough(dem Reader, seekPackets int) (Reader, error) {
 	for i := 0; i < seekPackets; i++ {
 		if _, err := dem.ReadPacket(); err != nil {
 			_ = dem.Close()
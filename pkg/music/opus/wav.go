@@ -0,0 +1,96 @@
+package opus
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ErrWAVTooLarge is returned once a WAV recording would pass the format's
+// 32-bit size fields — about 6.2 hours of 48kHz stereo s16le. Record to Ogg or
+// WebM for anything longer; they have no such ceiling.
+var ErrWAVTooLarge = errors.New("opus: wav data exceeds 4 GiB")
+
+const wavHeaderBytes = 44
+
+// WAVWriter writes the PCM that DecodeReader produces (s16le, 48kHz stereo) as
+// a RIFF/WAVE file. The sizes in the header are only known at the end, so the
+// header is written with zeros up front and patched by Close — which is why w
+// must seek. Feed it with io.Copy(wav, DecodeReader(r)).
+type WAVWriter struct {
+	w       io.WriteSeeker
+	data    int64 // PCM bytes written so far
+	started bool
+	closed  bool
+}
+
+// NewWAVWriter returns a writer that emits the header on the first Write.
+func NewWAVWriter(w io.WriteSeeker) *WAVWriter {
+	return &WAVWriter{w: w}
+}
+
+// Write appends PCM bytes.
+func (v *WAVWriter) Write(pcm []byte) (int, error) {
+	if v.closed {
+		return 0, ErrWriterClosed
+	}
+	if !v.started {
+		if err := v.writeHeader(0); err != nil {
+			return 0, err
+		}
+		v.started = true
+	}
+	if v.data+int64(len(pcm)) > math.MaxUint32-wavHeaderBytes {
+		return 0, ErrWAVTooLarge
+	}
+	n, err := v.w.Write(pcm)
+	v.data += int64(n)
+	if err != nil {
+		return n, fmt.Errorf("opus: write wav data: %w", err)
+	}
+	return n, nil
+}
+
+// Close rewrites the header with the final sizes and leaves w positioned at
+// the end of the data.
+func (v *WAVWriter) Close() error {
+	if v.closed {
+		return nil
+	}
+	v.closed = true
+	if _, err := v.w.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("opus: seek wav header: %w", err)
+	}
+	if err := v.writeHeader(uint32(v.data)); err != nil {
+		return err
+	}
+	if _, err := v.w.Seek(0, io.SeekEnd); err != nil {
+		return fmt.Errorf("opus: seek wav end: %w", err)
+	}
+	return nil
+}
+
+func (v *WAVWriter) writeHeader(dataBytes uint32) error {
+	const bitsPerSample = 16
+	blockAlign := Channels * bitsPerSample / 8
+
+	h := make([]byte, 0, wavHeaderBytes)
+	h = append(h, "RIFF"...)
+	h = binary.LittleEndian.AppendUint32(h, wavHeaderBytes-8+dataBytes)
+	h = append(h, "WAVEfmt "...)
+	h = binary.LittleEndian.AppendUint32(h, 16) // fmt chunk size
+	h = binary.LittleEndian.AppendUint16(h, 1)  // PCM
+	h = binary.LittleEndian.AppendUint16(h, Channels)
+	h = binary.LittleEndian.AppendUint32(h, SampleRate)
+	h = binary.LittleEndian.AppendUint32(h, uint32(SampleRate*blockAlign))
+	h = binary.LittleEndian.AppendUint16(h, uint16(blockAlign))
+	h = binary.LittleEndian.AppendUint16(h, bitsPerSample)
+	h = append(h, "data"...)
+	h = binary.LittleEndian.AppendUint32(h, dataBytes)
+	if _, err := v.w.Write(h); err != nil {
+		return fmt.Errorf("opus: write wav header: %w", err)
+	}
+	return nil
+}
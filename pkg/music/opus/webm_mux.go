@@ -0,0 +1,227 @@
+package opus
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// WebM muxing is the mirror image of Demux: the same few elements, written in
+// the shape YouTube serves (an unknown-size Segment, so nothing has to be
+// seeked back and patched, and the output can go to a pipe). Clusters are
+// buffered and written with a known size, because a SimpleBlock's timecode is a
+// signed 16-bit offset from its cluster — webmClusterMs stays well under that
+// 32767ms ceiling; past it timecodes wrap and players jump backwards.
+//
+// There are no Cues, so players seek by scanning. That is the price of not
+// seeking the output; do not add a Cues element that would need one.
+
+const webmClusterMs = 5000
+
+// EBML element IDs the writer needs on top of the demuxer's (see demux.go).
+const (
+	idEBMLVersion        = 0x4286
+	idEBMLReadVersion    = 0x42F7
+	idEBMLMaxIDLength    = 0x42F2
+	idEBMLMaxSizeLength  = 0x42F3
+	idDocType            = 0x4282
+	idDocTypeVersion     = 0x4287
+	idDocTypeReadVersion = 0x4285
+	idInfo               = 0x1549A966
+	idTimecodeScale      = 0x2AD7B1
+	idMuxingApp          = 0x4D80
+	idWritingApp         = 0x5741
+	idTrackUID           = 0x73C5
+	idCodecDelay         = 0x56AA
+	idSeekPreRoll        = 0x56BB
+	idAudio              = 0xE1
+	idSamplingFrequency  = 0xB5
+	idChannels           = 0x9F
+	idTimecode           = 0xE7
+)
+
+// WebMWriter muxes Opus packets into a WebM stream on track 1. Not safe for
+// concurrent use; the caller owns w and closes it after Close.
+type WebMWriter struct {
+	w       io.Writer
+	started bool
+	closed  bool
+
+	posMs     float64 // start of the next packet, from the packets' own TOCs
+	clusterMs int64   // timecode of the pending cluster
+	blocks    []byte  // pending cluster's SimpleBlocks
+}
+
+// NewWebMWriter returns a writer that emits the EBML header and track
+// description before the first packet.
+func NewWebMWriter(w io.Writer) *WebMWriter {
+	return &WebMWriter{w: w}
+}
+
+// WritePacket appends one Opus packet as a keyframe SimpleBlock (every Opus
+// packet decodes on its own).
+func (m *WebMWriter) WritePacket(pkt []byte) error {
+	if m.closed {
+		return ErrWriterClosed
+	}
+	if len(pkt) == 0 {
+		return nil
+	}
+	if !m.started {
+		if err := m.writeHeader(); err != nil {
+			return err
+		}
+		m.started = true
+	}
+	ts := int64(m.posMs)
+	if len(m.blocks) > 0 && ts-m.clusterMs >= webmClusterMs {
+		if err := m.flush(); err != nil {
+			return err
+		}
+	}
+	if len(m.blocks) == 0 {
+		m.clusterMs = ts
+	}
+	rel := ts - m.clusterMs
+	block := make([]byte, 0, 4+len(pkt))
+	block = append(block, 0x81) // track number 1 as a vint
+	block = binary.BigEndian.AppendUint16(block, uint16(int16(rel)))
+	block = append(block, 0x80) // keyframe, no lacing
+	block = append(block, pkt...)
+	m.blocks = appendElem(m.blocks, idSimpleBlock, block)
+	m.posMs += PacketDurationMs(pkt)
+	return nil
+}
+
+// Close writes the last cluster. The Segment stays unknown-size, which every
+// WebM reader (and Demux) accepts as "runs to end of file".
+func (m *WebMWriter) Close() error {
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+	if !m.started {
+		if err := m.writeHeader(); err != nil {
+			return err
+		}
+	}
+	return m.flush()
+}
+
+func (m *WebMWriter) writeHeader() error {
+	var ebml []byte
+	ebml = appendUintElem(ebml, idEBMLVersion, 1)
+	ebml = appendUintElem(ebml, idEBMLReadVersion, 1)
+	ebml = appendUintElem(ebml, idEBMLMaxIDLength, 4)
+	ebml = appendUintElem(ebml, idEBMLMaxSizeLength, 8)
+	ebml = appendElem(ebml, idDocType, []byte("webm"))
+	ebml = appendUintElem(ebml, idDocTypeVersion, 4)
+	ebml = appendUintElem(ebml, idDocTypeReadVersion, 2)
+
+	var info []byte
+	info = appendUintElem(info, idTimecodeScale, 1000000) // timecodes in ms
+	info = appendElem(info, idMuxingApp, []byte("melodix"))
+	info = appendElem(info, idWritingApp, []byte("melodix"))
+
+	var audio []byte
+	audio = appendElem(audio, idSamplingFrequency,
+		binary.BigEndian.AppendUint64(nil, math.Float64bits(SampleRate)))
+	audio = appendUintElem(audio, idChannels, Channels)
+
+	var entry []byte
+	entry = appendUintElem(entry, idTrackNumber, 1)
+	entry = appendUintElem(entry, idTrackUID, 1)
+	entry = appendUintElem(entry, idTrackType, 2) // audio
+	entry = appendElem(entry, idCodecID, []byte("A_OPUS"))
+	entry = appendElem(entry, idCodecPrivate, opusHead())
+	// CodecDelay and SeekPreRoll in ns, as the WebM Opus mapping requires.
+	entry = appendUintElem(entry, idCodecDelay, oggPreSkip*1000000000/SampleRate)
+	entry = appendUintElem(entry, idSeekPreRoll, 80000000)
+	entry = appendElem(entry, idAudio, audio)
+
+	var out []byte
+	out = appendElem(out, idEBML, ebml)
+	out = appendID(out, idSegment)
+	out = append(out, 0x01, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF) // unknown size
+	out = appendElem(out, idInfo, info)
+	out = appendElem(out, idTracks, appendElem(nil, idTrackEntry, entry))
+	if _, err := m.w.Write(out); err != nil {
+		return fmt.Errorf("opus: write webm header: %w", err)
+	}
+	return nil
+}
+
+func (m *WebMWriter) flush() error {
+	if len(m.blocks) == 0 {
+		return nil
+	}
+	body := appendUintElem(nil, idTimecode, uint64(m.clusterMs))
+	body = append(body, m.blocks...)
+	m.blocks = m.blocks[:0]
+	if _, err := m.w.Write(appendElem(nil, idCluster, body)); err != nil {
+		return fmt.Errorf("opus: write webm cluster: %w", err)
+	}
+	return nil
+}
+
+// opusHead is the 19-byte identification header shared by Ogg's first page and
+// WebM's CodecPrivate.
+func opusHead() []byte {
+	head := make([]byte, 19)
+	copy(head, "OpusHead")
+	head[8] = 1 // version
+	head[9] = Channels
+	binary.LittleEndian.PutUint16(head[10:], oggPreSkip)
+	binary.LittleEndian.PutUint32(head[12:], SampleRate)
+	// output gain 0, channel mapping family 0 (mono/stereo, no table)
+	return head
+}
+
+// --- EBML writing primitives ---
+
+// appendID writes an element ID; IDs carry their own length marker, so the
+// significant bytes go out as-is.
+func appendID(b []byte, id uint32) []byte {
+	switch {
+	case id > 0xFFFFFF:
+		return append(b, byte(id>>24), byte(id>>16), byte(id>>8), byte(id))
+	case id > 0xFFFF:
+		return append(b, byte(id>>16), byte(id>>8), byte(id))
+	case id > 0xFF:
+		return append(b, byte(id>>8), byte(id))
+	default:
+		return append(b, byte(id))
+	}
+}
+
+// appendSize writes n as the shortest EBML size vint. The all-ones value of
+// each length is reserved for "unknown size", hence the strict comparison.
+func appendSize(b []byte, n int) []byte {
+	for l := 1; l <= 8; l++ {
+		if uint64(n) < (uint64(1)<<(7*l))-1 {
+			v := uint64(n) | uint64(1)<<(7*l)
+			for i := l - 1; i >= 0; i-- {
+				b = append(b, byte(v>>(8*i)))
+			}
+			return b
+		}
+	}
+	panic("opus: ebml element too large")
+}
+
+func appendElem(b []byte, id uint32, payload []byte) []byte {
+	b = appendID(b, id)
+	b = appendSize(b, len(payload))
+	return append(b, payload...)
+}
+
+func appendUintElem(b []byte, id uint32, v uint64) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+	i := 0
+	for i < 7 && buf[i] == 0 {
+		i++
+	}
+	return appendElem(b, id, buf[i:])
+}
@@ -0,0 +1,33 @@
+package bcnative
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/keshon/melodix/pkg/music/bandcampapi"
+	"github.com/keshon/melodix/pkg/music/opus"
+	"github.com/keshon/melodix/pkg/music/parsers"
+	ffmpegparser "github.com/keshon/melodix/pkg/music/parsers/ffmpeg"
+)
+
+// bcnativeLink reads the stream URL from the track page on every open: it is
+// signed and expires, so the one seen when the album was queued may be gone
+// by the time the queue reaches the track.
+func bcnativeLink(ctx context.Context, track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
+	rel, err := bandcampapi.Default().ReleaseContext(ctx, track.URL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("bcnative: %w", err)
+	}
+	streamable := rel.Streamable()
+	if len(streamable) == 0 {
+		return nil, nil, fmt.Errorf("bcnative: %w", bandcampapi.ErrNotStreamable)
+	}
+	t := streamable[0]
+	if track.Title == "" {
+		track.Title = t.Label()
+	}
+	track.Duration = t.Duration
+
+	cmd := ffmpegparser.NewPCMCommand(t.Stream, seekSec, true, "bcnative-link")
+	return ffmpegparser.OpusReader(cmd, "bcnative")
+}
@@ -0,0 +1,27 @@
+// Package bcnative streams Bandcamp tracks from the page's own player data (no
+// yt-dlp): track page → data-tralbum → mp3-128 stream URL → ffmpeg → Opus
+// packets.
+package bcnative
+
+import (
+	"context"
+
+	"github.com/keshon/melodix/pkg/music/opus"
+	"github.com/keshon/melodix/pkg/music/parsers"
+)
+
+type Streamer struct{}
+
+func (s *Streamer) Open(track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
+	return s.OpenContext(context.Background(), track, seekSec)
+}
+
+// OpenContext is Open with the page fetch bound to ctx.
+func (s *Streamer) OpenContext(ctx context.Context, track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
+	return bcnativeLink(ctx, track, seekSec)
+}
+
+// Capabilities: ffmpeg transcodes the mp3 stream and seeks in it.
+func (s *Streamer) Capabilities() parsers.Capabilities {
+	return parsers.Capabilities{Seekable: true, NeedsFFmpeg: true}
+}
@@ -0,0 +1,151 @@
+// Package directfile plays audio files behind plain HTTP URLs (see
+// pkg/music/httpfile): Ogg Opus and WebM are forwarded as-is, everything else
+// goes through ffmpeg, which seeks with ranged requests.
+package directfile
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+
+	"github.com/keshon/melodix/pkg/music/audiotag"
+	"github.com/keshon/melodix/pkg/music/httpfile"
+	"github.com/keshon/melodix/pkg/music/opus"
+	"github.com/keshon/melodix/pkg/music/parsers"
+	ffmpegparser "github.com/keshon/melodix/pkg/music/parsers/ffmpeg"
+)
+
+var logPtr atomic.Pointer[zerolog.Logger]
+
+// SetLogger sets the package logger. Safe for concurrent use; call once at
+// process startup.
+func SetLogger(l zerolog.Logger) {
+	logPtr.Store(&l)
+}
+
+func logger() zerolog.Logger {
+	if l := logPtr.Load(); l != nil {
+		return *l
+	}
+	return zerolog.Nop()
+}
+
+// streamClient carries audio bodies, which stay open for the whole track, so
+// unlike httpfile.Client it has no overall timeout.
+var streamClient = &http.Client{}
+
+// Mode selects how the file becomes Opus: Passthrough demuxes a native Opus
+// container and falls back to ffmpeg when the file isn't one; FFmpeg always
+// transcodes.
+type Mode int
+
+const (
+	ModePassthrough Mode = iota
+	ModeFFmpeg
+)
+
+// Streamer opens remote audio files.
+type Streamer struct{ Mode Mode }
+
+func (s *Streamer) Open(track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
+	return s.OpenContext(context.Background(), track, seekSec)
+}
+
+// OpenContext is Open with the probe bound to ctx. The stream itself is not.
+func (s *Streamer) OpenContext(ctx context.Context, track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
+	info, err := probe(ctx, track.URL)
+	if err != nil {
+		return nil, nil, err
+	}
+	if track.Title == "" {
+		track.Title = info.Tags.Title
+	}
+	if track.Artist == "" {
+		track.Artist = info.Tags.Artist
+	}
+	// Never zero (see httpfile.Info.Duration): a file that ends must not be
+	// reopened as if a live stream had dropped.
+	track.Duration = info.Duration()
+
+	if s.Mode == ModePassthrough && info.Format.MayHoldOpus() {
+		r, cleanup, err := openPassthrough(info, seekSec)
+		l := logger()
+		if err == nil {
+			track.Passthrough = true
+			l.Info().Str("url_host", hostOf(info.URL)).Msg("directfile_passthrough")
+			return r, cleanup, nil
+		}
+		l.Warn().Str("url_host", hostOf(info.URL)).Err(err).Msg("directfile_passthrough_failed_ffmpeg_fallback")
+	}
+
+	// ffmpeg gets the URL rather than a pipe: with -ss ahead of -i it seeks by
+	// a ranged request instead of reading everything before the position, and
+	// an MP4 whose index sits at the end needs that to play at all.
+	cmd := ffmpegparser.NewPCMCommandUA(info.URL, seekSec, true, "direct-ffmpeg", "Mozilla/5.0")
+	return ffmpegparser.OpusReader(cmd, "directfile")
+}
+
+// Capabilities: both modes seek. Passthrough needs no ffmpeg for Opus files
+// (other formats still go through it); the ffmpeg mode always does.
+func (s *Streamer) Capabilities() parsers.Capabilities {
+	if s.Mode == ModePassthrough {
+		return parsers.Capabilities{Seekable: true, Passthrough: true}
+	}
+	return parsers.Capabilities{Seekable: true, NeedsFFmpeg: true}
+}
+
+// probe fetches the file's details, renewing a Discord attachment link first.
+// track.URL keeps the link as it was resolved: the signature is only good for
+// a day, so the renewed one is for this open alone, and the next open — a
+// reconnect, a replay from /history — renews again when it has to.
+func probe(ctx context.Context, rawURL string) (httpfile.Info, error) {
+	fresh, err := httpfile.Fresh(rawURL)
+	if err != nil {
+		return httpfile.Info{}, err
+	}
+	info, err := httpfile.ProbeContext(ctx, fresh)
+	if err == nil || ctx.Err() != nil || !httpfile.IsDiscordAttachment(rawURL) {
+		return info, err
+	}
+	// The CDN refused a link its ex called valid: a skewed clock, or a
+	// signature Discord revoked. One forced renewal settles which.
+	fresh, rerr := httpfile.Renew(rawURL)
+	if rerr != nil {
+		return httpfile.Info{}, err
+	}
+	l := logger()
+	l.Debug().Str("url_host", hostOf(rawURL)).Err(err).Msg("directfile_attachment_link_renewed")
+	return httpfile.ProbeContext(ctx, fresh)
+}
+
+// openPassthrough seeks the way ytnative's passthrough does, by discarding
+// packets from the start: a container with no index cannot be entered
+// mid-file. Attachments are small enough that this rarely matters.
+func openPassthrough(info httpfile.Info, seekSec float64) (opus.Reader, func(), error) {
+	body, err := httpfile.Open(info.URL, streamClient)
+	if err != nil {
+		return nil, nil, err
+	}
+	var r opus.Reader
+	if info.Format == audiotag.WebM {
+		r, err = opus.Passthrough(body, opus.SeekPackets(seekSec))
+	} else {
+		r, err = opus.PassthroughOgg(body, opus.SeekPackets(seekSec))
+	}
+	if err != nil {
+		return nil, nil, err // Passthrough closed body
+	}
+	return r, func() { _ = r.Close() }, nil
+}
+
+// hostOf keeps signed attachment URLs out of the logs.
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
@@ -0,0 +1,96 @@
+package ffmpeg
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/icy"
+	"github.com/keshon/melodix/pkg/music/opus"
+	"github.com/keshon/melodix/pkg/music/parsers"
+)
+
+// icyClient fetches stations for the ICY path. The body stays open for as
+// long as the station plays, so only the wait for headers is bounded.
+var icyClient = func() *http.Client {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.ResponseHeaderTimeout = 10 * time.Second
+	return &http.Client{Transport: t}
+}()
+
+// openStation opens one stream. It asks the station for interleaved metadata
+// and, when there is some, feeds ffmpeg the audio with the metadata blocks
+// stripped, reporting each StreamTitle to track.OnStreamTitle. A station that
+// sends no icy-metaint gets its URL handed to ffmpeg as before. That costs a
+// second connection for stations without metadata, but keeps playlists (.m3u,
+// .pls) and HLS, which only ffmpeg understands, on the path that already plays
+// them.
+//
+// A stream that does not answer is an error, so Open can move on to the next
+// one. The last candidate goes to ffmpeg regardless: ffmpeg speaks protocols and
+// tolerates servers that net/http does not, and its failure surfaces on the
+// first read, where recovery handles it.
+func openStation(track *parsers.Track, url string, last bool) (opus.Reader, func(), error) {
+	l := logger()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		if last {
+			return ffmpegLink(url)
+		}
+		return nil, nil, fmt.Errorf("ffmpeg: station request: %w", err)
+	}
+	req.Header.Set(icy.RequestHeader, "1")
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+
+	resp, err := icyClient.Do(req)
+	if err == nil && resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		err = fmt.Errorf("ffmpeg: station answered %s", resp.Status)
+	}
+	if err != nil {
+		if last {
+			l.Debug().Str("url", url).Err(err).Msg("icy_open_failed")
+			return ffmpegLink(url)
+		}
+		return nil, nil, err
+	}
+	if track.Title == "" {
+		// The station's own name beats its URL as a title, metadata or not. It
+		// is set once and never overwritten, so a reconnect does not touch a
+		// live track.
+		track.Title = strings.TrimSpace(resp.Header.Get("icy-name"))
+	}
+	metaInt, ok, err := icy.MetaInt(resp.Header)
+	if !ok || err != nil {
+		_ = resp.Body.Close()
+		if err != nil {
+			l.Debug().Str("url", url).Err(err).Msg("icy_metaint_rejected")
+		}
+		return ffmpegLink(url)
+	}
+
+	cmd := NewPCMCommand("pipe:0", 0, false, "ffmpeg-link")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, nil, fmt.Errorf("ffmpeg: stdin pipe: %w", err)
+	}
+	r, cleanup, err := OpusReader(cmd, "ffmpeg")
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, nil, err
+	}
+	// The copy ends when the station drops (ffmpeg sees EOF, and recovery
+	// reconnects the live stream) or when cleanup closes the body.
+	go func() {
+		_, _ = io.Copy(stdin, icy.NewReader(resp.Body, metaInt, track.OnStreamTitle))
+		_ = stdin.Close()
+	}()
+	l.Debug().Str("url", url).Int("metaint", metaInt).Msg("icy_stream_opened")
+	return r, func() {
+		_ = resp.Body.Close()
+		cleanup()
+	}, nil
+}
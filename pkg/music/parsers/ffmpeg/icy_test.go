@@ -0,0 +1,131 @@
+package ffmpeg
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/icy"
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/sources"
+)
+
+// fakeFFmpeg swaps FFmpegPath for a script that swallows stdin and prints
+// nothing, so a stream "opens" without a real ffmpeg.
+func fakeFFmpeg(t *testing.T) {
+	t.Helper()
+	script := filepath.Join(t.TempDir(), "ffmpeg")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ncat >/dev/null\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	orig := FFmpegPath
+	FFmpegPath = script
+	t.Cleanup(func() { FFmpegPath = orig })
+}
+
+func TestOpenStationHandsPlainStreamToFFmpeg(t *testing.T) {
+	orig := FFmpegPath
+	FFmpegPath = filepath.Join(t.TempDir(), "missing-ffmpeg")
+	t.Cleanup(func() { FFmpegPath = orig })
+
+	var asked string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		asked = r.Header.Get(icy.RequestHeader)
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Header().Set("icy-name", "Plain FM")
+		_, _ = w.Write(make([]byte, 512))
+	}))
+	t.Cleanup(srv.Close)
+
+	track := &parsers.Track{URL: srv.URL}
+	// Without icy-metaint the URL goes to ffmpeg, which is missing here, so
+	// the start error proves which path was taken.
+	if _, _, err := openStation(track, srv.URL, true); err == nil || !strings.Contains(err.Error(), "ffmpeg start") {
+		t.Fatalf("err = %v, want the ffmpeg start failure", err)
+	}
+	if asked != "1" {
+		t.Fatalf("%s = %q, want 1", icy.RequestHeader, asked)
+	}
+	if track.Title != "Plain FM" {
+		t.Fatalf("title = %q, want the station's icy-name", track.Title)
+	}
+}
+
+func TestOpenReportsStreamTitles(t *testing.T) {
+	fakeFFmpeg(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("icy-metaint", "4")
+		w.WriteHeader(http.StatusOK)
+		meta := "StreamTitle='Artist - Song';"
+		block := make([]byte, 1+32)
+		block[0] = 2
+		copy(block[1:], meta)
+		_, _ = w.Write(append([]byte("aaaa"), block...))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	t.Cleanup(srv.Close)
+
+	titles := make(chan string, 1)
+	track := &parsers.Track{URL: srv.URL, OnStreamTitle: func(title string) { titles <- title }}
+	_, cleanup, err := (&Streamer{}).Open(track, 0)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(cleanup)
+	select {
+	case got := <-titles:
+		if got != "Artist - Song" {
+			t.Fatalf("title = %q", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no stream title reported")
+	}
+}
+
+func TestOpenFailsOverBetweenStationStreams(t *testing.T) {
+	fakeFFmpeg(t)
+	dead := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(dead.Close)
+	live := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(live.Close)
+
+	track := &parsers.Track{
+		URL:        "https://example.com/station.pls",
+		SourceInfo: sources.TrackInfo{StreamURLs: []string{dead.URL, live.URL}},
+	}
+	for attempt := 1; attempt <= 2; attempt++ {
+		_, cleanup, err := (&Streamer{}).Open(track, 0)
+		if err != nil {
+			t.Fatalf("open %d: %v", attempt, err)
+		}
+		cleanup()
+		if track.StreamURL != live.URL {
+			t.Fatalf("open %d played %q, want the live stream", attempt, track.StreamURL)
+		}
+	}
+}
+
+func TestStationURLsRotatePastThePlayedStream(t *testing.T) {
+	track := &parsers.Track{
+		URL:        "https://example.com/station.pls",
+		SourceInfo: sources.TrackInfo{StreamURLs: []string{"a", "b", "c"}},
+	}
+	if got := strings.Join(stationURLs(track), ","); got != "a,b,c" {
+		t.Fatalf("first open order = %s", got)
+	}
+	track.StreamURL = "b"
+	if got := strings.Join(stationURLs(track), ","); got != "c,a,b" {
+		t.Fatalf("reopen order = %s", got)
+	}
+	if got := stationURLs(&parsers.Track{URL: "https://x/stream"}); len(got) != 1 || got[0] != "https://x/stream" {
+		t.Fatalf("no list should mean the track URL, got %v", got)
+	}
+}
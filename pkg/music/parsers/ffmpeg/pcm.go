@@ -43,15 +43,33 @@ func NewPCMCommand(input string, seekSec float64, reconnect bool, tag string) *e
 // googlevideo, measured, does not — what it gates on is the issuing InnerTube
 // client (see kkdai.VisionOSClient), so don't treat this as a 403 remedy.
 func NewPCMCommandUA(input string, seekSec float64, reconnect bool, tag, userAgent string) *exec.Cmd {
-	args := make([]string, 0, 18)
+	return NewPCMCommandHTTP(input, seekSec, reconnect, tag, HTTPOptions{UserAgent: userAgent})
+}
+
+// HTTPOptions are the request settings for a URL input.
+type HTTPOptions struct {
+	// UserAgent is sent instead of ffmpeg's default ("" keeps it).
+	UserAgent string
+	// Proxy is an HTTP proxy URL for the fetch (ffmpeg -http_proxy). A URL
+	// that is bound to the address that resolved it has to be fetched
+	// through the same proxy.
+	Proxy string
+}
+
+// NewPCMCommandHTTP is NewPCMCommand with HTTP request settings for URL inputs.
+func NewPCMCommandHTTP(input string, seekSec float64, reconnect bool, tag string, h HTTPOptions) *exec.Cmd {
+	args := make([]string, 0, 20)
 	if seekSec > 0 {
 		args = append(args, "-ss", fmt.Sprintf("%.3f", seekSec))
 	}
 	if reconnect {
 		args = append(args, "-reconnect", "1", "-reconnect_streamed", "1", "-reconnect_delay_max", "5")
 	}
-	if userAgent != "" {
-		args = append(args, "-user_agent", userAgent)
+	if h.UserAgent != "" {
+		args = append(args, "-user_agent", h.UserAgent)
+	}
+	if h.Proxy != "" {
+		args = append(args, "-http_proxy", h.Proxy)
 	}
 	args = append(args,
 		"-i", input,
@@ -61,6 +79,8 @@ func NewPCMCommandUA(input string, seekSec float64, reconnect bool, tag, userAge
 		"-loglevel", "warning",
 		"pipe:1",
 	)
+	// Not CommandContext: ffmpeg here is the stream itself, which outlives the
+	// open that started it and ends by the reader's cleanup.
 	cmd := exec.Command(FFmpegPath, args...)
 	cmd.Stderr = &stderrLineWriter{tag: tag}
 	return cmd
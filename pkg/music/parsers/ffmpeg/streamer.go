@@ -11,9 +11,54 @@ const (
 )
 
 // Streamer plays a URL by handing it directly to ffmpeg (used for radio streams).
+// A station that interleaves ICY metadata is fetched here instead and piped in,
+// so the titles can be read out and the blocks kept away from the decoder.
 type Streamer struct{}
 
 // Open ignores seekSec — radio streams are live.
+//
+// A station resolved from a playlist has several streams. Open tries them in
+// turn, starting after the one played last: Open is only called again once a
+// stream stopped delivering, so the one that just died goes to the back.
 func (s *Streamer) Open(track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
-	return ffmpegLink(track.URL)
+	urls := stationURLs(track)
+	var lastErr error
+	for i, u := range urls {
+		r, cleanup, err := openStation(track, u, i == len(urls)-1)
+		if err != nil {
+			l := logger()
+			l.Warn().Str("url", u).Err(err).Msg("station_stream_unreachable")
+			lastErr = err
+			continue
+		}
+		track.StreamURL = u
+		return r, cleanup, nil
+	}
+	return nil, nil, lastErr
+}
+
+// Capabilities: a live-only parser. A reopen joins the broadcast where it is.
+func (s *Streamer) Capabilities() parsers.Capabilities {
+	return parsers.Capabilities{Live: true, NeedsFFmpeg: true}
+}
+
+// stationURLs orders a track's streams for Open: the station's list rotated to
+// start after track.StreamURL, or just the track URL when there is no list.
+func stationURLs(track *parsers.Track) []string {
+	list := track.SourceInfo.StreamURLs
+	if len(list) == 0 {
+		return []string{track.URL}
+	}
+	start := 0
+	for i, u := range list {
+		if u == track.StreamURL {
+			start = i + 1
+			break
+		}
+	}
+	out := make([]string, 0, len(list))
+	for i := range list {
+		out = append(out, list[(start+i)%len(list)])
+	}
+	return out
 }
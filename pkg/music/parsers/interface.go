@@ -1,6 +1,18 @@
 package parsers
 
-import "github.com/keshon/melodix/pkg/music/opus"
+import (
+	"context"
+	"errors"
+
+	"github.com/keshon/melodix/pkg/music/opus"
+)
+
+// ErrSignInRequired marks an open refused because the service wants a signed-in
+// session: an age-restricted video, or YouTube's "confirm you're not a bot"
+// check. Parsers wrap their own error with it. It is about the track and the
+// bot's standing with the service rather than the parser, and configured
+// cookies are the usual way through.
+var ErrSignInRequired = errors.New("parsers: age-restricted or sign-in required")
 
 // Streamer opens a track as a stream of 20ms Opus packets (opus.Reader). How it
 // produces them is internal: passthrough sources demux a native Opus container,
@@ -9,3 +21,55 @@ import "github.com/keshon/melodix/pkg/music/opus"
 type Streamer interface {
 	Open(track *Track, seekSec float64) (opus.Reader, func(), error)
 }
+
+// ContextStreamer is implemented by streamers whose Open can be abandoned
+// part way: an extraction that takes seconds is cancelled with ctx when the
+// listener skips. ctx bounds the open only — a stream already returned is
+// ended by its cleanup, not by ctx.
+type ContextStreamer interface {
+	Streamer
+	OpenContext(ctx context.Context, track *Track, seekSec float64) (opus.Reader, func(), error)
+}
+
+// OpenContext opens track with s, through its OpenContext when it has one.
+// A streamer without one is not started once ctx is done, but cannot be
+// stopped after that.
+func OpenContext(ctx context.Context, s Streamer, track *Track, seekSec float64) (opus.Reader, func(), error) {
+	if cs, ok := s.(ContextStreamer); ok {
+		return cs.OpenContext(ctx, track, seekSec)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	return s.Open(track, seekSec)
+}
+
+// Capabilities describes what a parser can do, so recovery and parser
+// selection do not have to guess.
+type Capabilities struct {
+	// Seekable parsers honour seekSec: a reopen resumes where playback was.
+	Seekable bool
+	// Live parsers play streams with no end (radio, live broadcasts).
+	Live bool
+	// Passthrough parsers can forward native Opus without a transcode.
+	Passthrough bool
+	// NeedsFFmpeg parsers produce nothing without the ffmpeg binary.
+	NeedsFFmpeg bool
+	// NeedsYtdlp parsers produce nothing without the yt-dlp binary.
+	NeedsYtdlp bool
+}
+
+// Capable is implemented by streamers that report their Capabilities. All
+// built-in parsers do; for others, callers fall back to guessing.
+type Capable interface {
+	Capabilities() Capabilities
+}
+
+// CapabilitiesOf returns s's capabilities; ok is false when s does not
+// report them.
+func CapabilitiesOf(s Streamer) (caps Capabilities, ok bool) {
+	if c, ok := s.(Capable); ok {
+		return c.Capabilities(), true
+	}
+	return Capabilities{}, false
+}
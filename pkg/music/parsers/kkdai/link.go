@@ -1,6 +1,7 @@
 package kkdai
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sync"
@@ -12,7 +13,7 @@ import (
 	"github.com/kkdai/youtube/v2"
 )
 
-func kkdaiLink(track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
+func kkdaiLink(ctx context.Context, track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
 	videoID, err := extractYouTubeID(track.URL)
 	if err != nil {
 		return nil, nil, err
@@ -31,7 +32,7 @@ func kkdaiLink(track *parsers.Track, seekSec float64) (opus.Reader, func(), erro
 	go func() {
 		defer wg.Done()
 		client := &youtube.Client{}
-		video, err := client.GetVideo(videoID)
+		video, err := client.GetVideoContext(ctx, videoID)
 		ch <- res{client: client, video: video, err: err}
 	}()
 
@@ -55,7 +56,7 @@ func kkdaiLink(track *parsers.Track, seekSec float64) (opus.Reader, func(), erro
 	}
 
 	if client == nil || video == nil {
-		return nil, nil, fmt.Errorf("kkdai: youtube client: %w", lastErr)
+		return nil, nil, clientErr(lastErr)
 	}
 
 	track.Duration = video.Duration
@@ -66,7 +67,7 @@ func kkdaiLink(track *parsers.Track, seekSec float64) (opus.Reader, func(), erro
 		return nil, nil, errors.New("kkdai: no audio formats found")
 	}
 
-	link, err := client.GetStreamURL(video, &formats[0])
+	link, err := client.GetStreamURLContext(ctx, video, &formats[0])
 	if err != nil {
 		return nil, nil, fmt.Errorf("kkdai: get stream url: %w", err)
 	}
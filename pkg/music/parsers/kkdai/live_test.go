@@ -1,6 +1,7 @@
 package kkdai
 
 import (
+	"context"
 	"os"
 	"testing"
 
@@ -34,7 +35,7 @@ func TestLivePipeOpensAnOrdinaryVideo(t *testing.T) {
 	skipUnlessLive(t)
 
 	track := liveTrack(vodURL)
-	r, cleanup, err := kkdaiPipe(track, 0)
+	r, cleanup, err := kkdaiPipe(context.Background(), track, 0)
 	if err != nil {
 		t.Fatalf("kkdai pipe refused an ordinary video: %v", err)
 	}
@@ -55,7 +56,7 @@ func TestLiveLinkOpensAnOrdinaryVideo(t *testing.T) {
 	skipUnlessLive(t)
 
 	track := liveTrack(vodURL)
-	r, cleanup, err := kkdaiLink(track, 0)
+	r, cleanup, err := kkdaiLink(context.Background(), track, 0)
 	if err != nil {
 		t.Fatalf("kkdai link refused an ordinary video: %v", err)
 	}
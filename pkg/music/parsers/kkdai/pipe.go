@@ -1,6 +1,7 @@
 package kkdai
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
@@ -17,16 +18,16 @@ import (
 // isn't forwardable, it errors and recovery falls through to kkdai-link.
 // The InnerTube client this rides on is set in streamer.go, and the choice
 // decides whether the CDN answers at all — see VisionOSClient.
-func kkdaiPipe(track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
+func kkdaiPipe(ctx context.Context, track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
 	videoID, err := extractYouTubeID(track.URL)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	client := &youtube.Client{}
-	video, err := client.GetVideo(videoID)
+	video, err := client.GetVideoContext(ctx, videoID)
 	if err != nil {
-		return nil, nil, fmt.Errorf("kkdai: youtube client: %w", err)
+		return nil, nil, clientErr(err)
 	}
 	track.Duration = video.Duration
 	track.Title = video.Title
@@ -35,6 +36,7 @@ func kkdaiPipe(track *parsers.Track, seekSec float64) (opus.Reader, func(), erro
 	if !ok {
 		return nil, nil, errors.New("kkdai: no webm/opus format for passthrough")
 	}
+	// Not GetStreamContext: the stream outlives the open, and ends by cleanup.
 	stream, _, err := client.GetStream(video, &f)
 	if err != nil {
 		return nil, nil, fmt.Errorf("kkdai: get stream: %w", err)
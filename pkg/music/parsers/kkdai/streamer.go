@@ -1,6 +1,7 @@
 package kkdai
 
 import (
+	"context"
 	"sync/atomic"
 
 	"github.com/keshon/melodix/pkg/music/opus"
@@ -65,8 +66,22 @@ func logger() zerolog.Logger {
 }
 
 func (s *Streamer) Open(track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
+	return s.OpenContext(context.Background(), track, seekSec)
+}
+
+// OpenContext is Open with the InnerTube requests bound to ctx.
+func (s *Streamer) OpenContext(ctx context.Context, track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
+	if s.Mode == ModePipe {
+		return kkdaiPipe(ctx, track, seekSec)
+	}
+	return kkdaiLink(ctx, track, seekSec)
+}
+
+// Capabilities: kkdai's client gets no formats for a live broadcast (see
+// above). The pipe path is passthrough only; the link path transcodes.
+func (s *Streamer) Capabilities() parsers.Capabilities {
 	if s.Mode == ModePipe {
-		return kkdaiPipe(track, seekSec)
+		return parsers.Capabilities{Seekable: true, Passthrough: true}
 	}
-	return kkdaiLink(track, seekSec)
+	return parsers.Capabilities{Seekable: true, NeedsFFmpeg: true}
 }
@@ -2,9 +2,25 @@ package kkdai
 
 import (
 	"errors"
+	"fmt"
 	"strings"
+
+	"github.com/keshon/melodix/pkg/music/parsers"
+
+	"github.com/kkdai/youtube/v2"
 )
 
+// clientErr wraps a failed video lookup, marking YouTube's age gate with
+// parsers.ErrSignInRequired. kkdai retries an age-restricted video through the
+// embedded player by itself, so one that still fails has nowhere left to go
+// without cookies.
+func clientErr(err error) error {
+	if errors.Is(err, youtube.ErrLoginRequired) {
+		return fmt.Errorf("kkdai: youtube client: %w: %w", parsers.ErrSignInRequired, err)
+	}
+	return fmt.Errorf("kkdai: youtube client: %w", err)
+}
+
 func extractYouTubeID(url string) (string, error) {
 	switch {
 	case strings.Contains(url, "youtu.be/"):
@@ -0,0 +1,115 @@
+// Package localfile plays files from the local library (see pkg/music/library):
+// Ogg Opus and WebM are forwarded as-is, everything else goes through ffmpeg.
+// Every path is confined to the library roots before it is opened.
+package localfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+
+	"github.com/keshon/melodix/pkg/music/library"
+	"github.com/keshon/melodix/pkg/music/opus"
+	"github.com/keshon/melodix/pkg/music/parsers"
+	ffmpegparser "github.com/keshon/melodix/pkg/music/parsers/ffmpeg"
+)
+
+var logPtr atomic.Pointer[zerolog.Logger]
+
+// SetLogger sets the package logger. Safe for concurrent use; call once at
+// process startup.
+func SetLogger(l zerolog.Logger) {
+	logPtr.Store(&l)
+}
+
+func logger() zerolog.Logger {
+	if l := logPtr.Load(); l != nil {
+		return *l
+	}
+	return zerolog.Nop()
+}
+
+// Mode selects how the file becomes Opus: Passthrough demuxes a native Opus
+// container and falls back to ffmpeg when the file isn't one; FFmpeg always
+// transcodes.
+type Mode int
+
+const (
+	ModePassthrough Mode = iota
+	ModeFFmpeg
+)
+
+// Streamer opens library files.
+type Streamer struct{ Mode Mode }
+
+func (s *Streamer) Open(track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
+	lib := library.Default()
+	if lib == nil {
+		return nil, nil, fmt.Errorf("localfile: %w", library.ErrNoLibrary)
+	}
+	e, err := lib.Find(track.URL)
+	if err != nil {
+		return nil, nil, err
+	}
+	if track.Title == "" {
+		track.Title = e.Title
+	}
+	if track.Artist == "" {
+		track.Artist = e.Artist
+	}
+	// Never zero (see library.Entry.DurationMs): recovery must not mistake the
+	// end of a file for a dropped live stream.
+	track.Duration = e.Duration()
+
+	if s.Mode == ModePassthrough && library.Passthrough(e.Path) {
+		r, cleanup, err := openPassthrough(e.Path, seekSec)
+		l := logger()
+		if err == nil {
+			track.Passthrough = true
+			l.Info().Str("id", e.ID).Msg("localfile_passthrough")
+			return r, cleanup, nil
+		}
+		l.Warn().Str("id", e.ID).Err(err).Msg("localfile_passthrough_failed_ffmpeg_fallback")
+	}
+
+	// ffmpeg opens the file itself rather than reading it from a pipe: an MP4
+	// with its index at the end cannot be decoded from a non-seekable input.
+	// The "file:" prefix keeps a name like "concat:..." or "http:..." from
+	// being taken as a protocol.
+	cmd := ffmpegparser.NewPCMCommand("file:"+e.Path, seekSec, false, "local-ffmpeg")
+	return ffmpegparser.OpusReader(cmd, "localfile")
+}
+
+// Capabilities: both modes seek. Passthrough needs no ffmpeg for Opus files
+// (other formats still go through it); the ffmpeg mode always does.
+func (s *Streamer) Capabilities() parsers.Capabilities {
+	if s.Mode == ModePassthrough {
+		return parsers.Capabilities{Seekable: true, Passthrough: true}
+	}
+	return parsers.Capabilities{Seekable: true, NeedsFFmpeg: true}
+}
+
+func openPassthrough(path string, seekSec float64) (opus.Reader, func(), error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var r opus.Reader
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".webm":
+		r, err = opus.Passthrough(f, opus.SeekPackets(seekSec))
+	case ".opus", ".ogg", ".oga":
+		r, err = opus.PassthroughOgg(f, opus.SeekPackets(seekSec))
+	default:
+		_ = f.Close()
+		return nil, nil, fmt.Errorf("localfile: %s is not an opus container", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, nil, err // Passthrough closed f
+	}
+	return r, func() { _ = r.Close() }, nil
+}
@@ -0,0 +1,119 @@
+package localfile
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/keshon/melodix/pkg/music/library"
+	"github.com/keshon/melodix/pkg/music/opus"
+	"github.com/keshon/melodix/pkg/music/parsers"
+)
+
+// withLibrary installs a library over root for the test's duration.
+func withLibrary(t *testing.T, root string) *library.Library {
+	t.Helper()
+	lib, err := library.New([]string{root}, nil, zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := library.Default()
+	library.SetDefault(lib)
+	t.Cleanup(func() { library.SetDefault(prev) })
+	return lib
+}
+
+func writeOggOpus(t *testing.T, path string, pkts [][]byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	w := opus.NewOggWriter(&buf)
+	for _, p := range pkts {
+		if err := w.WritePacket(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpenPassesOggOpusThrough(t *testing.T) {
+	root := t.TempDir()
+	pkts := make([][]byte, 100)
+	for i := range pkts {
+		pkts[i] = []byte{31 << 3, byte(i), 0xAA} // 20ms CELT frames, numbered
+	}
+	path := filepath.Join(root, "rec.ogg")
+	writeOggOpus(t, path, pkts)
+	lib := withLibrary(t, root)
+	if _, _, err := lib.Scan(); err != nil {
+		t.Fatal(err)
+	}
+	e, err := lib.ByPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The local source hands out ids, not paths.
+	track := &parsers.Track{URL: library.IDPrefix + e.ID}
+	r, cleanup, err := (&Streamer{Mode: ModePassthrough}).Open(track, 1)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer cleanup()
+	if !track.Passthrough {
+		t.Error("an Ogg Opus file should play without ffmpeg")
+	}
+	if track.Duration <= 0 {
+		t.Error("Duration must be set, or recovery treats the end of the file as a dropout")
+	}
+	if track.Title != "rec" {
+		t.Errorf("Title = %q, want the file name", track.Title)
+	}
+
+	// A 1s seek skips 50 packets.
+	for i := 50; i < len(pkts); i++ {
+		got, err := r.ReadPacket()
+		if err != nil {
+			t.Fatalf("ReadPacket %d: %v", i, err)
+		}
+		if !bytes.Equal(got, pkts[i]) {
+			t.Fatalf("packet %d = % x, want % x", i, got, pkts[i])
+		}
+	}
+	if _, err := r.ReadPacket(); !errors.Is(err, io.EOF) {
+		t.Fatalf("trailing ReadPacket = %v, want EOF", err)
+	}
+}
+
+func TestOpenRefusesPathsOutsideTheLibrary(t *testing.T) {
+	root, outside := t.TempDir(), t.TempDir()
+	path := filepath.Join(outside, "x.opus")
+	writeOggOpus(t, path, [][]byte{{31 << 3, 0}})
+	withLibrary(t, root)
+
+	for _, mode := range []Mode{ModePassthrough, ModeFFmpeg} {
+		_, _, err := (&Streamer{Mode: mode}).Open(&parsers.Track{URL: path}, 0)
+		if !errors.Is(err, library.ErrOutsideRoots) {
+			t.Errorf("mode %d: Open = %v, want ErrOutsideRoots", mode, err)
+		}
+	}
+}
+
+func TestOpenWithoutLibrary(t *testing.T) {
+	prev := library.Default()
+	library.SetDefault(nil)
+	t.Cleanup(func() { library.SetDefault(prev) })
+
+	if _, _, err := (&Streamer{}).Open(&parsers.Track{URL: "/music/a.opus"}, 0); !errors.Is(err, library.ErrNoLibrary) {
+		t.Fatalf("Open = %v, want ErrNoLibrary", err)
+	}
+}
@@ -1,6 +1,7 @@
 package scnative
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -10,10 +11,10 @@ import (
 	"github.com/keshon/melodix/pkg/music/soundcloudapi"
 )
 
-func scnativeLink(track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
+func scnativeLink(ctx context.Context, track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
 	sc := soundcloudapi.Default()
 
-	t, err := sc.ResolveTrack(track.URL)
+	t, err := sc.ResolveTrackContext(ctx, track.URL)
 	if err != nil {
 		return nil, nil, fmt.Errorf("scnative: resolve: %w", err)
 	}
@@ -24,7 +25,7 @@ func scnativeLink(track *parsers.Track, seekSec float64) (opus.Reader, func(), e
 	if err != nil {
 		return nil, nil, fmt.Errorf("scnative: %w", err)
 	}
-	streamURL, err := sc.StreamURL(transcoding)
+	streamURL, err := sc.StreamURLContext(ctx, transcoding)
 	if err != nil {
 		return nil, nil, fmt.Errorf("scnative: stream url: %w", err)
 	}
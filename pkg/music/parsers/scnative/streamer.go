@@ -3,6 +3,8 @@
 package scnative
 
 import (
+	"context"
+
 	"github.com/keshon/melodix/pkg/music/opus"
 	"github.com/keshon/melodix/pkg/music/parsers"
 )
@@ -10,5 +12,15 @@ import (
 type Streamer struct{}
 
 func (s *Streamer) Open(track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
-	return scnativeLink(track, seekSec)
+	return s.OpenContext(context.Background(), track, seekSec)
+}
+
+// OpenContext is Open with the api-v2 requests bound to ctx.
+func (s *Streamer) OpenContext(ctx context.Context, track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
+	return scnativeLink(ctx, track, seekSec)
+}
+
+// Capabilities: ffmpeg transcodes SoundCloud's AAC or MP3 and seeks in it.
+func (s *Streamer) Capabilities() parsers.Capabilities {
+	return parsers.Capabilities{Seekable: true, NeedsFFmpeg: true}
 }
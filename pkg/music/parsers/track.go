@@ -27,6 +27,21 @@ type Track struct {
 	// Cached is true when the active stream is served from the local track cache
 	// (set by RecoveryStream at open; not persisted).
 	Cached bool
+	// StreamURL is the entry of SourceInfo.StreamURLs being played, set by the
+	// parser at open. A reopen starts from the entry after it, so a stream that
+	// died gives way to the next one the station listed.
+	StreamURL string
+	// StreamTitle is the song a live stream says is on air (ICY StreamTitle),
+	// as of the last change; "" for everything else. Set by the player, not
+	// persisted.
+	StreamTitle string
+	// OnStreamTitle, when set, receives every title a live stream announces.
+	// Parsers that read in-band metadata call it from their read path, so it
+	// must not block. Set by the player before Open.
+	OnStreamTitle func(title string)
+	// StartAt is where playback of this run began: the guild's saved position
+	// for a resumable track, zero otherwise. Set by the player, not persisted.
+	StartAt time.Duration
 	// SourceInfo is the resolver's original metadata, including the ordered
 	// parser preference list recovery iterates over.
 	SourceInfo sources.TrackInfo
@@ -1,6 +1,7 @@
 package ytdlp
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,8 +14,8 @@ import (
 	ffmpegparser "github.com/keshon/melodix/pkg/music/parsers/ffmpeg"
 )
 
-func ytdlpLink(track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
-	output, err := runJSON(exec.Command(YtdlpPath, args("-j", "-f", audioFormatSelector, track.URL)...), "get url")
+func ytdlpLink(ctx context.Context, track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
+	output, err := runJSON(exec.CommandContext(ctx, YtdlpPath, args("-j", "-f", audioFormatSelector, track.URL)...), "get url")
 	if err != nil {
 		return nil, nil, err
 	}
@@ -63,8 +64,12 @@ func ytdlpLink(track *parsers.Track, seekSec float64) (opus.Reader, func(), erro
 	// yt-dlp reports the headers it used in http_headers so the fetch can be handed
 	// off; passing the UA on keeps ffmpeg's request faithful to the one that
 	// resolved the URL. Measured against googlevideo, the UA does not decide a 403
-	// — the issuing InnerTube client does — so this is hygiene, not a fix.
-	cmd := ffmpegparser.NewPCMCommandUA(link, seekSec, true, "ytdlp-link", headerValue(headers, "User-Agent"))
+	// — the issuing InnerTube client does — so this is hygiene, not a fix. The
+	// proxy, by contrast, is load-bearing: see Options.ffmpegProxy.
+	cmd := ffmpegparser.NewPCMCommandHTTP(link, seekSec, true, "ytdlp-link", ffmpegparser.HTTPOptions{
+		UserAgent: headerValue(headers, "User-Agent"),
+		Proxy:     CurrentOptions().ffmpegProxy(),
+	})
 	return ffmpegparser.OpusReader(cmd, "ytdlp")
 }
 
@@ -0,0 +1,116 @@
+package ytdlp
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync/atomic"
+
+	"github.com/keshon/melodix/pkg/music/parsers"
+)
+
+// Options are the operator's yt-dlp settings, passed on every invocation after
+// this package's own YouTube arguments. They are what gets an age-restricted
+// or bot-checked video through: YouTube serves those only to a signed-in
+// session (CookiesFile), to an address it trusts (Proxy), or with a PO token
+// and the visitor data it was minted for (ExtractorArgs).
+type Options struct {
+	// CookiesFile is a Netscape-format cookies.txt exported from a browser
+	// signed in to YouTube (yt-dlp --cookies).
+	CookiesFile string
+	// Proxy is a proxy URL for yt-dlp's own requests (yt-dlp --proxy).
+	Proxy string
+	// ExtractorArgs are extra --extractor-args values, e.g.
+	// "youtube:po_token=web.gvs+TOKEN;visitor_data=DATA". They come after the
+	// embedded web client argument, so a player_client given here wins.
+	ExtractorArgs []string
+}
+
+var options atomic.Pointer[Options]
+
+// SetOptions installs the operator's yt-dlp settings. Safe for concurrent use;
+// call once at process startup.
+func SetOptions(o Options) {
+	o.ExtractorArgs = append([]string(nil), o.ExtractorArgs...)
+	options.Store(&o)
+}
+
+// CurrentOptions returns the settings SetOptions installed.
+func CurrentOptions() Options {
+	if o := options.Load(); o != nil {
+		return *o
+	}
+	return Options{}
+}
+
+// flags renders o as yt-dlp arguments.
+func (o Options) flags() []string {
+	var out []string
+	if o.CookiesFile != "" {
+		out = append(out, "--cookies", o.CookiesFile)
+	}
+	if o.Proxy != "" {
+		out = append(out, "--proxy", o.Proxy)
+	}
+	for _, ea := range o.ExtractorArgs {
+		out = append(out, "--extractor-args", ea)
+	}
+	return out
+}
+
+// VisitorData returns the visitor_data the extractor args give YouTube, or ""
+// when they give none. A PO token is bound to the visitor data it was minted
+// for, so anything else talking to InnerTube for the same videos should
+// present the same one.
+func (o Options) VisitorData() string {
+	for _, ea := range o.ExtractorArgs {
+		ie, kv, ok := strings.Cut(ea, ":")
+		if !ok || ie != "youtube" {
+			continue
+		}
+		for _, pair := range strings.Split(kv, ";") {
+			if k, v, ok := strings.Cut(pair, "="); ok && strings.TrimSpace(k) == "visitor_data" {
+				return strings.TrimSpace(v)
+			}
+		}
+	}
+	return ""
+}
+
+// ffmpegProxy is the proxy to hand ffmpeg for a URL yt-dlp resolved, or "".
+// googlevideo binds a stream URL to the address that asked for it, so when
+// yt-dlp went through a proxy the fetch has to as well. ffmpeg only speaks
+// HTTP proxies; for any other kind use the pipe parser, where yt-dlp does
+// the download itself.
+func (o Options) ffmpegProxy() string {
+	u, err := url.Parse(o.Proxy)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return o.Proxy
+}
+
+// signInMarkers are the phrases yt-dlp's errors use when YouTube wants a
+// signed-in session: age-restricted videos, and the "not a bot" check.
+var signInMarkers = []string{
+	"Sign in to confirm",
+	"age-restricted",
+	"inappropriate for some users",
+	"--cookies-from-browser or --cookies",
+}
+
+// classify marks a yt-dlp failure that asks for sign-in with
+// parsers.ErrSignInRequired, leaving others as they are.
+func classify(err error) error {
+	if err == nil || errors.Is(err, parsers.ErrSignInRequired) {
+		return err
+	}
+	msg := err.Error()
+	for _, m := range signInMarkers {
+		if strings.Contains(msg, m) {
+			return fmt.Errorf("%w: %w", parsers.ErrSignInRequired, err)
+		}
+	}
+	return err
+}
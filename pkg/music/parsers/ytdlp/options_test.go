@@ -0,0 +1,76 @@
+package ytdlp
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/keshon/melodix/pkg/music/parsers"
+)
+
+func setOptions(t *testing.T, o Options) {
+	t.Helper()
+	orig := CurrentOptions()
+	t.Cleanup(func() { SetOptions(orig) })
+	SetOptions(o)
+}
+
+// The operator's arguments come after the built-in ones, so a player_client
+// in them wins over the embedded web client.
+func TestArgsAppendsTheOperatorsOptionsLast(t *testing.T) {
+	resetRuntimeDetection(t, map[string]bool{"deno": true})
+	setOptions(t, Options{
+		CookiesFile:   "/data/cookies.txt",
+		Proxy:         "http://proxy:3128",
+		ExtractorArgs: []string{"youtube:player_client=tv", "youtube:po_token=web.gvs+T"},
+	})
+
+	got := args("-j", "https://a.test/1")
+	want := []string{
+		"--js-runtimes", "deno", "--extractor-args", embeddedWebClient,
+		"--cookies", "/data/cookies.txt",
+		"--proxy", "http://proxy:3128",
+		"--extractor-args", "youtube:player_client=tv",
+		"--extractor-args", "youtube:po_token=web.gvs+T",
+		"-j", "https://a.test/1",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("args =\n %v\nwant\n %v", got, want)
+	}
+}
+
+func TestOptionsVisitorData(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{nil, ""},
+		{[]string{"youtube:po_token=web.gvs+T;visitor_data=CgtABC"}, "CgtABC"},
+		{[]string{"youtube:player_client=tv", "youtube:visitor_data=CgtXYZ"}, "CgtXYZ"},
+		{[]string{"youtubetab:visitor_data=nope"}, ""},
+	} {
+		if got := (Options{ExtractorArgs: tc.args}).VisitorData(); got != tc.want {
+			t.Errorf("VisitorData(%v) = %q, want %q", tc.args, got, tc.want)
+		}
+	}
+}
+
+func TestOptionsFFmpegProxyIsHTTPOnly(t *testing.T) {
+	if got := (Options{Proxy: "http://u:p@proxy:3128"}).ffmpegProxy(); got != "http://u:p@proxy:3128" {
+		t.Errorf("http proxy = %q", got)
+	}
+	if got := (Options{Proxy: "socks5://proxy:1080"}).ffmpegProxy(); got != "" {
+		t.Errorf("socks proxy = %q, want none: ffmpeg cannot use it", got)
+	}
+}
+
+func TestClassifyMarksSignInFailures(t *testing.T) {
+	signIn := errors.New("ytdlp: get url: exit status 1: ERROR: [youtube] abc: Sign in to confirm your age. This video may be inappropriate for some users.")
+	if err := classify(signIn); !errors.Is(err, parsers.ErrSignInRequired) {
+		t.Errorf("classify(%v) is not ErrSignInRequired", err)
+	}
+	other := errors.New("ytdlp: get url: exit status 1: ERROR: Video unavailable")
+	if err := classify(other); errors.Is(err, parsers.ErrSignInRequired) {
+		t.Errorf("classify(%v) was marked sign-in", err)
+	}
+}
@@ -1,6 +1,7 @@
 package ytdlp
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os/exec"
@@ -11,8 +12,8 @@ import (
 	ffmpegparser "github.com/keshon/melodix/pkg/music/parsers/ffmpeg"
 )
 
-func ytdlpPipe(track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
-	output, err := runJSON(exec.Command(YtdlpPath, args("-j", "-f", audioFormatSelector, track.URL)...), "get json")
+func ytdlpPipe(ctx context.Context, track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
+	output, err := runJSON(exec.CommandContext(ctx, YtdlpPath, args("-j", "-f", audioFormatSelector, track.URL)...), "get json")
 	if err != nil {
 		return nil, nil, err
 	}
@@ -43,6 +44,7 @@ func ytdlpPipe(track *parsers.Track, seekSec float64) (opus.Reader, func(), erro
 
 	track.Duration = time.Duration(info.Duration * float64(time.Second))
 
+	// Not CommandContext: this process streams the track, and ends by cleanup.
 	ytdlp := exec.Command(YtdlpPath, args("-o", "-", "-f", audioFormatSelector, track.URL)...)
 	ffmpeg := ffmpegparser.NewPCMCommand("pipe:0", seekSec, false, "ytdlp-pipe")
 
@@ -47,10 +47,7 @@ var (
 // yt-dlp keeps its own defaults.
 func youtubeArgs() []string {
 	runtimeOnce.Do(func() {
-		for _, rt := range jsRuntimeCandidates {
-			if _, err := lookPath(rt); err != nil {
-				continue
-			}
+		if rt, _, ok := JSRuntime(); ok {
 			youtubeOpts = []string{"--js-runtimes", rt, "--extractor-args", embeddedWebClient}
 			l := logger()
 			l.Info().Str("js_runtime", rt).Str("player_client", "web_embedded").
@@ -64,12 +61,33 @@ func youtubeArgs() []string {
 	return youtubeOpts
 }
 
-// args builds a full yt-dlp argument list, prefixing the YouTube options.
+// JSRuntime returns the JavaScript runtime yt-dlp is given — the first of
+// deno, node and bun found on PATH — and where it was found. ok is false when
+// none is installed.
+func JSRuntime() (name, path string, ok bool) {
+	for _, rt := range jsRuntimeCandidates {
+		if p, err := lookPath(rt); err == nil {
+			return rt, p, true
+		}
+	}
+	return "", "", false
+}
+
+// JSRuntimeCandidates lists the runtimes JSRuntime looks for, in preference
+// order.
+func JSRuntimeCandidates() []string {
+	return append([]string(nil), jsRuntimeCandidates...)
+}
+
+// args builds a full yt-dlp argument list, prefixing the YouTube options and
+// then the operator's (see Options), which come later so they can override.
 // The prefix is copied rather than appended to: youtubeArgs returns the shared
 // slice, and appending into it would let one invocation scribble on the next.
 func args(rest ...string) []string {
 	opts := youtubeArgs()
-	out := make([]string, 0, len(opts)+len(rest))
+	flags := CurrentOptions().flags()
+	out := make([]string, 0, len(opts)+len(flags)+len(rest))
 	out = append(out, opts...)
+	out = append(out, flags...)
 	return append(out, rest...)
 }
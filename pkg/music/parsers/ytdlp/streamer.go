@@ -1,6 +1,7 @@
 package ytdlp
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os/exec"
@@ -28,7 +29,8 @@ func logger() zerolog.Logger {
 }
 
 // runJSON runs a yt-dlp command and returns its stdout, folding stderr into the
-// error when it fails.
+// error when it fails, and marking it parsers.ErrSignInRequired when that is
+// what the stderr says.
 //
 // Without this, a failure reaches the log as "exit status 1" and yt-dlp's own
 // explanation is discarded — which is exactly how a misconfigured environment
@@ -41,7 +43,7 @@ func runJSON(cmd *exec.Cmd, what string) ([]byte, error) {
 	}
 	var ee *exec.ExitError
 	if errors.As(err, &ee) && len(ee.Stderr) > 0 {
-		return nil, fmt.Errorf("ytdlp: %s: %w: %s", what, err, lastLines(string(ee.Stderr), 2))
+		return nil, classify(fmt.Errorf("ytdlp: %s: %w: %s", what, err, lastLines(string(ee.Stderr), 2)))
 	}
 	return nil, fmt.Errorf("ytdlp: %s: %w", what, err)
 }
@@ -96,8 +98,20 @@ const (
 type Streamer struct{ Mode Mode }
 
 func (s *Streamer) Open(track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
+	return s.OpenContext(context.Background(), track, seekSec)
+}
+
+// OpenContext is Open with the extraction bound to ctx: a cancelled open
+// kills the yt-dlp process that is still resolving the video.
+func (s *Streamer) OpenContext(ctx context.Context, track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
 	if s.Mode == ModePipe {
-		return ytdlpPipe(track, seekSec)
+		return ytdlpPipe(ctx, track, seekSec)
 	}
-	return ytdlpLink(track, seekSec)
+	return ytdlpLink(ctx, track, seekSec)
+}
+
+// Capabilities: yt-dlp extracts, ffmpeg transcodes and seeks. The format
+// selector's muxed fallback is what makes live broadcasts play.
+func (s *Streamer) Capabilities() parsers.Capabilities {
+	return parsers.Capabilities{Seekable: true, Live: true, NeedsFFmpeg: true, NeedsYtdlp: true}
 }
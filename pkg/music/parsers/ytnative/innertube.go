@@ -2,6 +2,7 @@ package ytnative
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/keshon/melodix/pkg/music/innertube"
+	"github.com/keshon/melodix/pkg/music/parsers"
 )
 
 // Thin InnerTube player client. The client identity it presents — VISIONOS, the
@@ -76,8 +78,9 @@ type playerResponse struct {
 // fetchPlayer POSTs the player request. InnerTube accepts keyless
 // requests; no poToken is sent — visionos is one of the clients that does not
 // require one. A visitorData session id is sent when one could be obtained (see
-// visitor.go), both in the client context and as X-Goog-Visitor-Id.
-func fetchPlayer(httpc *http.Client, endpoint, videoID string) (*playerResponse, error) {
+// visitor.go), both in the client context and as X-Goog-Visitor-Id, along with
+// the session's cookies (see session.go).
+func fetchPlayer(ctx context.Context, httpc *http.Client, endpoint, videoID string) (*playerResponse, error) {
 	client := innertube.Client()
 	vid := visitorID(httpc)
 	if vid != "" {
@@ -94,7 +97,7 @@ func fetchPlayer(httpc *http.Client, endpoint, videoID string) (*playerResponse,
 		return nil, err
 	}
 
-	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
@@ -103,6 +106,7 @@ func fetchPlayer(httpc *http.Client, endpoint, videoID string) (*playerResponse,
 	if vid != "" {
 		req.Header.Set("X-Goog-Visitor-Id", vid)
 	}
+	addCookies(req)
 
 	resp, err := httpc.Do(req)
 	if err != nil {
@@ -123,12 +127,29 @@ func fetchPlayer(httpc *http.Client, endpoint, videoID string) (*playerResponse,
 	// Adopt before the playability check: a refused response still carries a
 	// usable session id, and the next attempt should reuse it.
 	rememberVisitorID(pr.ResponseContext.VisitorData)
-	if pr.PlayabilityStatus.Status != "OK" {
-		return nil, fmt.Errorf("%w: %s (%s)", ErrNotPlayable, pr.PlayabilityStatus.Status, pr.PlayabilityStatus.Reason)
+	if st := pr.PlayabilityStatus; st.Status != "OK" {
+		if signInRequired(st.Status, st.Reason) {
+			return nil, fmt.Errorf("%w: %w: %s (%s)", ErrNotPlayable, parsers.ErrSignInRequired, st.Status, st.Reason)
+		}
+		return nil, fmt.Errorf("%w: %s (%s)", ErrNotPlayable, st.Status, st.Reason)
 	}
 	return &pr, nil
 }
 
+// signInRequired reports whether a playability status asks for a signed-in
+// session. LOGIN_REQUIRED covers both age-restricted videos and the "confirm
+// you're not a bot" check, but also private videos, which no session of the
+// bot's will open.
+func signInRequired(status, reason string) bool {
+	switch status {
+	case "AGE_CHECK_REQUIRED", "AGE_VERIFICATION_REQUIRED", "CONTENT_CHECK_REQUIRED":
+		return true
+	case "LOGIN_REQUIRED":
+		return !strings.Contains(strings.ToLower(reason), "private")
+	}
+	return false
+}
+
 // pickOpusFormat returns the highest-bitrate WebM/Opus audio format with a direct
 // URL (itag 251/250/249) that fits under the configured bitrate cap — the
 // passthrough candidate. ok is false if none exist.
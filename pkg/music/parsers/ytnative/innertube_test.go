@@ -1,6 +1,7 @@
 package ytnative
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -25,7 +26,7 @@ func TestFetchPlayerSendsClientContext(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	pr, err := fetchPlayer(srv.Client(), srv.URL, "dQw4w9WgXcQ")
+	pr, err := fetchPlayer(context.Background(), srv.Client(), srv.URL, "dQw4w9WgXcQ")
 	if err != nil {
 		t.Fatalf("fetchPlayer: %v", err)
 	}
@@ -56,7 +57,7 @@ func TestFetchPlayerNotPlayable(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	_, err := fetchPlayer(srv.Client(), srv.URL, "abcdefghijk")
+	_, err := fetchPlayer(context.Background(), srv.Client(), srv.URL, "abcdefghijk")
 	if !errors.Is(err, ErrNotPlayable) {
 		t.Fatalf("err = %v, want ErrNotPlayable", err)
 	}
@@ -1,6 +1,7 @@
 package ytnative
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -11,20 +12,22 @@ import (
 	ffmpegparser "github.com/keshon/melodix/pkg/music/parsers/ffmpeg"
 )
 
-func ytnativeLink(track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
+func ytnativeLink(ctx context.Context, track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
 	videoID, err := extractVideoID(track.URL)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	pr, err := fetchPlayer(httpClient, playerEndpoint, videoID)
+	pr, err := fetchPlayer(ctx, httpClient, playerEndpoint, videoID)
 	if err != nil {
 		l := logger()
 		l.Warn().Str("video_id", videoID).Str("client_version", clientVersion).Err(err).Msg("ytnative_player_failed")
 		return nil, nil, err
 	}
 
-	if pr.VideoDetails.Title != "" {
+	// A track matched from a catalogue link keeps the catalogue's title, which
+	// is the song's; the upload's own may be anybody's take on it.
+	if pr.VideoDetails.Title != "" && track.SourceInfo.OriginURL == "" {
 		track.Title = pr.VideoDetails.Title
 	}
 
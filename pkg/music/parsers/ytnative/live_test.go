@@ -1,22 +1,23 @@
 package ytnative
 
 import (
+	"context"
 	"net/http"
 	"os"
 	"testing"
 
 	gopus "github.com/godeps/opus"
+	"github.com/keshon/melodix/pkg/music/httpreplay/replaytest"
 	"github.com/keshon/melodix/pkg/music/opus"
 )
 
 // TestLivePassthrough exercises the full passthrough path against real YouTube:
 // InnerTube → pick Opus/WebM → HTTP stream → demux → framing guard → decode.
-// It proves a track plays with no ffmpeg. Opt-in via MELODIX_LIVE_TESTS=1.
+// It proves a track plays with no ffmpeg. Replays testdata/replay; see replaytest
+// for hitting the network or re-recording.
 func TestLivePassthrough(t *testing.T) {
-	if os.Getenv("MELODIX_LIVE_TESTS") == "" {
-		t.Skip("set MELODIX_LIVE_TESTS=1 to hit real YouTube")
-	}
-	pr, err := fetchPlayer(httpClient, playerEndpoint, "dQw4w9WgXcQ")
+	replaytest.Start(t, "testdata/replay")
+	pr, err := fetchPlayer(context.Background(), httpClient, playerEndpoint, "dQw4w9WgXcQ")
 	if err != nil {
 		t.Fatalf("fetchPlayer: %v", err)
 	}
@@ -59,13 +60,11 @@ func TestLivePassthrough(t *testing.T) {
 // TestLiveInnerTube hits the real InnerTube API with the configured client and checks
 // that a direct (cipher-free) audio URL comes back and the CDN accepts our UA.
 // This is the canary for clientVersion rot.
-// Opt-in: MELODIX_LIVE_TESTS=1 go test -run Live -v ./pkg/music/parsers/ytnative
+// Against the network: MELODIX_LIVE_TESTS=1 go test -run Live -v ./pkg/music/parsers/ytnative
 func TestLiveInnerTube(t *testing.T) {
-	if os.Getenv("MELODIX_LIVE_TESTS") == "" {
-		t.Skip("set MELODIX_LIVE_TESTS=1 to hit real YouTube")
-	}
+	replaytest.Start(t, "testdata/replay")
 
-	pr, err := fetchPlayer(httpClient, playerEndpoint, "dQw4w9WgXcQ")
+	pr, err := fetchPlayer(context.Background(), httpClient, playerEndpoint, "dQw4w9WgXcQ")
 	if err != nil {
 		t.Fatalf("fetchPlayer (clientVersion %s may need a bump): %v", clientVersion, err)
 	}
@@ -102,12 +101,13 @@ func TestLiveInnerTube(t *testing.T) {
 // plain GET) and ffmpeg (Range: bytes=0-) ask open-ended, so if this test starts
 // failing, playback is broken and the client constants are where to look —
 // bounded-range reads or a different client would be the fix, not a UA or nsig
-// change. Opt-in via MELODIX_LIVE_TESTS=1.
+// change. A recording cannot answer that, only the CDN can, so this one stays
+// opt-in via MELODIX_LIVE_TESTS=1.
 func TestLiveOpenEndedRequestAccepted(t *testing.T) {
 	if os.Getenv("MELODIX_LIVE_TESTS") == "" {
 		t.Skip("set MELODIX_LIVE_TESTS=1 to hit real YouTube")
 	}
-	pr, err := fetchPlayer(httpClient, playerEndpoint, "dQw4w9WgXcQ")
+	pr, err := fetchPlayer(context.Background(), httpClient, playerEndpoint, "dQw4w9WgXcQ")
 	if err != nil {
 		t.Fatalf("fetchPlayer: %v", err)
 	}
@@ -1,6 +1,7 @@
 package ytnative
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -61,7 +62,7 @@ func TestOpenAcceptsAVODThatCarriesAnHLSManifest(t *testing.T) {
 	withPlayerResponse(t, vodPlayerResponse)
 
 	track := testTrack()
-	pr, err := fetchPlayer(httpClient, playerEndpoint, "dQw4w9WgXcQ")
+	pr, err := fetchPlayer(context.Background(), httpClient, playerEndpoint, "dQw4w9WgXcQ")
 	if err != nil {
 		t.Fatalf("fetchPlayer on a playable VOD: %v", err)
 	}
@@ -76,7 +77,7 @@ func TestOpenAcceptsAVODThatCarriesAnHLSManifest(t *testing.T) {
 	// point of fetching the CDN URL, rather than bailing out early. The stub
 	// serves JSON rather than media, so a transport-shaped failure is expected
 	// and fine — a live-stream rejection is not.
-	_, _, err = ytnativeLink(track, 0)
+	_, _, err = ytnativeLink(context.Background(), track, 0)
 	if err != nil && errors.Is(err, ErrNotPlayable) {
 		t.Fatalf("VOD rejected as not playable: %v", err)
 	}
@@ -95,7 +96,7 @@ func TestOpenRejectsALiveBroadcast(t *testing.T) {
 		"streamingData": {"adaptiveFormats": []}
 	}`)
 
-	_, _, err := ytnativeLink(testTrack(), 0)
+	_, _, err := ytnativeLink(context.Background(), testTrack(), 0)
 	if err == nil {
 		t.Fatal("a live broadcast opened successfully")
 	}
@@ -114,7 +115,7 @@ func TestLiveBroadcastReportsANonZeroDuration(t *testing.T) {
 		"streamingData": {"adaptiveFormats": []}
 	}`)
 
-	pr, err := fetchPlayer(httpClient, playerEndpoint, "jfKfPfyJRdk")
+	pr, err := fetchPlayer(context.Background(), httpClient, playerEndpoint, "jfKfPfyJRdk")
 	if err != nil {
 		t.Fatalf("fetchPlayer: %v", err)
 	}
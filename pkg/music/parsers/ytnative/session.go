@@ -0,0 +1,94 @@
+package ytnative
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// The operator's YouTube session, shared with yt-dlp (see ytdlp.Options), so
+// both fallbacks look like the same visitor. What it buys here is narrower than
+// what it buys yt-dlp: VISIONOS, like yt-dlp's other app clients, does not
+// take a signed-in session, so cookies do not unlock an age-restricted video
+// for this parser — that stays yt-dlp's job. They are still sent, on the
+// bootstrap as well as the player request, because the visitor identity and
+// consent state they carry are part of what the bot check looks at, and a
+// pinned visitorData has to match the PO token yt-dlp was given with it.
+
+// Session is the YouTube identity InnerTube requests present.
+type Session struct {
+	// Cookies go out with every request to youtube.com.
+	Cookies []*http.Cookie
+	// VisitorData, when set, is used instead of a bootstrapped id and is never
+	// replaced by one from a response.
+	VisitorData string
+}
+
+var session atomic.Pointer[Session]
+
+// SetSession installs the YouTube session. Safe for concurrent use; call once
+// at process startup.
+func SetSession(s Session) {
+	session.Store(&s)
+}
+
+func currentSession() Session {
+	if s := session.Load(); s != nil {
+		return *s
+	}
+	return Session{}
+}
+
+// addCookies sets the session's cookies on a request to youtube.com.
+func addCookies(req *http.Request) {
+	for _, c := range currentSession().Cookies {
+		req.AddCookie(c)
+	}
+}
+
+// LoadCookies reads the youtube.com cookies out of a Netscape-format
+// cookies.txt — the file yt-dlp takes with --cookies — skipping expired ones.
+func LoadCookies(path string) ([]*http.Cookie, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ytnative: cookies: %w", err)
+	}
+	defer f.Close()
+
+	var out []*http.Cookie
+	now := time.Now()
+	sc := bufio.NewScanner(f)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		// curl marks HttpOnly cookies with a prefix on an otherwise commented line.
+		text = strings.TrimPrefix(text, "#HttpOnly_")
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("ytnative: cookies: %s:%d: want 7 tab-separated fields, got %d", path, line, len(fields))
+		}
+		domain := strings.TrimPrefix(fields[0], ".")
+		if domain != "youtube.com" && !strings.HasSuffix(domain, ".youtube.com") {
+			continue
+		}
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("ytnative: cookies: %s:%d: bad expiry %q", path, line, fields[4])
+		}
+		if expires != 0 && time.Unix(expires, 0).Before(now) {
+			continue
+		}
+		out = append(out, &http.Cookie{Name: fields[5], Value: fields[6]})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("ytnative: cookies: %w", err)
+	}
+	return out, nil
+}
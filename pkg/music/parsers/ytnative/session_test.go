@@ -0,0 +1,108 @@
+package ytnative
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/keshon/melodix/pkg/music/parsers"
+)
+
+func setSession(t *testing.T, s Session) {
+	t.Helper()
+	orig := currentSession()
+	t.Cleanup(func() { SetSession(orig) })
+	SetSession(s)
+}
+
+func TestLoadCookiesKeepsLiveYouTubeCookies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.txt")
+	content := "# Netscape HTTP Cookie File\n" +
+		".youtube.com\tTRUE\t/\tTRUE\t4102444800\tSAPISID\tabc\n" +
+		"#HttpOnly_.youtube.com\tTRUE\t/\tTRUE\t0\tLOGIN_INFO\tdef\n" +
+		".youtube.com\tTRUE\t/\tTRUE\t946684800\tEXPIRED\told\n" +
+		".google.com\tTRUE\t/\tTRUE\t4102444800\tSID\tnot-ours\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cookies, err := LoadCookies(path)
+	if err != nil {
+		t.Fatalf("LoadCookies: %v", err)
+	}
+	var names []string
+	for _, c := range cookies {
+		names = append(names, c.Name)
+	}
+	if fmt.Sprint(names) != "[SAPISID LOGIN_INFO]" {
+		t.Fatalf("cookies = %v, want SAPISID and LOGIN_INFO", names)
+	}
+}
+
+func TestLoadCookiesRejectsAMalformedLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.txt")
+	if err := os.WriteFile(path, []byte(".youtube.com SAPISID abc\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCookies(path); err == nil {
+		t.Fatal("expected an error for a line that is not tab-separated")
+	}
+}
+
+// The configured cookies and pinned visitor data go out with the player
+// request, and a response's own visitor data does not replace the pin.
+func TestFetchPlayerPresentsTheSession(t *testing.T) {
+	setSession(t, Session{
+		Cookies:     []*http.Cookie{{Name: "SAPISID", Value: "abc"}},
+		VisitorData: "CgtPINNED",
+	})
+	var gotCookie, gotVisitor string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotCookie = r.Header.Get("Cookie")
+		gotVisitor = r.Header.Get("X-Goog-Visitor-Id")
+		fmt.Fprint(w, `{"responseContext": {"visitorData": "CgtOTHER"}, "playabilityStatus": {"status": "OK"}}`)
+	}))
+	defer srv.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := fetchPlayer(context.Background(), srv.Client(), srv.URL, "dQw4w9WgXcQ"); err != nil {
+			t.Fatalf("fetchPlayer: %v", err)
+		}
+		if gotCookie != "SAPISID=abc" {
+			t.Fatalf("Cookie = %q", gotCookie)
+		}
+		if gotVisitor != "CgtPINNED" {
+			t.Fatalf("X-Goog-Visitor-Id = %q on request %d, want the pinned one", gotVisitor, i+1)
+		}
+	}
+}
+
+func TestFetchPlayerSignInRequired(t *testing.T) {
+	for _, tc := range []struct {
+		status, reason string
+		want           bool
+	}{
+		{"LOGIN_REQUIRED", "Sign in to confirm your age", true},
+		{"LOGIN_REQUIRED", "Sign in to confirm you’re not a bot", true},
+		{"AGE_CHECK_REQUIRED", "", true},
+		{"LOGIN_REQUIRED", "This video is private", false},
+		{"UNPLAYABLE", "Video unavailable", false},
+	} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"playabilityStatus": {"status": %q, "reason": %q}}`, tc.status, tc.reason)
+		}))
+		_, err := fetchPlayer(context.Background(), srv.Client(), srv.URL, "abcdefghijk")
+		srv.Close()
+		if !errors.Is(err, ErrNotPlayable) {
+			t.Errorf("%s: err = %v, want ErrNotPlayable", tc.status, err)
+		}
+		if got := errors.Is(err, parsers.ErrSignInRequired); got != tc.want {
+			t.Errorf("%s (%s): sign-in required = %v, want %v", tc.status, tc.reason, got, tc.want)
+		}
+	}
+}
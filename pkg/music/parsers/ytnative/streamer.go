@@ -1,14 +1,17 @@
 // Package ytnative streams YouTube audio via a thin InnerTube client (VISIONOS
 // context, direct cipher-free URLs), preferring Opus passthrough and falling
 // back to ffmpeg. No JS engine, no signature deciphering, no PO token —
-// protected videos fail fast into the kkdai/yt-dlp fallbacks.
+// protected videos fail fast into the kkdai/yt-dlp fallbacks, marked
+// parsers.ErrSignInRequired when YouTube wants a signed-in session.
 package ytnative
 
 import (
+	"context"
 	"net/http"
 	"sync/atomic"
 	"time"
 
+	"github.com/keshon/melodix/pkg/music/httpreplay"
 	"github.com/keshon/melodix/pkg/music/opus"
 	"github.com/keshon/melodix/pkg/music/parsers"
 	"github.com/rs/zerolog"
@@ -18,14 +21,26 @@ type Streamer struct{}
 
 // httpClient is for the quick InnerTube POST. streamClient has no total timeout
 // because a passthrough body streams for the whole track; a dropped connection
-// surfaces as a read error and the player's recovery re-opens.
+// surfaces as a read error and the player's recovery re-opens. Both go through
+// httpreplay, so tests can serve them recorded responses.
 var (
-	httpClient   = &http.Client{Timeout: 10 * time.Second}
-	streamClient = &http.Client{}
+	httpClient   = &http.Client{Timeout: 10 * time.Second, Transport: httpreplay.Wrap(nil)}
+	streamClient = &http.Client{Transport: httpreplay.Wrap(nil)}
 )
 
 func (s *Streamer) Open(track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
-	return ytnativeLink(track, seekSec)
+	return s.OpenContext(context.Background(), track, seekSec)
+}
+
+// OpenContext is Open with the player request bound to ctx.
+func (s *Streamer) OpenContext(ctx context.Context, track *parsers.Track, seekSec float64) (opus.Reader, func(), error) {
+	return ytnativeLink(ctx, track, seekSec)
+}
+
+// Capabilities: passthrough when YouTube offers Opus, ffmpeg otherwise. Live
+// broadcasts never get this far (see ytnativeLink).
+func (s *Streamer) Capabilities() parsers.Capabilities {
+	return parsers.Capabilities{Seekable: true, Passthrough: true}
 }
 
 // maxBitrate caps which audio format is chosen, in bits per second (0 = no cap,
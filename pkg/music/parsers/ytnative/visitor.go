@@ -46,12 +46,16 @@ var visitor struct {
 
 var visitorDataRe = regexp.MustCompile(`"visitorData":"([^"]+)"`)
 
-// visitorID returns the cached id, bootstrapping one when the cache is empty or
-// stale. It returns "" when none could be obtained; callers then proceed without
-// one. The HTTP call happens under the lock, which serializes a concurrent first
-// play across guilds for at most httpc's timeout -- acceptable given it runs
-// once per visitorTTL on success and once per visitorRetryWait on failure.
+// visitorID returns the pinned id when the session has one (see Session), else
+// the cached id, bootstrapping one when the cache is empty or stale. It returns
+// "" when none could be obtained; callers then proceed without one. The HTTP
+// call happens under the lock, which serializes a concurrent first play across
+// guilds for at most httpc's timeout -- acceptable given it runs once per
+// visitorTTL on success and once per visitorRetryWait on failure.
 func visitorID(httpc *http.Client) string {
+	if pinned := currentSession().VisitorData; pinned != "" {
+		return pinned
+	}
 	visitor.mu.Lock()
 	defer visitor.mu.Unlock()
 
@@ -95,6 +99,7 @@ func fetchVisitorID(httpc *http.Client) (string, error) {
 	}
 	req.Header.Set("User-Agent", bootstrapUA)
 	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	addCookies(req)
 
 	resp, err := httpc.Do(req)
 	if err != nil {
@@ -1,6 +1,7 @@
 package ytnative
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -106,7 +107,7 @@ func TestFetchPlayer_SendsAndAdoptsVisitorID(t *testing.T) {
 	defer srv.Close()
 
 	// A refused response is still expected to yield its id.
-	if _, err := fetchPlayer(srv.Client(), srv.URL, "ZTidn2dBYbY"); err == nil {
+	if _, err := fetchPlayer(context.Background(), srv.Client(), srv.URL, "ZTidn2dBYbY"); err == nil {
 		t.Fatal("expected LOGIN_REQUIRED to surface as an error")
 	}
 	if gotHeader != "CACHED_ID" {
@@ -137,7 +138,7 @@ func TestFetchPlayer_NoVisitorIDOmitsHeader(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	if _, err := fetchPlayer(srv.Client(), srv.URL, "ZTidn2dBYbY"); err != nil {
+	if _, err := fetchPlayer(context.Background(), srv.Client(), srv.URL, "ZTidn2dBYbY"); err != nil {
 		t.Fatalf("fetchPlayer: %v", err)
 	}
 	if present {
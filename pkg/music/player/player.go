@@ -4,12 +4,15 @@
 package player
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/keshon/melodix/pkg/music/opus"
 	"github.com/keshon/melodix/pkg/music/parsers"
 	"github.com/keshon/melodix/pkg/music/sink"
 	"github.com/keshon/melodix/pkg/music/sources"
@@ -51,6 +54,9 @@ const (
 	StatusPaused  Status = "Playback Paused"
 	StatusResumed Status = "Playback Resumed"
 	StatusError   Status = "Error"
+	// StatusStreamTitle means the live stream playing now announced a new
+	// song; read it from CurrentTrack().StreamTitle.
+	StatusStreamTitle Status = "Stream Title Changed"
 )
 
 var (
@@ -76,6 +82,13 @@ type Resolver interface {
 	Resolve(input, source, parser string) ([]sources.TrackInfo, error)
 }
 
+// ContextResolver is a Resolver whose resolves can be abandoned. EnqueueContext
+// uses it when the resolver has it; pkg/music/resolve does.
+type ContextResolver interface {
+	Resolver
+	ResolveContext(ctx context.Context, input, source, parser string) ([]sources.TrackInfo, error)
+}
+
 // PlaybackRecorder is called once a track has actually produced audio — the
 // first packet, not a successful Open — to persist guild playback history.
 // Track carries the parser that produced it, so a parser that opened and then
@@ -84,6 +97,32 @@ type PlaybackRecorder interface {
 	Record(guildID string, playedAt time.Time, track parsers.Track)
 }
 
+// StreamTitleRecorder is optionally implemented by a PlaybackRecorder that
+// also keeps the songs heard on a live stream. RecordStreamTitle follows the
+// Record call for the same track, once per title change; a title announced
+// before the track was recorded arrives in Record as track.StreamTitle.
+type StreamTitleRecorder interface {
+	RecordStreamTitle(guildID, title string)
+}
+
+// ResumeStore is optionally implemented by a PlaybackRecorder that remembers
+// how far a guild got into a resumable track (see sources.Resumable), so the
+// next play of it opens there instead of at the start.
+type ResumeStore interface {
+	ResumePosition(guildID, url string) time.Duration
+	SaveResumePosition(guildID, url string, pos time.Duration)
+	ClearResumePosition(guildID, url string)
+}
+
+// resumeMargin is how close to either end a position may be and still count
+// as nothing worth resuming: the first seconds replay at no cost, and the last
+// are usually an outro the listener skipped on purpose.
+const resumeMargin = 30 * time.Second
+
+// maxTraces is how many playback traces a player keeps: enough to reach back
+// past a run of tracks that failed over to the one someone is asking about.
+const maxTraces = 10
+
 // Player is a queue-based playback engine: it resolves input through a
 // Resolver, opens tracks via the parser registry with recovery, and streams the
 // resulting Opus packets to an AudioSink. One Player per playback target.
@@ -152,6 +191,12 @@ type Player struct {
 	// onPlaybackFailed is set once at construction (Options.OnPlaybackFailed)
 	// and never mutated, so the playback goroutine reads it without a lock.
 	onPlaybackFailed func(guildID string, track parsers.Track, playbackErr error)
+
+	// traceMu protects traces. Like errMu it is separate from mu, so /why is
+	// never held up behind a slow stop.
+	traceMu sync.Mutex
+	// traces are the last maxTraces playbacks' traces, oldest first.
+	traces []*stream.Tracer
 }
 
 // Options configures optional Player behavior; the zero value is usable.
@@ -231,8 +276,20 @@ func (p *Player) SetRecorder(r PlaybackRecorder) {
 // and emitted on PlayerStatus, because the caller that asked may not be the one
 // rendering the result.
 func (p *Player) Enqueue(input string, source string, parser string) error {
+	return p.EnqueueContext(context.Background(), input, source, parser)
+}
+
+// EnqueueContext is Enqueue with the resolve bound to ctx, for a resolver
+// that implements ContextResolver.
+func (p *Player) EnqueueContext(ctx context.Context, input string, source string, parser string) error {
 	p.log.Info().Str("input", input).Str("source", source).Str("parser", parser).Msg("enqueue_called")
-	tracksInfo, err := p.resolver.Resolve(input, source, parser)
+	var tracksInfo []sources.TrackInfo
+	var err error
+	if cr, ok := p.resolver.(ContextResolver); ok {
+		tracksInfo, err = cr.ResolveContext(ctx, input, source, parser)
+	} else {
+		tracksInfo, err = p.resolver.Resolve(input, source, parser)
+	}
 	if err != nil {
 		p.log.Warn().Err(err).Msg("resolve_tracks_failed")
 		p.emitPlaybackError(err)
@@ -257,35 +314,109 @@ func (p *Player) EnqueueTrackInfos(tracksInfo []sources.TrackInfo) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	tracks := p.tracksFromInfos(tracksInfo)
+	if len(tracks) == 0 {
+		p.emitPlaybackError(ErrNoParsersForTrack)
+		return ErrNoParsersForTrack
+	}
+
+	p.queue = append(p.queue, tracks...)
+	p.log.Info().Int("added", len(tracks)).Int("queue_len", len(p.queue)).Msg("queue_tracks_added")
+	if p.currTrack != nil {
+		p.emitStatus(StatusAdded)
+	}
+	return nil
+}
+
+// tracksFromInfos turns resolver output into queue entries, skipping tracks
+// with no parser to play them. A lazy playlist entry passes regardless: it is
+// never played itself, and its tracks are checked when they are paged in.
+func (p *Player) tracksFromInfos(tracksInfo []sources.TrackInfo) []parsers.Track {
 	tracks := make([]parsers.Track, 0, len(tracksInfo))
 	for _, trackInfo := range tracksInfo {
-		if len(trackInfo.AvailableParsers) == 0 {
+		if len(trackInfo.AvailableParsers) == 0 && trackInfo.Lazy == nil {
 			p.log.Warn().Str("title", trackInfo.Title).Msg("track_skipped_no_parsers")
 			continue
 		}
+		var first string
+		if len(trackInfo.AvailableParsers) > 0 {
+			first = trackInfo.AvailableParsers[0]
+		}
 		tracks = append(tracks, parsers.Track{
 			URL:           trackInfo.URL,
 			Title:         trackInfo.Title,
-			CurrentParser: trackInfo.AvailableParsers[0],
+			Duration:      trackInfo.Duration,
+			CurrentParser: first,
 			SourceInfo:    trackInfo,
 		})
 	}
-	if len(tracks) == 0 {
-		p.emitPlaybackError(ErrNoParsersForTrack)
-		return ErrNoParsersForTrack
-	}
+	return tracks
+}
 
-	p.queue = append(p.queue, tracks...)
-	p.log.Info().Int("added", len(tracks)).Int("queue_len", len(p.queue)).Msg("queue_tracks_added")
-	if p.currTrack != nil {
-		p.emitStatus(StatusAdded)
+// expandLazyHead replaces a lazy playlist entry at the head of the queue with
+// its next page, keeping the entry behind the page while the list goes on.
+// So the list is fetched only once everything queued ahead of it has played.
+// Caller holds playNextMu, which makes this the only writer at the head;
+// the fetch itself runs without mu so /queue and Enqueue are not held up by
+// a network round trip.
+//
+// A fetch abandoned through ctx leaves the entry where it was, to be paged by
+// the next PlayNext, and returns ctx's error.
+func (p *Player) expandLazyHead(ctx context.Context) error {
+	for {
+		p.mu.Lock()
+		if len(p.queue) == 0 || p.queue[0].SourceInfo.Lazy == nil {
+			p.mu.Unlock()
+			return nil
+		}
+		entry := p.queue[0]
+		lazy := entry.SourceInfo.Lazy
+		p.mu.Unlock()
+
+		infos, err := lazy.NextPageContext(ctx)
+		if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+			// Only a failed fetch is abandoned: a page that arrived has been
+			// taken from the list and must be queued, cancelled or not.
+			p.log.Info().Str("list_id", lazy.ListID).Err(ctxErr).Msg("lazy_playlist_page_cancelled")
+			return ctxErr
+		}
+		page := p.tracksFromInfos(infos)
+
+		p.mu.Lock()
+		if len(p.queue) == 0 || p.queue[0].SourceInfo.Lazy != lazy {
+			// A Stop cleared the queue while the page was fetched.
+			p.mu.Unlock()
+			continue
+		}
+		rest := p.queue[1:]
+		switch {
+		case err != nil:
+			// The list is dropped rather than retried: a page that failed once
+			// (a list gone private, a dead token) fails the same way on every
+			// PlayNext, and would wedge the queue behind it.
+			p.log.Warn().Str("list_id", lazy.ListID).Err(err).Msg("lazy_playlist_page_failed")
+		case !lazy.Done():
+			rest = append([]parsers.Track{entry}, rest...)
+		}
+		served, total := lazy.Progress()
+		p.log.Info().Str("list_id", lazy.ListID).Int("added", len(page)).
+			Int("served", served).Int("total", total).Msg("lazy_playlist_page_queued")
+		p.queue = append(page, rest...)
+		p.mu.Unlock()
 	}
-	return nil
 }
 
 // PlayNext stops current track (if any) and plays the next in queue.
 // target is the voice channel ID for Discord, or "" for CLI.
 func (p *Player) PlayNext(target string) error {
+	return p.PlayNextContext(context.Background(), target)
+}
+
+// PlayNextContext is PlayNext with the wait for the next track bound to ctx:
+// a lazy playlist's page fetch and the track's open. Playback itself is not —
+// it outlives the command that started it. A track whose open was abandoned
+// goes back to the head of the queue, and ctx's error is returned.
+func (p *Player) PlayNextContext(ctx context.Context, target string) error {
 	p.log.Info().Int("queue_len", len(p.Queue())).Msg("play_next_called")
 	for {
 		if p.IsPlaying() {
@@ -294,6 +425,10 @@ func (p *Player) PlayNext(target string) error {
 		}
 
 		p.playNextMu.Lock()
+		if err := p.expandLazyHead(ctx); err != nil {
+			p.playNextMu.Unlock()
+			return err
+		}
 		p.mu.Lock()
 		if len(p.queue) == 0 {
 			p.mu.Unlock()
@@ -309,10 +444,24 @@ func (p *Player) PlayNext(target string) error {
 
 		p.log.Info().Str("title", track.Title).Str("url", track.URL).Msg("track_attempt_play")
 
-		err := p.startTrack(&track, false)
+		err := p.startTrack(ctx, &track, false)
+		if err != nil && ctx.Err() != nil && !errors.Is(err, stream.ErrPlaybackStopped) {
+			p.mu.Lock()
+			p.queue = append([]parsers.Track{track}, p.queue...)
+			p.mu.Unlock()
+			p.playNextMu.Unlock()
+			p.log.Info().Str("title", track.Title).Err(ctx.Err()).Msg("track_open_abandoned")
+			return ctx.Err()
+		}
 		p.playNextMu.Unlock()
 
 		if err != nil {
+			if errors.Is(err, stream.ErrPlaybackStopped) {
+				// Stopped or skipped while opening: whoever stopped it decides
+				// what plays next, not this loop.
+				p.log.Info().Str("title", track.Title).Msg("track_open_cancelled")
+				return err
+			}
 			p.log.Warn().Str("title", track.Title).Err(err).Msg("track_skipped_error")
 			p.mu.Lock()
 			qEmpty := len(p.queue) == 0
@@ -320,16 +469,17 @@ func (p *Player) PlayNext(target string) error {
 			if qEmpty {
 				// The Discord slash handler reports this one itself; emitting here
 				// too would put a stray StatusError on the channel.
-				return fmt.Errorf("%w: %v", ErrTrackStartFailed, err)
+				return fmt.Errorf("%w: %w", ErrTrackStartFailed, err)
 			}
 			continue
 		}
 
 		// History is written from onParserConfirmed, once a parser has actually
 		// produced audio — opening one proves nothing (see RecoveryStream.Open).
+		// The track is the playback goroutine's from here on, which rewrites its
+		// metadata as it recovers; track_starting named the parser.
 		p.log.Info().
-			Str("title", track.Title).
-			Str("parser", track.CurrentParser).
+			Str("url", track.URL).
 			Int("queue_len", len(p.Queue())).
 			Msg("track_now_playing")
 		return nil
@@ -439,8 +589,9 @@ func cloneTrack(tp parsers.Track) parsers.Track {
 //
 // The per-run channels are minted here rather than in Stop, so a run always
 // gets its own pair and a late Stop from the previous run cannot signal this
-// one. Opening is deliberately outside the lock: it does network I/O.
-func (p *Player) startTrack(track *parsers.Track, resumed bool) error {
+// one. Opening is deliberately outside the lock: it does network I/O, which
+// ctx can abandon.
+func (p *Player) startTrack(ctx context.Context, track *parsers.Track, resumed bool) error {
 	p.log.Info().
 		Str("title", track.Title).
 		Str("url", track.URL).
@@ -457,16 +608,54 @@ func (p *Player) startTrack(track *parsers.Track, resumed bool) error {
 	p.currTrack = track
 	p.announcedParser = ""
 	p.recorded = false
+	guildID := p.guildID
+	stopCh := p.stopPlayback
+	doneCh := p.playbackDone
 	p.mu.Unlock()
 
+	track.StreamTitle = ""
+	track.OnStreamTitle = func(title string) { p.onStreamTitle(track, title) }
+	track.StartAt = p.resumePosition(track, guildID)
 	rs := stream.NewRecoveryStreamWithLogger(track, p.log)
 	rs.SetOnParserConfirmed(func(parser string) { p.onParserConfirmed(track, parser) })
-	if err := rs.Open(0); err != nil {
+	p.pushTrace(rs.Trace())
+
+	// A stop or skip while the track is still opening closes the stream, which
+	// cancels the parser's extraction rather than waiting it out for a track
+	// nobody will hear. So does the caller giving up on ctx.
+	opened := make(chan struct{})
+	go func() {
+		select {
+		case <-stopCh:
+			_ = rs.Close()
+		case <-ctx.Done():
+			_ = rs.Close()
+		case <-opened:
+		}
+	}()
+	err := rs.Open(track.StartAt.Seconds())
+	close(opened)
+	select {
+	case <-stopCh:
+		// The goroutine above may have closed the stream already; one that
+		// opened regardless is not played either.
+		_ = rs.Close()
+		err = fmt.Errorf("%w while the track was opening", stream.ErrPlaybackStopped)
+	case <-ctx.Done():
+		_ = rs.Close()
+		err = ctx.Err()
+	default:
+	}
+	if err != nil {
 		p.log.Error().Err(err).Msg("stream_open_failed")
+		finishTrace(rs.Trace(), err)
 		p.mu.Lock()
 		p.starting = false
 		p.currTrack = nil
 		p.mu.Unlock()
+		// No playback goroutine will close it, and a Stop waiting on it would
+		// otherwise sit out its whole timeout.
+		close(doneCh)
 		return err
 	}
 
@@ -485,8 +674,6 @@ func (p *Player) startTrack(track *parsers.Track, resumed bool) error {
 	p.playing = true
 	p.currTrack = track
 	p.announcedParser = track.CurrentParser
-	stopCh := p.stopPlayback
-	doneCh := p.playbackDone
 	p.mu.Unlock()
 
 	// Completion chain: runPlayback -> this goroutine -> PlayNext -> startTrack
@@ -532,9 +719,9 @@ const maxVoiceTransportAttempts = 3
 // runPlayback streams to the sink. track, stopCh and doneCh belong to this run
 // alone: track must be the run's own pointer, because reading p.currTrack here
 // could observe a newer run's track if this goroutine is scheduled late.
-func (p *Player) runPlayback(track *parsers.Track, rs *stream.RecoveryStream, stopCh, doneCh chan struct{}) error {
-	defer rs.Close()
+func (p *Player) runPlayback(track *parsers.Track, rs *stream.RecoveryStream, stopCh, doneCh chan struct{}) (runErr error) {
 	defer close(doneCh)
+	defer func() { finishTrace(rs.Trace(), runErr) }()
 
 	p.mu.Lock()
 	target := p.target
@@ -543,12 +730,33 @@ func (p *Player) runPlayback(track *parsers.Track, rs *stream.RecoveryStream, st
 	guildID := p.guildID
 	p.mu.Unlock()
 
+	// Read before Packets: from there on, with the read-ahead buffer, the
+	// track's fields are written by the buffer's producer as it recovers, and
+	// only it and onParserConfirmed may touch them until the stream is closed.
+	failedSnapshot := cloneTrack(*track)
+	p.log.Info().Str("title", track.Title).Str("parser", track.CurrentParser).Msg("playback_running")
+
 	// The buffered view is built once and reused across transport reopens, so the
 	// read-ahead lead is not thrown away every time voice reconnects.
-	packets := rs.Packets()
+	// Counted above the buffer, so the position is what the sink took, not
+	// what the read-ahead fetched.
+	packets := &countingReader{Reader: rs.Packets()}
+	defer func() {
+		_ = rs.Close() // first: the producer must be gone before the track is read
+		p.rememberPosition(track, guildID, track.StartAt+packets.played(), runErr == nil)
+	}()
 
-	failedSnapshot := cloneTrack(*track)
-	p.log.Info().Str("title", track.Title).Str("parser", track.CurrentParser).Msg("playback_running")
+	// A sink parked in ReadPacket on a stalled source never gets to look at
+	// stopCh. Tearing the source down underneath it is what lets a stop
+	// through; the result is then a stop, whatever the sink makes of the
+	// failed read.
+	go func() {
+		select {
+		case <-stopCh:
+			rs.Interrupt()
+		case <-doneCh:
+		}
+	}()
 
 	var err error
 	softUsed := 0
@@ -557,6 +765,7 @@ func (p *Player) runPlayback(track *parsers.Track, rs *stream.RecoveryStream, st
 		audioSink, err = p.sinkProvider.Sink(target)
 		if err != nil {
 			p.log.Warn().Int("attempt", attempt).Int("max", maxVoiceTransportAttempts).Err(err).Msg("sink_get_failed")
+			rs.Trace().Add(stream.TraceEvent{Kind: stream.TraceSinkFailed, Err: err.Error()})
 			p.sinkProvider.InvalidateSink()
 			if attempt == maxVoiceTransportAttempts {
 				p.markPlaybackFailed(track, failedSnapshot, guildID, errors.Join(ErrSinkUnavailable, fmt.Errorf("get sink: %w", err)))
@@ -567,6 +776,11 @@ func (p *Player) runPlayback(track *parsers.Track, rs *stream.RecoveryStream, st
 		}
 
 		err = audioSink.Stream(packets, stopCh)
+		select {
+		case <-stopCh:
+			err = stream.ErrPlaybackStopped
+		default:
+		}
 		if err == nil {
 			break
 		}
@@ -578,6 +792,11 @@ func (p *Player) runPlayback(track *parsers.Track, rs *stream.RecoveryStream, st
 		}
 		if errors.Is(err, stream.ErrVoiceTransport) {
 			p.log.Warn().Int("attempt", attempt).Int("max", maxVoiceTransportAttempts).Err(err).Msg("voice_transport_error")
+			p.mu.Lock()
+			parser := p.announcedParser // not track.CurrentParser: see failedSnapshot
+			p.mu.Unlock()
+			rs.Trace().Add(stream.TraceEvent{Kind: stream.TraceTransportFailed, Parser: parser,
+				Pos: (track.StartAt + packets.played()).Seconds(), Err: err.Error()})
 
 			softTry := recoveryMode == RecoverySoft && softUsed < softAttempts
 			if softTry {
@@ -619,6 +838,59 @@ func (p *Player) runPlayback(track *parsers.Track, rs *stream.RecoveryStream, st
 	return nil
 }
 
+// countingReader counts the packets the sink has read from the stream.
+type countingReader struct {
+	opus.Reader
+	n atomic.Int64
+}
+
+func (r *countingReader) ReadPacket() ([]byte, error) {
+	pkt, err := r.Reader.ReadPacket()
+	if err == nil {
+		r.n.Add(1)
+	}
+	return pkt, err
+}
+
+// played is the audio read so far, at one 20ms frame per packet.
+func (r *countingReader) played() time.Duration {
+	return time.Duration(r.n.Load()) * opus.FrameMs * time.Millisecond
+}
+
+// resumePosition is where track should open: the guild's saved position when
+// the track is resumable and the recorder keeps positions, zero otherwise.
+func (p *Player) resumePosition(track *parsers.Track, guildID string) time.Duration {
+	p.mu.Lock()
+	store, ok := p.recorder.(ResumeStore)
+	p.mu.Unlock()
+	if !ok || guildID == "" || !sources.Resumable(track.SourceInfo.SourceName) {
+		return 0
+	}
+	pos := store.ResumePosition(guildID, track.URL)
+	if pos > 0 {
+		p.log.Info().Str("title", track.Title).Dur("position", pos).Msg("track_resume_position")
+	}
+	return pos
+}
+
+// rememberPosition saves where a resumable track was left, or forgets it when
+// the track ran to its end or the position is within resumeMargin of either
+// end. A failed run keeps its position too: the next play picks up there.
+func (p *Player) rememberPosition(track *parsers.Track, guildID string, pos time.Duration, finished bool) {
+	p.mu.Lock()
+	store, ok := p.recorder.(ResumeStore)
+	p.mu.Unlock()
+	if !ok || guildID == "" || !sources.Resumable(track.SourceInfo.SourceName) {
+		return
+	}
+	nearEnd := track.Duration > 0 && track.Duration-pos < resumeMargin
+	if finished || nearEnd || pos < resumeMargin {
+		store.ClearResumePosition(guildID, track.URL)
+		return
+	}
+	store.SaveResumePosition(guildID, track.URL, pos)
+}
+
 // onParserConfirmed runs when RecoveryStream proves a parser is actually
 // producing audio. It is the one point where "what is playing" becomes known,
 // so it owns both the history row and the UI refresh: a mid-track switch (the
@@ -640,15 +912,18 @@ func (p *Player) onParserConfirmed(track *parsers.Track, parser string) {
 	gid := p.guildID
 	rec := p.recorder
 	record := rec != nil && gid != "" && !p.recorded
+	var snapshot parsers.Track
 	if record {
 		p.recorded = true
+		// Under mu: onStreamTitle writes StreamTitle from another goroutine.
+		snapshot = cloneTrack(*track)
 	}
 	p.mu.Unlock()
 
 	if record {
 		// Future: listened-duration aggregation would need completion callbacks,
 		// from here or from runPlayback.
-		rec.Record(gid, time.Now(), cloneTrack(*track))
+		rec.Record(gid, time.Now(), snapshot)
 	}
 	if !stale {
 		return // the UI already names this parser (same parser reopened)
@@ -664,6 +939,30 @@ func (p *Player) onParserConfirmed(track *parsers.Track, parser string) {
 	p.emitStatus(StatusPlaying)
 }
 
+// onStreamTitle runs when a live stream announces a title, from the parser's
+// read path. Servers repeat the current title and re-send it on every
+// reconnect, so only a change is published. Must not be called with p.mu held.
+func (p *Player) onStreamTitle(track *parsers.Track, title string) {
+	p.mu.Lock()
+	if p.currTrack != track || track.StreamTitle == title {
+		p.mu.Unlock()
+		return
+	}
+	track.StreamTitle = title
+	gid := p.guildID
+	rec, _ := p.recorder.(StreamTitleRecorder)
+	// Before the first packet there is no history row to add to; Record picks
+	// the title up from the track instead.
+	record := rec != nil && gid != "" && p.recorded && title != ""
+	p.mu.Unlock()
+
+	p.log.Info().Str("url", track.URL).Str("stream_title", title).Msg("stream_title_changed")
+	if record {
+		rec.RecordStreamTitle(gid, title)
+	}
+	p.emitStatus(StatusStreamTitle)
+}
+
 func (p *Player) emitStatus(status Status) {
 	select {
 	case p.PlayerStatus <- status:
@@ -722,6 +1021,68 @@ func (p *Player) LastPlaybackUserError() string {
 	return s
 }
 
+// pushTrace keeps tr as the newest trace, dropping the oldest past maxTraces.
+func (p *Player) pushTrace(tr *stream.Tracer) {
+	p.traceMu.Lock()
+	defer p.traceMu.Unlock()
+	p.traces = append(p.traces, tr)
+	if len(p.traces) > maxTraces {
+		p.traces = slices.Delete(p.traces, 0, len(p.traces)-maxTraces)
+	}
+}
+
+// finishTrace records how a run ended: finished on a nil err, stopped when it
+// was stopped or skipped, failed otherwise.
+func finishTrace(tr *stream.Tracer, err error) {
+	switch {
+	case err == nil:
+		tr.Finish(stream.TraceFinished, nil)
+	case errors.Is(err, stream.ErrPlaybackStopped), errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		tr.Finish(stream.TraceStopped, err)
+	default:
+		tr.Finish(stream.TraceFailed, err)
+	}
+}
+
+// Traces returns the traces of the player's last playbacks, newest first:
+// every parser each one tried, what failed, and how it ended.
+func (p *Player) Traces() []stream.Trace {
+	p.traceMu.Lock()
+	defer p.traceMu.Unlock()
+	out := make([]stream.Trace, 0, len(p.traces))
+	for i := len(p.traces) - 1; i >= 0; i-- {
+		out = append(out, p.traces[i].Snapshot())
+	}
+	return out
+}
+
+// CurrentTrace returns the trace of the track opening or playing now; ok is
+// false when the player is idle.
+func (p *Player) CurrentTrace() (stream.Trace, bool) {
+	p.traceMu.Lock()
+	var newest *stream.Tracer
+	if n := len(p.traces); n > 0 {
+		newest = p.traces[n-1]
+	}
+	p.traceMu.Unlock()
+	if newest == nil {
+		return stream.Trace{}, false
+	}
+	t := newest.Snapshot()
+	return t, t.Outcome == stream.TraceInProgress
+}
+
+// LastFailedTrace returns the newest trace of a playback that failed; ok is
+// false when none of the kept ones did.
+func (p *Player) LastFailedTrace() (stream.Trace, bool) {
+	for _, t := range p.Traces() {
+		if t.Outcome == stream.TraceFailed {
+			return t, true
+		}
+	}
+	return stream.Trace{}, false
+}
+
 // ChannelID returns the current target: a voice channel id, or "" for the CLI.
 func (p *Player) ChannelID() string {
 	p.mu.Lock()
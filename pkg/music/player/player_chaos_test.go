@@ -0,0 +1,405 @@
+package player
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/breaker"
+	"github.com/keshon/melodix/pkg/music/faultinject"
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+// chaosCase is a queue played through faultinject parsers and sink. Whatever
+// the faults, playChaos checks what must always hold: no two tracks or
+// streams play at once, every packet is played while the UI names the parser
+// it came from, each track that was heard has exactly one history row, and
+// nothing is left running afterwards. heard and the rest are what the case
+// itself should come to.
+type chaosCase struct {
+	name      string
+	parsers   []*faultinject.Streamer // the tracks' parsers, in order
+	failAfter []int                   // the sink's transport failures, see faultinject.Sink
+	tracks    int                     // queued tracks; 0 means 2
+
+	// heard is, per track, the parsers the sink heard it from, in order; nil
+	// for a track never heard.
+	heard [][]string
+	// opens is the total opens across parsers; 0 leaves it unchecked, for
+	// cases where health may reorder the parsers between tracks.
+	opens   int
+	corrupt int // malformed packets the sink is expected to play
+	// stopOnStall stops the player as soon as a read stalls, rather than
+	// letting the queue run out.
+	stopOnStall bool
+	// failed cases end in StatusError, which stops the queue.
+	failed bool
+	// startErr cases fail every track before playback begins, so PlayNext
+	// itself returns the error.
+	startErr bool
+}
+
+// chaosVariant is a player configuration every case is run under.
+type chaosVariant struct {
+	name     string
+	bufferMs int
+	mode     TransportRecoveryMode
+}
+
+var chaosVariants = []chaosVariant{
+	{name: "direct", mode: RecoveryHard},
+	// With the read-ahead buffer the parser runs ahead of the sink, so a
+	// switch is confirmed before the sink plays up to it; the per-packet
+	// announcement check does not apply.
+	{name: "buffered", bufferMs: 200, mode: RecoverySoft},
+}
+
+func finite(name string, script ...faultinject.Open) *faultinject.Streamer {
+	s := faultinject.New(name, script...)
+	s.Duration = time.Second // 50 packets
+	return s
+}
+
+func chaosCases() []chaosCase {
+	errForbidden := errors.New("HTTP 403")
+	errReset := errors.New("connection reset by peer")
+	live := faultinject.New("radio", faultinject.Open{Packets: 10})
+	live.Caps = parsers.Capabilities{Live: true}
+
+	return []chaosCase{
+		{
+			name:    "clean",
+			parsers: []*faultinject.Streamer{finite("a", faultinject.Open{Packets: 50})},
+			heard:   [][]string{{"a"}, {"a"}},
+			opens:   2,
+		},
+		{
+			name:    "slow open",
+			parsers: []*faultinject.Streamer{finite("a", faultinject.Open{Delay: 150 * time.Millisecond, Packets: 50})},
+			heard:   [][]string{{"a"}, {"a"}},
+			opens:   2,
+		},
+		{
+			name: "open fails, next parser plays",
+			parsers: []*faultinject.Streamer{
+				finite("a", faultinject.Open{Err: errors.New("extraction failed")}),
+				finite("b", faultinject.Open{Packets: 50}),
+			},
+			heard: [][]string{{"b"}, {"b"}},
+		},
+		{
+			name: "first read fails, next parser plays",
+			parsers: []*faultinject.Streamer{
+				finite("a", faultinject.Open{End: errForbidden}),
+				finite("b", faultinject.Open{Packets: 50}),
+			},
+			heard: [][]string{{"b"}, {"b"}},
+		},
+		{
+			name:    "early EOF reopens the same parser",
+			parsers: []*faultinject.Streamer{finite("a", faultinject.Open{Packets: 10}, faultinject.Open{Packets: 50})},
+			heard:   [][]string{{"a"}, {"a"}},
+			opens:   3,
+		},
+		{
+			name: "connection reset reopens the same parser",
+			parsers: []*faultinject.Streamer{finite("a",
+				faultinject.Open{Packets: 10, End: errReset}, faultinject.Open{Packets: 50})},
+			heard: [][]string{{"a"}, {"a"}},
+			opens: 3,
+		},
+		{
+			name: "reopen fails, next parser resumes",
+			parsers: []*faultinject.Streamer{
+				finite("a", faultinject.Open{Packets: 10}, faultinject.Open{End: errForbidden}),
+				finite("b", faultinject.Open{Packets: 50}),
+			},
+			heard: [][]string{{"a", "b"}, {"b"}},
+		},
+		{
+			name: "every parser fails on its first read",
+			parsers: []*faultinject.Streamer{
+				finite("a", faultinject.Open{Err: errors.New("extraction failed")}),
+				finite("b", faultinject.Open{End: errForbidden}),
+			},
+			heard: [][]string{nil, nil},
+		},
+		{
+			name: "every open fails",
+			parsers: []*faultinject.Streamer{
+				finite("a", faultinject.Open{Err: errors.New("extraction failed")}),
+				finite("b", faultinject.Open{Err: errForbidden}),
+			},
+			heard:    [][]string{nil, nil},
+			startErr: true,
+		},
+		{
+			name: "stall, then the stream resumes",
+			parsers: []*faultinject.Streamer{finite("a",
+				faultinject.Open{Packets: 50, StallAt: 10, Stall: 300 * time.Millisecond})},
+			heard: [][]string{{"a"}, {"a"}},
+			opens: 2,
+		},
+		{
+			name: "stall until stopped",
+			parsers: []*faultinject.Streamer{finite("a",
+				faultinject.Open{Packets: 50, StallAt: 10, Stall: faultinject.Forever})},
+			heard:       [][]string{{"a"}, nil},
+			opens:       1,
+			stopOnStall: true,
+		},
+		{
+			name:    "corrupt packets are played, not recovered from",
+			parsers: []*faultinject.Streamer{finite("a", faultinject.Open{Packets: 50, Corrupt: []int{0, 7, 49}})},
+			heard:   [][]string{{"a"}, {"a"}},
+			opens:   2,
+			corrupt: 6,
+		},
+		{
+			name:      "voice transport fails once",
+			parsers:   []*faultinject.Streamer{finite("a", faultinject.Open{Packets: 50})},
+			failAfter: []int{10},
+			heard:     [][]string{{"a"}, {"a"}},
+			opens:     3,
+		},
+		{
+			name:      "voice transport never recovers",
+			parsers:   []*faultinject.Streamer{finite("a", faultinject.Open{Packets: 50})},
+			failAfter: []int{5, 5, 5},
+			heard:     [][]string{{"a"}, nil},
+			// Opens unchecked: the reopen after the last failure is one more
+			// without the read-ahead buffer, which leaves it to its producer.
+			failed: true,
+		},
+		{
+			name:    "live stream reconnects with backoff",
+			parsers: []*faultinject.Streamer{live},
+			tracks:  1,
+			heard:   [][]string{{"radio"}},
+			opens:   3,
+		},
+	}
+}
+
+// TestChaos runs every case under every variant. The cases are built afresh
+// for each run: Streamers count their opens.
+func TestChaos(t *testing.T) {
+	for _, v := range chaosVariants {
+		for _, c := range chaosCases() {
+			t.Run(v.name+"/"+c.name, func(t *testing.T) { playChaos(t, v, c) })
+		}
+	}
+}
+
+func playChaos(t *testing.T, v chaosVariant, c chaosCase) {
+	baseline := runtime.NumGoroutine()
+
+	reg := make(map[string]parsers.Streamer)
+	var keys []string
+	for _, s := range c.parsers {
+		reg[s.Name] = s
+		keys = append(keys, s.Name)
+	}
+	swapRegistry(t, reg)
+	origHealth := stream.SetHealth(stream.NewHealthTracker())
+	origBreakers := stream.SetBreakers(breaker.NewSet())
+	stream.SetBufferAhead(v.bufferMs)
+	t.Cleanup(func() {
+		stream.SetHealth(origHealth)
+		stream.SetBreakers(origBreakers)
+		stream.SetBufferAhead(0)
+	})
+
+	var p *Player
+	var violations violationLog
+	s := &faultinject.Sink{FailAfter: c.failAfter}
+	if v.bufferMs == 0 {
+		s.OnPacket = func(pkt []byte) {
+			parser, title, ok := faultinject.Origin(pkt)
+			if !ok {
+				return
+			}
+			p.mu.Lock()
+			announced, cur := p.announcedParser, p.currTrack
+			p.mu.Unlock()
+			if cur == nil || cur.Title != title || announced != parser {
+				curTitle := ""
+				if cur != nil {
+					curTitle = cur.Title
+				}
+				violations.add("played %s from %s while the player announced %s from %s", title, parser, curTitle, announced)
+			}
+		}
+	}
+	provider := faultinject.NewProvider(s)
+	p = NewWithOptions(provider, fakeResolver{}, Options{TransportRecoveryMode: v.mode})
+	rec := &titleRecorder{}
+	p.SetGuildID("g1")
+	p.SetRecorder(rec)
+
+	failed := make(chan struct{}, 1)
+	drained := make(chan struct{})
+	quit := make(chan struct{})
+	go func() {
+		defer close(drained)
+		for {
+			select {
+			case st := <-p.PlayerStatus:
+				if st == StatusError {
+					select {
+					case failed <- struct{}{}:
+					default:
+					}
+				}
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	tracks := c.tracks
+	if tracks == 0 {
+		tracks = 2
+	}
+	for i := range tracks {
+		if err := p.EnqueueTrackInfo(testTrack(fmt.Sprintf("t%d", i+1), keys...)); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+	}
+
+	err := p.PlayNext("")
+	switch {
+	case c.startErr:
+		if !errors.Is(err, ErrTrackStartFailed) {
+			t.Fatalf("PlayNext = %v, want ErrTrackStartFailed", err)
+		}
+	case err != nil:
+		t.Fatalf("PlayNext: %v", err)
+	case c.stopOnStall:
+		select {
+		case <-c.parsers[0].Stalled():
+		case <-time.After(10 * time.Second):
+			t.Fatal("the stream never stalled")
+		}
+		start := time.Now()
+		_ = p.Stop(true)
+		if took := time.Since(start); took > 2*time.Second {
+			t.Errorf("Stop took %v with the stream stalled, want it to interrupt the read", took)
+		}
+	case c.failed:
+		select {
+		case <-failed:
+		case <-time.After(15 * time.Second):
+			t.Fatal("playback never failed")
+		}
+	default:
+		select {
+		case <-provider.Released():
+		case <-time.After(15 * time.Second):
+			t.Fatal("the queue never ran out")
+		}
+	}
+	_ = p.Stop(true)
+	close(quit)
+	<-drained
+
+	settleGoroutines(t, baseline)
+	for _, s := range c.parsers {
+		if n := s.Live(); n != 0 {
+			t.Errorf("parser %s: %d streams never cleaned up", s.Name, n)
+		}
+		if n := s.MaxLive(); n > 1 {
+			t.Errorf("parser %s had %d streams open at once", s.Name, n)
+		}
+	}
+	for _, msg := range violations.list() {
+		t.Error(msg)
+	}
+	if _, maxActive := s.Calls(); maxActive > 1 {
+		t.Errorf("the sink streamed %d playbacks at once", maxActive)
+	}
+
+	// What the sink heard, per track, with tracks in the order first heard.
+	var order []string
+	byTitle := make(map[string][]string)
+	for _, h := range s.Heard() {
+		if i := slices.Index(order, h.Title); i < 0 {
+			order = append(order, h.Title)
+		} else if i != len(order)-1 {
+			t.Errorf("%s was heard again after %s had started", h.Title, order[len(order)-1])
+		}
+		byTitle[h.Title] = append(byTitle[h.Title], h.Parser)
+	}
+	for i, want := range c.heard {
+		title := fmt.Sprintf("t%d", i+1)
+		if got := byTitle[title]; !slices.Equal(got, want) {
+			t.Errorf("%s heard from %v, want %v", title, got, want)
+		}
+	}
+
+	recorded, _ := rec.snapshot()
+	var rows []string
+	for _, tr := range recorded {
+		rows = append(rows, tr.Title)
+		if first := byTitle[tr.Title]; len(first) > 0 && tr.CurrentParser != first[0] {
+			t.Errorf("%s recorded as played by %s, but was first heard from %s", tr.Title, tr.CurrentParser, first[0])
+		}
+	}
+	if !slices.Equal(rows, order) {
+		t.Errorf("history rows %v, want one per track heard: %v", rows, order)
+	}
+
+	if c.opens > 0 {
+		opens := 0
+		for _, s := range c.parsers {
+			opens += s.Opens()
+		}
+		if opens != c.opens {
+			t.Errorf("%d opens, want %d", opens, c.opens)
+		}
+	}
+	if _, corrupt := s.Packets(); corrupt != c.corrupt {
+		t.Errorf("sink played %d malformed packets, want %d", corrupt, c.corrupt)
+	}
+}
+
+// violationLog collects invariant breaches seen on the playback goroutine,
+// for the test goroutine to report.
+type violationLog struct {
+	mu   sync.Mutex
+	msgs []string
+}
+
+func (l *violationLog) add(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.msgs) < 5 { // the first few say it; a whole track's worth would bury it
+		l.msgs = append(l.msgs, fmt.Sprintf(format, args...))
+	}
+}
+
+func (l *violationLog) list() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.msgs...)
+}
+
+// settleGoroutines waits for the goroutine count to fall back to baseline,
+// and fails with every goroutine's stack if it does not.
+func settleGoroutines(t *testing.T, baseline int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<20)
+			buf = buf[:runtime.Stack(buf, true)]
+			t.Fatalf("%d goroutines left running, want at most %d:\n%s", runtime.NumGoroutine(), baseline, buf)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
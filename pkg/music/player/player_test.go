@@ -2,8 +2,11 @@ package player
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
 	"io"
+	"slices"
 	"sync"
 	"testing"
 	"time"
@@ -245,6 +248,92 @@ func TestStopUnblocksBlockedSink(t *testing.T) {
 	}
 }
 
+// slowStreamer's open blocks until cancelled, as an extraction stuck on a slow
+// site does.
+type slowStreamer struct{ started chan struct{} }
+
+func (s slowStreamer) Open(track *parsers.Track, seek float64) (opus.Reader, func(), error) {
+	return s.OpenContext(context.Background(), track, seek)
+}
+
+func (s slowStreamer) OpenContext(ctx context.Context, _ *parsers.Track, _ float64) (opus.Reader, func(), error) {
+	close(s.started)
+	<-ctx.Done()
+	return nil, nil, ctx.Err()
+}
+
+func TestStopCancelsAnOpeningTrack(t *testing.T) {
+	opened := &openLog{}
+	slow := slowStreamer{started: make(chan struct{})}
+	swapRegistry(t, map[string]parsers.Streamer{"slow": slow, "ok": okStreamer(opened)})
+	p := New(newFakeProvider(&fakeSink{}), fakeResolver{})
+
+	if err := p.EnqueueTrackInfo(testTrack("slow", "slow")); err != nil {
+		t.Fatalf("enqueue slow: %v", err)
+	}
+	if err := p.EnqueueTrackInfo(testTrack("next", "ok")); err != nil {
+		t.Fatalf("enqueue next: %v", err)
+	}
+	played := make(chan error, 1)
+	go func() { played <- p.PlayNext("") }()
+	<-slow.started
+
+	start := time.Now()
+	if err := p.Stop(false); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Fatalf("Stop took %v; should cancel the open promptly", elapsed)
+	}
+	select {
+	case err := <-played:
+		if !errors.Is(err, stream.ErrPlaybackStopped) {
+			t.Fatalf("PlayNext = %v, want ErrPlaybackStopped", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("PlayNext still opening after Stop")
+	}
+	// The stop decides what plays next: the opening loop must not move on.
+	if got := opened.list(); len(got) != 0 {
+		t.Fatalf("opened %v after the stop, want nothing", got)
+	}
+	if q := p.Queue(); len(q) != 1 || q[0].Title != "next" {
+		t.Fatalf("queue = %v, want [next] left for whoever stopped", q)
+	}
+}
+
+// A caller that gives up on the open (a command past its timeout) cancels the
+// extraction, and the track waits at the head of the queue for the next try.
+func TestPlayNextContextCancelRequeuesTheTrack(t *testing.T) {
+	slow := slowStreamer{started: make(chan struct{})}
+	swapRegistry(t, map[string]parsers.Streamer{"slow": slow})
+	p := New(newFakeProvider(&fakeSink{}), fakeResolver{})
+
+	if err := p.EnqueueTrackInfo(testTrack("slow", "slow")); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	played := make(chan error, 1)
+	go func() { played <- p.PlayNextContext(ctx, "") }()
+	<-slow.started
+	cancel()
+
+	select {
+	case err := <-played:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("PlayNextContext = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("PlayNextContext still opening after cancel")
+	}
+	if p.IsPlaying() {
+		t.Fatal("player still starting after an abandoned open")
+	}
+	if q := p.Queue(); len(q) != 1 || q[0].Title != "slow" {
+		t.Fatalf("queue = %v, want the abandoned track back at the head", q)
+	}
+}
+
 func TestPlayNextEmptyQueue(t *testing.T) {
 	swapRegistry(t, map[string]parsers.Streamer{})
 	p := New(newFakeProvider(&fakeSink{}), fakeResolver{})
@@ -277,6 +366,43 @@ func TestStartFailureSkipsToNextTrack(t *testing.T) {
 	}
 }
 
+// A track that failed to start keeps its trace after the queue moved on, so
+// /why can still say which parsers were tried.
+func TestStartFailureIsTraced(t *testing.T) {
+	swapRegistry(t, map[string]parsers.Streamer{
+		"ok":  okStreamer(nil),
+		"bad": badStreamer(),
+	})
+	provider := newFakeProvider(&fakeSink{})
+	p := New(provider, fakeResolver{})
+
+	if err := p.EnqueueTrackInfos([]sources.TrackInfo{testTrack("broken", "bad"), testTrack("good", "ok")}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if err := p.PlayNext(""); err != nil {
+		t.Fatalf("PlayNext: %v", err)
+	}
+	waitRelease(t, provider, 5*time.Second)
+
+	failed, ok := p.LastFailedTrace()
+	if !ok || failed.Title != "broken" {
+		t.Fatalf("LastFailedTrace = %q, %v; want broken", failed.Title, ok)
+	}
+	if len(failed.Events) == 0 || failed.Events[0].Kind != stream.TraceOpenFailed || failed.Events[0].Parser != "bad" {
+		t.Errorf("broken's trace = %+v, want bad's failed open first", failed.Events)
+	}
+	traces := p.Traces()
+	if len(traces) != 2 {
+		t.Fatalf("kept %d traces, want 2", len(traces))
+	}
+	if traces[0].Title != "good" || traces[0].Outcome != stream.TraceFinished {
+		t.Errorf("Traces()[0] = %q %q, want good finished", traces[0].Title, traces[0].Outcome)
+	}
+	if _, ok := p.CurrentTrace(); ok {
+		t.Error("CurrentTrace reports a track after the queue ended")
+	}
+}
+
 func TestAllTracksFailToStart(t *testing.T) {
 	swapRegistry(t, map[string]parsers.Streamer{"bad": badStreamer()})
 	p := New(newFakeProvider(&fakeSink{}), fakeResolver{})
@@ -362,3 +488,308 @@ func TestOnPlaybackFailedCalled(t *testing.T) {
 		t.Fatal("OnPlaybackFailed was not called")
 	}
 }
+
+func lazyEntry(l *sources.LazyList) sources.TrackInfo {
+	return sources.TrackInfo{URL: "https://example.com/list", Title: l.Title, SourceName: "test", Lazy: l}
+}
+
+func TestLazyEntryPagesInAtQueueHead(t *testing.T) {
+	swapRegistry(t, map[string]parsers.Streamer{"ok": okStreamer(nil)})
+	p := New(newFakeProvider(&fakeSink{block: true}), fakeResolver{})
+	t.Cleanup(func() { _ = p.Stop(false) })
+
+	first := make([]sources.TrackInfo, 30)
+	for i := range first {
+		first[i] = testTrack(fmt.Sprintf("p%d", i), "ok")
+	}
+	fetched := false
+	lazy := sources.NewLazyList("PL1", "Long", 30, first, "", func(string) ([]sources.TrackInfo, string, error) {
+		fetched = true
+		return nil, "", nil
+	})
+
+	if err := p.EnqueueTrackInfos([]sources.TrackInfo{lazyEntry(lazy), testTrack("after", "ok")}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if served, _ := lazy.Progress(); served != 0 {
+		t.Fatalf("list paged at enqueue time: %d served", served)
+	}
+	if err := p.PlayNext(""); err != nil {
+		t.Fatalf("PlayNext: %v", err)
+	}
+
+	if cur := p.CurrentTrack(); cur == nil || cur.Title != "p0" {
+		t.Fatalf("expected the list's first track to play, got %+v", cur)
+	}
+	q := p.Queue()
+	if len(q) != sources.LazyPageSize+1 {
+		t.Fatalf("queue length %d, want the rest of the page, the lazy entry and the track after it", len(q))
+	}
+	if q[len(q)-2].SourceInfo.Lazy != lazy || q[len(q)-1].Title != "after" {
+		t.Fatalf("expected the lazy entry to stay behind its page, ahead of later tracks")
+	}
+	if fetched {
+		t.Fatal("fetched a page while the first one was still buffered")
+	}
+}
+
+func TestFailingLazyEntryIsDropped(t *testing.T) {
+	opened := &openLog{}
+	swapRegistry(t, map[string]parsers.Streamer{"ok": okStreamer(opened)})
+	provider := newFakeProvider(&fakeSink{})
+	p := New(provider, fakeResolver{})
+
+	lazy := sources.NewLazyList("PL1", "Gone", 0, nil, "tok", func(string) ([]sources.TrackInfo, string, error) {
+		return nil, "", errors.New("list went private")
+	})
+	if err := p.EnqueueTrackInfos([]sources.TrackInfo{lazyEntry(lazy), testTrack("after", "ok")}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if err := p.PlayNext(""); err != nil {
+		t.Fatalf("PlayNext should move past the failed list, got: %v", err)
+	}
+	waitRelease(t, provider, 5*time.Second)
+	if got := opened.list(); len(got) != 1 || got[0] != "after" {
+		t.Fatalf("expected only [after] to open, got %v", got)
+	}
+}
+
+// A page that arrives as ctx is cancelled has already left the list, so it is
+// queued rather than dropped along with the cancelled PlayNext.
+func TestLazyPageFetchedAsCtxEndsIsQueued(t *testing.T) {
+	p := New(newFakeProvider(&fakeSink{}), fakeResolver{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lazy := sources.NewLazyListContext("PL1", "Long", 2, nil, "tok", func(context.Context, string) ([]sources.TrackInfo, string, error) {
+		cancel()
+		return []sources.TrackInfo{testTrack("p0", "ok"), testTrack("p1", "ok")}, "", nil
+	})
+	if err := p.EnqueueTrackInfos([]sources.TrackInfo{lazyEntry(lazy), testTrack("after", "ok")}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if err := p.expandLazyHead(ctx); err != nil {
+		t.Fatalf("expandLazyHead = %v, want the fetched page queued", err)
+	}
+	var titles []string
+	for _, tr := range p.Queue() {
+		titles = append(titles, tr.Title)
+	}
+	if want := []string{"p0", "p1", "after"}; !slices.Equal(titles, want) {
+		t.Fatalf("queue = %v, want %v", titles, want)
+	}
+}
+
+// titleRecorder is a PlaybackRecorder that also keeps stream titles.
+type titleRecorder struct {
+	mu       sync.Mutex
+	recorded []parsers.Track
+	titles   []string
+}
+
+func (r *titleRecorder) Record(guildID string, playedAt time.Time, track parsers.Track) {
+	r.mu.Lock()
+	r.recorded = append(r.recorded, track)
+	r.mu.Unlock()
+}
+
+func (r *titleRecorder) RecordStreamTitle(guildID, title string) {
+	r.mu.Lock()
+	r.titles = append(r.titles, title)
+	r.mu.Unlock()
+}
+
+func (r *titleRecorder) snapshot() ([]parsers.Track, []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]parsers.Track(nil), r.recorded...), append([]string(nil), r.titles...)
+}
+
+// firstPacketSink reads one packet, so the parser is confirmed and the track
+// recorded, then holds the stream until stopped.
+type firstPacketSink struct{ read chan struct{} }
+
+func (s *firstPacketSink) Stream(r opus.Reader, stop <-chan struct{}) error {
+	if _, err := r.ReadPacket(); err == nil {
+		close(s.read)
+	}
+	<-stop
+	return stream.ErrPlaybackStopped
+}
+
+func TestStreamTitleChangesArePublishedAndRecorded(t *testing.T) {
+	announced := make(chan func(string), 1)
+	swapRegistry(t, map[string]parsers.Streamer{"live": fakeStreamer{
+		open: func(track *parsers.Track, seek float64) (opus.Reader, func(), error) {
+			// A station repeats its current title; only the change counts.
+			track.OnStreamTitle("Before Audio")
+			track.OnStreamTitle("Before Audio")
+			announced <- track.OnStreamTitle
+			pcm := make([]byte, opus.PCMFrameBytes*3)
+			return opus.Encode(io.NopCloser(bytes.NewReader(pcm))), func() {}, nil
+		},
+	}})
+	s := &firstPacketSink{read: make(chan struct{})}
+	p := New(newFakeProvider(s), fakeResolver{})
+	rec := &titleRecorder{}
+	p.SetGuildID("g1")
+	p.SetRecorder(rec)
+	t.Cleanup(func() { _ = p.Stop(false) })
+
+	if err := p.EnqueueTrackInfo(testTrack("station", "live")); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if err := p.PlayNext(""); err != nil {
+		t.Fatalf("PlayNext: %v", err)
+	}
+	select {
+	case <-s.read:
+	case <-time.After(5 * time.Second):
+		t.Fatal("sink never read a packet")
+	}
+	onTitle := <-announced
+	onTitle("Second Song")
+	onTitle("Second Song")
+
+	titleEvents := 0
+	for drained := false; !drained; {
+		select {
+		case st := <-p.PlayerStatus:
+			if st == StatusStreamTitle {
+				titleEvents++
+			}
+		default:
+			drained = true
+		}
+	}
+	if titleEvents != 2 {
+		t.Fatalf("got %d title events, want one per change (2)", titleEvents)
+	}
+	if cur := p.CurrentTrack(); cur == nil || cur.StreamTitle != "Second Song" {
+		t.Fatalf("current track does not carry the latest title: %+v", cur)
+	}
+	recorded, titles := rec.snapshot()
+	if len(recorded) != 1 || recorded[0].StreamTitle != "Before Audio" {
+		t.Fatalf("the row should open with the title heard before audio, got %+v", recorded)
+	}
+	if len(titles) != 1 || titles[0] != "Second Song" {
+		t.Fatalf("titles after the row = %q, want [Second Song]", titles)
+	}
+}
+
+// resumeRecorder is a PlaybackRecorder that also keeps resume positions.
+type resumeRecorder struct {
+	mu        sync.Mutex
+	positions map[string]time.Duration
+	cleared   []string
+}
+
+func (r *resumeRecorder) Record(guildID string, playedAt time.Time, track parsers.Track) {}
+
+func (r *resumeRecorder) ResumePosition(guildID, url string) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.positions[guildID+" "+url]
+}
+
+func (r *resumeRecorder) SaveResumePosition(guildID, url string, pos time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.positions[guildID+" "+url] = pos
+}
+
+func (r *resumeRecorder) ClearResumePosition(guildID, url string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.positions, guildID+" "+url)
+	r.cleared = append(r.cleared, url)
+}
+
+func TestResumableTrackOpensAtSavedPositionAndSavesOnStop(t *testing.T) {
+	seeks := make(chan float64, 4)
+	swapRegistry(t, map[string]parsers.Streamer{"file": fakeStreamer{
+		open: func(track *parsers.Track, seek float64) (opus.Reader, func(), error) {
+			seeks <- seek
+			track.Duration = time.Hour
+			pcm := make([]byte, opus.PCMFrameBytes*3)
+			return opus.Encode(io.NopCloser(bytes.NewReader(pcm))), func() {}, nil
+		},
+	}})
+	s := &firstPacketSink{read: make(chan struct{})}
+	p := New(newFakeProvider(s), fakeResolver{})
+	episode := testTrack("episode", "file")
+	episode.SourceName = sources.Podcast
+	rec := &resumeRecorder{positions: map[string]time.Duration{"g1 " + episode.URL: 10 * time.Minute}}
+	p.SetGuildID("g1")
+	p.SetRecorder(rec)
+
+	if err := p.EnqueueTrackInfo(episode); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if err := p.PlayNext(""); err != nil {
+		t.Fatalf("PlayNext: %v", err)
+	}
+	if seek := <-seeks; seek != 600 {
+		t.Fatalf("opened at %vs, want the saved 600s", seek)
+	}
+	if cur := p.CurrentTrack(); cur == nil || cur.StartAt != 10*time.Minute {
+		t.Fatalf("current track does not say where it started: %+v", cur)
+	}
+	select {
+	case <-s.read:
+	case <-time.After(5 * time.Second):
+		t.Fatal("sink never read a packet")
+	}
+	if err := p.Stop(false); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	// One 20ms packet played past the saved position.
+	if got := rec.ResumePosition("g1", episode.URL); got != 10*time.Minute+20*time.Millisecond {
+		t.Fatalf("saved position = %v", got)
+	}
+}
+
+func TestResumePositionClearedAtEndAndIgnoredForOtherSources(t *testing.T) {
+	var seeks []float64
+	var mu sync.Mutex
+	swapRegistry(t, map[string]parsers.Streamer{"file": fakeStreamer{
+		open: func(track *parsers.Track, seek float64) (opus.Reader, func(), error) {
+			mu.Lock()
+			seeks = append(seeks, seek)
+			mu.Unlock()
+			track.Duration = time.Millisecond
+			pcm := make([]byte, opus.PCMFrameBytes*3)
+			return opus.Encode(io.NopCloser(bytes.NewReader(pcm))), func() {}, nil
+		},
+	}})
+	prov := newFakeProvider(&fakeSink{})
+	p := New(prov, fakeResolver{})
+	episode := testTrack("episode", "file")
+	episode.SourceName = sources.Podcast
+	song := testTrack("song", "file")
+	rec := &resumeRecorder{positions: map[string]time.Duration{
+		"g1 " + episode.URL: 0,
+		"g1 " + song.URL:    5 * time.Minute,
+	}}
+	p.SetGuildID("g1")
+	p.SetRecorder(rec)
+
+	if err := p.EnqueueTrackInfos([]sources.TrackInfo{song, episode}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if err := p.PlayNext(""); err != nil {
+		t.Fatalf("PlayNext: %v", err)
+	}
+	waitRelease(t, prov, 5*time.Second)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seeks) != 2 || seeks[0] != 0 || seeks[1] != 0 {
+		t.Fatalf("seeks = %v, want both from the start", seeks)
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if len(rec.cleared) != 1 || rec.cleared[0] != episode.URL {
+		t.Fatalf("cleared = %v, want only the finished episode", rec.cleared)
+	}
+}
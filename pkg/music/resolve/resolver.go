@@ -1,41 +1,211 @@
-// Package resolve resolves URLs and search queries to track metadata using configurable sources (YouTube, SoundCloud, radio).
+// Package resolve resolves URLs and search queries to track metadata using configurable sources (YouTube, SoundCloud, Bandcamp, Spotify and Apple Music links, local files, direct file links, podcast feeds, radio).
 package resolve
 
 import (
+	"cmp"
+	"context"
 	"errors"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/keshon/melodix/pkg/music/sources"
+	"github.com/keshon/melodix/pkg/music/sources/bandcamp"
+	"github.com/keshon/melodix/pkg/music/sources/catalog"
+	"github.com/keshon/melodix/pkg/music/sources/direct"
+	"github.com/keshon/melodix/pkg/music/sources/local"
+	"github.com/keshon/melodix/pkg/music/sources/podcast"
 	"github.com/keshon/melodix/pkg/music/sources/radio"
 	"github.com/keshon/melodix/pkg/music/sources/soundcloud"
 	"github.com/keshon/melodix/pkg/music/sources/youtube"
 )
 
-// Resolver routes input (URL or search query) to the matching Source.
+// URL auto-detect priorities of the built-in sources: a higher one is asked
+// first. They are spaced so that an embedder's source can go between any two.
+// Bandcamp and the catalogue (Spotify, Apple Music links) are sites like
+// YouTube and SoundCloud: their Match reads only the URL. Direct goes after
+// the sites: it claims any URL that answers like a finite file, which a
+// site's page never does and a radio stream never should. Podcast follows
+// direct because its check reads a body where direct's is a HEAD, and a feed
+// is never an audio file.
+const (
+	PriorityYouTube    = 600
+	PrioritySoundCloud = 500
+	PriorityBandcamp   = 400
+	PriorityCatalog    = 300
+	PriorityDirect     = 200
+	PriorityPodcast    = 100
+
+	// NoAutoDetect registers a source that URL auto-detect never asks. It is
+	// still used when selected explicitly. Local and radio have it: the
+	// library is asked before the bare-query fallback instead, and radio is the
+	// last resort for any URL nothing else claimed, because its Match probes
+	// the network.
+	NoAutoDetect = -1
+)
+
+// Prefs are per-call overrides of the resolver's defaults; a Discord guild's
+// settings are one set. The zero value changes nothing.
+type Prefs struct {
+	// SearchSource takes bare queries in place of YouTube. It has to be
+	// registered and resolve queries (see sources.QuerySource); otherwise
+	// YouTube is used.
+	SearchSource string
+	// Precedence names sources to ask first during URL auto-detect, in that
+	// order. Registered sources it leaves out follow by priority. Unknown
+	// names and NoAutoDetect sources are ignored.
+	Precedence []string
+}
+
+// Resolver routes input (URL or search query) to the matching Source. Sources
+// are added with Register; New registers the built-in ones. Safe for
+// concurrent use.
 type Resolver struct {
-	Sources map[string]sources.Source
+	mu sync.RWMutex
+	// entries is in registration order, which is the order Sources lists.
+	entries []entry
+}
+
+type entry struct {
+	src      sources.Source
+	priority int
 }
 
-// New creates a Resolver with the built-in sources (YouTube, SoundCloud, radio).
+// New creates a Resolver with the built-in sources (YouTube, SoundCloud,
+// Bandcamp, Spotify and Apple Music links, radio, local files, direct file
+// links, podcast feeds).
 func New() *Resolver {
-	youtubeSource := youtube.New()
-	soundcloudSource := soundcloud.New()
-	radioSource := radio.New()
+	r := &Resolver{}
+	r.Register(youtube.New(), PriorityYouTube)
+	r.Register(soundcloud.New(), PrioritySoundCloud)
+	r.Register(bandcamp.New(), PriorityBandcamp)
+	r.Register(radio.New(), NoAutoDetect)
+	r.Register(local.New(), NoAutoDetect)
+	r.Register(direct.New(), PriorityDirect)
+	r.Register(podcast.New(), PriorityPodcast)
+	r.Register(catalog.New(), PriorityCatalog)
+	return r
+}
+
+// Register adds src under its SourceName, replacing any source registered by
+// that name (a replacement keeps the original's place in Sources). priority
+// places it in URL auto-detect, higher first; sources of equal priority are
+// asked in registration order. Use NoAutoDetect for a source that should only
+// be used when selected.
+func (r *Resolver) Register(src sources.Source, priority int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e := entry{src: src, priority: priority}
+	if i := r.index(src.SourceName()); i >= 0 {
+		r.entries[i] = e
+		return
+	}
+	r.entries = append(r.entries, e)
+}
+
+// index returns the entry position of the named source, or -1. Caller holds mu.
+func (r *Resolver) index(name string) int {
+	return slices.IndexFunc(r.entries, func(e entry) bool { return e.src.SourceName() == name })
+}
+
+// Source returns the source registered under name.
+func (r *Resolver) Source(name string) (sources.Source, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if i := r.index(name); i >= 0 {
+		return r.entries[i].src, true
+	}
+	return nil, false
+}
+
+// Sources lists the registered sources in registration order, for pickers
+// such as /play's source option.
+func (r *Resolver) Sources() []sources.Source {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]sources.Source, 0, len(r.entries))
+	for _, e := range r.entries {
+		out = append(out, e.src)
+	}
+	return out
+}
+
+// QuerySources lists the registered sources that take bare search queries,
+// in registration order.
+func (r *Resolver) QuerySources() []string {
+	var out []string
+	for _, src := range r.Sources() {
+		if sources.ResolvesQueries(src) {
+			out = append(out, src.SourceName())
+		}
+	}
+	return out
+}
+
+// Precedence is the order URL auto-detect asks sources in under prefs:
+// prefs.Precedence first, then the rest by priority. NoAutoDetect sources are
+// not in it.
+func (r *Resolver) Precedence(prefs Prefs) []string {
+	r.mu.RLock()
+	detectable := make([]entry, 0, len(r.entries))
+	for _, e := range r.entries {
+		if e.priority != NoAutoDetect {
+			detectable = append(detectable, e)
+		}
+	}
+	r.mu.RUnlock()
+	slices.SortStableFunc(detectable, func(a, b entry) int { return cmp.Compare(b.priority, a.priority) })
 
-	return &Resolver{
-		Sources: map[string]sources.Source{
-			youtubeSource.SourceName():    youtubeSource,
-			soundcloudSource.SourceName(): soundcloudSource,
-			radioSource.SourceName():      radioSource,
-		},
+	order := make([]string, 0, len(detectable))
+	for _, name := range prefs.Precedence {
+		ok := slices.ContainsFunc(detectable, func(e entry) bool { return e.src.SourceName() == name })
+		if ok && !slices.Contains(order, name) {
+			order = append(order, name)
+		}
+	}
+	for _, e := range detectable {
+		if name := e.src.SourceName(); !slices.Contains(order, name) {
+			order = append(order, name)
+		}
 	}
+	return order
 }
 
-// Resolve turns input into track metadata. selectedSource/selectedParser are
-// optional overrides ("" = auto-detect); see the precedence rules in the body.
+// Resolve turns input into track metadata with the resolver's defaults; see
+// ResolveWith.
 func (r *Resolver) Resolve(input, selectedSource, selectedParser string) ([]sources.TrackInfo, error) {
+	return r.ResolveWithContext(context.Background(), input, selectedSource, selectedParser, Prefs{})
+}
+
+// ResolveContext is Resolve bound to ctx; see ResolveWithContext.
+func (r *Resolver) ResolveContext(ctx context.Context, input, selectedSource, selectedParser string) ([]sources.TrackInfo, error) {
+	return r.ResolveWithContext(ctx, input, selectedSource, selectedParser, Prefs{})
+}
+
+// ResolveWith turns input into track metadata. selectedSource/selectedParser
+// are optional overrides ("" = auto-detect); prefs adjust auto-detect. See
+// the precedence rules in the body.
+func (r *Resolver) ResolveWith(input, selectedSource, selectedParser string, prefs Prefs) ([]sources.TrackInfo, error) {
+	return r.ResolveWithContext(context.Background(), input, selectedSource, selectedParser, prefs)
+}
+
+// ResolveWithContext is ResolveWith with the chosen source's requests bound
+// to ctx (see sources.ResolveContext). Match probes are not: they are short
+// HEAD requests with their own timeouts, and auto-detect only stops between
+// them once ctx is done.
+//
+// A selectedParser is pinned on the tracks that list it (sources.PinParser),
+// so health ordering cannot move the listener's pick off the front.
+func (r *Resolver) ResolveWithContext(ctx context.Context, input, selectedSource, selectedParser string, prefs Prefs) ([]sources.TrackInfo, error) {
+	tracks, err := r.resolve(ctx, input, selectedSource, selectedParser, prefs)
+	sources.PinParser(tracks, selectedParser)
+	return tracks, err
+}
+
+func (r *Resolver) resolve(ctx context.Context, input, selectedSource, selectedParser string, prefs Prefs) ([]sources.TrackInfo, error) {
 	// Direct source selection
 	if selectedSource != "" {
-		src, ok := r.Sources[selectedSource]
+		src, ok := r.Source(selectedSource)
 		if !ok {
 			return nil, errors.New("unknown source: " + selectedSource)
 		}
@@ -45,35 +215,46 @@ func (r *Resolver) Resolve(input, selectedSource, selectedParser string) ([]sour
 		}
 
 		if !isURL(input) {
-			if selectedSource != sources.YouTube && selectedSource != sources.SoundCloud {
-				return nil, errors.New("title search is only supported on " + sources.YouTube + " and " + sources.SoundCloud)
+			if !sources.ResolvesQueries(src) {
+				return nil, errors.New("title search is only supported on " + joinNames(r.QuerySources()))
 			}
-			return src.Resolve(input, selectedParser)
+			return sources.ResolveContext(ctx, src, input, selectedParser)
 		}
 		if !src.Match(input) {
 			return nil, errors.New("input does not match selected source: " + selectedSource)
 		}
-		return src.Resolve(input, selectedParser)
+		return sources.ResolveContext(ctx, src, input, selectedParser)
 	}
 
-	// Automatic detection
+	// Automatic detection. Local ids and paths are not URLs, so the local source
+	// is asked before the non-URL input is taken for a title search; its Match
+	// only claims "local:" ids and paths inside the library.
+	if localSrc, ok := r.Source(sources.Local); ok && !isURL(input) && localSrc.Match(input) {
+		selectedParser, err := ensureParser(localSrc, selectedParser)
+		if err != nil {
+			return nil, err
+		}
+		return sources.ResolveContext(ctx, localSrc, input, selectedParser)
+	}
 	if !isURL(input) {
-		yt, ok := r.Sources[sources.YouTube]
+		search, ok := r.searchSource(prefs.SearchSource)
 		if !ok {
 			return nil, errors.New(youtube.Name + " source not available for title search")
 		}
-		selectedParser, err := ensureParser(yt, selectedParser)
+		selectedParser, err := ensureParser(search, selectedParser)
 		if err != nil {
 			return nil, err
 		}
-		return yt.Resolve(input, selectedParser)
+		return sources.ResolveContext(ctx, search, input, selectedParser)
 	}
 
-	// Deterministic precedence for URL auto-detect (map iteration order is random);
-	// radio stays the final fallback below. A new source must be added here as well
-	// as in New().
-	for _, typ := range []string{sources.YouTube, sources.SoundCloud} {
-		s, ok := r.Sources[typ]
+	// Deterministic precedence for URL auto-detect (see Precedence; map
+	// iteration order would be random); radio stays the final fallback below.
+	for _, name := range r.Precedence(prefs) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		s, ok := r.Source(name)
 		if !ok {
 			continue
 		}
@@ -82,21 +263,40 @@ func (r *Resolver) Resolve(input, selectedSource, selectedParser string) ([]sour
 			if err != nil {
 				return nil, err
 			}
-			return s.Resolve(input, selectedParser)
+			return sources.ResolveContext(ctx, s, input, selectedParser)
 		}
 	}
 
-	if radioSrc, ok := r.Sources[sources.Radio]; ok {
+	if radioSrc, ok := r.Source(sources.Radio); ok {
 		selectedParser, err := ensureParser(radioSrc, selectedParser)
 		if err != nil {
 			return nil, err
 		}
-		return radioSrc.Resolve(input, selectedParser)
+		return sources.ResolveContext(ctx, radioSrc, input, selectedParser)
 	}
 
 	return nil, errors.New("no matching source found")
 }
 
+// searchSource is the source bare queries go to: the preferred one when it
+// can take them, YouTube otherwise.
+func (r *Resolver) searchSource(preferred string) (sources.Source, bool) {
+	if preferred != "" {
+		if src, ok := r.Source(preferred); ok && sources.ResolvesQueries(src) {
+			return src, true
+		}
+	}
+	return r.Source(sources.YouTube)
+}
+
+// joinNames renders "a, b and c".
+func joinNames(names []string) string {
+	if len(names) < 2 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
 func ensureParser(src sources.Source, selected string) (string, error) {
 	if selected != "" {
 		return selected, nil
@@ -0,0 +1,142 @@
+package resolve
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/keshon/melodix/pkg/music/sources"
+)
+
+// fakeSource claims URLs containing its name and answers with one track
+// naming itself, so a test can see which source resolved.
+type fakeSource struct {
+	name string
+}
+
+func (f *fakeSource) Match(input string) bool { return strings.Contains(input, f.name) }
+
+func (f *fakeSource) Resolve(input, parser string) ([]sources.TrackInfo, error) {
+	return []sources.TrackInfo{{URL: input, SourceName: f.name}}, nil
+}
+
+func (f *fakeSource) SourceName() string         { return f.name }
+func (f *fakeSource) AvailableParsers() []string { return []string{"p"} }
+
+// fakeQuerySource is a fakeSource that also takes bare queries.
+type fakeQuerySource struct {
+	fakeSource
+	sources.TakesQueries
+}
+
+func querySource(name string) *fakeQuerySource {
+	return &fakeQuerySource{fakeSource: fakeSource{name: name}}
+}
+
+func resolvedBy(t *testing.T, r *Resolver, input string, prefs Prefs) string {
+	t.Helper()
+	tracks, err := r.ResolveWith(input, "", "", prefs)
+	if err != nil {
+		t.Fatalf("ResolveWith(%q): %v", input, err)
+	}
+	return tracks[0].SourceName
+}
+
+func TestRegisterOrdersAutoDetectByPriority(t *testing.T) {
+	r := &Resolver{}
+	r.Register(querySource(sources.YouTube), PriorityYouTube)
+	r.Register(&fakeSource{name: "low"}, 10)
+	r.Register(&fakeSource{name: "high"}, 900)
+	r.Register(&fakeSource{name: "hidden"}, NoAutoDetect)
+
+	if got, want := r.Precedence(Prefs{}), []string{"high", sources.YouTube, "low"}; !slices.Equal(got, want) {
+		t.Fatalf("Precedence = %q, want %q", got, want)
+	}
+	// "https://x/high/low" matches both; the higher priority wins.
+	if got := resolvedBy(t, r, "https://x/high/low", Prefs{}); got != "high" {
+		t.Errorf("resolved by %q, want high", got)
+	}
+	// A NoAutoDetect source is never asked, but can still be selected.
+	if _, err := r.ResolveWith("https://x/hidden", "", "", Prefs{}); err == nil {
+		t.Error("a NoAutoDetect source claimed a URL")
+	}
+	if tracks, err := r.Resolve("https://x/hidden", "hidden", ""); err != nil || tracks[0].SourceName != "hidden" {
+		t.Errorf("selected NoAutoDetect source: %v, %v", tracks, err)
+	}
+
+	// Re-registering replaces in place.
+	r.Register(&fakeSource{name: "low"}, 1000)
+	if got := r.Precedence(Prefs{})[0]; got != "low" {
+		t.Errorf("after re-register, first = %q", got)
+	}
+	if n := len(r.Sources()); n != 4 {
+		t.Errorf("%d sources after re-register, want 4", n)
+	}
+}
+
+func TestPrefsPrecedenceAndSearchSource(t *testing.T) {
+	r := &Resolver{}
+	r.Register(querySource(sources.YouTube), PriorityYouTube)
+	r.Register(querySource(sources.SoundCloud), PrioritySoundCloud)
+	r.Register(&fakeSource{name: sources.Direct}, PriorityDirect)
+
+	prefs := Prefs{Precedence: []string{sources.Direct, "unknown", sources.Direct}}
+	if got, want := r.Precedence(prefs), []string{sources.Direct, sources.YouTube, sources.SoundCloud}; !slices.Equal(got, want) {
+		t.Fatalf("Precedence = %q, want %q", got, want)
+	}
+	both := "https://x/" + sources.YouTube + "/" + sources.Direct
+	if got := resolvedBy(t, r, both, prefs); got != sources.Direct {
+		t.Errorf("with precedence, resolved by %q", got)
+	}
+	if got := resolvedBy(t, r, both, Prefs{}); got != sources.YouTube {
+		t.Errorf("without precedence, resolved by %q", got)
+	}
+
+	if got := resolvedBy(t, r, "some song", Prefs{}); got != sources.YouTube {
+		t.Errorf("bare query went to %q, want youtube", got)
+	}
+	if got := resolvedBy(t, r, "some song", Prefs{SearchSource: sources.SoundCloud}); got != sources.SoundCloud {
+		t.Errorf("bare query went to %q, want the guild's soundcloud", got)
+	}
+	// A source that cannot take queries is ignored as a search source.
+	if got := resolvedBy(t, r, "some song", Prefs{SearchSource: sources.Direct}); got != sources.YouTube {
+		t.Errorf("bare query went to %q, want youtube", got)
+	}
+
+	_, err := r.Resolve("some song", sources.Direct, "")
+	if err == nil || !strings.Contains(err.Error(), "youtube and soundcloud") {
+		t.Errorf("query on direct: err = %v, want the query sources named", err)
+	}
+}
+
+// blockingSource answers only once its request is given up.
+type blockingSource struct{ fakeQuerySource }
+
+func (b *blockingSource) ResolveContext(ctx context.Context, input, parser string) ([]sources.TrackInfo, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestResolveContextCancels(t *testing.T) {
+	r := &Resolver{}
+	r.Register(&blockingSource{*querySource("slow")}, 10)
+	r.Register(&fakeSource{name: "plain"}, 20)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := r.ResolveContext(ctx, "https://x/slow", "", "")
+		done <- err
+	}()
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("ResolveContext = %v, want context.Canceled", err)
+	}
+
+	// A source without ResolveContext is not started once ctx is done.
+	if _, err := r.ResolveContext(ctx, "https://x/plain", "plain", ""); !errors.Is(err, context.Canceled) {
+		t.Fatalf("ResolveContext on a plain source = %v, want context.Canceled", err)
+	}
+}
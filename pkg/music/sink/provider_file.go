@@ -0,0 +1,93 @@
+package sink
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// FileProvider records playback into dir: one FileSink per target, opened on
+// the first Sink call and finished on ReleaseSink. The player releases its
+// sink when it disconnects (Stop(true), or the queue running out), so each
+// listening session becomes one file.
+type FileProvider struct {
+	dir    string
+	format FileFormat
+	log    zerolog.Logger
+
+	mu    sync.Mutex
+	sinks map[string]*FileSink
+}
+
+// NewFileProvider creates dir if needed and records into it in format.
+func NewFileProvider(dir string, format FileFormat, log zerolog.Logger) (*FileProvider, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("sink: create recording dir: %w", err)
+	}
+	return &FileProvider{dir: dir, format: format, log: log, sinks: make(map[string]*FileSink)}, nil
+}
+
+// Sink returns the target's recording, starting a new file if none is open.
+func (p *FileProvider) Sink(target string) (AudioSink, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if s, ok := p.sinks[target]; ok {
+		return s, nil
+	}
+	path := filepath.Join(p.dir, recordingName(target, time.Now(), p.format))
+	s, err := NewFileSink(path)
+	if err != nil {
+		return nil, err
+	}
+	p.sinks[target] = s
+	p.log.Info().Str("target", target).Str("path", path).Msg("recording_started")
+	return s, nil
+}
+
+// ReleaseSink finishes the target's file.
+func (p *FileProvider) ReleaseSink(target string) {
+	p.mu.Lock()
+	s, ok := p.sinks[target]
+	delete(p.sinks, target)
+	p.mu.Unlock()
+	if !ok {
+		return
+	}
+	if err := s.Close(); err != nil {
+		p.log.Warn().Err(err).Str("target", target).Msg("recording_close_failed")
+		return
+	}
+	p.log.Info().Str("target", target).Msg("recording_finished")
+}
+
+// InvalidateSink is a no-op: a file has no transport to re-acquire, and
+// starting a new file on a voice-style invalidation would split a recording.
+func (p *FileProvider) InvalidateSink() {}
+
+// Close finishes every open recording. Call when the process exits, or files
+// still being written lose their Ogg end page or WAV sizes.
+func (p *FileProvider) Close() error {
+	p.mu.Lock()
+	sinks := p.sinks
+	p.sinks = make(map[string]*FileSink)
+	p.mu.Unlock()
+	var errs []error
+	for _, s := range sinks {
+		errs = append(errs, s.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// recordingName is unique per target down to the millisecond, so a session
+// released and restarted within the same second does not truncate the last one.
+func recordingName(target string, at time.Time, format FileFormat) string {
+	if target == "" {
+		target = "melodix"
+	}
+	return fmt.Sprintf("%s-%s.%s", target, at.Format("20060102-150405.000"), format)
+}
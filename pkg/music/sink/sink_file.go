@@ -0,0 +1,161 @@
+package sink
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/keshon/melodix/pkg/music/opus"
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+// FileFormat selects the container a FileSink writes.
+type FileFormat string
+
+// Supported recording formats. Ogg and WebM copy the Opus packets as they are;
+// WAV decodes them to PCM.
+const (
+	FormatOgg  FileFormat = "ogg"
+	FormatWebM FileFormat = "webm"
+	FormatWAV  FileFormat = "wav"
+)
+
+// ErrUnknownFileFormat is returned for a path whose extension names no
+// supported format.
+var ErrUnknownFileFormat = errors.New("sink: unknown file format (want .ogg, .opus, .webm or .wav)")
+
+// FormatFromPath picks the format from the file extension (.opus is Ogg Opus).
+func FormatFromPath(path string) (FileFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ogg", ".opus":
+		return FormatOgg, nil
+	case ".webm":
+		return FormatWebM, nil
+	case ".wav":
+		return FormatWAV, nil
+	}
+	return "", ErrUnknownFileFormat
+}
+
+// packetWriter is what the Ogg and WebM muxers have in common.
+type packetWriter interface {
+	WritePacket(pkt []byte) error
+	Close() error
+}
+
+// FileSink records every track it is handed into one file, back to back, in
+// the order the player plays them. It does not pace: packets are written as
+// fast as the stream yields them, so a track "plays" in however long the
+// parser takes to deliver it. That is what an export wants; anyone who needs
+// wall-clock timing should pace upstream instead of adding sleeps here.
+//
+// Close must be called to finish the file — the Ogg end-of-stream page and the
+// WAV header sizes are only written then.
+type FileSink struct {
+	// mu serialises Stream and Close; the player never streams two tracks into
+	// one sink at once, but Close can race the last Stream on shutdown.
+	mu     sync.Mutex
+	f      *os.File
+	pw     packetWriter    // Ogg/WebM; nil for WAV
+	wav    *opus.WAVWriter // WAV; nil otherwise
+	closed bool
+}
+
+// NewFileSink creates (or truncates) path and records into it in the format
+// its extension names.
+func NewFileSink(path string) (*FileSink, error) {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("sink: create recording: %w", err)
+	}
+	s := &FileSink{f: f}
+	switch format {
+	case FormatOgg:
+		s.pw = opus.NewOggWriter(f)
+	case FormatWebM:
+		s.pw = opus.NewWebMWriter(f)
+	case FormatWAV:
+		s.wav = opus.NewWAVWriter(f)
+	}
+	return s, nil
+}
+
+func (s *FileSink) Stream(r opus.Reader, stop <-chan struct{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return opus.ErrWriterClosed
+	}
+	if s.wav != nil {
+		pcm := opus.DecodeReader(&stopReader{r: r, stop: stop})
+		_, err := io.Copy(s.wav, pcm)
+		return streamResult(err)
+	}
+	for {
+		select {
+		case <-stop:
+			return stream.ErrPlaybackStopped
+		default:
+		}
+		pkt, err := r.ReadPacket()
+		if err != nil {
+			return streamResult(err)
+		}
+		if err := s.pw.WritePacket(pkt); err != nil {
+			return err
+		}
+	}
+}
+
+// streamResult maps the end of the read loop onto the AudioSink contract: a
+// natural end is nil, everything else — including a stop — passes through.
+func streamResult(err error) error {
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+// stopReader turns a closed stop channel into stream.ErrPlaybackStopped at the
+// next packet boundary. Its Close is a no-op because r belongs to the player,
+// which closes it after Stream returns.
+type stopReader struct {
+	r    opus.Reader
+	stop <-chan struct{}
+}
+
+func (s *stopReader) ReadPacket() ([]byte, error) {
+	select {
+	case <-s.stop:
+		return nil, stream.ErrPlaybackStopped
+	default:
+	}
+	return s.r.ReadPacket()
+}
+
+func (s *stopReader) Close() error { return nil }
+
+// Close finishes the container and closes the file. Safe to call twice.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	var err error
+	if s.wav != nil {
+		err = s.wav.Close()
+	} else {
+		err = s.pw.Close()
+	}
+	return errors.Join(err, s.f.Close())
+}
@@ -0,0 +1,121 @@
+package sink
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/keshon/melodix/pkg/music/opus"
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+// silence returns an opus.Reader of n real 20ms packets.
+func silence(n int) opus.Reader {
+	return opus.Encode(io.NopCloser(bytes.NewReader(make([]byte, opus.PCMFrameBytes*n))))
+}
+
+// Two tracks streamed into one sink come out as one continuous recording.
+func TestFileSink_RecordsTracksBackToBack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rec.webm")
+	s, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("NewFileSink: %v", err)
+	}
+	for _, n := range []int{7, 5} {
+		if err := s.Stream(silence(n), make(chan struct{})); err != nil {
+			t.Fatalf("Stream: %v", err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := opus.Demux(f)
+	defer d.Close()
+	n := 0
+	for {
+		if _, err := d.ReadPacket(); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatalf("ReadPacket: %v", err)
+		}
+		n++
+	}
+	if n != 12 {
+		t.Fatalf("recorded %d packets, want 12", n)
+	}
+}
+
+func TestFileSink_StopEndsStream(t *testing.T) {
+	for _, name := range []string{"rec.ogg", "rec.wav"} {
+		t.Run(name, func(t *testing.T) {
+			s, err := NewFileSink(filepath.Join(t.TempDir(), name))
+			if err != nil {
+				t.Fatalf("NewFileSink: %v", err)
+			}
+			defer s.Close()
+			stop := make(chan struct{})
+			close(stop)
+			if err := s.Stream(silence(3), stop); !errors.Is(err, stream.ErrPlaybackStopped) {
+				t.Fatalf("Stream = %v, want ErrPlaybackStopped", err)
+			}
+		})
+	}
+}
+
+func TestNewFileSink_RejectsUnknownExtension(t *testing.T) {
+	if _, err := NewFileSink(filepath.Join(t.TempDir(), "rec.mp3")); !errors.Is(err, ErrUnknownFileFormat) {
+		t.Fatalf("err = %v, want ErrUnknownFileFormat", err)
+	}
+}
+
+// One file per session: the same target keeps its sink until released, and the
+// next Sink after a release starts a fresh file.
+func TestFileProvider_OneFilePerSession(t *testing.T) {
+	dir := t.TempDir()
+	p, err := NewFileProvider(dir, FormatOgg, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("NewFileProvider: %v", err)
+	}
+	defer p.Close()
+
+	a, _ := p.Sink("")
+	b, _ := p.Sink("")
+	if a != b {
+		t.Fatal("same target got two sinks within one session")
+	}
+	if err := a.Stream(silence(3), make(chan struct{})); err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	p.ReleaseSink("")
+	time.Sleep(2 * time.Millisecond) // names are unique to the millisecond
+	c, _ := p.Sink("")
+	if c == a {
+		t.Fatal("Sink after ReleaseSink reused the finished recording")
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "melodix-*.ogg"))
+	if len(files) != 2 {
+		t.Fatalf("got files %v, want two recordings", files)
+	}
+}
+
+func TestNullSink_PacesToRealTime(t *testing.T) {
+	start := time.Now()
+	if err := (NullSink{}).Stream(silence(10), make(chan struct{})); err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	if got := time.Since(start); got < 180*time.Millisecond {
+		t.Fatalf("10 packets took %v, want about 200ms", got)
+	}
+}
@@ -0,0 +1,52 @@
+package sink
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/opus"
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+// NullSink reads and discards packets at the pace they would play, so a
+// headless player behaves like an audible one: tracks last their real length,
+// queue and status commands see what they would see, and a stall or reopen
+// shows up at the same moment it would on a speaker. Do not drop the pacing to
+// "go faster" — that turns it into a parser benchmark and hides exactly the
+// timing bugs a headless run is for.
+type NullSink struct{}
+
+func (NullSink) Stream(r opus.Reader, stop <-chan struct{}) error {
+	start := time.Now()
+	var played time.Duration
+	for {
+		select {
+		case <-stop:
+			return stream.ErrPlaybackStopped
+		default:
+		}
+		pkt, err := r.ReadPacket()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		played += time.Duration(opus.PacketDurationMs(pkt) * float64(time.Millisecond))
+		if ahead := played - time.Since(start); ahead > 0 {
+			select {
+			case <-stop:
+				return stream.ErrPlaybackStopped
+			case <-time.After(ahead):
+			}
+		}
+	}
+}
+
+// NullProvider hands every target the same NullSink.
+type NullProvider struct{}
+
+func (NullProvider) Sink(target string) (AudioSink, error) { return NullSink{}, nil }
+func (NullProvider) ReleaseSink(target string)             {}
+func (NullProvider) InvalidateSink()                       {}
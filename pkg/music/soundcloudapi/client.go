@@ -5,6 +5,7 @@
 package soundcloudapi
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -13,6 +14,9 @@ import (
 	"regexp"
 	"sync"
 	"time"
+
+	"github.com/keshon/melodix/pkg/music/breaker"
+	"github.com/keshon/melodix/pkg/music/httpreplay"
 )
 
 // Client talks to SoundCloud api-v2 with automatic client_id management.
@@ -28,18 +32,31 @@ type Client struct {
 	// concurrent re-scrapes so rotation triggers one fetch, not many.
 	mu       sync.Mutex
 	clientID string
+
+	// scrape guards the client_id scrape: while the web player keeps failing
+	// to yield one, callers fail at once instead of fetching it again per track.
+	scrape *breaker.Breaker
 }
 
-// New creates a Client with production defaults.
+// ScrapeBreaker names the client_id scrape's circuit breaker in
+// breaker.Default(), where the shared client's breaker lives.
+const ScrapeBreaker = "soundcloud client_id"
+
+// New creates a Client with production defaults and a breaker of its own.
 func New() *Client {
 	return &Client{
-		HTTP:    &http.Client{Timeout: 10 * time.Second},
+		HTTP:    &http.Client{Timeout: 10 * time.Second, Transport: httpreplay.Wrap(nil)},
 		APIBase: "https://api-v2.soundcloud.com",
 		WebBase: "https://soundcloud.com",
+		scrape:  breaker.New(ScrapeBreaker),
 	}
 }
 
-var defaultClient = sync.OnceValue(New)
+var defaultClient = sync.OnceValue(func() *Client {
+	c := New()
+	c.scrape = breaker.Default().Get(ScrapeBreaker)
+	return c
+})
 
 // Default returns the process-wide shared client, so the scnative parser and the
 // soundcloud source's searcher reuse one client_id cache.
@@ -51,16 +68,32 @@ var (
 )
 
 // ClientID returns the cached client_id, scraping the web player on first use.
+// While the scrape's circuit breaker is open it fails with breaker.ErrOpen.
 func (c *Client) ClientID() (string, error) {
+	return c.ClientIDContext(context.Background())
+}
+
+// ClientIDContext is ClientID with the scrape bound to ctx. A scrape cancelled
+// through ctx is no verdict on the web player and leaves the breaker alone.
+func (c *Client) ClientIDContext(ctx context.Context) (string, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	if c.clientID != "" {
 		return c.clientID, nil
 	}
-	id, err := c.scrapeClientID()
+	if err := c.scrape.Allow(); err != nil {
+		return "", err
+	}
+	id, err := c.scrapeClientID(ctx)
 	if err != nil {
+		if ctx.Err() != nil {
+			c.scrape.Skip()
+		} else {
+			c.scrape.Failure(err)
+		}
 		return "", err
 	}
+	c.scrape.Success()
 	c.clientID = id
 	l := logger()
 	l.Debug().Msg("soundcloud_client_id_scraped")
@@ -80,8 +113,8 @@ func (c *Client) invalidateClientID(failed string) {
 // scrapeClientID fetches the web player page and greps its JS bundles for the
 // client_id. Bundles are tried in reverse order — the id historically lives in
 // one of the last chunks. Caller holds c.mu.
-func (c *Client) scrapeClientID() (string, error) {
-	page, err := c.getBody(c.WebBase)
+func (c *Client) scrapeClientID(ctx context.Context) (string, error) {
+	page, err := c.getBody(ctx, c.WebBase)
 	if err != nil {
 		return "", fmt.Errorf("soundcloud client_id: fetch web player: %w", err)
 	}
@@ -90,8 +123,11 @@ func (c *Client) scrapeClientID() (string, error) {
 		return "", fmt.Errorf("soundcloud client_id: no script bundles found")
 	}
 	for i := len(matches) - 1; i >= 0; i-- {
-		body, err := c.getBody(matches[i][1])
+		body, err := c.getBody(ctx, matches[i][1])
 		if err != nil {
+			if ctx.Err() != nil {
+				return "", err
+			}
 			continue
 		}
 		if m := clientIDRe.FindStringSubmatch(body); m != nil {
@@ -101,8 +137,12 @@ func (c *Client) scrapeClientID() (string, error) {
 	return "", fmt.Errorf("soundcloud client_id: not found in %d script bundles", len(matches))
 }
 
-func (c *Client) getBody(rawURL string) (string, error) {
-	resp, err := c.HTTP.Get(rawURL)
+func (c *Client) getBody(ctx context.Context, rawURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.HTTP.Do(req)
 	if err != nil {
 		return "", err
 	}
@@ -120,9 +160,9 @@ func (c *Client) getBody(rawURL string) (string, error) {
 // getJSON GETs rawURL with client_id appended and decodes the response into v.
 // On 401/403 the cached client_id is dropped (it rotates every few days),
 // re-scraped, and the request retried exactly once.
-func (c *Client) getJSON(rawURL string, v any) error {
+func (c *Client) getJSON(ctx context.Context, rawURL string, v any) error {
 	for attempt := 0; ; attempt++ {
-		id, err := c.ClientID()
+		id, err := c.ClientIDContext(ctx)
 		if err != nil {
 			return err
 		}
@@ -134,7 +174,11 @@ func (c *Client) getJSON(rawURL string, v any) error {
 		q.Set("client_id", id)
 		u.RawQuery = q.Encode()
 
-		resp, err := c.HTTP.Get(u.String())
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return err
+		}
+		resp, err := c.HTTP.Do(req)
 		if err != nil {
 			return err
 		}
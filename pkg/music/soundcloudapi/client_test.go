@@ -1,20 +1,24 @@
 package soundcloudapi
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/keshon/melodix/pkg/music/breaker"
 )
 
 // newTestClient points a Client at the given test server for both web and API bases.
 func newTestClient(srv *httptest.Server) *Client {
-	return &Client{
-		HTTP:    srv.Client(),
-		APIBase: srv.URL,
-		WebBase: srv.URL,
-	}
+	c := New()
+	c.HTTP = srv.Client()
+	c.APIBase = srv.URL
+	c.WebBase = srv.URL
+	return c
 }
 
 func TestClientIDScrapedFromLastBundle(t *testing.T) {
@@ -107,3 +111,47 @@ func TestGetJSONFailsAfterSecondUnauthorized(t *testing.T) {
 		t.Fatalf("err = %v, want failure after single retry", err)
 	}
 }
+
+func TestClientIDScrapeBreaker(t *testing.T) {
+	pageHits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pageHits++
+		http.Error(w, "blocked", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(srv)
+	for range breaker.DefaultThreshold {
+		if _, err := c.ClientID(); err == nil || errors.Is(err, breaker.ErrOpen) {
+			t.Fatalf("ClientID = %v, want the scrape's own failure", err)
+		}
+	}
+	// Tripped: the web player is not fetched again until the cooldown is over.
+	if _, err := c.ClientID(); !errors.Is(err, breaker.ErrOpen) {
+		t.Fatalf("ClientID = %v, want breaker.ErrOpen", err)
+	}
+	if pageHits != breaker.DefaultThreshold {
+		t.Fatalf("web player fetched %d times, want %d", pageHits, breaker.DefaultThreshold)
+	}
+}
+
+// A scrape abandoned by its caller says nothing about the web player, so it
+// never counts towards the breaker.
+func TestClientIDCancelledScrapeIsNotAFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	c := newTestClient(srv)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for range breaker.DefaultThreshold + 1 {
+		if _, err := c.ClientIDContext(ctx); !errors.Is(err, context.Canceled) {
+			t.Fatalf("ClientIDContext = %v, want context.Canceled", err)
+		}
+	}
+	if st := c.scrape.Status(); st.State != breaker.Closed || st.Trips != 0 {
+		t.Fatalf("breaker = %+v, want closed and untouched", st)
+	}
+}
@@ -0,0 +1,188 @@
+package soundcloudapi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// pageSize is what a collection page is asked for. api-v2 accepts up to
+	// 200 but answers large pages slowly; 50 is what the web player uses.
+	pageSize = 50
+
+	// idsBatch is the most ids /tracks?ids= takes at once.
+	idsBatch = 50
+
+	// maxPages stops a walk that keeps returning pages with nothing usable in
+	// them — a likes list made entirely of liked playlists, say — instead of
+	// following next_href through a years-long history.
+	maxPages = 20
+)
+
+// Playlist is a set (playlist or album) with its tracks in order.
+type Playlist struct {
+	Title  string  `json:"title"`
+	Tracks []Track `json:"tracks"`
+}
+
+// page is one page of a linked-partitioning collection.
+type page[T any] struct {
+	Collection []T    `json:"collection"`
+	NextHref   string `json:"next_href"`
+}
+
+// ResolvePlaylist expands a set URL into at most limit full tracks. The
+// resolve answer carries only the first few tracks in full; the rest are
+// stubs holding just an id, fetched here in batches.
+func (c *Client) ResolvePlaylist(setURL string, limit int) (*Playlist, error) {
+	return c.ResolvePlaylistContext(context.Background(), setURL, limit)
+}
+
+// ResolvePlaylistContext is ResolvePlaylist with its requests bound to ctx.
+func (c *Client) ResolvePlaylistContext(ctx context.Context, setURL string, limit int) (*Playlist, error) {
+	var p Playlist
+	if err := c.getJSON(ctx, c.APIBase+"/resolve?url="+url.QueryEscape(setURL), &p); err != nil {
+		return nil, err
+	}
+	if len(p.Tracks) > limit {
+		p.Tracks = p.Tracks[:limit]
+	}
+	tracks, err := c.fillStubs(ctx, p.Tracks)
+	if err != nil {
+		return nil, err
+	}
+	if len(tracks) == 0 {
+		return nil, ErrNoResults
+	}
+	p.Tracks = tracks
+	return &p, nil
+}
+
+// UserTracks lists at most limit of a user's uploads, newest first, as their
+// profile shows them. profileURL is the user's page, soundcloud.com/<name>.
+func (c *Client) UserTracks(profileURL string, limit int) ([]Track, error) {
+	return c.UserTracksContext(context.Background(), profileURL, limit)
+}
+
+// UserTracksContext is UserTracks with its requests bound to ctx.
+func (c *Client) UserTracksContext(ctx context.Context, profileURL string, limit int) ([]Track, error) {
+	id, err := c.resolveUserID(ctx, profileURL)
+	if err != nil {
+		return nil, err
+	}
+	return collect(ctx, c, fmt.Sprintf("%s/users/%d/tracks", c.APIBase, id), limit,
+		func(t Track) (Track, bool) { return t, true })
+}
+
+// UserLikes lists at most limit tracks a user liked, most recent first.
+// Liked playlists share the list and are skipped: one like would otherwise
+// spend the whole cap on someone else's set.
+func (c *Client) UserLikes(profileURL string, limit int) ([]Track, error) {
+	return c.UserLikesContext(context.Background(), profileURL, limit)
+}
+
+// UserLikesContext is UserLikes with its requests bound to ctx.
+func (c *Client) UserLikesContext(ctx context.Context, profileURL string, limit int) ([]Track, error) {
+	id, err := c.resolveUserID(ctx, profileURL)
+	if err != nil {
+		return nil, err
+	}
+	type like struct {
+		Track *Track `json:"track"`
+	}
+	return collect(ctx, c, fmt.Sprintf("%s/users/%d/likes", c.APIBase, id), limit,
+		func(l like) (Track, bool) {
+			if l.Track == nil {
+				return Track{}, false
+			}
+			return *l.Track, true
+		})
+}
+
+func (c *Client) resolveUserID(ctx context.Context, profileURL string) (int64, error) {
+	var u struct {
+		Kind string `json:"kind"`
+		ID   int64  `json:"id"`
+	}
+	if err := c.getJSON(ctx, c.APIBase+"/resolve?url="+url.QueryEscape(profileURL), &u); err != nil {
+		return 0, err
+	}
+	if u.Kind != "user" || u.ID == 0 {
+		return 0, fmt.Errorf("soundcloud api: %s is not a user profile", profileURL)
+	}
+	return u.ID, nil
+}
+
+// collect walks a paged collection from endpoint, following next_href until
+// limit tracks are gathered, pick has turned each item into a track or
+// skipped it, and stubs are filled in.
+func collect[T any](ctx context.Context, c *Client, endpoint string, limit int, pick func(T) (Track, bool)) ([]Track, error) {
+	next := fmt.Sprintf("%s?limit=%d&linked_partitioning=1", endpoint, pageSize)
+	var out []Track
+	for pages := 0; next != "" && len(out) < limit && pages < maxPages; pages++ {
+		var p page[T]
+		if err := c.getJSON(ctx, next, &p); err != nil {
+			return nil, err
+		}
+		for _, item := range p.Collection {
+			if t, ok := pick(item); ok && len(out) < limit {
+				out = append(out, t)
+			}
+		}
+		next = p.NextHref
+	}
+	tracks, err := c.fillStubs(ctx, out)
+	if err != nil {
+		return nil, err
+	}
+	if len(tracks) == 0 {
+		return nil, ErrNoResults
+	}
+	return tracks, nil
+}
+
+// fillStubs replaces id-only track stubs with full tracks, keeping order.
+// Tracks SoundCloud no longer returns — deleted, or blocked in this region —
+// are dropped rather than queued as untitled entries that fail on open.
+func (c *Client) fillStubs(ctx context.Context, tracks []Track) ([]Track, error) {
+	var missing []string
+	for _, t := range tracks {
+		if isStub(t) && t.ID != 0 {
+			missing = append(missing, strconv.FormatInt(t.ID, 10))
+		}
+	}
+	full := make(map[int64]Track, len(missing))
+	for start := 0; start < len(missing); start += idsBatch {
+		end := min(start+idsBatch, len(missing))
+		var batch []Track
+		endpoint := c.APIBase + "/tracks?ids=" + url.QueryEscape(strings.Join(missing[start:end], ","))
+		if err := c.getJSON(ctx, endpoint, &batch); err != nil {
+			return nil, err
+		}
+		for _, t := range batch {
+			full[t.ID] = t
+		}
+	}
+
+	out := make([]Track, 0, len(tracks))
+	for _, t := range tracks {
+		if isStub(t) {
+			f, ok := full[t.ID]
+			if !ok || isStub(f) {
+				continue
+			}
+			t = f
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// isStub reports whether t is a placeholder holding little more than its id.
+// The permalink is what a stub lacks that every playable track has.
+func isStub(t Track) bool {
+	return t.PermalinkURL == ""
+}
@@ -0,0 +1,118 @@
+package soundcloudapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newCollectionServer serves the client_id scrape plus whatever api routes
+// the test registers on mux.
+func newCollectionServer(t *testing.T, mux *http.ServeMux) *Client {
+	t.Helper()
+	var srv *httptest.Server
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<script src="%s/app.js"></script>`, srv.URL)
+	})
+	mux.HandleFunc("/app.js", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `client_id:"cid"`)
+	})
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return newTestClient(srv)
+}
+
+func fullTrack(id int) string {
+	return fmt.Sprintf(`{"id":%d,"title":"Song %d","permalink_url":"https://soundcloud.com/a/song-%d"}`, id, id, id)
+}
+
+func TestResolvePlaylistFillsStubsInOrder(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/resolve", func(w http.ResponseWriter, r *http.Request) {
+		// Two full tracks, then stubs — the shape resolve returns for a set.
+		fmt.Fprintf(w, `{"title":"Set","tracks":[%s,%s,{"id":3},{"id":4},{"id":5}]}`, fullTrack(1), fullTrack(2))
+	})
+	mux.HandleFunc("/tracks", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("ids"); got != "3,4,5" {
+			t.Errorf("ids = %q, want 3,4,5", got)
+		}
+		// Out of order, and 4 is gone (deleted or region-blocked).
+		fmt.Fprintf(w, `[%s,%s]`, fullTrack(5), fullTrack(3))
+	})
+	c := newCollectionServer(t, mux)
+
+	p, err := c.ResolvePlaylist("https://soundcloud.com/a/sets/s", 100)
+	if err != nil {
+		t.Fatalf("ResolvePlaylist: %v", err)
+	}
+	var titles []string
+	for _, tr := range p.Tracks {
+		titles = append(titles, tr.Title)
+	}
+	if got := strings.Join(titles, ","); got != "Song 1,Song 2,Song 3,Song 5" {
+		t.Fatalf("titles = %s", got)
+	}
+}
+
+func TestUserTracksFollowsNextHrefUpToLimit(t *testing.T) {
+	var srv string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/resolve", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"kind":"user","id":7}`)
+	})
+	pages := 0
+	mux.HandleFunc("/users/7/tracks", func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		off := 0
+		fmt.Sscan(r.URL.Query().Get("offset"), &off)
+		fmt.Fprintf(w, `{"collection":[%s,%s],"next_href":"%s/users/7/tracks?offset=%d"}`,
+			fullTrack(off+1), fullTrack(off+2), srv, off+2)
+	})
+	c := newCollectionServer(t, mux)
+	srv = c.APIBase
+
+	tracks, err := c.UserTracks("https://soundcloud.com/a", 5)
+	if err != nil {
+		t.Fatalf("UserTracks: %v", err)
+	}
+	if len(tracks) != 5 || tracks[4].Title != "Song 5" {
+		t.Fatalf("got %d tracks, last %+v; want 5 ending in Song 5", len(tracks), tracks[len(tracks)-1])
+	}
+	if pages != 3 {
+		t.Fatalf("fetched %d pages, want 3 (stop once the limit is met)", pages)
+	}
+}
+
+func TestUserLikesSkipsLikedPlaylists(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/resolve", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"kind":"user","id":7}`)
+	})
+	mux.HandleFunc("/users/7/likes", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"collection":[{"track":%s},{"playlist":{"title":"P"}},{"track":%s}],"next_href":null}`,
+			fullTrack(1), fullTrack(2))
+	})
+	c := newCollectionServer(t, mux)
+
+	tracks, err := c.UserLikes("https://soundcloud.com/a", 100)
+	if err != nil {
+		t.Fatalf("UserLikes: %v", err)
+	}
+	if len(tracks) != 2 {
+		t.Fatalf("got %d tracks, want the 2 liked tracks", len(tracks))
+	}
+}
+
+func TestUserTracksRejectsNonUser(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/resolve", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"kind":"track","id":1}`)
+	})
+	c := newCollectionServer(t, mux)
+
+	if _, err := c.UserTracks("https://soundcloud.com/a", 10); err == nil {
+		t.Fatal("a track resolved as a user profile")
+	}
+}
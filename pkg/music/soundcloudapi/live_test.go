@@ -2,17 +2,17 @@ package soundcloudapi
 
 import (
 	"net/http"
-	"os"
 	"testing"
+
+	"github.com/keshon/melodix/pkg/music/httpreplay/replaytest"
 )
 
 // TestLiveSoundCloudPipeline hits the real SoundCloud endpoints: client_id scrape →
 // search → resolve → transcoding pick → signed stream URL → HTTP reachability.
-// Opt-in (network + third-party dependent): MELODIX_LIVE_TESTS=1 go test -run Live -v ./pkg/music/soundcloudapi
+// Replays testdata/replay when recorded; against the network (third-party
+// dependent): MELODIX_LIVE_TESTS=1 go test -run Live -v ./pkg/music/soundcloudapi
 func TestLiveSoundCloudPipeline(t *testing.T) {
-	if os.Getenv("MELODIX_LIVE_TESTS") == "" {
-		t.Skip("set MELODIX_LIVE_TESTS=1 to hit real SoundCloud")
-	}
+	replaytest.Start(t, "testdata/replay")
 
 	c := New()
 	id, err := c.ClientID()
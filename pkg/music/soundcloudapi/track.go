@@ -1,6 +1,7 @@
 package soundcloudapi
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/url"
@@ -39,8 +40,13 @@ type Track struct {
 
 // ResolveTrack turns a soundcloud.com track URL into track metadata + transcodings.
 func (c *Client) ResolveTrack(trackURL string) (*Track, error) {
+	return c.ResolveTrackContext(context.Background(), trackURL)
+}
+
+// ResolveTrackContext is ResolveTrack with its requests bound to ctx.
+func (c *Client) ResolveTrackContext(ctx context.Context, trackURL string) (*Track, error) {
 	var t Track
-	if err := c.getJSON(c.APIBase+"/resolve?url="+url.QueryEscape(trackURL), &t); err != nil {
+	if err := c.getJSON(ctx, c.APIBase+"/resolve?url="+url.QueryEscape(trackURL), &t); err != nil {
 		return nil, err
 	}
 	if len(t.Media.Transcodings) == 0 {
@@ -52,10 +58,15 @@ func (c *Client) ResolveTrack(trackURL string) (*Track, error) {
 // StreamURL exchanges a transcoding for its signed CDN URL (an m3u8 for HLS).
 // The URL expires in ~30 minutes — use it immediately, never store it.
 func (c *Client) StreamURL(t Transcoding) (string, error) {
+	return c.StreamURLContext(context.Background(), t)
+}
+
+// StreamURLContext is StreamURL with its requests bound to ctx.
+func (c *Client) StreamURLContext(ctx context.Context, t Transcoding) (string, error) {
 	var out struct {
 		URL string `json:"url"`
 	}
-	if err := c.getJSON(t.URL, &out); err != nil {
+	if err := c.getJSON(ctx, t.URL, &out); err != nil {
 		return "", err
 	}
 	if out.URL == "" {
@@ -96,7 +107,12 @@ func isAAC(t Transcoding) bool {
 
 // SearchFirstTrack returns the top track for a text query via api-v2 search.
 func (c *Client) SearchFirstTrack(query string) (*Track, error) {
-	tracks, err := c.SearchTracks(query, 1)
+	return c.SearchFirstTrackContext(context.Background(), query)
+}
+
+// SearchFirstTrackContext is SearchFirstTrack with its requests bound to ctx.
+func (c *Client) SearchFirstTrackContext(ctx context.Context, query string) (*Track, error) {
+	tracks, err := c.SearchTracksContext(ctx, query, 1)
 	if err != nil {
 		return nil, err
 	}
@@ -105,6 +121,11 @@ func (c *Client) SearchFirstTrack(query string) (*Track, error) {
 
 // SearchTracks returns up to limit tracks for a text query via api-v2 search.
 func (c *Client) SearchTracks(query string, limit int) ([]Track, error) {
+	return c.SearchTracksContext(context.Background(), query, limit)
+}
+
+// SearchTracksContext is SearchTracks with its requests bound to ctx.
+func (c *Client) SearchTracksContext(ctx context.Context, query string, limit int) ([]Track, error) {
 	if limit < 1 {
 		limit = 1
 	}
@@ -112,7 +133,7 @@ func (c *Client) SearchTracks(query string, limit int) ([]Track, error) {
 		Collection []Track `json:"collection"`
 	}
 	endpoint := fmt.Sprintf("%s/search/tracks?q=%s&limit=%d", c.APIBase, url.QueryEscape(query), limit)
-	if err := c.getJSON(endpoint, &out); err != nil {
+	if err := c.getJSON(ctx, endpoint, &out); err != nil {
 		return nil, err
 	}
 	if len(out.Collection) == 0 {
@@ -127,11 +148,16 @@ func (c *Client) SearchTracks(query string, limit int) ([]Track, error) {
 // enough to survive a round trip through a Discord component id: permalinks run
 // past 130 characters, well over that budget.
 func (c *Client) TrackByID(id string) (*Track, error) {
+	return c.TrackByIDContext(context.Background(), id)
+}
+
+// TrackByIDContext is TrackByID with its requests bound to ctx.
+func (c *Client) TrackByIDContext(ctx context.Context, id string) (*Track, error) {
 	if strings.TrimSpace(id) == "" {
 		return nil, ErrNoResults
 	}
 	var t Track
-	if err := c.getJSON(c.APIBase+"/tracks/"+url.PathEscape(id), &t); err != nil {
+	if err := c.getJSON(ctx, c.APIBase+"/tracks/"+url.PathEscape(id), &t); err != nil {
 		return nil, err
 	}
 	if t.PermalinkURL == "" {
@@ -0,0 +1,94 @@
+// Package bandcamp is the source for Bandcamp track and album pages. An album
+// expands to its streamable tracks; each is queued by its own track page and
+// played by the bcnative parser, which reads a fresh stream URL at open.
+package bandcamp
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/keshon/melodix/pkg/music/bandcampapi"
+	source "github.com/keshon/melodix/pkg/music/sources"
+)
+
+// Name is this source's identifier (equals sources.Bandcamp).
+const Name = "bandcamp"
+
+// Source resolves Bandcamp track and album URLs.
+type Source struct {
+	api *bandcampapi.Client
+}
+
+// New creates the Bandcamp source.
+func New() *Source {
+	return &Source{api: bandcampapi.Default()}
+}
+
+// Match claims <artist>.bandcamp.com/track/... and /album/... links. Artists
+// on a custom domain are not recognised: nothing in the URL says Bandcamp.
+func (s *Source) Match(input string) bool {
+	u, err := url.Parse(strings.TrimSpace(input))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	if !strings.HasSuffix(strings.ToLower(u.Hostname()), ".bandcamp.com") {
+		return false
+	}
+	return strings.HasPrefix(u.Path, "/track/") || strings.HasPrefix(u.Path, "/album/")
+}
+
+// Resolve returns the track a track page names, or every streamable track of
+// an album in album order. Purchase-only tracks have no stream and are left
+// out; an album with none fails rather than queueing nothing.
+func (s *Source) Resolve(input string, selectedParser string) ([]source.TrackInfo, error) {
+	return s.ResolveContext(context.Background(), input, selectedParser)
+}
+
+// ResolveContext is Resolve with the page fetch bound to ctx.
+func (s *Source) ResolveContext(ctx context.Context, input string, selectedParser string) ([]source.TrackInfo, error) {
+	parsers := s.AvailableParsers()
+	if selectedParser != "" && !slices.Contains(parsers, selectedParser) {
+		return nil, errors.New(Name + " source does not support " + selectedParser + " parser")
+	}
+	if !s.Match(input) {
+		return nil, errors.New(Name + " source needs a track or album link")
+	}
+	rel, err := s.api.ReleaseContext(ctx, strings.TrimSpace(input))
+	if err != nil {
+		return nil, err
+	}
+	tracks := rel.Streamable()
+	if len(tracks) == 0 {
+		return nil, bandcampapi.ErrNotStreamable
+	}
+	preferred := source.PreferParser(parsers, selectedParser)
+	out := make([]source.TrackInfo, 0, len(tracks))
+	for _, t := range tracks {
+		out = append(out, source.TrackInfo{
+			URL:              t.Page,
+			Title:            t.Label(),
+			SourceName:       Name,
+			AvailableParsers: preferred,
+			Duration:         t.Duration,
+		})
+	}
+	return out, nil
+}
+
+func (s *Source) SourceName() string {
+	return Name
+}
+
+func (s *Source) DisplayName() string {
+	return "Bandcamp"
+}
+
+// AvailableParsers puts yt-dlp behind the native parser, as SoundCloud does:
+// it reads Bandcamp pages too, and survives a change to the page layout that
+// would break data-tralbum parsing.
+func (s *Source) AvailableParsers() []string {
+	return []string{source.ParserBcnativeLink, source.ParserYtdlpPipe, source.ParserYtdlpLink}
+}
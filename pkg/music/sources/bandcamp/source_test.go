@@ -0,0 +1,125 @@
+package bandcamp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/bandcampapi"
+	"github.com/keshon/melodix/pkg/music/sources"
+)
+
+// pageServer returns a Source whose client serves the bandcampapi fixtures.
+func pageServer(t *testing.T) *Source {
+	t.Helper()
+	pages := map[string]string{
+		"/album/night-lines": "album.html",
+		"/track/low-tide":    "track.html",
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		b, err := os.ReadFile("../../bandcampapi/testdata/" + name)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, _ = w.Write(b)
+	}))
+	t.Cleanup(srv.Close)
+	// Every request goes to the test server whatever its host, so the real
+	// bandcamp.com URLs Match insists on still resolve here.
+	client := srv.Client()
+	client.Transport = rewriteHost{target: srv.URL, next: client.Transport}
+	return &Source{api: &bandcampapi.Client{HTTP: client}}
+}
+
+type rewriteHost struct {
+	target string
+	next   http.RoundTripper
+}
+
+func (rt rewriteHost) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.URL.Scheme = "http"
+	r.URL.Host = strings.TrimPrefix(rt.target, "http://")
+	return rt.next.RoundTrip(r)
+}
+
+func TestMatch(t *testing.T) {
+	s := &Source{}
+	cases := map[string]bool{
+		"https://harbourlights.bandcamp.com/album/night-lines":  true,
+		"https://harbourlights.bandcamp.com/track/low-tide?t=1": true,
+		"https://HarbourLights.Bandcamp.com/track/low-tide":     true,
+		"https://harbourlights.bandcamp.com/":                   false,
+		"https://harbourlights.bandcamp.com/music":              false,
+		"https://bandcamp.com/discover":                         false,
+		"https://music.harbourlights.com/album/night-lines":     false,
+		"harbourlights.bandcamp.com/track/low-tide":             false,
+	}
+	for input, want := range cases {
+		if got := s.Match(input); got != want {
+			t.Errorf("Match(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestResolveExpandsAlbum(t *testing.T) {
+	s := pageServer(t)
+	got, err := s.Resolve("https://harbourlights.bandcamp.com/album/night-lines", "")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d tracks, want the 2 streamable ones: %+v", len(got), got)
+	}
+	first := got[0]
+	if first.URL != "https://harbourlights.bandcamp.com/track/low-tide" || first.Title != "Harbour Lights - Low Tide" {
+		t.Fatalf("first = %+v; tracks are queued by their page, not the expiring stream", first)
+	}
+	if first.Duration != 212440*time.Millisecond || first.SourceName != sources.Bandcamp {
+		t.Fatalf("first = %+v", first)
+	}
+	if first.AvailableParsers[0] != sources.ParserBcnativeLink {
+		t.Fatalf("parsers = %v", first.AvailableParsers)
+	}
+}
+
+func TestResolveTrackAndParserChoice(t *testing.T) {
+	s := pageServer(t)
+	got, err := s.Resolve("https://harbourlights.bandcamp.com/track/low-tide", sources.ParserYtdlpLink)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if len(got) != 1 || got[0].AvailableParsers[0] != sources.ParserYtdlpLink {
+		t.Fatalf("got %+v", got)
+	}
+	if _, err := s.Resolve("https://harbourlights.bandcamp.com/track/low-tide", sources.ParserFFmpegLink); err == nil {
+		t.Fatal("an unsupported parser must be refused")
+	}
+	if _, err := s.Resolve("https://harbourlights.bandcamp.com/track/gone", ""); err == nil {
+		t.Fatal("a missing page must fail")
+	}
+}
+
+func TestResolveAlbumWithNothingToStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<script data-tralbum="{&quot;item_type&quot;:&quot;album&quot;,&quot;trackinfo&quot;:[{&quot;title&quot;:&quot;Paid&quot;,&quot;title_link&quot;:&quot;/track/paid&quot;,&quot;file&quot;:null}]}"></script>`))
+	}))
+	t.Cleanup(srv.Close)
+	client := srv.Client()
+	client.Transport = rewriteHost{target: srv.URL, next: client.Transport}
+	s := &Source{api: &bandcampapi.Client{HTTP: client}}
+
+	if _, err := s.Resolve("https://x.bandcamp.com/album/paid", ""); !errors.Is(err, bandcampapi.ErrNotStreamable) {
+		t.Fatalf("err = %v, want ErrNotStreamable", err)
+	}
+}
@@ -0,0 +1,190 @@
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// appleLink is a parsed music.apple.com link.
+type appleLink struct {
+	Kind string // "track", "album" or "playlist"
+	// Path is the link's path, which the page is fetched at.
+	Path string
+	// SongID is the ?i= parameter of an album link, which names one of its
+	// songs: the share link for a song usually takes that form.
+	SongID string
+}
+
+// parseAppleLink recognises music.apple.com/<cc>/{song,album,playlist}/<slug>/<id>.
+func parseAppleLink(raw string) (appleLink, bool) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return appleLink{}, false
+	}
+	if h := strings.ToLower(u.Hostname()); h != "music.apple.com" && h != "geo.music.apple.com" {
+		return appleLink{}, false
+	}
+	segs := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(segs) < 3 {
+		return appleLink{}, false
+	}
+	link := appleLink{Path: "/" + strings.Join(segs, "/")}
+	switch segs[1] {
+	case "song":
+		link.Kind = "track"
+	case "album":
+		link.Kind = "album"
+		if i := u.Query().Get("i"); i != "" {
+			link.Kind, link.SongID = "track", i
+		}
+	case "playlist":
+		link.Kind = "playlist"
+	default:
+		return appleLink{}, false
+	}
+	return link, true
+}
+
+var ldJSONRe = regexp.MustCompile(`(?s)<script[^>]*type="application/ld\+json"[^>]*>(.*?)</script>`)
+
+// ldNode is the part of a schema.org node Apple Music pages describe music
+// with: MusicRecording, MusicAlbum, MusicPlaylist, and MusicComposition
+// wrapping a recording under "audio".
+type ldNode struct {
+	Type     string          `json:"@type"`
+	Name     string          `json:"name"`
+	URL      string          `json:"url"`
+	Duration string          `json:"duration"` // ISO 8601, e.g. PT3M34S
+	ISRC     string          `json:"isrcCode"`
+	ByArtist json.RawMessage `json:"byArtist"` // one node or a list
+	Tracks   []ldNode        `json:"tracks"`
+	Track    []ldNode        `json:"track"`
+	Audio    *ldNode         `json:"audio"`
+}
+
+// appleRelease reads the link's web page. Apple renders the page's music as
+// schema.org JSON-LD for search engines, which needs no developer token.
+func appleRelease(ctx context.Context, client *http.Client, link appleLink) (*Release, error) {
+	body, err := fetchPage(ctx, client, endpoint(&appleMusicURL, DefaultAppleMusicURL)+link.Path)
+	if err != nil {
+		return nil, err
+	}
+	return parseApplePage(body, link)
+}
+
+func parseApplePage(body []byte, link appleLink) (*Release, error) {
+	for _, m := range ldJSONRe.FindAllSubmatch(body, -1) {
+		var n ldNode
+		if err := json.Unmarshal(bytes.TrimSpace(m[1]), &n); err != nil {
+			continue
+		}
+		if rel := appleFromNode(n, link); rel != nil {
+			return rel, nil
+		}
+	}
+	return nil, ErrNoMetadata
+}
+
+func appleFromNode(n ldNode, link appleLink) *Release {
+	switch n.Type {
+	case "MusicRecording", "MusicComposition":
+		rec := n
+		if n.Audio != nil {
+			rec = *n.Audio
+		}
+		t := appleMeta(rec, nil)
+		if t.Title == "" {
+			return nil
+		}
+		return &Release{Kind: "track", Title: t.Title, Tracks: []Meta{t}}
+
+	case "MusicAlbum", "MusicPlaylist":
+		albumArtists := ldArtists(n.ByArtist)
+		rel := &Release{Kind: link.Kind, Title: strings.TrimSpace(n.Name)}
+		for _, tn := range append(n.Tracks, n.Track...) {
+			t := appleMeta(tn, albumArtists)
+			if t.Title == "" {
+				continue
+			}
+			if link.SongID != "" {
+				if !strings.HasSuffix(strings.TrimRight(t.URL, "/"), "/"+link.SongID) {
+					continue
+				}
+				rel.Title = t.Title
+			}
+			rel.Tracks = append(rel.Tracks, t)
+		}
+		if len(rel.Tracks) == 0 {
+			return nil
+		}
+		return rel
+	}
+	return nil
+}
+
+// appleMeta converts one recording node; fallbackArtists stand in when the
+// node names none (album tracks usually leave the artist to the album).
+func appleMeta(n ldNode, fallbackArtists []string) Meta {
+	t := Meta{
+		Title:    strings.TrimSpace(n.Name),
+		Artists:  ldArtists(n.ByArtist),
+		Duration: parseISODuration(n.Duration),
+		ISRC:     n.ISRC,
+		URL:      n.URL,
+	}
+	if len(t.Artists) == 0 {
+		t.Artists = fallbackArtists
+	}
+	return t
+}
+
+func ldArtists(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var list []ldNode
+	if err := json.Unmarshal(raw, &list); err != nil {
+		var one ldNode
+		if json.Unmarshal(raw, &one) != nil {
+			return nil
+		}
+		list = []ldNode{one}
+	}
+	var out []string
+	for _, a := range list {
+		if name := strings.TrimSpace(a.Name); name != "" {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+var isoDurationRe = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// parseISODuration reads the ISO 8601 durations schema.org uses (PT1H2M3S);
+// anything else is zero.
+func parseISODuration(s string) time.Duration {
+	m := isoDurationRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0
+	}
+	var d time.Duration
+	for i, unit := range []time.Duration{24 * time.Hour, time.Hour, time.Minute} {
+		if m[i+1] != "" {
+			n, _ := strconv.Atoi(m[i+1])
+			d += time.Duration(n) * unit
+		}
+	}
+	if m[4] != "" {
+		f, _ := strconv.ParseFloat(m[4], 64)
+		d += time.Duration(f * float64(time.Second))
+	}
+	return d
+}
@@ -0,0 +1,115 @@
+// Package catalog is the source for links to streaming catalogues that
+// Melodix cannot play from: Spotify and Apple Music. It is metadata only. A
+// link's public embed data gives each track's title, artists, duration and,
+// where present, ISRC, and every track is then matched to a YouTube video,
+// which is what plays. The original link stays on the track for display.
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Name is this source's identifier (equals sources.Catalog).
+const Name = "catalog"
+
+// Default endpoints. Embed data is fetched from these rather than from the
+// link's own host, so a local stand-in can serve it (see SetSpotifyURL and
+// SetAppleMusicURL).
+const (
+	DefaultSpotifyURL    = "https://open.spotify.com"
+	DefaultAppleMusicURL = "https://music.apple.com"
+)
+
+// maxPageBytes bounds an embed page read. A long playlist's embed runs to a
+// few hundred kilobytes.
+const maxPageBytes = 8 << 20
+
+// userAgent is a browser string: both services serve a stripped page, without
+// the embedded data, to clients they do not recognise.
+const userAgent = "Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0"
+
+var (
+	// ErrNoMetadata means the page carried no track data this package can read.
+	ErrNoMetadata = errors.New("catalog: no track metadata in the page")
+	// ErrNoMatch means no YouTube video was close enough to the track.
+	ErrNoMatch = errors.New("catalog: no YouTube video matches the track")
+)
+
+var spotifyURL, appleMusicURL atomic.Pointer[string]
+
+// SetSpotifyURL points embed fetches for Spotify links at another server ("" restores
+// DefaultSpotifyURL). Call once at process startup.
+func SetSpotifyURL(u string) { storeEndpoint(&spotifyURL, u) }
+
+// SetAppleMusicURL points page fetches for Apple Music links at another server ("" restores
+// DefaultAppleMusicURL). Call once at process startup.
+func SetAppleMusicURL(u string) { storeEndpoint(&appleMusicURL, u) }
+
+func storeEndpoint(p *atomic.Pointer[string], u string) {
+	u = strings.TrimRight(strings.TrimSpace(u), "/")
+	p.Store(&u)
+}
+
+func endpoint(p *atomic.Pointer[string], def string) string {
+	if u := p.Load(); u != nil && *u != "" {
+		return *u
+	}
+	return def
+}
+
+// Meta is one catalogue track as the service describes it.
+type Meta struct {
+	Title    string
+	Artists  []string
+	Duration time.Duration
+	// ISRC is the recording's international code, when the data carries one.
+	ISRC string
+	// URL is the track's own page on the service; empty when the data does
+	// not say, in which case the release's link stands in.
+	URL string
+}
+
+// Label is how the track reads in a queue: "Artist, Artist - Title".
+func (m Meta) Label() string {
+	if len(m.Artists) == 0 {
+		return m.Title
+	}
+	return strings.Join(m.Artists, ", ") + " - " + m.Title
+}
+
+// Release is what a link names: one track, or an album or playlist of them.
+type Release struct {
+	// Kind is "track", "album" or "playlist".
+	Kind   string
+	Title  string
+	Tracks []Meta
+}
+
+// fetchPage GETs a page with the browser user agent and returns its body.
+func fetchPage(ctx context.Context, client *http.Client, pageURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("catalog: request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("catalog: fetch %s: %w", pageURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("catalog: fetch %s: %s", pageURL, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPageBytes))
+	if err != nil {
+		return nil, fmt.Errorf("catalog: read page: %w", err)
+	}
+	return body, nil
+}
@@ -0,0 +1,148 @@
+package catalog
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	b, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestParseSpotifyLink(t *testing.T) {
+	cases := map[string]spotifyLink{
+		"https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC":              {Kind: "track", ID: "4uLU6hMCjMI75M1A2tKUQC"},
+		"https://open.spotify.com/intl-de/album/6dVIqQ8qmQ5GBnJ9shOYGE?si=x": {Kind: "album", ID: "6dVIqQ8qmQ5GBnJ9shOYGE"},
+		"https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M":           {Kind: "playlist", ID: "37i9dQZF1DXcBWIGoYBM5M"},
+	}
+	for in, want := range cases {
+		got, ok := parseSpotifyLink(in)
+		if !ok || got != want {
+			t.Errorf("parseSpotifyLink(%q) = %+v, %v; want %+v", in, got, ok, want)
+		}
+	}
+	for _, in := range []string{
+		"https://open.spotify.com/artist/1",
+		"https://open.spotify.com/track/",
+		"https://example.com/track/abc",
+		"spotify:track:4uLU6hMCjMI75M1A2tKUQC",
+	} {
+		if _, ok := parseSpotifyLink(in); ok {
+			t.Errorf("parseSpotifyLink(%q) matched", in)
+		}
+	}
+}
+
+func TestParseAppleLink(t *testing.T) {
+	cases := map[string]appleLink{
+		"https://music.apple.com/gb/song/low-tide/1500000001":             {Kind: "track", Path: "/gb/song/low-tide/1500000001"},
+		"https://music.apple.com/gb/album/night-lines/1500000000":         {Kind: "album", Path: "/gb/album/night-lines/1500000000"},
+		"https://music.apple.com/gb/album/night-lines/1500000000?i=15002": {Kind: "track", Path: "/gb/album/night-lines/1500000000", SongID: "15002"},
+		"https://music.apple.com/us/playlist/todays-hits/pl.f4d106fed2bd": {Kind: "playlist", Path: "/us/playlist/todays-hits/pl.f4d106fed2bd"},
+	}
+	for in, want := range cases {
+		got, ok := parseAppleLink(in)
+		if !ok || got != want {
+			t.Errorf("parseAppleLink(%q) = %+v, %v; want %+v", in, got, ok, want)
+		}
+	}
+	for _, in := range []string{
+		"https://music.apple.com/gb/artist/harbour-lights/1",
+		"https://music.apple.com/gb",
+		"https://podcasts.apple.com/gb/podcast/x/id1",
+	} {
+		if _, ok := parseAppleLink(in); ok {
+			t.Errorf("parseAppleLink(%q) matched", in)
+		}
+	}
+}
+
+func TestParseSpotifyEmbed(t *testing.T) {
+	rel, err := parseSpotifyEmbed(readFixture(t, "spotify_track.html"), spotifyLink{Kind: "track", ID: "4uLU6hMCjMI75M1A2tKUQC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := rel.Tracks[0]
+	if got.Label() != "Harbour Lights, Mara Quill - Low Tide" || got.Duration != 214*time.Second || got.ISRC != "GBXYZ2400001" {
+		t.Errorf("track = %+v", got)
+	}
+	if got.URL != "https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC" {
+		t.Errorf("URL = %q", got.URL)
+	}
+
+	rel, err = parseSpotifyEmbed(readFixture(t, "spotify_album.html"), spotifyLink{Kind: "album", ID: "6dVIqQ8qmQ5GBnJ9shOYGE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rel.Title != "Night Lines" || len(rel.Tracks) != 3 {
+		t.Fatalf("release = %q with %d tracks", rel.Title, len(rel.Tracks))
+	}
+	first := rel.Tracks[0]
+	if !slices.Equal(first.Artists, []string{"Harbour Lights", "Mara Quill"}) {
+		t.Errorf("artists = %q, want the subtitle split on its non-breaking comma", first.Artists)
+	}
+	if rel.Tracks[1].Duration != 188500*time.Millisecond || rel.Tracks[1].URL != "https://open.spotify.com/track/t2" {
+		t.Errorf("second track = %+v", rel.Tracks[1])
+	}
+
+	if _, err := parseSpotifyEmbed([]byte("<html></html>"), spotifyLink{Kind: "track"}); !errors.Is(err, ErrNoMetadata) {
+		t.Errorf("page without data: err = %v, want ErrNoMetadata", err)
+	}
+}
+
+func TestParseApplePage(t *testing.T) {
+	rel, err := parseApplePage(readFixture(t, "apple_song.html"), appleLink{Kind: "track"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := rel.Tracks[0]
+	if got.Label() != "Harbour Lights - Low Tide" || got.Duration != 214*time.Second || got.ISRC != "GBXYZ2400001" {
+		t.Errorf("song = %+v", got)
+	}
+
+	rel, err = parseApplePage(readFixture(t, "apple_album.html"), appleLink{Kind: "album"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rel.Title != "Night Lines" || len(rel.Tracks) != 2 {
+		t.Fatalf("album = %q with %d tracks", rel.Title, len(rel.Tracks))
+	}
+	if rel.Tracks[0].Label() != "Harbour Lights - Low Tide" {
+		t.Errorf("first track = %q, want the album's artist", rel.Tracks[0].Label())
+	}
+	if rel.Tracks[1].Label() != "Mara Quill - Lanterns" || rel.Tracks[1].Duration != 188500*time.Millisecond {
+		t.Errorf("second track = %+v, want its own artist", rel.Tracks[1])
+	}
+
+	rel, err = parseApplePage(readFixture(t, "apple_album.html"), appleLink{Kind: "track", SongID: "1500000002"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rel.Tracks) != 1 || rel.Tracks[0].Title != "Lanterns" {
+		t.Errorf("?i= picked %+v, want Lanterns alone", rel.Tracks)
+	}
+}
+
+func TestParseISODuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"PT3M34S":  3*time.Minute + 34*time.Second,
+		"PT1H2M3S": time.Hour + 2*time.Minute + 3*time.Second,
+		"PT45.5S":  45500 * time.Millisecond,
+		"P1DT1S":   24*time.Hour + time.Second,
+		"3:34":     0,
+		"":         0,
+	}
+	for in, want := range cases {
+		if got := parseISODuration(in); got != want {
+			t.Errorf("parseISODuration(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
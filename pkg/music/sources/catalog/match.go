@@ -0,0 +1,126 @@
+package catalog
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+
+	source "github.com/keshon/melodix/pkg/music/sources"
+)
+
+const (
+	// matchCandidates is how many search hits are weighed per query.
+	matchCandidates = 6
+	// minMatchScore is the score below which no hit is taken: better a gap in
+	// an album than somebody else's song in it.
+	minMatchScore = 0.55
+	// durationExact and durationFar bound the duration score: within
+	// durationExact is full credit, beyond durationFar none. Music videos run
+	// long with intros and outros, hence the generous far end.
+	durationExact = 3 * time.Second
+	durationFar   = 30 * time.Second
+	// variantPenalty is taken off for each variant word (see variantWords) a
+	// hit has and the catalogue title does not.
+	variantPenalty = 0.5
+)
+
+// variantWords mark a different recording of the same song. "Song (Live)"
+// finding "Song (Live)" is right; "Song" finding it is not.
+var variantWords = []string{
+	"live", "cover", "remix", "karaoke", "instrumental", "acoustic",
+	"nightcore", "slowed", "reverb", "sped", "8d", "reaction",
+}
+
+// bestMatch searches for the track and returns the highest-scoring hit, or
+// ErrNoMatch. An ISRC, when known, is tried first: YouTube's auto-generated
+// "Topic" uploads are indexed by it, and those are the label's own audio.
+func bestMatch(ctx context.Context, search source.Searcher, m Meta) (source.SearchResult, error) {
+	var queries []string
+	if m.ISRC != "" {
+		queries = append(queries, m.ISRC)
+	}
+	queries = append(queries, strings.TrimSpace(strings.Join(m.Artists, " ")+" "+m.Title))
+
+	var lastErr error
+	for _, q := range queries {
+		hits, err := source.SearchContext(ctx, search, q, matchCandidates)
+		if ctx.Err() != nil {
+			return source.SearchResult{}, ctx.Err()
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		best, bestScore := source.SearchResult{}, minMatchScore
+		for _, h := range hits {
+			if s := matchScore(m, h); s >= bestScore {
+				best, bestScore = h, s
+			}
+		}
+		if best.URL != "" {
+			return best, nil
+		}
+	}
+	if lastErr != nil {
+		return source.SearchResult{}, lastErr
+	}
+	return source.SearchResult{}, ErrNoMatch
+}
+
+// matchScore rates a hit for a track between 0 and 1, more or less: title and
+// artist words found weigh 0.6, duration closeness 0.4, less variantPenalty
+// for each variant word the track does not share.
+func matchScore(m Meta, h source.SearchResult) float64 {
+	want := words(m.Title + " " + strings.Join(m.Artists, " "))
+	got := words(h.Title + " " + h.Author)
+
+	found := 0
+	for w := range want {
+		if got[w] {
+			found++
+		}
+	}
+	text := 0.0
+	if len(want) > 0 {
+		text = float64(found) / float64(len(want))
+	}
+
+	score := 0.6*text + 0.4*durationScore(m.Duration, h.Duration)
+
+	title := words(m.Title)
+	for _, v := range variantWords {
+		if got[v] && !title[v] {
+			score -= variantPenalty
+		}
+	}
+	return score
+}
+
+// durationScore is 1 for durations within durationExact of each other, 0
+// beyond durationFar, and linear between. An unknown duration scores half:
+// it neither confirms nor rules out the hit.
+func durationScore(want, got time.Duration) float64 {
+	if want <= 0 || got <= 0 {
+		return 0.5
+	}
+	diff := (want - got).Abs()
+	switch {
+	case diff <= durationExact:
+		return 1
+	case diff >= durationFar:
+		return 0
+	}
+	return 1 - float64(diff-durationExact)/float64(durationFar-durationExact)
+}
+
+// words is the set of lower-cased letter-and-digit runs in s.
+func words(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		set[w] = true
+	}
+	return set
+}
@@ -0,0 +1,105 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/sources"
+)
+
+// fakeSearch answers queries from a fixed table, or fails them all with err,
+// and records what was asked.
+type fakeSearch struct {
+	hits map[string][]sources.SearchResult
+	err  error
+
+	mu      sync.Mutex
+	queries []string
+}
+
+func (f *fakeSearch) Search(query string, limit int) ([]sources.SearchResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.queries = append(f.queries, query)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.hits[query], nil
+}
+
+var lowTide = Meta{Title: "Low Tide", Artists: []string{"Harbour Lights"}, Duration: 214 * time.Second}
+
+func TestMatchScorePrefersTheOriginal(t *testing.T) {
+	original := sources.SearchResult{Title: "Harbour Lights - Low Tide (Official Audio)", Author: "Harbour Lights", Duration: 215 * time.Second}
+	live := sources.SearchResult{Title: "Harbour Lights - Low Tide (Live at the Pier)", Author: "Harbour Lights", Duration: 214 * time.Second}
+	video := sources.SearchResult{Title: "Low Tide", Author: "Harbour Lights", Duration: 250 * time.Second}
+	other := sources.SearchResult{Title: "Low Tide", Author: "Someone Else", Duration: 120 * time.Second}
+
+	o, l, v, x := matchScore(lowTide, original), matchScore(lowTide, live), matchScore(lowTide, video), matchScore(lowTide, other)
+	if !(o > v && v > l) {
+		t.Errorf("original %.2f, long music video %.2f, live %.2f: want that order", o, v, l)
+	}
+	if o < minMatchScore || x >= minMatchScore {
+		t.Errorf("original %.2f, unrelated %.2f: want only the original over %.2f", o, x, minMatchScore)
+	}
+
+	liveTrack := Meta{Title: "Low Tide (Live)", Artists: []string{"Harbour Lights"}, Duration: 214 * time.Second}
+	if matchScore(liveTrack, live) < minMatchScore {
+		t.Errorf("a live track's own live upload scores %.2f", matchScore(liveTrack, live))
+	}
+}
+
+func TestDurationScore(t *testing.T) {
+	cases := []struct {
+		want, got time.Duration
+		score     float64
+	}{
+		{200 * time.Second, 202 * time.Second, 1},
+		{200 * time.Second, 260 * time.Second, 0},
+		{200 * time.Second, 0, 0.5},
+		{200 * time.Second, 200*time.Second + durationExact + (durationFar-durationExact)/2, 0.5},
+	}
+	for _, c := range cases {
+		if got := durationScore(c.want, c.got); got != c.score {
+			t.Errorf("durationScore(%v, %v) = %.2f, want %.2f", c.want, c.got, got, c.score)
+		}
+	}
+}
+
+func TestBestMatchTriesISRCFirst(t *testing.T) {
+	topic := sources.SearchResult{URL: "https://www.youtube.com/watch?v=topic", Title: "Low Tide", Author: "Harbour Lights - Topic", Duration: 214 * time.Second}
+	search := &fakeSearch{hits: map[string][]sources.SearchResult{"GBXYZ2400001": {topic}}}
+	m := lowTide
+	m.ISRC = "GBXYZ2400001"
+
+	hit, err := bestMatch(context.Background(), search, m)
+	if err != nil || hit.URL != topic.URL {
+		t.Fatalf("bestMatch = %+v, %v; want the ISRC hit", hit, err)
+	}
+	if len(search.queries) != 1 {
+		t.Errorf("queries = %q, want the ISRC alone", search.queries)
+	}
+}
+
+func TestBestMatchFallsBackToText(t *testing.T) {
+	wrong := sources.SearchResult{URL: "https://www.youtube.com/watch?v=wrong", Title: "Unrelated Vlog", Author: "Someone", Duration: 600 * time.Second}
+	right := sources.SearchResult{URL: "https://www.youtube.com/watch?v=right", Title: "Harbour Lights - Low Tide", Author: "Harbour Lights", Duration: 214 * time.Second}
+	search := &fakeSearch{hits: map[string][]sources.SearchResult{
+		"GBXYZ2400001":            {wrong},
+		"Harbour Lights Low Tide": {wrong, right},
+	}}
+	m := lowTide
+	m.ISRC = "GBXYZ2400001"
+
+	hit, err := bestMatch(context.Background(), search, m)
+	if err != nil || hit.URL != right.URL {
+		t.Fatalf("bestMatch = %+v, %v; want the text hit", hit, err)
+	}
+
+	if _, err := bestMatch(context.Background(), &fakeSearch{}, lowTide); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("no hits: err = %v, want ErrNoMatch", err)
+	}
+}
@@ -0,0 +1,210 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	source "github.com/keshon/melodix/pkg/music/sources"
+	"github.com/keshon/melodix/pkg/music/sources/youtube"
+)
+
+const (
+	// matchPageSize is how many tracks of an album or playlist are matched per
+	// page. Matching costs a search per track, so pages are small: the queue
+	// reaching the entry waits for one page, not for the whole album.
+	matchPageSize = 5
+	// matchWorkers is how many of a page's searches run at once.
+	matchWorkers = 5
+)
+
+// Source resolves Spotify and Apple Music links to YouTube tracks.
+type Source struct {
+	client *http.Client
+	search source.Searcher
+}
+
+// New creates the catalogue source, matching through YouTube search.
+func New() *Source {
+	return &Source{
+		client: &http.Client{Timeout: 15 * time.Second},
+		search: youtube.NewSearcher(),
+	}
+}
+
+func (c *Source) Match(input string) bool {
+	if _, ok := parseSpotifyLink(input); ok {
+		return true
+	}
+	_, ok := parseAppleLink(input)
+	return ok
+}
+
+func (c *Source) Resolve(input string, selectedParser string) ([]source.TrackInfo, error) {
+	return c.ResolveContext(context.Background(), input, selectedParser)
+}
+
+// ResolveContext is Resolve with the page fetch and, for a single track, its
+// match bound to ctx.
+func (c *Source) ResolveContext(ctx context.Context, input string, selectedParser string) ([]source.TrackInfo, error) {
+	parsers := c.AvailableParsers()
+
+	if selectedParser == "" {
+		selectedParser = parsers[0]
+	}
+	if !slices.Contains(parsers, selectedParser) {
+		return nil, errors.New(Name + " source does not support " + selectedParser + " parser")
+	}
+	preferred := source.PreferParser(parsers, selectedParser)
+
+	input = strings.TrimSpace(input)
+	var (
+		rel *Release
+		err error
+	)
+	if link, ok := parseSpotifyLink(input); ok {
+		rel, err = spotifyRelease(ctx, c.client, link)
+	} else if link, ok := parseAppleLink(input); ok {
+		rel, err = appleRelease(ctx, c.client, link)
+	} else {
+		return nil, errors.New("catalog: not a Spotify or Apple Music link")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if rel.Kind == "track" {
+		t := rel.Tracks[0]
+		hit, err := bestMatch(ctx, c.search, t)
+		if err != nil {
+			return nil, err
+		}
+		return []source.TrackInfo{matchedTrack(t, hit, input, preferred)}, nil
+	}
+	return []source.TrackInfo{c.lazyRelease(input, rel, preferred)}, nil
+}
+
+// lazyRelease queues an album or playlist as one lazy entry (see
+// sources.LazyList) whose pages are matched as the queue reaches them, so
+// resolving the link costs one page fetch however long the tracklist is. The
+// continuation token is the index of the next track to match.
+func (c *Source) lazyRelease(link string, rel *Release, parsers []string) source.TrackInfo {
+	page := func(ctx context.Context, token string) ([]source.TrackInfo, string, error) {
+		from, err := strconv.Atoi(token)
+		if err != nil || from >= len(rel.Tracks) {
+			return nil, "", nil
+		}
+		to := min(from+matchPageSize, len(rel.Tracks))
+		next := ""
+		if to < len(rel.Tracks) {
+			next = strconv.Itoa(to)
+		}
+		matched, err := c.matchAll(ctx, rel.Tracks[from:to], link, parsers)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, "", ctxErr
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		return matched, next, nil
+	}
+
+	title := rel.Title
+	if title == "" {
+		title = "Untitled " + rel.Kind
+	}
+	return source.TrackInfo{
+		URL:              link,
+		Title:            title,
+		SourceName:       Name,
+		AvailableParsers: parsers,
+		OriginURL:        link,
+		Lazy:             source.NewLazyListContext(link, title, len(rel.Tracks), nil, "0", page),
+	}
+}
+
+// matchAll matches tracks concurrently and keeps the order. Tracks without a
+// match are logged and left out rather than failing the page, unless none
+// matched and a search failed: a page lost to a search outage is reported as
+// that, not as a page of tracks YouTube does not have.
+func (c *Source) matchAll(ctx context.Context, tracks []Meta, link string, parsers []string) ([]source.TrackInfo, error) {
+	matched := make([]*source.TrackInfo, len(tracks))
+	errs := make([]error, len(tracks))
+	sem := make(chan struct{}, matchWorkers)
+	var wg sync.WaitGroup
+	for i, t := range tracks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			hit, err := bestMatch(ctx, c.search, t)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			ti := matchedTrack(t, hit, link, parsers)
+			matched[i] = &ti
+		}()
+	}
+	wg.Wait()
+
+	l := logger()
+	out := make([]source.TrackInfo, 0, len(tracks))
+	var searchErr error
+	for i, ti := range matched {
+		if ti != nil {
+			out = append(out, *ti)
+			continue
+		}
+		if ctx.Err() == nil {
+			l.Warn().Str("link", link).Str("track", tracks[i].Label()).Err(errs[i]).Msg("catalog_track_unmatched")
+		}
+		if !errors.Is(errs[i], ErrNoMatch) {
+			searchErr = errs[i]
+		}
+	}
+	if len(out) == 0 && searchErr != nil {
+		return nil, searchErr
+	}
+	return out, nil
+}
+
+// matchedTrack is the YouTube track a catalogue track plays as. It keeps the
+// catalogue's title and duration, which are cleaner than an upload's, and
+// the track's own link (the release's when it has none) as OriginURL.
+func matchedTrack(t Meta, hit source.SearchResult, link string, parsers []string) source.TrackInfo {
+	origin := t.URL
+	if origin == "" {
+		origin = link
+	}
+	d := t.Duration
+	if d == 0 {
+		d = hit.Duration
+	}
+	return source.TrackInfo{
+		URL:              hit.URL,
+		Title:            t.Label(),
+		SourceName:       youtube.Name,
+		AvailableParsers: parsers,
+		Duration:         d,
+		OriginURL:        origin,
+	}
+}
+
+func (c *Source) SourceName() string {
+	return Name
+}
+
+func (c *Source) DisplayName() string {
+	return "Spotify / Apple Music link"
+}
+
+// AvailableParsers are YouTube's: a catalogue track plays as its match.
+func (c *Source) AvailableParsers() []string {
+	return (&youtube.Source{}).AvailableParsers()
+}
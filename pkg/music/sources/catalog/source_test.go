@@ -0,0 +1,163 @@
+package catalog
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/sources"
+	"github.com/keshon/melodix/pkg/music/sources/youtube"
+)
+
+// standIn serves the fixtures at the paths the services use and points both
+// endpoints at it for the test.
+func standIn(t *testing.T) *http.Client {
+	t.Helper()
+	pages := map[string]string{
+		"/embed/track/4uLU6hMCjMI75M1A2tKUQC": "spotify_track.html",
+		"/embed/album/6dVIqQ8qmQ5GBnJ9shOYGE": "spotify_album.html",
+		"/gb/album/night-lines/1500000000":    "apple_album.html",
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		b, err := os.ReadFile("testdata/" + name)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, _ = w.Write(b)
+	}))
+	t.Cleanup(srv.Close)
+	SetSpotifyURL(srv.URL)
+	SetAppleMusicURL(srv.URL + "/")
+	t.Cleanup(func() { SetSpotifyURL(""); SetAppleMusicURL("") })
+	return srv.Client()
+}
+
+func video(id, title, author string, d time.Duration) sources.SearchResult {
+	return sources.SearchResult{ID: id, URL: youtube.VideoURL(id), Title: title, Author: author, Duration: d}
+}
+
+func TestMatch(t *testing.T) {
+	s := &Source{}
+	cases := map[string]bool{
+		"https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC":   true,
+		"https://music.apple.com/gb/album/night-lines/1500000000": true,
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ":             false,
+		"low tide harbour lights":                                 false,
+	}
+	for in, want := range cases {
+		if got := s.Match(in); got != want {
+			t.Errorf("Match(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestResolveSpotifyTrack(t *testing.T) {
+	search := &fakeSearch{hits: map[string][]sources.SearchResult{
+		"GBXYZ2400001": {video("abc", "Low Tide", "Harbour Lights - Topic", 214*time.Second)},
+	}}
+	s := &Source{client: standIn(t), search: search}
+
+	link := "https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC?si=share"
+	tracks, err := s.Resolve(link, sources.ParserYtdlpLink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracks) != 1 {
+		t.Fatalf("got %d tracks, want 1", len(tracks))
+	}
+	got := tracks[0]
+	if got.URL != youtube.VideoURL("abc") || got.SourceName != youtube.Name {
+		t.Errorf("track plays %q from %q, want the YouTube match", got.URL, got.SourceName)
+	}
+	if got.Title != "Harbour Lights, Mara Quill - Low Tide" || got.Duration != 214*time.Second {
+		t.Errorf("track = %q (%v), want the catalogue's title and duration", got.Title, got.Duration)
+	}
+	if got.OriginURL != "https://open.spotify.com/track/4uLU6hMCjMI75M1A2tKUQC" {
+		t.Errorf("OriginURL = %q", got.OriginURL)
+	}
+	if got.AvailableParsers[0] != sources.ParserYtdlpLink {
+		t.Errorf("parsers = %q, want the selected one first", got.AvailableParsers)
+	}
+}
+
+func TestResolveSpotifyAlbumIsLazy(t *testing.T) {
+	search := &fakeSearch{hits: map[string][]sources.SearchResult{
+		"Harbour Lights Mara Quill Low Tide": {video("t1", "Harbour Lights - Low Tide", "Harbour Lights", 214*time.Second)},
+		"Harbour Lights Lanterns":            {video("t2", "Lanterns (Official Video)", "Harbour Lights", 190*time.Second)},
+		// Only a cover of the third track exists; it should be left out.
+		"Harbour Lights Breakwater (Live)": {video("t3", "Breakwater cover", "Busker", 120*time.Second)},
+	}}
+	s := &Source{client: standIn(t), search: search}
+
+	link := "https://open.spotify.com/album/6dVIqQ8qmQ5GBnJ9shOYGE"
+	tracks, err := s.Resolve(link, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracks) != 1 || tracks[0].Lazy == nil {
+		t.Fatalf("got %+v, want one lazy entry", tracks)
+	}
+	if len(search.queries) != 0 {
+		t.Errorf("resolving searched %q; matching should wait for the queue", search.queries)
+	}
+	lazy := tracks[0].Lazy
+	if lazy.Title != "Night Lines" || tracks[0].OriginURL != link {
+		t.Errorf("entry = %q from %q", lazy.Title, tracks[0].OriginURL)
+	}
+
+	page, err := lazy.NextPage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 2 || page[0].URL != youtube.VideoURL("t1") || page[1].URL != youtube.VideoURL("t2") {
+		t.Fatalf("page = %+v, want t1 and t2 in album order", page)
+	}
+	if page[1].OriginURL != "https://open.spotify.com/track/t2" {
+		t.Errorf("OriginURL = %q, want the track's own link", page[1].OriginURL)
+	}
+	if !lazy.Done() {
+		t.Error("three-track album not done after its only page")
+	}
+}
+
+func TestResolveAppleSongFromAlbumLink(t *testing.T) {
+	search := &fakeSearch{hits: map[string][]sources.SearchResult{
+		"Mara Quill Lanterns": {video("t2", "Mara Quill - Lanterns", "Mara Quill", 189*time.Second)},
+	}}
+	s := &Source{client: standIn(t), search: search}
+
+	tracks, err := s.Resolve("https://music.apple.com/gb/album/night-lines/1500000000?i=1500000002", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracks) != 1 || tracks[0].Lazy != nil || tracks[0].URL != youtube.VideoURL("t2") {
+		t.Fatalf("got %+v, want the one song", tracks)
+	}
+	if tracks[0].OriginURL != "https://music.apple.com/gb/song/lanterns/1500000002" {
+		t.Errorf("OriginURL = %q", tracks[0].OriginURL)
+	}
+}
+
+// A page where every search failed reports the failure rather than coming back
+// empty, which would read as an album YouTube has none of.
+func TestAlbumPageReportsFailedSearches(t *testing.T) {
+	errDown := errors.New("search is down")
+	s := &Source{client: standIn(t), search: &fakeSearch{err: errDown}}
+
+	tracks, err := s.Resolve("https://open.spotify.com/album/6dVIqQ8qmQ5GBnJ9shOYGE", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tracks[0].Lazy.NextPage(); !errors.Is(err, errDown) {
+		t.Fatalf("NextPage = %v, want the search error", err)
+	}
+}
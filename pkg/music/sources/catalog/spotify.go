@@ -0,0 +1,152 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// spotifyLink is a parsed open.spotify.com link.
+type spotifyLink struct {
+	Kind string // "track", "album" or "playlist"
+	ID   string
+}
+
+// parseSpotifyLink recognises open.spotify.com/{track,album,playlist}/<id>,
+// with or without the /intl-xx/ locale prefix the site adds.
+func parseSpotifyLink(raw string) (spotifyLink, bool) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return spotifyLink{}, false
+	}
+	if h := strings.ToLower(u.Hostname()); h != "open.spotify.com" && h != "play.spotify.com" {
+		return spotifyLink{}, false
+	}
+	segs := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(segs) > 0 && strings.HasPrefix(segs[0], "intl-") {
+		segs = segs[1:]
+	}
+	if len(segs) != 2 || segs[1] == "" {
+		return spotifyLink{}, false
+	}
+	switch segs[0] {
+	case "track", "album", "playlist":
+		return spotifyLink{Kind: segs[0], ID: segs[1]}, true
+	}
+	return spotifyLink{}, false
+}
+
+var nextDataRe = regexp.MustCompile(`(?s)<script id="__NEXT_DATA__" type="application/json">(.*?)</script>`)
+
+// spotifyEntity is the embed player's entity (only the fields Melodix needs).
+type spotifyEntity struct {
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Title    string `json:"title"`
+	Duration int64  `json:"duration"` // milliseconds
+	ISRC     string `json:"isrc"`
+	Artists  []struct {
+		Name string `json:"name"`
+	} `json:"artists"`
+	TrackList []struct {
+		URI      string `json:"uri"`
+		Title    string `json:"title"`
+		Subtitle string `json:"subtitle"` // the artists, comma-separated
+		Duration int64  `json:"duration"`
+		ISRC     string `json:"isrc"`
+	} `json:"trackList"`
+}
+
+// spotifyRelease reads a link's embed page. The embed is the public player
+// any site can frame, and it carries the same data the player shows: no
+// account, token or API key is involved.
+func spotifyRelease(ctx context.Context, client *http.Client, link spotifyLink) (*Release, error) {
+	page := endpoint(&spotifyURL, DefaultSpotifyURL) + "/embed/" + link.Kind + "/" + url.PathEscape(link.ID)
+	body, err := fetchPage(ctx, client, page)
+	if err != nil {
+		return nil, err
+	}
+	return parseSpotifyEmbed(body, link)
+}
+
+func parseSpotifyEmbed(body []byte, link spotifyLink) (*Release, error) {
+	m := nextDataRe.FindSubmatch(body)
+	if m == nil {
+		return nil, ErrNoMetadata
+	}
+	var doc struct {
+		Props struct {
+			PageProps struct {
+				State struct {
+					Data struct {
+						Entity spotifyEntity `json:"entity"`
+					} `json:"data"`
+				} `json:"state"`
+			} `json:"pageProps"`
+		} `json:"props"`
+	}
+	if err := json.Unmarshal(m[1], &doc); err != nil {
+		return nil, fmt.Errorf("catalog: decode spotify embed: %w", err)
+	}
+	e := doc.Props.PageProps.State.Data.Entity
+	title := strings.TrimSpace(e.Name)
+	if title == "" {
+		title = strings.TrimSpace(e.Title)
+	}
+	rel := &Release{Kind: link.Kind, Title: title}
+
+	if link.Kind == "track" {
+		if title == "" {
+			return nil, ErrNoMetadata
+		}
+		t := Meta{
+			Title:    title,
+			Duration: time.Duration(e.Duration) * time.Millisecond,
+			ISRC:     e.ISRC,
+			URL:      "https://open.spotify.com/track/" + link.ID,
+		}
+		for _, a := range e.Artists {
+			if n := strings.TrimSpace(a.Name); n != "" {
+				t.Artists = append(t.Artists, n)
+			}
+		}
+		rel.Tracks = []Meta{t}
+		return rel, nil
+	}
+
+	for _, it := range e.TrackList {
+		t := Meta{
+			Title:    strings.TrimSpace(it.Title),
+			Artists:  splitArtists(it.Subtitle),
+			Duration: time.Duration(it.Duration) * time.Millisecond,
+			ISRC:     it.ISRC,
+		}
+		if id, ok := strings.CutPrefix(it.URI, "spotify:track:"); ok {
+			t.URL = "https://open.spotify.com/track/" + id
+		}
+		if t.Title != "" {
+			rel.Tracks = append(rel.Tracks, t)
+		}
+	}
+	if len(rel.Tracks) == 0 {
+		return nil, ErrNoMetadata
+	}
+	return rel, nil
+}
+
+// splitArtists undoes the embed's "A, B" subtitle. The embed joins with a
+// non-breaking space after the comma, which strings.Fields treats as space.
+func splitArtists(s string) []string {
+	var out []string
+	for _, a := range strings.Split(s, ",") {
+		if a = strings.Join(strings.Fields(a), " "); a != "" {
+			out = append(out, a)
+		}
+	}
+	return out
+}
@@ -0,0 +1,116 @@
+// Package direct is the source for links straight to an audio file — an .mp3
+// on a web server, a Discord attachment. Before it existed such links fell
+// through to radio and played as live streams: no duration, no seeking, and
+// nothing to cache.
+package direct
+
+import (
+	"context"
+	"errors"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/keshon/melodix/pkg/music/httpfile"
+	source "github.com/keshon/melodix/pkg/music/sources"
+)
+
+// Name is this source's identifier (equals sources.Direct).
+const Name = "direct"
+
+// Source resolves URLs of finite audio files.
+type Source struct{}
+
+// New creates the direct source.
+func New() *Source { return &Source{} }
+
+// Match claims URLs that answer like a file: a stated length, no ICY headers,
+// and an audio type by extension or Content-Type (httpfile.Stat). It costs a
+// HEAD request, the same as radio's check, and runs only for URLs YouTube and
+// SoundCloud did not claim.
+//
+// Discord attachments are claimed on sight: they are files by construction,
+// and their signed links may have expired, in which case a HEAD would fail
+// and hand a perfectly good attachment to radio.
+func (s *Source) Match(input string) bool {
+	if !source.IsURL(input) {
+		return false
+	}
+	input = strings.TrimSpace(input)
+	if httpfile.IsDiscordAttachment(input) {
+		return true
+	}
+	_, err := httpfile.Stat(input)
+	return err == nil
+}
+
+func (s *Source) Resolve(input string, selectedParser string) ([]source.TrackInfo, error) {
+	return s.ResolveContext(context.Background(), input, selectedParser)
+}
+
+// ResolveContext is Resolve with the file probe bound to ctx. Renewing an
+// expired attachment link goes through Discord's API and is not.
+func (s *Source) ResolveContext(ctx context.Context, input string, selectedParser string) ([]source.TrackInfo, error) {
+	parsers := s.AvailableParsers()
+	if selectedParser != "" && !slices.Contains(parsers, selectedParser) {
+		return nil, errors.New(Name + " source does not support " + selectedParser + " parser")
+	}
+	input = strings.TrimSpace(input)
+	if !source.IsURL(input) {
+		return nil, errors.New(Name + " source needs a link to an audio file")
+	}
+	// Renewed for this probe only; see directfile, which does the same at
+	// every open.
+	fresh, err := httpfile.Fresh(input)
+	if err != nil {
+		return nil, err
+	}
+	info, err := httpfile.ProbeContext(ctx, fresh)
+	if err != nil {
+		return nil, err
+	}
+
+	title := info.Tags.Title
+	if title == "" {
+		title = fileName(info.URL)
+	} else if info.Tags.Artist != "" {
+		title = info.Tags.Artist + " - " + title
+	}
+	// Only containers that can carry Opus are worth a passthrough attempt; for
+	// the rest PreferParser leaves it out, as the local source does.
+	if !info.Format.MayHoldOpus() {
+		parsers = []string{source.ParserDirectFFmpeg}
+	}
+	return []source.TrackInfo{
+		{
+			// The link as given, not the post-redirect one: that is what the
+			// cache key and history should remember.
+			URL:              input,
+			Title:            title,
+			SourceName:       Name,
+			AvailableParsers: source.PreferParser(parsers, selectedParser),
+		},
+	}, nil
+}
+
+func (s *Source) SourceName() string {
+	return Name
+}
+
+func (s *Source) DisplayName() string {
+	return "Direct file link"
+}
+
+func (s *Source) AvailableParsers() []string {
+	return []string{source.ParserDirectPassthrough, source.ParserDirectFFmpeg}
+}
+
+// fileName is the last path segment without its extension, the title of last
+// resort for an untagged file.
+func fileName(rawURL string) string {
+	if i := strings.IndexAny(rawURL, "?#"); i >= 0 {
+		rawURL = rawURL[:i]
+	}
+	base := path.Base(rawURL)
+	return strings.TrimSuffix(base, path.Ext(base))
+}
@@ -1,7 +1,10 @@
 package sources
 
+import "context"
+
 // Source turns user input (URL or search query) into playable track metadata.
-// Implementations: youtube, soundcloud, radio.
+// Implementations: youtube, soundcloud, bandcamp, catalog, local, direct,
+// podcast, radio. Embedders add their own with resolve.Resolver.Register.
 type Source interface {
 	// Match checks if this source can handle the given input
 	Match(input string) bool
@@ -15,3 +18,60 @@ type Source interface {
 	// AvailableParsers returns the list of parsers supported by this source
 	AvailableParsers() []string
 }
+
+// QuerySource is implemented by sources whose Resolve also takes a bare
+// search query, playing the top match, rather than only URLs. The resolver
+// sends bare queries to no other kind, and only these can be a guild's
+// search source. Implement it by embedding TakesQueries.
+type QuerySource interface {
+	Source
+	resolvesQueries()
+}
+
+// TakesQueries makes the source it is embedded in a QuerySource.
+type TakesQueries struct{}
+
+func (TakesQueries) resolvesQueries() {}
+
+// ResolvesQueries reports whether src takes bare search queries.
+func ResolvesQueries(src Source) bool {
+	_, ok := src.(QuerySource)
+	return ok
+}
+
+// DisplayNamer is implemented by sources with a human-readable name for
+// pickers, such as /play's source option. Others are shown by SourceName.
+type DisplayNamer interface {
+	DisplayName() string
+}
+
+// DisplayName is src's display name, or its SourceName without one.
+func DisplayName(src Source) string {
+	if d, ok := src.(DisplayNamer); ok {
+		if name := d.DisplayName(); name != "" {
+			return name
+		}
+	}
+	return src.SourceName()
+}
+
+// ContextSource is implemented by sources whose Resolve can be abandoned: its
+// requests are bound to ctx, so a cancelled /play stops the fetches in flight
+// instead of letting them run to their timeouts.
+type ContextSource interface {
+	Source
+	ResolveContext(ctx context.Context, input string, selectedParser string) ([]TrackInfo, error)
+}
+
+// ResolveContext resolves input with src, bound to ctx when src supports it.
+// A source without ResolveContext is resolved as before, and ctx is only
+// checked before it starts.
+func ResolveContext(ctx context.Context, src Source, input, selectedParser string) ([]TrackInfo, error) {
+	if c, ok := src.(ContextSource); ok {
+		return c.ResolveContext(ctx, input, selectedParser)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return src.Resolve(input, selectedParser)
+}
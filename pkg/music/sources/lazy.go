@@ -0,0 +1,145 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// LazyPageSize is how many tracks a LazyList hands the queue at a time,
+// whatever page size its source fetches in. It keeps a 100-entry first fetch
+// from landing in the queue all at once, which is the flood the lazy entry
+// exists to avoid.
+const LazyPageSize = 25
+
+// PageFunc fetches one page of a list. token is the continuation returned with
+// the previous page; next is "" once the list has ended.
+type PageFunc func(token string) (page []TrackInfo, next string, err error)
+
+// PageContextFunc is a PageFunc whose fetch is bound to ctx.
+type PageContextFunc func(ctx context.Context, token string) (page []TrackInfo, next string, err error)
+
+// LazyList is a playlist too long, or too endless, to expand eagerly: a
+// 2,000-video playlist, a YouTube mix. It is queued as a single entry and
+// holds what it needs to continue — the list id and the continuation token —
+// so tracks are only fetched once the queue has played its way up to it.
+//
+// Safe for concurrent use; the queue view reads Progress while the player
+// pages.
+type LazyList struct {
+	// ListID is the source's id for the list, for logs and display.
+	ListID string
+	// Title is the list's own title.
+	Title string
+
+	fetch PageContextFunc
+
+	// pageMu serializes NextPageContext callers and is held across the fetch;
+	// mu guards the fields below and never is, so Progress does not wait on
+	// the network.
+	pageMu   sync.Mutex
+	mu       sync.Mutex
+	pinned   string // see PinParser
+	token    string
+	buffered []TrackInfo
+	total    int
+	served   int
+	done     bool
+}
+
+// NewLazyList builds a lazy entry. first holds tracks already fetched while
+// deciding the list was too long for eager expansion — they are served before
+// anything new is fetched — and token continues after them. total is the
+// list's stated length, or 0 when the source does not know it (a mix).
+func NewLazyList(listID, title string, total int, first []TrackInfo, token string, fetch PageFunc) *LazyList {
+	return NewLazyListContext(listID, title, total, first, token, func(_ context.Context, token string) ([]TrackInfo, string, error) {
+		return fetch(token)
+	})
+}
+
+// NewLazyListContext is NewLazyList for a source whose page fetch takes a
+// context, so NextPageContext can abandon it.
+func NewLazyListContext(listID, title string, total int, first []TrackInfo, token string, fetch PageContextFunc) *LazyList {
+	return &LazyList{
+		ListID:   listID,
+		Title:    title,
+		fetch:    fetch,
+		token:    token,
+		buffered: first,
+		total:    total,
+		done:     len(first) == 0 && token == "",
+	}
+}
+
+// NextPage returns up to LazyPageSize further tracks, fetching a page when the
+// buffer is empty. It returns nothing once the list is exhausted; check Done.
+func (l *LazyList) NextPage() ([]TrackInfo, error) {
+	return l.NextPageContext(context.Background())
+}
+
+// NextPageContext is NextPage with the page fetch bound to ctx. A cancelled
+// fetch leaves the list where it was, to be continued by the next call.
+func (l *LazyList) NextPageContext(ctx context.Context) ([]TrackInfo, error) {
+	l.pageMu.Lock()
+	defer l.pageMu.Unlock()
+
+	l.mu.Lock()
+	empty, token := len(l.buffered) == 0, l.token
+	l.mu.Unlock()
+
+	// A page can come back with nothing usable in it (every video private),
+	// so keep going while a continuation remains. Only this goroutine moves
+	// the token (pageMu), so the copy stays current across the fetch.
+	for empty && token != "" {
+		page, next, err := l.fetch(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+		l.mu.Lock()
+		l.buffered, l.token = page, next
+		l.mu.Unlock()
+		empty, token = len(page) == 0, next
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := min(len(l.buffered), LazyPageSize)
+	page := l.buffered[:n:n]
+	l.buffered = l.buffered[n:]
+	l.served += n
+	l.done = len(l.buffered) == 0 && l.token == ""
+	PinParser(page, l.pinned)
+	return page, nil
+}
+
+// pin makes the tracks paged in from here on carry parser as their pick.
+func (l *LazyList) pin(parser string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pinned = parser
+}
+
+// Done reports whether every track has been handed out.
+func (l *LazyList) Done() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.done
+}
+
+// Progress reports how many tracks have been handed to the queue and the
+// list's length, 0 when unknown.
+func (l *LazyList) Progress() (served, total int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.served, l.total
+}
+
+// Label is the entry's one-line form for a queue listing: "Title (37/2000)",
+// or "Title (37 so far)" for a list of unknown length.
+func (l *LazyList) Label() string {
+	served, total := l.Progress()
+	if total > 0 {
+		return fmt.Sprintf("%s (%d/%d)", l.Title, served, total)
+	}
+	return fmt.Sprintf("%s (%d so far)", l.Title, served)
+}
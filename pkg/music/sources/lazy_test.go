@@ -0,0 +1,167 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func lazyTracks(prefix string, n int) []TrackInfo {
+	out := make([]TrackInfo, n)
+	for i := range out {
+		out[i] = TrackInfo{Title: fmt.Sprintf("%s%d", prefix, i)}
+	}
+	return out
+}
+
+func TestLazyListServesBufferThenFetches(t *testing.T) {
+	var tokens []string
+	fetch := func(token string) ([]TrackInfo, string, error) {
+		tokens = append(tokens, token)
+		return lazyTracks("b", 10), "", nil
+	}
+	l := NewLazyList("PL1", "List", 40, lazyTracks("a", 30), "tok2", fetch)
+
+	if got := l.Label(); got != "List (0/40)" {
+		t.Fatalf("label before paging: %q", got)
+	}
+
+	page, err := l.NextPage()
+	if err != nil || len(page) != LazyPageSize {
+		t.Fatalf("first page: %d tracks, err %v; want %d from the buffer", len(page), err, LazyPageSize)
+	}
+	if len(tokens) != 0 {
+		t.Fatalf("fetched %v while the buffer still held tracks", tokens)
+	}
+
+	page, err = l.NextPage()
+	if err != nil || len(page) != 5 {
+		t.Fatalf("second page: %d tracks, err %v; want the 5 left in the buffer", len(page), err)
+	}
+	if l.Done() {
+		t.Fatal("done with a continuation still pending")
+	}
+
+	page, err = l.NextPage()
+	if err != nil || len(page) != 10 || page[0].Title != "b0" {
+		t.Fatalf("third page: %v, err %v; want the fetched page", page, err)
+	}
+	if len(tokens) != 1 || tokens[0] != "tok2" {
+		t.Fatalf("fetch tokens = %v, want [tok2]", tokens)
+	}
+	if !l.Done() {
+		t.Fatal("expected done after the last page")
+	}
+	if got := l.Label(); got != "List (40/40)" {
+		t.Fatalf("label after paging: %q", got)
+	}
+}
+
+func TestLazyListSkipsEmptyPagesAndReportsErrors(t *testing.T) {
+	calls := 0
+	fetch := func(token string) ([]TrackInfo, string, error) {
+		calls++
+		switch token {
+		case "empty":
+			return nil, "full", nil
+		case "full":
+			return lazyTracks("x", 2), "broken", nil
+		}
+		return nil, "", errors.New("boom")
+	}
+	l := NewLazyList("RD1", "Mix", 0, nil, "empty", fetch)
+
+	page, err := l.NextPage()
+	if err != nil || len(page) != 2 || calls != 2 {
+		t.Fatalf("got %d tracks after %d fetches, err %v; want 2 after 2", len(page), calls, err)
+	}
+	if got := l.Label(); got != "Mix (2 so far)" {
+		t.Fatalf("label for unknown length: %q", got)
+	}
+	if _, err := l.NextPage(); err == nil {
+		t.Fatal("expected the fetch error to surface")
+	}
+}
+
+func TestLazyListCancelledFetchKeepsItsPlace(t *testing.T) {
+	fetch := func(ctx context.Context, token string) ([]TrackInfo, string, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, "", err
+		}
+		return lazyTracks(token, 3), "", nil
+	}
+	l := NewLazyListContext("PL1", "List", 3, nil, "t", fetch)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := l.NextPageContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("NextPageContext = %v, want context.Canceled", err)
+	}
+	if l.Done() {
+		t.Fatal("list done after a cancelled fetch")
+	}
+	page, err := l.NextPage()
+	if err != nil || len(page) != 3 || page[0].Title != "t0" {
+		t.Fatalf("NextPage = %v, %v; want the page the cancelled fetch asked for", page, err)
+	}
+}
+
+func TestLazyListProgressDoesNotWaitForAFetch(t *testing.T) {
+	entered, release := make(chan struct{}), make(chan struct{})
+	fetch := func(token string) ([]TrackInfo, string, error) {
+		close(entered)
+		<-release
+		return lazyTracks("b", 5), "", nil
+	}
+	l := NewLazyList("PL1", "List", 5, nil, "tok", fetch)
+
+	done := make(chan error)
+	go func() {
+		_, err := l.NextPage()
+		done <- err
+	}()
+	<-entered
+
+	// /queue reads the label while the player is mid-fetch; it must not block.
+	if got := l.Label(); got != "List (0/5)" {
+		t.Fatalf("label during fetch: %q", got)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if served, _ := l.Progress(); served != 5 {
+		t.Fatalf("served = %d, want 5", served)
+	}
+}
+
+// A parser picked for a long playlist reaches the tracks paged in later.
+func TestPinParserReachesLazyPages(t *testing.T) {
+	fetch := func(token string) ([]TrackInfo, string, error) {
+		page := lazyTracks("b", 2)
+		for i := range page {
+			page[i].AvailableParsers = []string{"p", "q"}
+		}
+		return page, "", nil
+	}
+	l := NewLazyList("PL1", "List", 2, nil, "tok", fetch)
+	tracks := []TrackInfo{
+		{Title: "listed", AvailableParsers: []string{"p", "q"}},
+		{Title: "other", AvailableParsers: []string{"q"}},
+		{Title: "lazy", Lazy: l},
+	}
+	PinParser(tracks, "p")
+	if tracks[0].PinnedParser != "p" || tracks[1].PinnedParser != "" {
+		t.Fatalf("pins = %q, %q; want p on the track listing it only", tracks[0].PinnedParser, tracks[1].PinnedParser)
+	}
+	page, err := l.NextPage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tr := range page {
+		if tr.PinnedParser != "p" {
+			t.Fatalf("paged track %q pinned %q, want p", tr.Title, tr.PinnedParser)
+		}
+	}
+}
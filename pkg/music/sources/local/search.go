@@ -0,0 +1,97 @@
+package local
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/keshon/melodix/pkg/music/library"
+	source "github.com/keshon/melodix/pkg/music/sources"
+)
+
+// Search ranks library entries against query; it is what /search and /play
+// autocomplete list. An empty query matches nothing rather than the whole
+// library.
+func (s *Source) Search(query string, limit int) ([]source.SearchResult, error) {
+	lib := library.Default()
+	if lib == nil {
+		return nil, fmt.Errorf("%s: %w", Name, library.ErrNoLibrary)
+	}
+	hits := search(lib.Entries(), query, limit)
+	out := make([]source.SearchResult, 0, len(hits))
+	for _, e := range hits {
+		// No URL: a host path is neither a link Discord can open nor
+		// something the server's layout should be showing to a channel.
+		out = append(out, source.SearchResult{
+			ID:       e.ID,
+			Title:    e.Title,
+			Author:   e.Artist,
+			Duration: e.Duration(),
+		})
+	}
+	return out, nil
+}
+
+// Field weights: a token in the title says more about intent than one in the
+// album, and the path (folder names) is a last resort that still lets
+// "soundtrack" find an untagged folder of files.
+const (
+	weightTitle  = 8
+	weightArtist = 4
+	weightAlbum  = 2
+	weightPath   = 1
+)
+
+// search keeps entries where every query token appears in some field, ranked
+// by summed field weight, then by title for a stable order.
+func search(entries []library.Entry, query string, limit int) []library.Entry {
+	tokens := strings.Fields(strings.ToLower(query))
+	if len(tokens) == 0 || limit <= 0 {
+		return nil
+	}
+	type scored struct {
+		e     library.Entry
+		score int
+	}
+	var hits []scored
+	for _, e := range entries {
+		title, artist := strings.ToLower(e.Title), strings.ToLower(e.Artist)
+		album, path := strings.ToLower(e.Album), strings.ToLower(filepath.ToSlash(e.Path))
+		score := 0
+		for _, tok := range tokens {
+			s := 0
+			if strings.Contains(title, tok) {
+				s += weightTitle
+			}
+			if strings.Contains(artist, tok) {
+				s += weightArtist
+			}
+			if strings.Contains(album, tok) {
+				s += weightAlbum
+			}
+			if strings.Contains(path, tok) {
+				s += weightPath
+			}
+			if s == 0 {
+				score = 0
+				break
+			}
+			score += s
+		}
+		if score > 0 {
+			hits = append(hits, scored{e, score})
+		}
+	}
+	slices.SortFunc(hits, func(a, b scored) int {
+		if a.score != b.score {
+			return b.score - a.score
+		}
+		return strings.Compare(a.e.Title, b.e.Title)
+	})
+	out := make([]library.Entry, 0, min(limit, len(hits)))
+	for _, h := range hits[:min(limit, len(hits))] {
+		out = append(out, h.e)
+	}
+	return out
+}
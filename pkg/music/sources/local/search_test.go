@@ -0,0 +1,37 @@
+package local
+
+import (
+	"testing"
+
+	"github.com/keshon/melodix/pkg/music/library"
+)
+
+func TestSearchRanksTitleOverPath(t *testing.T) {
+	entries := []library.Entry{
+		{ID: "a", Path: "/m/blue/song.mp3", Title: "Song", Artist: "Someone"},
+		{ID: "b", Path: "/m/x/b.mp3", Title: "Blue Monday", Artist: "New Order"},
+		{ID: "c", Path: "/m/x/c.mp3", Title: "Monday Morning", Artist: "Blue Band"},
+		{ID: "d", Path: "/m/x/d.mp3", Title: "Unrelated"},
+	}
+	got := search(entries, "blue", 10)
+	want := []string{"b", "c", "a"} // title, then artist, then only the folder
+	if len(got) != len(want) {
+		t.Fatalf("got %d hits, want %d", len(got), len(want))
+	}
+	for i, id := range want {
+		if got[i].ID != id {
+			t.Errorf("hit %d = %s, want %s", i, got[i].ID, id)
+		}
+	}
+
+	// Every token has to match somewhere.
+	if got := search(entries, "blue order", 10); len(got) != 1 || got[0].ID != "b" {
+		t.Errorf("two-token search = %+v, want only b", got)
+	}
+	if got := search(entries, "   ", 10); len(got) != 0 {
+		t.Errorf("blank query matched %d entries, want none", len(got))
+	}
+	if got := search(entries, "blue", 1); len(got) != 1 {
+		t.Errorf("limit ignored: %d hits", len(got))
+	}
+}
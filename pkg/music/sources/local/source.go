@@ -0,0 +1,118 @@
+// Package local is the source for files in the local library
+// (pkg/music/library). Inputs are "local:<id>" (what /search and autocomplete
+// hand back), an absolute path under a library directory, or a query.
+package local
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/keshon/melodix/pkg/music/library"
+	source "github.com/keshon/melodix/pkg/music/sources"
+)
+
+// Name is this source's identifier (equals sources.Local).
+const Name = "local"
+
+// IDPrefix marks an input as a library id rather than a path or a query.
+const IDPrefix = library.IDPrefix
+
+// Source resolves library ids, paths and queries. It reads library.Default()
+// on every call rather than holding the library, because the resolver is built
+// before the library is wired at startup.
+type Source struct {
+	source.TakesQueries
+}
+
+// New creates the local source.
+func New() *Source { return &Source{} }
+
+// Match claims "local:" ids and absolute paths inside the library. A path
+// outside it is not claimed, so it falls through to the other sources and
+// fails there instead of reaching the disk.
+func (s *Source) Match(input string) bool {
+	input = strings.TrimSpace(input)
+	if strings.HasPrefix(input, IDPrefix) {
+		return true
+	}
+	lib := library.Default()
+	if lib == nil || !filepath.IsAbs(input) {
+		return false
+	}
+	_, err := lib.Confine(input)
+	return err == nil
+}
+
+func (s *Source) Resolve(input string, selectedParser string) ([]source.TrackInfo, error) {
+	if selectedParser != "" && !slices.Contains(s.AvailableParsers(), selectedParser) {
+		return nil, errors.New(Name + " source does not support " + selectedParser + " parser")
+	}
+	lib := library.Default()
+	if lib == nil {
+		return nil, fmt.Errorf("%s: %w", Name, library.ErrNoLibrary)
+	}
+
+	input = strings.TrimSpace(input)
+	var e library.Entry
+	var err error
+	switch {
+	case strings.HasPrefix(input, IDPrefix):
+		e, err = lib.Lookup(strings.TrimPrefix(input, IDPrefix))
+	case filepath.IsAbs(input):
+		e, err = lib.ByPath(input)
+	default:
+		hits := search(lib.Entries(), input, 1)
+		if len(hits) == 0 {
+			return nil, errors.New("local: no library track matches " + input)
+		}
+		e = hits[0]
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The resolver defaults selectedParser to local-passthrough for every
+	// file; for one that cannot carry Opus, PreferParser leaves it out and
+	// ffmpeg plays it, which is what the passthrough parser would do anyway.
+	// The URL is the id, not the path: it ends up in embeds and history.
+	return []source.TrackInfo{
+		{
+			URL:              IDPrefix + e.ID,
+			Title:            displayTitle(e),
+			SourceName:       Name,
+			AvailableParsers: source.PreferParser(parsersFor(e.Path), selectedParser),
+		},
+	}, nil
+}
+
+func (s *Source) SourceName() string {
+	return Name
+}
+
+func (s *Source) DisplayName() string {
+	return "Local library"
+}
+
+func (s *Source) AvailableParsers() []string {
+	return []string{source.ParserLocalPassthrough, source.ParserLocalFFmpeg}
+}
+
+// parsersFor narrows the list per file: only containers that can carry Opus
+// are worth a passthrough attempt, which would otherwise cost an open and a
+// logged fallback on every MP3.
+func parsersFor(path string) []string {
+	if library.Passthrough(path) {
+		return []string{source.ParserLocalPassthrough, source.ParserLocalFFmpeg}
+	}
+	return []string{source.ParserLocalFFmpeg}
+}
+
+func displayTitle(e library.Entry) string {
+	if e.Artist != "" {
+		return e.Artist + " - " + e.Title
+	}
+	return e.Title
+}
@@ -1,5 +1,7 @@
 package sources
 
+import "slices"
+
 // Registry keys for the built-in parsers (this package is the import base for
 // both sources and parsers, so the names live here). These strings are persisted
 // in guild playback history and shown as slash-command choices — treat them as
@@ -7,11 +9,18 @@ package sources
 const (
 	ParserYtnativeLink = "ytnative-link"
 	ParserScnativeLink = "scnative-link"
+	ParserBcnativeLink = "bcnative-link"
 	ParserKkdaiLink    = "kkdai-link"
 	ParserKkdaiPipe    = "kkdai-pipe"
 	ParserYtdlpLink    = "ytdlp-link"
 	ParserYtdlpPipe    = "ytdlp-pipe"
 	ParserFFmpegLink   = "ffmpeg-link"
+
+	ParserLocalPassthrough = "local-passthrough"
+	ParserLocalFFmpeg      = "local-ffmpeg"
+
+	ParserDirectPassthrough = "direct-passthrough"
+	ParserDirectFFmpeg      = "direct-ffmpeg"
 )
 
 // PreferParser returns a new slice where selected is first (if present).
@@ -38,3 +47,20 @@ func PreferParser(available []string, selected string) []string {
 	ordered = append(ordered, available[pos+1:]...)
 	return ordered
 }
+
+// PinParser marks parser as the listener's pick on every track that lists it
+// (see TrackInfo.PinnedParser), and on the tracks a lazy entry pages in later.
+// An empty parser pins nothing.
+func PinParser(tracks []TrackInfo, parser string) {
+	if parser == "" {
+		return
+	}
+	for i := range tracks {
+		if tracks[i].Lazy != nil {
+			tracks[i].Lazy.pin(parser)
+		}
+		if slices.Contains(tracks[i].AvailableParsers, parser) {
+			tracks[i].PinnedParser = parser
+		}
+	}
+}
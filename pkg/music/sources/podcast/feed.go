@@ -0,0 +1,304 @@
+package podcast
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxFeedBytes bounds a feed read. Long-running shows carry every episode's
+// show notes and run to several megabytes; past this it is not a feed.
+const maxFeedBytes = 32 << 20
+
+var (
+	// ErrNotAFeed means the URL answered with something that is neither RSS nor Atom.
+	ErrNotAFeed = errors.New("podcast: not an RSS or Atom feed")
+	// ErrNoEpisodes means the feed has no item with an audio enclosure.
+	ErrNoEpisodes = errors.New("podcast: feed has no playable episodes")
+	// ErrEpisodeNotFound means no episode in the feed has the requested key.
+	ErrEpisodeNotFound = errors.New("podcast: episode not found in the feed")
+)
+
+// Feed is a parsed podcast feed: the show's title and its playable episodes,
+// newest first.
+type Feed struct {
+	Title    string
+	Episodes []Episode
+}
+
+// Episode is one item with an audio enclosure.
+type Episode struct {
+	// GUID is the item's own id, which survives the enclosure moving to a new
+	// host; empty when the feed gives none.
+	GUID string
+	// URL is the enclosure: the audio file itself.
+	URL   string
+	Title string
+	// Link is the episode's web page, when the feed names one.
+	Link      string
+	Published time.Time
+	// Duration is what the feed claims (itunes:duration), zero when absent.
+	Duration time.Duration
+}
+
+// Key is the episode's compact id: a hash of the GUID, or of the enclosure
+// when there is none. It is what a /search button carries.
+func (e Episode) Key() string {
+	id := e.GUID
+	if id == "" {
+		id = e.URL
+	}
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:8])
+}
+
+// FeedKey is a feed URL's compact id, short enough for a Discord component id.
+func FeedKey(feedURL string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(feedURL)))
+	return hex.EncodeToString(sum[:12])
+}
+
+// Episode returns the episode with the given key.
+func (f Feed) Episode(key string) (Episode, error) {
+	for _, e := range f.Episodes {
+		if e.Key() == key {
+			return e, nil
+		}
+	}
+	return Episode{}, ErrEpisodeNotFound
+}
+
+// fetchFeed downloads and parses a feed.
+func (s *Source) fetchFeed(ctx context.Context, feedURL string) (Feed, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return Feed{}, fmt.Errorf("podcast: feed request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.5")
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return Feed{}, fmt.Errorf("podcast: fetch feed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Feed{}, fmt.Errorf("podcast: fetch feed: %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedBytes))
+	if err != nil {
+		return Feed{}, fmt.Errorf("podcast: read feed: %w", err)
+	}
+	return parseFeed(body)
+}
+
+// looksLikeFeed sniffs the start of a document for an RSS or Atom root. The
+// Content-Type is no help: feeds go out as text/xml, application/xml,
+// application/rss+xml and, often enough, text/html.
+func looksLikeFeed(head []byte) bool {
+	head = bytes.ToLower(head)
+	return bytes.Contains(head, []byte("<rss")) || bytes.Contains(head, []byte("<feed"))
+}
+
+type rssDoc struct {
+	XMLName xml.Name `xml:"rss"`
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type rssItem struct {
+	Title     string `xml:"title"`
+	Link      string `xml:"link"`
+	GUID      string `xml:"guid"`
+	PubDate   string `xml:"pubDate"`
+	Duration  string `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+	Enclosure []struct {
+		URL  string `xml:"url,attr"`
+		Type string `xml:"type,attr"`
+	} `xml:"enclosure"`
+}
+
+type atomDoc struct {
+	XMLName xml.Name    `xml:"feed"`
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Title     string `xml:"title"`
+	ID        string `xml:"id"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+	Duration  string `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+	Links     []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+		Type string `xml:"type,attr"`
+	} `xml:"link"`
+}
+
+// parseFeed reads RSS 2.0 or Atom. Items without an audio enclosure (a
+// trailer page, a video-only episode) are dropped.
+func parseFeed(body []byte) (Feed, error) {
+	var feed Feed
+	switch {
+	case isRoot(body, "rss"):
+		var doc rssDoc
+		if err := xml.Unmarshal(body, &doc); err != nil {
+			return Feed{}, fmt.Errorf("podcast: parse rss: %w", err)
+		}
+		feed.Title = strings.TrimSpace(doc.Channel.Title)
+		for _, it := range doc.Channel.Items {
+			enc := ""
+			for _, e := range it.Enclosure {
+				if audioEnclosure(e.Type, e.URL) {
+					enc = strings.TrimSpace(e.URL)
+					break
+				}
+			}
+			if enc == "" {
+				continue
+			}
+			feed.Episodes = append(feed.Episodes, Episode{
+				GUID:      strings.TrimSpace(it.GUID),
+				URL:       enc,
+				Title:     strings.TrimSpace(it.Title),
+				Link:      strings.TrimSpace(it.Link),
+				Published: parseDate(it.PubDate),
+				Duration:  parseDuration(it.Duration),
+			})
+		}
+	case isRoot(body, "feed"):
+		var doc atomDoc
+		if err := xml.Unmarshal(body, &doc); err != nil {
+			return Feed{}, fmt.Errorf("podcast: parse atom: %w", err)
+		}
+		feed.Title = strings.TrimSpace(doc.Title)
+		for _, en := range doc.Entries {
+			var enc, link string
+			for _, l := range en.Links {
+				switch {
+				case l.Rel == "enclosure" && enc == "" && audioEnclosure(l.Type, l.Href):
+					enc = strings.TrimSpace(l.Href)
+				case (l.Rel == "" || l.Rel == "alternate") && link == "":
+					link = strings.TrimSpace(l.Href)
+				}
+			}
+			if enc == "" {
+				continue
+			}
+			published := parseDate(en.Published)
+			if published.IsZero() {
+				published = parseDate(en.Updated)
+			}
+			feed.Episodes = append(feed.Episodes, Episode{
+				GUID:      strings.TrimSpace(en.ID),
+				URL:       enc,
+				Title:     strings.TrimSpace(en.Title),
+				Link:      link,
+				Published: published,
+				Duration:  parseDuration(en.Duration),
+			})
+		}
+	default:
+		return Feed{}, ErrNotAFeed
+	}
+	if len(feed.Episodes) == 0 {
+		return Feed{}, ErrNoEpisodes
+	}
+	// Feeds are usually newest first already, but not always (serial shows list
+	// episode 1 first), and "latest" has to mean latest. Undated items keep
+	// their place relative to each other at the end.
+	slices.SortStableFunc(feed.Episodes, func(a, b Episode) int {
+		return b.Published.Compare(a.Published)
+	})
+	return feed, nil
+}
+
+// isRoot reports whether the document's first element is named root.
+func isRoot(body []byte, root string) bool {
+	dec := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return false
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			return se.Name.Local == root
+		}
+	}
+}
+
+// audioEnclosure accepts an audio type, or no type at all with an audio-looking
+// URL. Video enclosures are left out: there is nothing to play in a voice
+// channel that the audio-only edition of the show does not carry better.
+func audioEnclosure(mimeType, rawURL string) bool {
+	mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+	if rawURL == "" {
+		return false
+	}
+	if mimeType != "" {
+		return strings.HasPrefix(mimeType, "audio/")
+	}
+	u := strings.ToLower(rawURL)
+	if i := strings.IndexAny(u, "?#"); i >= 0 {
+		u = u[:i]
+	}
+	for _, ext := range []string{".mp3", ".m4a", ".aac", ".ogg", ".opus", ".flac", ".wav"} {
+		if strings.HasSuffix(u, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// dateLayouts covers RFC 822 as feeds actually write it — with or without the
+// weekday and seconds, numeric zones or names — and Atom's RFC 3339.
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	time.RFC3339,
+}
+
+func parseDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// parseDuration reads itunes:duration, which is either plain seconds or
+// [[h:]m:]s.
+func parseDuration(s string) time.Duration {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0
+	}
+	var total float64
+	for _, part := range strings.Split(s, ":") {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil || n < 0 {
+			return 0
+		}
+		total = total*60 + n
+	}
+	return time.Duration(total * float64(time.Second))
+}
@@ -0,0 +1,105 @@
+package podcast
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+const rssFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd">
+<channel>
+  <title>The Show</title>
+  <item>
+    <title>Episode 1</title>
+    <guid>ep-1</guid>
+    <pubDate>Mon, 05 Oct 2026 08:00:00 +0000</pubDate>
+    <itunes:duration>1:02:03</itunes:duration>
+    <enclosure url="https://cdn.example.com/ep1.mp3" type="audio/mpeg" length="1"/>
+  </item>
+  <item>
+    <title>Trailer video</title>
+    <enclosure url="https://cdn.example.com/trailer.mp4" type="video/mp4"/>
+  </item>
+  <item>
+    <title>Episode 2</title>
+    <link>https://example.com/ep2</link>
+    <guid>ep-2</guid>
+    <pubDate>Mon, 12 Oct 2026 08:00:00 GMT</pubDate>
+    <itunes:duration>2700</itunes:duration>
+    <enclosure url="https://cdn.example.com/ep2.m4a?src=rss" type="audio/x-m4a"/>
+  </item>
+</channel>
+</rss>`
+
+const atomFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Atom Show</title>
+  <entry>
+    <title>Pilot</title>
+    <id>urn:uuid:pilot</id>
+    <updated>2026-10-01T10:00:00Z</updated>
+    <link rel="alternate" href="https://example.com/pilot"/>
+    <link rel="enclosure" href="https://cdn.example.com/pilot.ogg"/>
+  </entry>
+</feed>`
+
+func TestParseRSSKeepsAudioNewestFirst(t *testing.T) {
+	feed, err := parseFeed([]byte(rssFeed))
+	if err != nil {
+		t.Fatalf("parseFeed: %v", err)
+	}
+	if feed.Title != "The Show" || len(feed.Episodes) != 2 {
+		t.Fatalf("feed = %+v, want 2 audio episodes", feed)
+	}
+	latest := feed.Episodes[0]
+	if latest.Title != "Episode 2" || latest.URL != "https://cdn.example.com/ep2.m4a?src=rss" || latest.Link != "https://example.com/ep2" {
+		t.Fatalf("latest = %+v", latest)
+	}
+	if latest.Duration != 45*time.Minute || feed.Episodes[1].Duration != time.Hour+2*time.Minute+3*time.Second {
+		t.Fatalf("durations = %v, %v", latest.Duration, feed.Episodes[1].Duration)
+	}
+	got, err := feed.Episode(feed.Episodes[1].Key())
+	if err != nil || got.GUID != "ep-1" {
+		t.Fatalf("Episode(key) = %+v, %v", got, err)
+	}
+	if _, err := feed.Episode("nope"); !errors.Is(err, ErrEpisodeNotFound) {
+		t.Fatalf("err = %v, want ErrEpisodeNotFound", err)
+	}
+}
+
+func TestParseAtomEnclosure(t *testing.T) {
+	feed, err := parseFeed([]byte(atomFeed))
+	if err != nil {
+		t.Fatalf("parseFeed: %v", err)
+	}
+	ep := feed.Episodes[0]
+	if feed.Title != "Atom Show" || ep.URL != "https://cdn.example.com/pilot.ogg" || ep.Link != "https://example.com/pilot" {
+		t.Fatalf("feed = %+v", feed)
+	}
+	if !ep.Published.Equal(time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("published = %v, want the updated date when none is given", ep.Published)
+	}
+}
+
+func TestParseRejectsNonFeeds(t *testing.T) {
+	if _, err := parseFeed([]byte(`<html><body>hi</body></html>`)); !errors.Is(err, ErrNotAFeed) {
+		t.Fatalf("err = %v, want ErrNotAFeed", err)
+	}
+	empty := `<rss><channel><title>x</title><item><title>text only</title></item></channel></rss>`
+	if _, err := parseFeed([]byte(empty)); !errors.Is(err, ErrNoEpisodes) {
+		t.Fatalf("err = %v, want ErrNoEpisodes", err)
+	}
+}
+
+func TestEpisodeKeyFallsBackToEnclosure(t *testing.T) {
+	a := Episode{URL: "https://cdn.example.com/a.mp3"}
+	b := Episode{URL: "https://cdn.example.com/b.mp3"}
+	if a.Key() == b.Key() || len(a.Key()) != 16 {
+		t.Fatalf("keys = %q, %q", a.Key(), b.Key())
+	}
+	moved := Episode{GUID: "ep-1", URL: "https://new-host.example.com/ep1.mp3"}
+	if moved.Key() != (Episode{GUID: "ep-1", URL: "https://cdn.example.com/ep1.mp3"}).Key() {
+		t.Fatal("the key must follow the GUID, not the enclosure host")
+	}
+}
@@ -0,0 +1,191 @@
+// Package podcast is the source for podcast feeds: an RSS or Atom URL resolves
+// to one of its episodes, by default the latest. Episodes are ordinary audio
+// files behind the enclosure URL, so they play through the direct-file parsers
+// and are cached like any other finite track.
+package podcast
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	source "github.com/keshon/melodix/pkg/music/sources"
+)
+
+// Name is this source's identifier (equals sources.Podcast).
+const Name = "podcast"
+
+// EpisodeFragment selects one episode of a feed: "<feed>#episode=<key>". A
+// feed URL has no fragment of its own worth keeping, and the fragment never
+// reaches the server, so the pair stays one resolvable string.
+const EpisodeFragment = "#episode="
+
+// sniffBytes is how much of a response Match reads to recognise a feed.
+const sniffBytes = 1024
+
+// userAgent is a browser string: some feed hosts refuse Go's default.
+const userAgent = "Mozilla/5.0"
+
+// Source resolves podcast feeds. Client is a field so tests can point it at an
+// httptest server.
+type Source struct {
+	Client *http.Client
+}
+
+// New creates the podcast source with production defaults.
+func New() *Source {
+	return &Source{Client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// EpisodeURL is the resolvable form of one episode of a feed.
+func EpisodeURL(feedURL, key string) string {
+	return feedURL + EpisodeFragment + key
+}
+
+// splitEpisode separates a feed URL from the episode key it may carry.
+func splitEpisode(input string) (feedURL, key string) {
+	feedURL, key, _ = strings.Cut(strings.TrimSpace(input), EpisodeFragment)
+	return feedURL, key
+}
+
+// Match claims URLs whose body opens like an RSS or Atom document. It reads
+// only the start of the response: feeds run to megabytes, and this runs for
+// every URL the sites before it did not claim.
+func (s *Source) Match(input string) bool {
+	feedURL, _ := splitEpisode(input)
+	if !source.IsURL(feedURL) {
+		return false
+	}
+	req, err := http.NewRequest(http.MethodGet, feedURL, nil)
+	if err != nil {
+		return false
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+	ct := strings.ToLower(resp.Header.Get("Content-Type"))
+	if strings.HasPrefix(ct, "audio/") || strings.HasPrefix(ct, "video/") {
+		return false
+	}
+	head, _ := io.ReadAll(io.LimitReader(resp.Body, sniffBytes))
+	return looksLikeFeed(head)
+}
+
+// Resolve returns the latest episode of the feed, or the one the URL's
+// EpisodeFragment names.
+func (s *Source) Resolve(input string, selectedParser string) ([]source.TrackInfo, error) {
+	return s.ResolveContext(context.Background(), input, selectedParser)
+}
+
+// ResolveContext is Resolve with the feed fetch bound to ctx.
+func (s *Source) ResolveContext(ctx context.Context, input string, selectedParser string) ([]source.TrackInfo, error) {
+	parsers := s.AvailableParsers()
+	if selectedParser != "" && !slices.Contains(parsers, selectedParser) {
+		return nil, errors.New(Name + " source does not support " + selectedParser + " parser")
+	}
+	feedURL, key := splitEpisode(input)
+	if !source.IsURL(feedURL) {
+		return nil, errors.New(Name + " source needs a feed link")
+	}
+	feed, err := s.fetchFeed(ctx, feedURL)
+	if err != nil {
+		return nil, err
+	}
+	ep := feed.Episodes[0]
+	if key != "" {
+		if ep, err = feed.Episode(key); err != nil {
+			return nil, err
+		}
+	}
+	return []source.TrackInfo{{
+		// The enclosure, not the feed: it is the file the parsers open, the
+		// cache keys on and history replays.
+		URL:              ep.URL,
+		Title:            episodeTitle(feed, ep),
+		SourceName:       Name,
+		AvailableParsers: source.PreferParser(parsers, selectedParser),
+	}}, nil
+}
+
+// Search lists the latest episodes of the feed the query links to. There is no
+// podcast directory behind it: the query is the feed. Each result's ID is
+// FeedKey and the episode key joined by a dot, and the caller keeps the feed
+// URL under FeedKey to turn a picked result back into an EpisodeURL.
+func (s *Source) Search(query string, limit int) ([]source.SearchResult, error) {
+	return s.SearchContext(context.Background(), query, limit)
+}
+
+// SearchContext is Search with the feed fetch bound to ctx.
+func (s *Source) SearchContext(ctx context.Context, query string, limit int) ([]source.SearchResult, error) {
+	feedURL, _ := splitEpisode(query)
+	if !source.IsURL(feedURL) {
+		return nil, errors.New(Name + ": search takes a feed link")
+	}
+	if limit <= 0 {
+		limit = 1
+	}
+	feed, err := s.fetchFeed(ctx, feedURL)
+	if err != nil {
+		return nil, err
+	}
+	fk := FeedKey(feedURL)
+	out := make([]source.SearchResult, 0, min(limit, len(feed.Episodes)))
+	for _, ep := range feed.Episodes[:min(limit, len(feed.Episodes))] {
+		link := ep.Link
+		if link == "" {
+			link = ep.URL
+		}
+		out = append(out, source.SearchResult{
+			ID:       fk + "." + ep.Key(),
+			URL:      link,
+			Title:    ep.Title,
+			Author:   episodeDate(ep),
+			Duration: ep.Duration,
+		})
+	}
+	return out, nil
+}
+
+func (s *Source) SourceName() string {
+	return Name
+}
+
+func (s *Source) DisplayName() string {
+	return "Podcast feed"
+}
+
+// AvailableParsers are the direct-file parsers: an episode is an audio file.
+func (s *Source) AvailableParsers() []string {
+	return []string{source.ParserDirectPassthrough, source.ParserDirectFFmpeg}
+}
+
+// episodeTitle names the show as well: episode titles are often just "Episode
+// 212", which says little in a queue of several feeds.
+func episodeTitle(feed Feed, ep Episode) string {
+	switch {
+	case ep.Title == "":
+		return feed.Title
+	case feed.Title == "" || strings.Contains(ep.Title, feed.Title):
+		return ep.Title
+	default:
+		return feed.Title + " - " + ep.Title
+	}
+}
+
+// episodeDate is the chooser's byline: when the episode came out.
+func episodeDate(ep Episode) string {
+	if ep.Published.IsZero() {
+		return ""
+	}
+	return ep.Published.Format("2 Jan 2006")
+}
@@ -0,0 +1,108 @@
+package podcast
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/keshon/melodix/pkg/music/sources"
+)
+
+func feedServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/feed.xml":
+			w.Header().Set("Content-Type", "text/html") // as some hosts really do
+			_, _ = w.Write([]byte(rssFeed))
+		case "/episode.mp3":
+			w.Header().Set("Content-Type", "audio/mpeg")
+			_, _ = w.Write([]byte("<rss looking audio"))
+		case "/page":
+			_, _ = w.Write([]byte("<html>not a feed</html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func testSource(srv *httptest.Server) *Source {
+	return &Source{Client: srv.Client()}
+}
+
+func TestMatchSniffsFeeds(t *testing.T) {
+	srv := feedServer(t)
+	src := testSource(srv)
+	cases := map[string]bool{
+		srv.URL + "/feed.xml":                         true,
+		srv.URL + "/feed.xml" + EpisodeFragment + "x": true,
+		srv.URL + "/episode.mp3":                      false,
+		srv.URL + "/page":                             false,
+		srv.URL + "/missing":                          false,
+		"not a url":                                   false,
+	}
+	for input, want := range cases {
+		if got := src.Match(input); got != want {
+			t.Errorf("Match(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestResolveLatestOrNamedEpisode(t *testing.T) {
+	srv := feedServer(t)
+	src := testSource(srv)
+	feedURL := srv.URL + "/feed.xml"
+
+	got, err := src.Resolve(feedURL, "")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if got[0].URL != "https://cdn.example.com/ep2.m4a?src=rss" || got[0].Title != "The Show - Episode 2" || got[0].SourceName != sources.Podcast {
+		t.Fatalf("latest = %+v", got[0])
+	}
+	if !sources.Resumable(got[0].SourceName) {
+		t.Fatal("an episode must be resumable")
+	}
+
+	first := Episode{GUID: "ep-1"}.Key()
+	got, err = src.Resolve(EpisodeURL(feedURL, first), sources.ParserDirectFFmpeg)
+	if err != nil {
+		t.Fatalf("Resolve episode: %v", err)
+	}
+	if got[0].URL != "https://cdn.example.com/ep1.mp3" || got[0].AvailableParsers[0] != sources.ParserDirectFFmpeg {
+		t.Fatalf("episode = %+v", got[0])
+	}
+
+	if _, err := src.Resolve(EpisodeURL(feedURL, "gone"), ""); err == nil {
+		t.Fatal("an unknown episode key must fail")
+	}
+	if _, err := src.Resolve(feedURL, sources.ParserYtdlpLink); err == nil {
+		t.Fatal("a non-direct parser must be refused")
+	}
+}
+
+func TestSearchListsEpisodesWithFeedKeyedIDs(t *testing.T) {
+	srv := feedServer(t)
+	feedURL := srv.URL + "/feed.xml"
+
+	hits, err := testSource(srv).Search(feedURL, 5)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(hits) != 2 {
+		t.Fatalf("got %d hits: %+v", len(hits), hits)
+	}
+	feedKey, episodeKey, _ := strings.Cut(hits[0].ID, ".")
+	if feedKey != FeedKey(feedURL) || episodeKey != (Episode{GUID: "ep-2"}).Key() {
+		t.Fatalf("id = %q", hits[0].ID)
+	}
+	if hits[0].URL != "https://example.com/ep2" || hits[0].Author != "12 Oct 2026" || hits[1].URL != "https://cdn.example.com/ep1.mp3" {
+		t.Fatalf("hits = %+v", hits)
+	}
+	if _, err := testSource(srv).Search("the show", 5); err == nil {
+		t.Fatal("a search needs a feed link")
+	}
+}
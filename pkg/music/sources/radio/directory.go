@@ -0,0 +1,223 @@
+package radio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	source "github.com/keshon/melodix/pkg/music/sources"
+)
+
+// A stream has nothing to rank, but a directory of stations does. Radio
+// Browser (radio-browser.info) is the open one: a community-kept list of some
+// fifty thousand stations behind a plain JSON API, mirrored across several
+// servers that all answer the same paths. Anything serving those paths works,
+// which is what lets tests and air-gapped deployments point it elsewhere.
+
+// DefaultDirectoryURL is the Radio Browser round-robin name, which lands on
+// whichever mirror is up.
+const DefaultDirectoryURL = "https://all.api.radio-browser.info"
+
+// directoryUserAgent identifies the bot, as Radio Browser asks every client to
+// do; anonymous agents are the first to be rate limited.
+const directoryUserAgent = "melodix/radio-directory"
+
+var (
+	// ErrNoStationMatch means the directory had no working station for a query.
+	ErrNoStationMatch = errors.New("radio: no station found for the given query")
+	// ErrStationNotFound means a station id is not (or no longer) in the directory.
+	ErrStationNotFound = errors.New("radio: station not found in the directory")
+)
+
+// directoryURL is the base URL NewDirectory uses; empty means the default.
+var directoryURL atomic.Pointer[string]
+
+// SetDirectoryURL points every Directory created afterwards at a Radio
+// Browser-compatible server ("" restores the default). Call once at process
+// startup.
+func SetDirectoryURL(u string) {
+	u = strings.TrimRight(strings.TrimSpace(u), "/")
+	directoryURL.Store(&u)
+}
+
+// Station is one directory entry, as Radio Browser describes it.
+type Station struct {
+	UUID string `json:"stationuuid"`
+	Name string `json:"name"`
+	// URL is what the station registered; URLResolved is the stream it led to
+	// at the directory's last check, with any playlist already unwrapped.
+	URL         string `json:"url"`
+	URLResolved string `json:"url_resolved"`
+	Homepage    string `json:"homepage"`
+	Codec       string `json:"codec"`
+	// Bitrate is in kbps; 0 when the station does not say.
+	Bitrate     int    `json:"bitrate"`
+	CountryCode string `json:"countrycode"`
+	Tags        string `json:"tags"`
+	// LastCheckOK is 1 when the directory's last probe of the stream worked.
+	LastCheckOK int `json:"lastcheckok"`
+}
+
+// StreamURL is the address to play: the resolved stream when the directory
+// has one, since the registered URL is often a playlist that has since moved.
+func (s Station) StreamURL() string {
+	if u := strings.TrimSpace(s.URLResolved); u != "" {
+		return u
+	}
+	return strings.TrimSpace(s.URL)
+}
+
+// Format describes the stream as "MP3 · 128 kbps", leaving out whatever the
+// station does not report.
+func (s Station) Format() string {
+	var parts []string
+	if c := strings.TrimSpace(s.Codec); c != "" && !strings.EqualFold(c, "unknown") {
+		parts = append(parts, strings.ToUpper(c))
+	}
+	if s.Bitrate > 0 {
+		parts = append(parts, strconv.Itoa(s.Bitrate)+" kbps")
+	}
+	return strings.Join(parts, " · ")
+}
+
+// Directory searches a Radio Browser-compatible station directory. BaseURL and
+// Client are fields so tests can point it at an httptest server.
+type Directory struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewDirectory creates a Directory on the configured server (see
+// SetDirectoryURL) with production defaults.
+func NewDirectory() *Directory {
+	base := DefaultDirectoryURL
+	if u := directoryURL.Load(); u != nil && *u != "" {
+		base = *u
+	}
+	return &Directory{
+		BaseURL: base,
+		Client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Search returns up to limit stations whose name matches the query, most
+// listened-to first. Stations the directory last found broken are left out:
+// a chooser full of dead streams is worse than a short one.
+func (d *Directory) Search(query string, limit int) ([]source.SearchResult, error) {
+	return d.SearchContext(context.Background(), query, limit)
+}
+
+// SearchContext is Search with its request bound to ctx.
+func (d *Directory) SearchContext(ctx context.Context, query string, limit int) ([]source.SearchResult, error) {
+	stations, err := d.SearchStationsContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]source.SearchResult, 0, len(stations))
+	for _, st := range stations {
+		out = append(out, source.SearchResult{
+			ID:     st.UUID,
+			URL:    st.StreamURL(),
+			Title:  strings.TrimSpace(st.Name),
+			Author: st.Format(),
+		})
+	}
+	return out, nil
+}
+
+// SearchStations is Search with the directory's full records, for callers
+// that want more than a chooser line.
+func (d *Directory) SearchStations(query string, limit int) ([]Station, error) {
+	return d.SearchStationsContext(context.Background(), query, limit)
+}
+
+// SearchStationsContext is SearchStations with its request bound to ctx.
+func (d *Directory) SearchStationsContext(ctx context.Context, query string, limit int) ([]Station, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("radio: empty search query")
+	}
+	if limit <= 0 {
+		limit = 1
+	}
+	q := url.Values{}
+	q.Set("name", query)
+	q.Set("limit", strconv.Itoa(limit))
+	q.Set("hidebroken", "true")
+	q.Set("order", "clickcount")
+	q.Set("reverse", "true")
+
+	var stations []Station
+	if err := d.get(ctx, "/json/stations/search?"+q.Encode(), &stations); err != nil {
+		return nil, err
+	}
+	out := stations[:0]
+	for _, st := range stations {
+		if st.UUID == "" || st.StreamURL() == "" {
+			continue
+		}
+		out = append(out, st)
+		if len(out) >= limit {
+			break
+		}
+	}
+	if len(out) == 0 {
+		return nil, ErrNoStationMatch
+	}
+	return out, nil
+}
+
+// Station looks a station up by the id a search result carried.
+func (d *Directory) Station(uuid string) (Station, error) {
+	return d.StationContext(context.Background(), uuid)
+}
+
+// StationContext is Station with its request bound to ctx.
+func (d *Directory) StationContext(ctx context.Context, uuid string) (Station, error) {
+	uuid = strings.TrimSpace(uuid)
+	if uuid == "" {
+		return Station{}, ErrStationNotFound
+	}
+	var stations []Station
+	if err := d.get(ctx, "/json/stations/byuuid/"+url.PathEscape(uuid), &stations); err != nil {
+		return Station{}, err
+	}
+	for _, st := range stations {
+		if st.UUID == uuid && st.StreamURL() != "" {
+			return st, nil
+		}
+	}
+	return Station{}, ErrStationNotFound
+}
+
+func (d *Directory) get(ctx context.Context, pathAndQuery string, into any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(d.BaseURL, "/")+pathAndQuery, nil)
+	if err != nil {
+		return fmt.Errorf("radio: directory request: %w", err)
+	}
+	req.Header.Set("User-Agent", directoryUserAgent)
+	req.Header.Set("Accept", "application/json")
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("radio: directory: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 300))
+		return fmt.Errorf("radio: directory: %s: %s", resp.Status, strings.Join(strings.Fields(string(snippet)), " "))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(into); err != nil {
+		return fmt.Errorf("radio: decode directory response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package radio
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const directoryJSON = "application/json"
+
+func testDirectory(srv *httptest.Server) *Directory {
+	return &Directory{BaseURL: srv.URL, Client: srv.Client()}
+}
+
+func TestDirectorySearchListsCodecAndBitrate(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", directoryJSON)
+		_, _ = w.Write([]byte(`[
+			{"stationuuid":"a","name":"Jazz24","url":"http://x/jazz.pls","url_resolved":"http://x/jazz","codec":"MP3","bitrate":128},
+			{"stationuuid":"","name":"No id","url":"http://x/noid"},
+			{"stationuuid":"b","name":"Smooth Jazz","url":"http://x/smooth","codec":"unknown","bitrate":0},
+			{"stationuuid":"c","name":"Nowhere","url":""}
+		]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	got, err := testDirectory(srv).Search("  jazz ", 5)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if query != "hidebroken=true&limit=5&name=jazz&order=clickcount&reverse=true" {
+		t.Fatalf("query = %q", query)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d results, want the 2 with an id and a stream: %+v", len(got), got)
+	}
+	if got[0].ID != "a" || got[0].URL != "http://x/jazz" || got[0].Title != "Jazz24" || got[0].Author != "MP3 · 128 kbps" {
+		t.Fatalf("first = %+v", got[0])
+	}
+	if got[1].URL != "http://x/smooth" || got[1].Author != "" {
+		t.Fatalf("second = %+v; want the registered URL and no format", got[1])
+	}
+	if got[0].Duration != 0 {
+		t.Fatal("a station is live and has no duration")
+	}
+}
+
+func TestDirectorySearchEmptyAndFailing(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("name") == "down" {
+			http.Error(w, "maintenance", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	t.Cleanup(srv.Close)
+	d := testDirectory(srv)
+
+	if _, err := d.Search("nothing", 5); !errors.Is(err, ErrNoStationMatch) {
+		t.Fatalf("err = %v, want ErrNoStationMatch", err)
+	}
+	if _, err := d.Search("down", 5); err == nil || errors.Is(err, ErrNoStationMatch) {
+		t.Fatalf("err = %v, want the server's failure", err)
+	}
+	if _, err := d.Search(" ", 5); err == nil {
+		t.Fatal("an empty query must be refused before any request")
+	}
+}
+
+func TestDirectoryStationByUUID(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json/stations/byuuid/a":
+			_, _ = w.Write([]byte(`[{"stationuuid":"a","name":"Jazz24","url":"http://x/jazz"}]`))
+		default:
+			_, _ = w.Write([]byte(`[]`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	d := testDirectory(srv)
+
+	st, err := d.Station("a")
+	if err != nil || st.Name != "Jazz24" || st.StreamURL() != "http://x/jazz" {
+		t.Fatalf("Station = %+v, %v", st, err)
+	}
+	if _, err := d.Station("gone"); !errors.Is(err, ErrStationNotFound) {
+		t.Fatalf("err = %v, want ErrStationNotFound", err)
+	}
+}
+
+func TestResolveQueryPlaysTopStation(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json/stations/search":
+			w.Header().Set("Content-Type", directoryJSON)
+			_, _ = w.Write([]byte(`[{"stationuuid":"a","name":"Jazz24","url":"` + srv.URL + `/live"}]`))
+		case "/live":
+			w.Header().Set("Content-Type", "audio/mpeg")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	src := testSource(srv)
+	src.directory = testDirectory(srv)
+
+	got, err := src.Resolve("jazz", "")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if got[0].URL != srv.URL+"/live" || got[0].Title != "Jazz24" || got[0].SourceName != Name {
+		t.Fatalf("got %+v", got[0])
+	}
+}
+
+func TestSetDirectoryURL(t *testing.T) {
+	t.Cleanup(func() { SetDirectoryURL("") })
+
+	SetDirectoryURL("http://127.0.0.1:8080/ ")
+	if got := NewDirectory().BaseURL; got != "http://127.0.0.1:8080" {
+		t.Fatalf("BaseURL = %q", got)
+	}
+	SetDirectoryURL("")
+	if got := NewDirectory().BaseURL; got != DefaultDirectoryURL {
+		t.Fatalf("BaseURL = %q, want the default back", got)
+	}
+}
@@ -0,0 +1,289 @@
+package radio
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Station links are often not the stream itself but a small playlist file
+// naming one or more streams: PLS (SHOUTcast's INI-style list), M3U, or XSPF.
+// ffmpeg reads some of these and not others, and never fails over between the
+// entries, so the source reads them itself and hands the parser the streams.
+
+// maxPlaylistBytes bounds a playlist read. Station lists are a few hundred
+// bytes; anything past this is a stream that was mistaken for one.
+const maxPlaylistBytes = 64 << 10
+
+var (
+	// ErrPlaylistEmpty means a station playlist named no stream.
+	ErrPlaylistEmpty = errors.New("radio: playlist has no streams")
+	// ErrNoReachableStream means every stream a playlist named failed to answer.
+	ErrNoReachableStream = errors.New("radio: no stream in the playlist is reachable")
+)
+
+type playlistFormat int
+
+const (
+	formatNone playlistFormat = iota
+	formatPLS
+	formatM3U
+	formatXSPF
+)
+
+// Playlist is a parsed station list: the station's name, when the list gives
+// one, and its streams in the order the list offers them.
+type Playlist struct {
+	Title   string
+	Entries []PlaylistEntry
+}
+
+// PlaylistEntry is one stream in a station list.
+type PlaylistEntry struct {
+	URL   string
+	Title string
+}
+
+// playlistFormatFor picks the format from the content type, falling back to
+// the extension: servers label playlists inconsistently, and plenty send
+// text/plain or application/octet-stream.
+func playlistFormatFor(contentType, rawURL string) playlistFormat {
+	ct := strings.ToLower(contentType)
+	if i := strings.Index(ct, ";"); i >= 0 {
+		ct = ct[:i]
+	}
+	switch strings.TrimSpace(ct) {
+	case "audio/x-scpls", "application/pls+xml":
+		return formatPLS
+	case "application/xspf+xml":
+		return formatXSPF
+	case "audio/x-mpegurl", "audio/mpegurl", "application/x-mpegurl", "application/vnd.apple.mpegurl":
+		return formatM3U
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		switch strings.ToLower(path.Ext(u.Path)) {
+		case ".pls":
+			return formatPLS
+		case ".xspf":
+			return formatXSPF
+		case ".m3u", ".m3u8":
+			return formatM3U
+		}
+	}
+	return formatNone
+}
+
+// errHLS marks an M3U that is an HLS media playlist rather than a station
+// list. Those are a stream in their own right, and ffmpeg plays them.
+var errHLS = errors.New("radio: playlist is HLS")
+
+// fetchPlaylist downloads and parses a station list. Relative entries are
+// resolved against the list's own (post-redirect) URL.
+func (v *Validator) fetchPlaylist(ctx context.Context, rawURL string, format playlistFormat) (Playlist, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return Playlist{}, fmt.Errorf("radio: playlist request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	resp, err := v.Client.Do(req)
+	if err != nil {
+		return Playlist{}, fmt.Errorf("radio: fetch playlist: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Playlist{}, fmt.Errorf("radio: fetch playlist: %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPlaylistBytes))
+	if err != nil {
+		return Playlist{}, fmt.Errorf("radio: read playlist: %w", err)
+	}
+
+	var pl Playlist
+	switch format {
+	case formatPLS:
+		pl = parsePLS(string(body))
+	case formatM3U:
+		if strings.Contains(string(body), "#EXT-X-") {
+			return Playlist{}, errHLS
+		}
+		pl = parseM3U(string(body))
+	case formatXSPF:
+		if pl, err = parseXSPF(body); err != nil {
+			return Playlist{}, err
+		}
+	}
+
+	base := resp.Request.URL
+	entries := pl.Entries[:0]
+	for _, e := range pl.Entries {
+		ref, err := url.Parse(e.URL)
+		if err != nil {
+			continue
+		}
+		abs := base.ResolveReference(ref)
+		if abs.Scheme != "http" && abs.Scheme != "https" {
+			continue
+		}
+		e.URL = abs.String()
+		entries = append(entries, e)
+	}
+	pl.Entries = entries
+	if len(pl.Entries) == 0 {
+		return Playlist{}, ErrPlaylistEmpty
+	}
+	return pl, nil
+}
+
+// shoutcastPrefix matches the "(#1 - 2/100) " position SHOUTcast directories
+// put in front of a PLS title.
+var shoutcastPrefix = regexp.MustCompile(`^\(#\d+ - \d+/\d+\)\s*`)
+
+// parsePLS reads the INI-style [playlist] section: FileN names a stream and
+// TitleN its title, with N giving the order. Keys are case-insensitive in the
+// wild, and N need not be contiguous.
+func parsePLS(body string) Playlist {
+	files := map[int]string{}
+	titles := map[int]string{}
+	sc := bufio.NewScanner(strings.NewReader(body))
+	for sc.Scan() {
+		key, val, ok := strings.Cut(strings.TrimSpace(sc.Text()), "=")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		val = strings.TrimSpace(val)
+		switch {
+		case strings.HasPrefix(key, "file"):
+			if n, err := strconv.Atoi(key[len("file"):]); err == nil {
+				files[n] = val
+			}
+		case strings.HasPrefix(key, "title"):
+			if n, err := strconv.Atoi(key[len("title"):]); err == nil {
+				titles[n] = shoutcastPrefix.ReplaceAllString(val, "")
+			}
+		}
+	}
+	nums := make([]int, 0, len(files))
+	for n := range files {
+		nums = append(nums, n)
+	}
+	slices.Sort(nums)
+	var pl Playlist
+	for _, n := range nums {
+		pl.Entries = append(pl.Entries, PlaylistEntry{URL: files[n], Title: titles[n]})
+	}
+	return pl
+}
+
+// parseM3U reads a plain or extended M3U: every non-comment line is a stream,
+// titled by the #EXTINF line before it. #PLAYLIST, when present, names the
+// whole list.
+func parseM3U(body string) Playlist {
+	var pl Playlist
+	var pending string
+	sc := bufio.NewScanner(strings.NewReader(strings.TrimPrefix(body, "\ufeff")))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#EXTINF:"):
+			// #EXTINF:<duration>[ attributes],<title>
+			if _, title, ok := strings.Cut(line, ","); ok {
+				pending = strings.TrimSpace(title)
+			}
+		case strings.HasPrefix(line, "#PLAYLIST:"):
+			pl.Title = strings.TrimSpace(strings.TrimPrefix(line, "#PLAYLIST:"))
+		case strings.HasPrefix(line, "#"):
+		default:
+			pl.Entries = append(pl.Entries, PlaylistEntry{URL: line, Title: pending})
+			pending = ""
+		}
+	}
+	return pl
+}
+
+type xspfDoc struct {
+	Title  string `xml:"title"`
+	Tracks []struct {
+		Locations  []string `xml:"location"`
+		Title      string   `xml:"title"`
+		Annotation string   `xml:"annotation"`
+	} `xml:"trackList>track"`
+}
+
+// parseXSPF reads an XSPF list. A track may carry several locations, which are
+// mirrors of one another, so each becomes an entry of its own.
+func parseXSPF(body []byte) (Playlist, error) {
+	var doc xspfDoc
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		return Playlist{}, fmt.Errorf("radio: parse xspf: %w", err)
+	}
+	pl := Playlist{Title: strings.TrimSpace(doc.Title)}
+	for _, t := range doc.Tracks {
+		title := strings.TrimSpace(t.Title)
+		if title == "" {
+			title = strings.TrimSpace(t.Annotation)
+		}
+		for _, loc := range t.Locations {
+			if loc = strings.TrimSpace(loc); loc != "" {
+				pl.Entries = append(pl.Entries, PlaylistEntry{URL: loc, Title: title})
+			}
+		}
+	}
+	return pl, nil
+}
+
+// reachable reports whether a stream answers a GET with 200. The body is
+// closed unread: the point is only that the server is up and serving.
+func (v *Validator) reachable(ctx context.Context, rawURL string) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return false
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	resp, err := v.Client.Do(req)
+	if err != nil {
+		return false
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
+
+// firstReachable returns the streams from the first one that answers onwards:
+// the one to play, then the ones to fail over to. Entries before it are left
+// out — they were just seen to be down.
+func (v *Validator) firstReachable(ctx context.Context, entries []PlaylistEntry) ([]PlaylistEntry, error) {
+	for i, e := range entries {
+		if v.reachable(ctx, e.URL) {
+			return entries[i:], nil
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return nil, ErrNoReachableStream
+}
+
+// stationName is the list's own name, or failing that the title of the stream
+// that will play, or of any entry at all.
+func (pl Playlist) stationName(playing []PlaylistEntry) string {
+	if pl.Title != "" {
+		return pl.Title
+	}
+	for _, e := range append(playing[:1:1], pl.Entries...) {
+		if e.Title != "" {
+			return e.Title
+		}
+	}
+	return ""
+}
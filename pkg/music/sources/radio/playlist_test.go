@@ -0,0 +1,142 @@
+package radio
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// stationServer serves playlists and streams from one httptest server: /live
+// answers as a stream, /dead with 404, and each key of lists is a playlist
+// served with its content type. "BASE" in a body is replaced with the server URL.
+func stationServer(t *testing.T, lists map[string][2]string) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/live", "/live2":
+			w.Header().Set("Content-Type", "audio/mpeg")
+			return
+		case "/dead":
+			http.NotFound(w, r)
+			return
+		}
+		list, ok := lists[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", list[0])
+		fmt.Fprint(w, strings.ReplaceAll(list[1], "BASE", srv.URL))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func testSource(srv *httptest.Server) *Source {
+	return &Source{validator: &Validator{Client: srv.Client()}}
+}
+
+func TestResolvePLSSkipsDeadStreams(t *testing.T) {
+	srv := stationServer(t, map[string][2]string{
+		"/station.pls": {"audio/x-scpls", "[playlist]\nNumberOfEntries=3\n" +
+			"File1=BASE/dead\nTitle1=(#1 - 5/100) Jazz FM\n" +
+			"file2=BASE/live\ntitle2=(#2 - 5/100) Jazz FM\n" +
+			"File4=BASE/live2\nVersion=2\n"},
+	})
+	got, err := testSource(srv).Resolve(srv.URL+"/station.pls", "")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	tr := got[0]
+	if tr.URL != srv.URL+"/station.pls" {
+		t.Fatalf("URL = %q, want the playlist kept for replay", tr.URL)
+	}
+	if tr.Title != "Jazz FM" {
+		t.Fatalf("title = %q", tr.Title)
+	}
+	want := []string{srv.URL + "/live", srv.URL + "/live2"}
+	if strings.Join(tr.StreamURLs, " ") != strings.Join(want, " ") {
+		t.Fatalf("streams = %v, want %v", tr.StreamURLs, want)
+	}
+}
+
+func TestResolveM3UResolvesRelativeEntries(t *testing.T) {
+	srv := stationServer(t, map[string][2]string{
+		"/radio/listen.m3u": {"audio/x-mpegurl", "#EXTM3U\n#EXTINF:-1,Night Radio\n/live\n#EXTINF:-1,Night Radio (backup)\nBASE/live2\n"},
+	})
+	got, err := testSource(srv).Resolve(srv.URL+"/radio/listen.m3u", "")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if got[0].Title != "Night Radio" || len(got[0].StreamURLs) != 2 || got[0].StreamURLs[0] != srv.URL+"/live" {
+		t.Fatalf("got %+v", got[0])
+	}
+}
+
+func TestResolveHLSIsLeftToFFmpeg(t *testing.T) {
+	srv := stationServer(t, map[string][2]string{
+		"/hls/index.m3u8": {"application/vnd.apple.mpegurl", "#EXTM3U\n#EXT-X-TARGETDURATION:6\n#EXTINF:6.0,\nseg1.aac\n"},
+	})
+	got, err := testSource(srv).Resolve(srv.URL+"/hls/index.m3u8", "")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if got[0].StreamURLs != nil || got[0].URL != srv.URL+"/hls/index.m3u8" {
+		t.Fatalf("an HLS playlist is a stream, got %+v", got[0])
+	}
+}
+
+func TestResolveXSPF(t *testing.T) {
+	srv := stationServer(t, map[string][2]string{
+		"/station.xspf": {"application/xspf+xml", `<?xml version="1.0" encoding="UTF-8"?>
+<playlist version="1" xmlns="http://xspf.org/ns/0/">
+  <title>Radio Paradise</title>
+  <trackList>
+    <track><location>BASE/dead</location><location>BASE/live</location><title>RP main mix</title></track>
+  </trackList>
+</playlist>`},
+	})
+	got, err := testSource(srv).Resolve(srv.URL+"/station.xspf", "")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if got[0].Title != "Radio Paradise" || len(got[0].StreamURLs) != 1 || got[0].StreamURLs[0] != srv.URL+"/live" {
+		t.Fatalf("got %+v", got[0])
+	}
+}
+
+func TestResolvePlaylistWithNoReachableStream(t *testing.T) {
+	srv := stationServer(t, map[string][2]string{
+		"/station.pls": {"audio/x-scpls", "[playlist]\nFile1=BASE/dead\n"},
+		"/empty.pls":   {"audio/x-scpls", "[playlist]\nNumberOfEntries=0\n"},
+	})
+	if _, err := testSource(srv).Resolve(srv.URL+"/station.pls", ""); !errors.Is(err, ErrNoReachableStream) {
+		t.Fatalf("err = %v, want ErrNoReachableStream", err)
+	}
+	if _, err := testSource(srv).Resolve(srv.URL+"/empty.pls", ""); !errors.Is(err, ErrPlaylistEmpty) {
+		t.Fatalf("err = %v, want ErrPlaylistEmpty", err)
+	}
+}
+
+func TestPlaylistFormatFor(t *testing.T) {
+	cases := []struct {
+		ct, url string
+		want    playlistFormat
+	}{
+		{"audio/x-scpls", "http://x/listen", formatPLS},
+		{"application/xspf+xml; charset=utf-8", "http://x/listen", formatXSPF},
+		{"audio/x-mpegurl", "http://x/listen", formatM3U},
+		{"text/plain", "http://x/tunein.PLS?id=1", formatPLS},
+		{"application/octet-stream", "http://x/a.m3u", formatM3U},
+		{"audio/mpeg", "http://x/stream", formatNone},
+	}
+	for _, tc := range cases {
+		if got := playlistFormatFor(tc.ct, tc.url); got != tc.want {
+			t.Errorf("playlistFormatFor(%q, %q) = %v, want %v", tc.ct, tc.url, got, tc.want)
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package radio
 
 import (
+	"context"
 	"errors"
 	"slices"
+	"strings"
 
 	source "github.com/keshon/melodix/pkg/music/sources"
 )
@@ -10,15 +12,21 @@ import (
 // Name is this source's identifier (equals sources.Radio).
 const Name = "radio"
 
-// Source plays internet radio streams (validated by probing Content-Type).
+// Source plays internet radio streams (validated by probing Content-Type). A
+// query that is not a URL is looked up in the station directory, and the best
+// match plays.
 type Source struct {
+	source.TakesQueries
+
 	validator *Validator
+	directory *Directory
 }
 
 // New creates the radio source.
 func New() *Source {
 	return &Source{
 		validator: NewValidator(),
+		directory: NewDirectory(),
 	}
 }
 
@@ -28,6 +36,12 @@ func (r *Source) Match(input string) bool {
 }
 
 func (r *Source) Resolve(input string, selectedParser string) ([]source.TrackInfo, error) {
+	return r.ResolveContext(context.Background(), input, selectedParser)
+}
+
+// ResolveContext is Resolve with the directory lookup and the stream checks
+// bound to ctx.
+func (r *Source) ResolveContext(ctx context.Context, input string, selectedParser string) ([]source.TrackInfo, error) {
 	parsers := r.AvailableParsers()
 
 	if selectedParser == "" {
@@ -41,7 +55,16 @@ func (r *Source) Resolve(input string, selectedParser string) ([]source.TrackInf
 		return nil, errors.New(Name + " source does not support " + selectedParser + " parser")
 	}
 
-	ok, _, err := r.validator.IsValidURL(input)
+	var stationName string
+	if !isURL(input) {
+		stations, err := r.directory.SearchStationsContext(ctx, input, 1)
+		if err != nil {
+			return nil, err
+		}
+		input, stationName = stations[0].StreamURL(), strings.TrimSpace(stations[0].Name)
+	}
+
+	ok, contentType, err := r.validator.IsValidURLContext(ctx, input)
 	if err != nil {
 		return nil, err
 	}
@@ -49,20 +72,51 @@ func (r *Source) Resolve(input string, selectedParser string) ([]source.TrackInf
 		return nil, errors.New("invalid radio URL: " + input)
 	}
 
-	return []source.TrackInfo{
-		{
-			URL:              input,
-			Title:            "", // maybe later via icy-* headers
-			SourceName:       Name,
-			AvailableParsers: source.PreferParser(parsers, selectedParser),
-		},
-	}, nil
+	info := source.TrackInfo{
+		URL:              input,
+		Title:            "", // the ffmpeg parser fills it from icy-name at open
+		SourceName:       Name,
+		AvailableParsers: source.PreferParser(parsers, selectedParser),
+	}
+	if format := playlistFormatFor(contentType, input); format != formatNone {
+		// URL stays the playlist: it is what history replays, and stream
+		// addresses inside a station list are the part that changes.
+		pl, err := r.validator.fetchPlaylist(ctx, input, format)
+		switch {
+		case errors.Is(err, errHLS):
+			// A stream after all; ffmpeg plays HLS from the URL.
+		case err != nil:
+			return nil, err
+		default:
+			streams, err := r.validator.firstReachable(ctx, pl.Entries)
+			if err != nil {
+				return nil, err
+			}
+			info.Title = pl.stationName(streams)
+			for _, e := range streams {
+				info.StreamURLs = append(info.StreamURLs, e.URL)
+			}
+		}
+	}
+	if info.Title == "" {
+		// The directory's name beats icy-name, which is often a server default.
+		info.Title = stationName
+	}
+	return []source.TrackInfo{info}, nil
+}
+
+func isURL(input string) bool {
+	return strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://")
 }
 
 func (r *Source) SourceName() string {
 	return Name
 }
 
+func (r *Source) DisplayName() string {
+	return "Radio"
+}
+
 func (r *Source) AvailableParsers() []string {
 	return []string{source.ParserFFmpegLink}
 }
@@ -1,6 +1,7 @@
 package radio
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -46,7 +47,12 @@ func NewValidator() *Validator {
 
 // IsValidURL checks stream validity based on headers, content-type, and file extension heuristics.
 func (r *Validator) IsValidURL(rawURL string) (bool, string, error) {
-	contentType, finalURL, err := r.fetchContentType(rawURL)
+	return r.IsValidURLContext(context.Background(), rawURL)
+}
+
+// IsValidURLContext is IsValidURL with its requests bound to ctx.
+func (r *Validator) IsValidURLContext(ctx context.Context, rawURL string) (bool, string, error) {
+	contentType, finalURL, err := r.fetchContentType(ctx, rawURL)
 	if err != nil {
 		return false, "", fmt.Errorf("failed to fetch content type: %w", err)
 	}
@@ -61,8 +67,8 @@ func (r *Validator) IsValidURL(rawURL string) (bool, string, error) {
 	return false, contentType, fmt.Errorf("invalid stream content-type: %q, url: %s", contentType, finalURL)
 }
 
-func (r *Validator) fetchContentType(rawURL string) (string, string, error) {
-	req, err := http.NewRequest(http.MethodHead, rawURL, nil)
+func (r *Validator) fetchContentType(ctx context.Context, rawURL string) (string, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, rawURL, nil)
 	if err != nil {
 		return "", "", fmt.Errorf("request creation failed: %w", err)
 	}
@@ -1,19 +1,19 @@
 package soundcloud
 
 import (
-	"os"
 	"strings"
 	"testing"
+
+	"github.com/keshon/melodix/pkg/music/httpreplay/replaytest"
 )
 
 // Live canary for the search path. api-v2 is an undocumented API behind a
 // rotating client_id, so drift here is a question of when.
-// Opt-in: MELODIX_LIVE_TESTS=1 go test -run Live -v ./pkg/music/sources/soundcloud
+// It replays testdata/replay when it has been recorded (MELODIX_RECORD_FIXTURES=1);
+// against the network: MELODIX_LIVE_TESTS=1 go test -run Live -v ./pkg/music/sources/soundcloud
 
 func TestLiveSearchAndPermalinkRoundTrip(t *testing.T) {
-	if os.Getenv("MELODIX_LIVE_TESTS") == "" {
-		t.Skip("set MELODIX_LIVE_TESTS=1 to hit real SoundCloud")
-	}
+	replaytest.Start(t, "testdata/replay")
 
 	s := NewSearcher()
 	hits, err := s.Search("lofi", 5)
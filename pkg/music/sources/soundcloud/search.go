@@ -1,6 +1,7 @@
 package soundcloud
 
 import (
+	"context"
 	"errors"
 	"strconv"
 	"time"
@@ -25,7 +26,12 @@ func NewSearcher() *Searcher {
 
 // SearchFirstTrackURL returns the permalink URL of the top search result.
 func (r *Searcher) SearchFirstTrackURL(query string) (string, error) {
-	track, err := r.api.SearchFirstTrack(query)
+	return r.SearchFirstTrackURLContext(context.Background(), query)
+}
+
+// SearchFirstTrackURLContext is SearchFirstTrackURL bound to ctx.
+func (r *Searcher) SearchFirstTrackURLContext(ctx context.Context, query string) (string, error) {
+	track, err := r.api.SearchFirstTrackContext(ctx, query)
 	if err != nil {
 		if errors.Is(err, soundcloudapi.ErrNoResults) {
 			return "", ErrNoTrackMatch
@@ -43,7 +49,12 @@ func (r *Searcher) SearchFirstTrackURL(query string) (string, error) {
 // ID carries the numeric track id rather than the permalink: permalinks run
 // past 130 characters, which does not survive a Discord component id.
 func (r *Searcher) Search(query string, limit int) ([]source.SearchResult, error) {
-	tracks, err := r.api.SearchTracks(query, limit)
+	return r.SearchContext(context.Background(), query, limit)
+}
+
+// SearchContext is Search bound to ctx.
+func (r *Searcher) SearchContext(ctx context.Context, query string, limit int) ([]source.SearchResult, error) {
+	tracks, err := r.api.SearchTracksContext(ctx, query, limit)
 	if err != nil {
 		if errors.Is(err, soundcloudapi.ErrNoResults) {
 			return nil, ErrNoTrackMatch
@@ -73,7 +84,12 @@ func (r *Searcher) Search(query string, limit int) ([]source.SearchResult, error
 // PermalinkByID turns a track id from a search result back into the page URL
 // the parsers resolve against.
 func (r *Searcher) PermalinkByID(id string) (string, error) {
-	track, err := r.api.TrackByID(id)
+	return r.PermalinkByIDContext(context.Background(), id)
+}
+
+// PermalinkByIDContext is PermalinkByID bound to ctx.
+func (r *Searcher) PermalinkByIDContext(ctx context.Context, id string) (string, error) {
+	track, err := r.api.TrackByIDContext(ctx, id)
 	if err != nil {
 		if errors.Is(err, soundcloudapi.ErrNoResults) {
 			return "", ErrNoTrackMatch
@@ -1,10 +1,13 @@
 package soundcloud
 
 import (
+	"context"
 	"errors"
+	"net/url"
 	"slices"
 	"strings"
 
+	"github.com/keshon/melodix/pkg/music/soundcloudapi"
 	source "github.com/keshon/melodix/pkg/music/sources"
 )
 
@@ -13,13 +16,17 @@ const Name string = "soundcloud"
 
 // Source resolves SoundCloud URLs and search queries.
 type Source struct {
+	source.TakesQueries
+
 	searcher *Searcher
+	api      *soundcloudapi.Client
 }
 
 // New creates the SoundCloud source.
 func New() *Source {
 	return &Source{
 		searcher: NewSearcher(),
+		api:      soundcloudapi.Default(),
 	}
 }
 
@@ -30,6 +37,11 @@ func (s *Source) Match(input string) bool {
 }
 
 func (s *Source) Resolve(input string, selectedParser string) ([]source.TrackInfo, error) {
+	return s.ResolveContext(context.Background(), input, selectedParser)
+}
+
+// ResolveContext is Resolve with its api-v2 requests bound to ctx.
+func (s *Source) ResolveContext(ctx context.Context, input string, selectedParser string) ([]source.TrackInfo, error) {
 	parsers := s.AvailableParsers()
 
 	if selectedParser == "" {
@@ -45,6 +57,26 @@ func (s *Source) Resolve(input string, selectedParser string) ([]source.TrackInf
 
 	input = strings.TrimSpace(input)
 
+	preferred := source.PreferParser(parsers, selectedParser)
+
+	// sets, artist pages and likes: one link expands to many tracks
+	if kind, canonical := classifyLink(input); kind != linkTrack {
+		tracks, err := s.expand(ctx, kind, canonical)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]source.TrackInfo, 0, len(tracks))
+		for _, t := range tracks {
+			out = append(out, source.TrackInfo{
+				URL:              t.PermalinkURL,
+				Title:            t.Title,
+				SourceName:       Name,
+				AvailableParsers: preferred,
+			})
+		}
+		return out, nil
+	}
+
 	// if it's a url, just return it as-is
 	if source.IsURL(input) {
 		return []source.TrackInfo{
@@ -58,7 +90,7 @@ func (s *Source) Resolve(input string, selectedParser string) ([]source.TrackInf
 	}
 
 	// otherwise, search by title
-	trackURL, err := s.searcher.SearchFirstTrackURL(input)
+	trackURL, err := s.searcher.SearchFirstTrackURLContext(ctx, input)
 	if err != nil {
 		return nil, err
 	}
@@ -73,10 +105,87 @@ func (s *Source) Resolve(input string, selectedParser string) ([]source.TrackInf
 	}, nil
 }
 
+// expand lists the tracks behind a collection link, up to the shared
+// per-link cap.
+func (s *Source) expand(ctx context.Context, kind linkKind, canonical string) ([]soundcloudapi.Track, error) {
+	switch kind {
+	case linkSet:
+		p, err := s.api.ResolvePlaylistContext(ctx, canonical, source.MaxPlaylistItems)
+		if err != nil {
+			return nil, err
+		}
+		return p.Tracks, nil
+	case linkUserTracks:
+		return s.api.UserTracksContext(ctx, canonical, source.MaxPlaylistItems)
+	case linkLikes:
+		return s.api.UserLikesContext(ctx, canonical, source.MaxPlaylistItems)
+	}
+	return nil, errors.New(Name + ": not a collection link")
+}
+
+// linkKind is what a soundcloud.com URL names.
+type linkKind int
+
+const (
+	linkTrack linkKind = iota
+	linkSet
+	linkUserTracks
+	linkLikes
+)
+
+// reservedPaths are first path segments that belong to the site rather than
+// to a user, so soundcloud.com/discover is not taken for an artist page.
+var reservedPaths = map[string]bool{
+	"charts": true, "discover": true, "feed": true, "go": true, "jobs": true,
+	"messages": true, "notifications": true, "pages": true, "people": true,
+	"pro": true, "search": true, "settings": true, "stations": true,
+	"stream": true, "tags": true, "terms-of-use": true, "upload": true,
+	"you": true,
+}
+
+// classifyLink sorts a SoundCloud URL into a track or a collection, returning
+// for a collection the URL the API should resolve: the set itself, or the
+// profile page for uploads and likes. Anything unrecognised — a user's
+// reposts, their list of sets — stays a track, which is how every link was
+// treated before and fails the same way it always did.
+func classifyLink(input string) (linkKind, string) {
+	u, err := url.Parse(strings.TrimSpace(input))
+	if err != nil {
+		return linkTrack, ""
+	}
+	switch strings.ToLower(u.Hostname()) {
+	case "soundcloud.com", "www.soundcloud.com", "m.soundcloud.com":
+	default:
+		return linkTrack, ""
+	}
+	segs := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if segs[0] == "" || reservedPaths[segs[0]] {
+		return linkTrack, ""
+	}
+	profile := "https://soundcloud.com/" + segs[0]
+	switch {
+	case len(segs) == 1:
+		return linkUserTracks, profile
+	case len(segs) == 2 && segs[1] == "tracks":
+		return linkUserTracks, profile
+	case len(segs) == 2 && segs[1] == "likes":
+		return linkLikes, profile
+	case len(segs) >= 3 && segs[1] == "sets":
+		// A private set carries its secret token as a fourth segment, which
+		// resolve needs; only the query (tracking noise) is dropped.
+		return linkSet, "https://soundcloud.com/" + strings.Join(segs, "/")
+	}
+	return linkTrack, ""
+}
+
 func (s *Source) SourceName() string {
 	return Name
 }
 
+func (s *Source) DisplayName() string {
+	return "SoundCloud"
+}
+
 func (s *Source) AvailableParsers() []string {
 	return []string{source.ParserScnativeLink, source.ParserYtdlpPipe, source.ParserYtdlpLink}
 }
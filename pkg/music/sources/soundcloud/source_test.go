@@ -0,0 +1,28 @@
+package soundcloud
+
+import "testing"
+
+func TestClassifyLink(t *testing.T) {
+	cases := []struct {
+		in        string
+		kind      linkKind
+		canonical string
+	}{
+		{"https://soundcloud.com/artist/a-song", linkTrack, ""},
+		{"https://soundcloud.com/artist/a-song/s-SECRET", linkTrack, ""},
+		{"https://soundcloud.com/artist/sets/an-album?si=abc", linkSet, "https://soundcloud.com/artist/sets/an-album"},
+		{"https://soundcloud.com/artist/sets/private/s-TOKEN", linkSet, "https://soundcloud.com/artist/sets/private/s-TOKEN"},
+		{"https://soundcloud.com/artist", linkUserTracks, "https://soundcloud.com/artist"},
+		{"https://m.soundcloud.com/artist/tracks/", linkUserTracks, "https://soundcloud.com/artist"},
+		{"https://soundcloud.com/artist/likes", linkLikes, "https://soundcloud.com/artist"},
+		{"https://soundcloud.com/artist/sets", linkTrack, ""},
+		{"https://soundcloud.com/discover", linkTrack, ""},
+		{"https://on.soundcloud.com/abc123", linkTrack, ""},
+	}
+	for _, tc := range cases {
+		kind, canonical := classifyLink(tc.in)
+		if kind != tc.kind || canonical != tc.canonical {
+			t.Errorf("classifyLink(%q) = %v, %q; want %v, %q", tc.in, kind, canonical, tc.kind, tc.canonical)
+		}
+	}
+}
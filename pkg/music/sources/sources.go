@@ -1,7 +1,10 @@
 // Package sources defines the Source interface and track types used by the resolver.
 package sources
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // Source name identifiers, used for source selection and persisted in playback history.
 const (
@@ -9,8 +12,28 @@ const (
 	YouTube    = "youtube"
 	Radio      = "radio"
 	SoundCloud = "soundcloud"
+	Local      = "local"
+	Direct     = "direct"
+	Podcast    = "podcast"
+	Bandcamp   = "bandcamp"
+	Catalog    = "catalog"
 )
 
+// MaxPlaylistItems caps how many tracks one link may contribute, whatever the
+// source. Playlists run to thousands of entries and mixes are formally
+// endless, so an unbounded expansion is a way to fill a guild's queue by
+// accident. It is exported because a limit nobody can see is
+// indistinguishable from a bug: /queue names it in its footer, which is why
+// every source shares this one number.
+const MaxPlaylistItems = 100
+
+// Resumable reports whether tracks from the source are long enough that a
+// guild's position in one is worth remembering, so playing it again continues
+// where it stopped rather than from the top.
+func Resumable(sourceName string) bool {
+	return sourceName == Podcast
+}
+
 // TrackInfo is a resolver's product: page-level track metadata plus an ordered
 // parser preference list. It deliberately carries no stream URLs — those expire,
 // so parsers resolve them lazily at open time.
@@ -19,20 +42,43 @@ type TrackInfo struct {
 	Title            string
 	SourceName       string
 	AvailableParsers []string
+	// PinnedParser is the parser the listener picked (/play parser:), which
+	// AvailableParsers lists first. Health ordering keeps it first and only
+	// reorders the fallbacks behind it. Empty when nobody picked one.
+	PinnedParser string
+	// Lazy is set on the one entry that stands for a whole long playlist (see
+	// LazyList). Such an entry is never played itself: the player swaps it for
+	// the list's next page when the queue reaches it.
+	Lazy *LazyList
+	// StreamURLs lists the streams a station playlist (PLS, M3U, XSPF) named,
+	// in the order to try them, when URL is that playlist rather than a
+	// stream. The parser plays the first that answers and moves down the list
+	// when one drops.
+	StreamURLs []string
+	// Duration is what the source already knows, such as an album's tracklist
+	// times, so the queue can show it before the track is opened. Zero when
+	// unknown; the parser sets the real one at open.
+	Duration time.Duration
+	// OriginURL is the link the track was queued from when that is not what
+	// plays: a Spotify or Apple Music track matched to a YouTube video keeps
+	// its catalogue link here, and the queue shows it in place of URL.
+	OriginURL string
 }
 
 // SearchResult is one hit from a source's ranked search, shaped for a chooser
 // UI rather than for playback.
 type SearchResult struct {
 	// ID is the source's own compact identifier for the track — a YouTube video
-	// id, a SoundCloud track id. It exists separately from URL because it has to
+	// id, a SoundCloud track id, a radio directory's station uuid. It exists separately from URL because it has to
 	// survive a round trip through a Discord component id, where a full URL does
 	// not reliably fit.
 	ID string
 	// URL is the canonical page for the track, used for display links.
 	URL string
 
-	Title  string
+	Title string
+	// Author is the uploader or artist. A radio station has neither, so there
+	// it carries the stream's codec and bitrate instead, e.g. "MP3 · 128 kbps".
 	Author string
 	// Duration is zero when the source reports none, which is what a live
 	// stream looks like.
@@ -40,9 +86,28 @@ type SearchResult struct {
 }
 
 // Searcher is implemented by sources that offer a ranked search worth choosing
-// from. It is deliberately not part of Source: radio has nothing to rank, and
-// resolving a query to one track is a different job from listing candidates.
+// from. It is deliberately not part of Source: a stream has nothing to rank
+// (radio's searcher is its station directory, a separate type), and resolving
+// a query to one track is a different job from listing candidates.
 type Searcher interface {
 	// Search returns at most limit hits in the source's own ranking.
 	Search(query string, limit int) ([]SearchResult, error)
 }
+
+// ContextSearcher is a Searcher whose requests can be bound to ctx.
+type ContextSearcher interface {
+	Searcher
+	SearchContext(ctx context.Context, query string, limit int) ([]SearchResult, error)
+}
+
+// SearchContext searches s, bound to ctx when s supports it; otherwise ctx is
+// only checked before the search starts.
+func SearchContext(ctx context.Context, s Searcher, query string, limit int) ([]SearchResult, error) {
+	if c, ok := s.(ContextSearcher); ok {
+		return c.SearchContext(ctx, query, limit)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Search(query, limit)
+}
@@ -0,0 +1,169 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Channel, artist and album links carry no list= but still name a list: a
+// channel's uploads are the playlist UU<channel id minus its UC>, and a
+// YouTube Music album is an OLAK5uy_ playlist behind an MPREb_ browse id. So
+// these links are mapped to a list id here and expanded by the same /browse
+// walk as any playlist, rather than each getting a walker of its own.
+
+// ListIDForURL maps a channel, artist or album link to the list it stands for.
+// ok is false for a URL that is none of those, which the caller handles as it
+// did before; err is set when the link is one but could not be mapped.
+//
+// Handles (/@name) and the legacy /c/ and /user/ forms need one request to
+// learn the channel id. A bare channel id and an album's OLAK id need none.
+func (p *PlaylistFetcher) ListIDForURL(raw string) (listID string, ok bool, err error) {
+	return p.ListIDForURLContext(context.Background(), raw)
+}
+
+// ListIDForURLContext is ListIDForURL with its request bound to ctx.
+func (p *PlaylistFetcher) ListIDForURLContext(ctx context.Context, raw string) (listID string, ok bool, err error) {
+	u, perr := url.Parse(strings.TrimSpace(raw))
+	if perr != nil {
+		return "", false, nil
+	}
+	switch strings.ToLower(u.Hostname()) {
+	case "youtube.com", "www.youtube.com", "m.youtube.com", "music.youtube.com":
+	default:
+		return "", false, nil
+	}
+	segs := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if !channelTab(segs) {
+		return "", false, nil
+	}
+
+	switch {
+	case segs[0] == "channel" && len(segs) >= 2,
+		segs[0] == "browse" && len(segs) >= 2 && strings.HasPrefix(segs[1], "UC"):
+		// /channel/UC… on either site; /browse/UC… is how YouTube Music links
+		// an artist.
+		id, err := uploadsListID(segs[1])
+		return id, true, err
+	case segs[0] == "browse" && len(segs) >= 2 && strings.HasPrefix(segs[1], "MPREb_"):
+		id, err := p.albumListID(ctx, segs[1])
+		return id, true, err
+	case strings.HasPrefix(segs[0], "@"),
+		(segs[0] == "c" || segs[0] == "user") && len(segs) >= 2:
+		channelID, err := p.resolveChannelID(ctx, u.String())
+		if err != nil {
+			return "", true, err
+		}
+		id, err := uploadsListID(channelID)
+		return id, true, err
+	}
+	return "", false, nil
+}
+
+// channelTab reports whether the path is a channel's front page or its videos
+// tab — the two that mean "this channel's uploads". /shorts, /streams and
+// /playlists name other lists, and are left alone rather than answered with
+// the wrong one.
+func channelTab(segs []string) bool {
+	base := 1
+	if segs[0] == "channel" || segs[0] == "browse" || segs[0] == "c" || segs[0] == "user" {
+		base = 2
+	}
+	switch {
+	case len(segs) == base:
+		return true
+	case len(segs) == base+1:
+		return segs[base] == "videos" || segs[base] == "featured"
+	}
+	return false
+}
+
+// uploadsListID is the uploads playlist of a channel id.
+func uploadsListID(channelID string) (string, error) {
+	if !strings.HasPrefix(channelID, "UC") || len(channelID) != 24 {
+		return "", fmt.Errorf("%w: %q is not a channel id", ErrPlaylistUnavailable, channelID)
+	}
+	return "UU" + channelID[2:], nil
+}
+
+// resolveChannelID asks InnerTube's navigation/resolve_url which channel a
+// handle or vanity URL belongs to. Like fetchMix it uses the WEB client, the
+// one the site itself resolves links with.
+func (p *PlaylistFetcher) resolveChannelID(ctx context.Context, channelURL string) (string, error) {
+	body := webContext()
+	body["url"] = channelURL
+
+	var resp struct {
+		Endpoint struct {
+			BrowseEndpoint struct {
+				BrowseID string `json:"browseId"`
+			} `json:"browseEndpoint"`
+		} `json:"endpoint"`
+	}
+	if err := p.post(ctx, "/youtubei/v1/navigation/resolve_url", body, webUserAgent, &resp); err != nil {
+		var he *httpError
+		if errors.As(err, &he) && he.Code >= 400 && he.Code < 500 {
+			return "", fmt.Errorf("%w (%s)", ErrPlaylistUnavailable, he.Status)
+		}
+		return "", err
+	}
+	id := resp.Endpoint.BrowseEndpoint.BrowseID
+	if id == "" {
+		return "", fmt.Errorf("%w: no channel behind %s", ErrPlaylistUnavailable, channelURL)
+	}
+	return id, nil
+}
+
+const (
+	musicClientName = "WEB_REMIX"
+	// musicClientVersion, like webClientVersion, is old and known to work.
+	musicClientVersion = "1.20230815.01.00"
+)
+
+// albumListID reads the OLAK5uy_ playlist behind a YouTube Music album browse
+// id. The album page states it as its canonical URL, music.youtube.com/
+// playlist?list=OLAK5uy_…, which is the same list a shared album link
+// carries. Only the YouTube Music client gets an album page at all.
+func (p *PlaylistFetcher) albumListID(ctx context.Context, browseID string) (string, error) {
+	body := map[string]any{
+		"context": map[string]any{"client": map[string]any{
+			"clientName":    musicClientName,
+			"clientVersion": musicClientVersion,
+			"hl":            "en",
+			"gl":            "US",
+		}},
+		"browseId": browseID,
+	}
+	var resp struct {
+		Microformat struct {
+			Data struct {
+				URLCanonical string `json:"urlCanonical"`
+			} `json:"microformatDataRenderer"`
+		} `json:"microformat"`
+	}
+	if err := p.post(ctx, "/youtubei/v1/browse", body, webUserAgent, &resp); err != nil {
+		var he *httpError
+		if errors.As(err, &he) && he.Code >= 400 && he.Code < 500 {
+			return "", fmt.Errorf("%w (%s)", ErrPlaylistUnavailable, he.Status)
+		}
+		return "", err
+	}
+	if id := ExtractListID(resp.Microformat.Data.URLCanonical); id != "" {
+		return id, nil
+	}
+	return "", fmt.Errorf("%w: album %s has no playlist", ErrPlaylistUnavailable, browseID)
+}
+
+// webContext is the request base for the WEB client; see fetchMix.
+func webContext() map[string]any {
+	return map[string]any{
+		"context": map[string]any{"client": map[string]any{
+			"clientName":    webClientName,
+			"clientVersion": webClientVersion,
+			"hl":            "en",
+			"gl":            "US",
+		}},
+	}
+}
@@ -2,20 +2,20 @@ package youtube
 
 import (
 	"errors"
-	"os"
 	"strings"
 	"testing"
+
+	"github.com/keshon/melodix/pkg/music/httpreplay/replaytest"
 )
 
 // Live canaries for playlist expansion. InnerTube response shapes drift and the
 // two endpoints drift independently, so each kind of list gets its own check.
-// Opt-in: MELODIX_LIVE_TESTS=1 go test -run Live -v ./pkg/music/sources/youtube
+// They replay testdata/replay when it has been recorded (MELODIX_RECORD_FIXTURES=1);
+// against the network: MELODIX_LIVE_TESTS=1 go test -run Live -v ./pkg/music/sources/youtube
 
 func liveFetcher(t *testing.T) *PlaylistFetcher {
 	t.Helper()
-	if os.Getenv("MELODIX_LIVE_TESTS") == "" {
-		t.Skip("set MELODIX_LIVE_TESTS=1 to hit real YouTube")
-	}
+	replaytest.Start(t, "testdata/replay")
 	return NewPlaylistFetcher()
 }
 
@@ -89,9 +89,7 @@ func TestLivePlaylistUnavailable(t *testing.T) {
 // TestLiveSearch is the canary for the /search path. It shares the client
 // version with playback, so a failure here usually means the same bump.
 func TestLiveSearch(t *testing.T) {
-	if os.Getenv("MELODIX_LIVE_TESTS") == "" {
-		t.Skip("set MELODIX_LIVE_TESTS=1 to hit real YouTube")
-	}
+	replaytest.Start(t, "testdata/replay")
 
 	got, err := NewSearcher().Search("daft punk around the world", 5)
 	if err != nil {
@@ -2,6 +2,7 @@ package youtube
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,7 +11,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/keshon/melodix/pkg/music/httpreplay"
 	"github.com/keshon/melodix/pkg/music/innertube"
+	source "github.com/keshon/melodix/pkg/music/sources"
 )
 
 // Playlist expansion goes through InnerTube rather than page scraping: the watch
@@ -24,15 +27,14 @@ import (
 // has no browsable page at all — /browse answers "This playlist type is
 // unviewable" — and comes from /next instead.
 const (
-	// MaxPlaylistItems caps how many tracks one link may contribute. Playlists
-	// run to thousands of entries and mixes are formally endless, so an
-	// unbounded expansion is a way to fill a guild's queue by accident. It is
-	// exported because a limit nobody can see is indistinguishable from a bug:
-	// /queue names it in its footer.
-	MaxPlaylistItems = 100
+	// MaxPlaylistItems caps how many tracks one link may contribute.
+	//
+	// Deprecated: use sources.MaxPlaylistItems.
+	MaxPlaylistItems = source.MaxPlaylistItems
 
 	// playlistPageSize is what /browse returns per continuation. Informational:
-	// paging stops on the cap or a missing token, never on this number.
+	// paging stops on the cap (sources.MaxPlaylistItems) or a missing token,
+	// never on this number.
 	playlistPageSize = 20
 )
 
@@ -56,6 +58,12 @@ type PlaylistEntry struct {
 type PlaylistResult struct {
 	Title   string
 	Entries []PlaylistEntry
+	// Continuation is set when the list goes on past Entries: the token for
+	// the next /browse page, or for a mix the last video id, which /next
+	// continues from. Callers that want more use it through a LazyList.
+	Continuation string
+	// Total is the list's stated length, 0 when YouTube gives none (a mix).
+	Total int
 }
 
 // PlaylistFetcher expands a YouTube list id into entries. BaseURL and Client are
@@ -70,7 +78,8 @@ func NewPlaylistFetcher() *PlaylistFetcher {
 	return &PlaylistFetcher{
 		BaseURL: "https://www.youtube.com",
 		Client: &http.Client{
-			Timeout: 15 * time.Second,
+			Timeout:   15 * time.Second,
+			Transport: httpreplay.Wrap(nil),
 		},
 	}
 }
@@ -85,79 +94,108 @@ func IsMixID(listID string) bool {
 // seedVideoID is optional and only used for mixes, where it pins the generated
 // order to start at the video the user actually linked.
 func (p *PlaylistFetcher) Fetch(listID, seedVideoID string) (PlaylistResult, error) {
+	return p.FetchContext(context.Background(), listID, seedVideoID)
+}
+
+// FetchContext is Fetch with its requests bound to ctx.
+func (p *PlaylistFetcher) FetchContext(ctx context.Context, listID, seedVideoID string) (PlaylistResult, error) {
 	if strings.TrimSpace(listID) == "" {
 		return PlaylistResult{}, errors.New("youtube: empty playlist id")
 	}
 	if IsMixID(listID) {
-		return p.fetchMix(listID, seedVideoID)
+		return p.fetchMix(ctx, listID, seedVideoID)
 	}
-	return p.fetchPlaylist(listID)
+	return p.fetchPlaylist(ctx, listID)
 }
 
-// fetchPlaylist walks /browse, following legacy continuation tokens until the
-// list ends or MaxPlaylistItems is reached.
-func (p *PlaylistFetcher) fetchPlaylist(listID string) (PlaylistResult, error) {
-	body := innertube.Context()
-	body["browseId"] = "VL" + listID
-	body["contentCheckOk"] = true
-	body["racyCheckOk"] = true
-
+// fetchPlaylist walks /browse until the list ends or a page boundary at or
+// past the per-link cap. Stopping at a boundary rather than mid-page keeps
+// Continuation exact: it resumes after the last entry returned, so a lazy
+// entry built from the result neither skips nor repeats a video.
+func (p *PlaylistFetcher) fetchPlaylist(ctx context.Context, listID string) (PlaylistResult, error) {
 	var out PlaylistResult
-	var token string
-
+	token := ""
 	for {
-		var resp browseResponse
-		if err := p.post("/youtubei/v1/browse", body, innertube.UserAgent, &resp); err != nil {
-			// 400/404 is how a bad, deleted or private list id comes back; the
-			// raw API body is noise in a chat reply, so only the status carries
-			// over. (The 200-with-ERROR-alert shape below is the other refusal.)
-			var he *httpError
-			if errors.As(err, &he) && he.Code >= 400 && he.Code < 500 {
-				return PlaylistResult{}, fmt.Errorf("%w (%s)", ErrPlaylistUnavailable, he.Status)
+		page, err := p.playlistPage(ctx, listID, token)
+		if err != nil {
+			if out.Entries != nil && errors.Is(err, ErrPlaylistEmpty) {
+				break // a later page with no list simply ends the walk
 			}
 			return PlaylistResult{}, err
 		}
-		if reason := resp.errorAlert(); reason != "" {
-			return PlaylistResult{}, fmt.Errorf("%w: %s", ErrPlaylistUnavailable, reason)
-		}
 		if out.Title == "" {
-			out.Title = resp.Header.PlaylistHeader.Title.String()
+			out.Title, out.Total = page.Title, page.Total
 		}
-
-		list := resp.videoList()
-		if list == nil {
-			// First page with no list at all is a refusal we could not read from
-			// an alert; a later page simply ends the walk.
-			if out.Entries == nil {
-				return PlaylistResult{}, ErrPlaylistUnavailable
-			}
-			break
+		if out.Entries == nil {
+			out.Entries = []PlaylistEntry{}
 		}
-		for _, item := range list.Contents {
-			if item.PlaylistVideoRenderer == nil || item.PlaylistVideoRenderer.VideoID == "" {
-				continue
-			}
-			out.Entries = append(out.Entries, PlaylistEntry{
-				VideoID: item.PlaylistVideoRenderer.VideoID,
-				Title:   item.PlaylistVideoRenderer.Title.String(),
-			})
-			if len(out.Entries) >= MaxPlaylistItems {
-				return out, nil
-			}
-		}
-
-		token = list.continuation()
-		if token == "" {
+		out.Entries = append(out.Entries, page.Entries...)
+		token = page.Continuation
+		if token == "" || len(out.Entries) >= source.MaxPlaylistItems {
 			break
 		}
+	}
+	out.Continuation = token
+
+	if len(out.Entries) == 0 {
+		return PlaylistResult{}, ErrPlaylistEmpty
+	}
+	return out, nil
+}
+
+// playlistPage fetches one /browse page of a stored playlist: the first when
+// token is "", otherwise the page the token continues to. A later page with
+// no list in it is reported as ErrPlaylistEmpty.
+func (p *PlaylistFetcher) playlistPage(ctx context.Context, listID, token string) (PlaylistResult, error) {
+	body := innertube.Context()
+	if token == "" {
+		body["browseId"] = "VL" + listID
+		body["contentCheckOk"] = true
+		body["racyCheckOk"] = true
+	} else {
 		// A continuation request carries the token instead of the browseId.
-		body = innertube.Context()
 		body["continuation"] = token
 	}
 
-	if len(out.Entries) == 0 {
+	var resp browseResponse
+	if err := p.post(ctx, "/youtubei/v1/browse", body, innertube.UserAgent, &resp); err != nil {
+		// 400/404 is how a bad, deleted or private list id comes back; the
+		// raw API body is noise in a chat reply, so only the status carries
+		// over. (The 200-with-ERROR-alert shape below is the other refusal.)
+		var he *httpError
+		if errors.As(err, &he) && he.Code >= 400 && he.Code < 500 {
+			return PlaylistResult{}, fmt.Errorf("%w (%s)", ErrPlaylistUnavailable, he.Status)
+		}
+		return PlaylistResult{}, err
+	}
+	if reason := resp.errorAlert(); reason != "" {
+		return PlaylistResult{}, fmt.Errorf("%w: %s", ErrPlaylistUnavailable, reason)
+	}
+
+	list := resp.videoList()
+	if list == nil {
+		// A first page with no list at all is a refusal we could not read
+		// from an alert; a later one just means the list has ended.
+		if token == "" {
+			return PlaylistResult{}, ErrPlaylistUnavailable
+		}
 		return PlaylistResult{}, ErrPlaylistEmpty
 	}
+	out := PlaylistResult{
+		Title:        resp.Header.PlaylistHeader.Title.String(),
+		Total:        resp.Header.PlaylistHeader.videoCount(),
+		Entries:      []PlaylistEntry{},
+		Continuation: list.continuation(),
+	}
+	for _, item := range list.Contents {
+		if item.PlaylistVideoRenderer == nil || item.PlaylistVideoRenderer.VideoID == "" {
+			continue
+		}
+		out.Entries = append(out.Entries, PlaylistEntry{
+			VideoID: item.PlaylistVideoRenderer.VideoID,
+			Title:   item.PlaylistVideoRenderer.Title.String(),
+		})
+	}
 	return out, nil
 }
 
@@ -169,24 +207,17 @@ func (p *PlaylistFetcher) fetchPlaylist(listID string) (PlaylistResult, error) {
 // serves this endpoint against a clientVersion from 2022, whereas app clients
 // get retired. The CDN's per-issuing-client rules do not apply here: nothing in
 // this response is a stream URL.
-func (p *PlaylistFetcher) fetchMix(listID, seedVideoID string) (PlaylistResult, error) {
-	body := map[string]any{
-		"context": map[string]any{"client": map[string]any{
-			"clientName":    webClientName,
-			"clientVersion": webClientVersion,
-			"hl":            "en",
-			"gl":            "US",
-		}},
-		"playlistId":     listID,
-		"contentCheckOk": true,
-		"racyCheckOk":    true,
-	}
+func (p *PlaylistFetcher) fetchMix(ctx context.Context, listID, seedVideoID string) (PlaylistResult, error) {
+	body := webContext()
+	body["playlistId"] = listID
+	body["contentCheckOk"] = true
+	body["racyCheckOk"] = true
 	if seedVideoID != "" {
 		body["videoId"] = seedVideoID
 	}
 
 	var resp nextResponse
-	if err := p.post("/youtubei/v1/next", body, webUserAgent, &resp); err != nil {
+	if err := p.post(ctx, "/youtubei/v1/next", body, webUserAgent, &resp); err != nil {
 		return PlaylistResult{}, err
 	}
 
@@ -200,13 +231,16 @@ func (p *PlaylistFetcher) fetchMix(listID, seedVideoID string) (PlaylistResult,
 			VideoID: item.PlaylistPanelVideoRenderer.VideoID,
 			Title:   item.PlaylistPanelVideoRenderer.Title.String(),
 		})
-		if len(out.Entries) >= MaxPlaylistItems {
+		if len(out.Entries) >= source.MaxPlaylistItems {
 			break
 		}
 	}
 	if len(out.Entries) == 0 {
 		return PlaylistResult{}, ErrPlaylistEmpty
 	}
+	// A mix never ends: asking /next again, seeded with the last video,
+	// returns the window that follows it.
+	out.Continuation = out.Entries[len(out.Entries)-1].VideoID
 	return out, nil
 }
 
@@ -231,12 +265,12 @@ func (e *httpError) Error() string {
 	return fmt.Sprintf("youtube: %s: %s: %s", e.Endpoint, e.Status, e.Body)
 }
 
-func (p *PlaylistFetcher) post(path string, body map[string]any, userAgent string, out any) error {
+func (p *PlaylistFetcher) post(ctx context.Context, path string, body map[string]any, userAgent string, out any) error {
 	payload, err := json.Marshal(body)
 	if err != nil {
 		return err
 	}
-	req, err := http.NewRequest(http.MethodPost, p.BaseURL+path+"?prettyPrint=false", bytes.NewReader(payload))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.BaseURL+path+"?prettyPrint=false", bytes.NewReader(payload))
 	if err != nil {
 		return err
 	}
@@ -70,9 +70,7 @@ type browseResponse struct {
 		PlaylistVideoListContinuation *playlistVideoList `json:"playlistVideoListContinuation"`
 	} `json:"continuationContents"`
 	Header struct {
-		PlaylistHeader struct {
-			Title runsText `json:"title"`
-		} `json:"playlistHeaderRenderer"`
+		PlaylistHeader playlistHeader `json:"playlistHeaderRenderer"`
 	} `json:"header"`
 	Alerts []struct {
 		AlertRenderer struct {
@@ -82,6 +80,44 @@ type browseResponse struct {
 	} `json:"alerts"`
 }
 
+type playlistHeader struct {
+	Title runsText `json:"title"`
+	// NumVideosText ("2,000 videos") and Stats (whose first entry says the
+	// same) are the two places the length has been seen; which one is filled
+	// depends on the client.
+	NumVideosText runsText   `json:"numVideosText"`
+	Stats         []runsText `json:"stats"`
+}
+
+// videoCount reads the list's stated length, or 0 when neither field has one.
+func (h playlistHeader) videoCount() int {
+	texts := []string{h.NumVideosText.String()}
+	for _, st := range h.Stats {
+		texts = append(texts, st.String())
+	}
+	for _, t := range texts {
+		if !strings.Contains(strings.ToLower(t), "video") {
+			continue
+		}
+		n := 0
+		for _, r := range t {
+			switch {
+			case r >= '0' && r <= '9':
+				n = n*10 + int(r-'0')
+			case r == ',' || r == '.' || r == ' ' || r == '\u00a0':
+			default:
+				if n > 0 {
+					return n
+				}
+			}
+		}
+		if n > 0 {
+			return n
+		}
+	}
+	return 0
+}
+
 // videoList returns the page's list, from either the first-page path or the
 // continuation path, or nil when the response carries neither.
 func (r *browseResponse) videoList() *playlistVideoList {
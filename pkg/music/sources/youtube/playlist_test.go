@@ -8,7 +8,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
+
+	source "github.com/keshon/melodix/pkg/music/sources"
 )
 
 // browsePage builds a /browse response body with the given entries and an
@@ -108,8 +111,8 @@ func TestFetchPlaylistStopsAtCap(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Fetch: %v", err)
 	}
-	if len(got.Entries) != MaxPlaylistItems {
-		t.Fatalf("entries = %d, want cap %d", len(got.Entries), MaxPlaylistItems)
+	if len(got.Entries) != source.MaxPlaylistItems {
+		t.Fatalf("entries = %d, want cap %d", len(got.Entries), source.MaxPlaylistItems)
 	}
 }
 
@@ -321,3 +324,176 @@ func TestIsMixID(t *testing.T) {
 		}
 	}
 }
+
+func TestListIDForChannelLinksNeedsNoRequest(t *testing.T) {
+	t.Parallel()
+	f := newFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	})
+	for _, u := range []string{
+		"https://www.youtube.com/channel/UCuAXFkgsw1L7xaCfnd5JJOw",
+		"https://www.youtube.com/channel/UCuAXFkgsw1L7xaCfnd5JJOw/videos",
+		"https://music.youtube.com/channel/UCuAXFkgsw1L7xaCfnd5JJOw",
+		"https://music.youtube.com/browse/UCuAXFkgsw1L7xaCfnd5JJOw",
+	} {
+		id, ok, err := f.ListIDForURL(u)
+		if !ok || err != nil || id != "UUuAXFkgsw1L7xaCfnd5JJOw" {
+			t.Errorf("ListIDForURL(%q) = %q, %v, %v; want the UU uploads list", u, id, ok, err)
+		}
+	}
+}
+
+func TestListIDForHandleResolvesChannel(t *testing.T) {
+	t.Parallel()
+	var body map[string]any
+	var path string
+	f := newFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		raw, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(raw, &body)
+		fmt.Fprint(w, `{"endpoint":{"browseEndpoint":{"browseId":"UCuAXFkgsw1L7xaCfnd5JJOw"}}}`)
+	})
+
+	id, ok, err := f.ListIDForURL("https://www.youtube.com/@RickAstleyYT/videos")
+	if !ok || err != nil {
+		t.Fatalf("ListIDForURL: ok=%v err=%v", ok, err)
+	}
+	if id != "UUuAXFkgsw1L7xaCfnd5JJOw" {
+		t.Fatalf("list id = %q", id)
+	}
+	if path != "/youtubei/v1/navigation/resolve_url" {
+		t.Fatalf("handle must go through resolve_url, got %q", path)
+	}
+	if body["url"] != "https://www.youtube.com/@RickAstleyYT/videos" {
+		t.Fatalf("request body = %v", body)
+	}
+}
+
+func TestListIDForUnknownHandleIsUnavailable(t *testing.T) {
+	t.Parallel()
+	f := newFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":{"code":404,"message":"Requested entity was not found."}}`)
+	})
+	_, ok, err := f.ListIDForURL("https://www.youtube.com/@nobody-at-all")
+	if !ok || !errors.Is(err, ErrPlaylistUnavailable) {
+		t.Fatalf("ok=%v err=%v, want ErrPlaylistUnavailable", ok, err)
+	}
+}
+
+func TestListIDForAlbumReadsCanonicalPlaylist(t *testing.T) {
+	t.Parallel()
+	var body map[string]any
+	f := newFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(raw, &body)
+		fmt.Fprint(w, `{"microformat":{"microformatDataRenderer":{
+			"urlCanonical":"https://music.youtube.com/playlist?list=OLAK5uy_kx9bNtJHbXnUhDQkY6p8jVDrUyAHW2wW0"}}}`)
+	})
+
+	id, ok, err := f.ListIDForURL("https://music.youtube.com/browse/MPREb_BkfDEt8GFvZ")
+	if !ok || err != nil || id != "OLAK5uy_kx9bNtJHbXnUhDQkY6p8jVDrUyAHW2wW0" {
+		t.Fatalf("ListIDForURL = %q, %v, %v", id, ok, err)
+	}
+	if body["browseId"] != "MPREb_BkfDEt8GFvZ" {
+		t.Fatalf("browseId = %v", body["browseId"])
+	}
+	client := body["context"].(map[string]any)["client"].(map[string]any)
+	if client["clientName"] != musicClientName {
+		t.Fatalf("album page needs the YouTube Music client, got %v", client["clientName"])
+	}
+}
+
+func TestListIDForOtherLinks(t *testing.T) {
+	t.Parallel()
+	f := newFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	})
+	for _, u := range []string{
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		"https://youtu.be/dQw4w9WgXcQ",
+		"https://www.youtube.com/@RickAstleyYT/shorts", // another list; not uploads
+		"https://www.youtube.com/results?search_query=x",
+		"https://example.com/channel/UCuAXFkgsw1L7xaCfnd5JJOw",
+	} {
+		if _, ok, _ := f.ListIDForURL(u); ok {
+			t.Errorf("ListIDForURL(%q) claimed a link it should leave alone", u)
+		}
+	}
+}
+
+func TestResolveLongPlaylistQueuesOneLazyEntry(t *testing.T) {
+	t.Parallel()
+	pageIDs := func(prefix string) []string {
+		ids := make([]string, playlistPageSize)
+		for i := range ids {
+			ids[i] = fmt.Sprintf("%s%09d", prefix, i)
+		}
+		return ids
+	}
+	var requests atomic.Int32
+	f := newFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		raw, _ := io.ReadAll(r.Body)
+		var b map[string]any
+		_ = json.Unmarshal(raw, &b)
+		if tok, ok := b["continuation"].(string); ok {
+			fmt.Fprint(w, continuationPage(pageIDs(tok), tok+"x"))
+			return
+		}
+		page := browsePage("Long List", pageIDs("aa"), "bb")
+		page = strings.Replace(page, `"playlistHeaderRenderer":{`,
+			`"playlistHeaderRenderer":{"numVideosText":{"runs":[{"text":"2,000"},{"text":" videos"}]},`, 1)
+		fmt.Fprint(w, page)
+	})
+	y := &Source{playlists: f}
+
+	got, err := y.Resolve("https://www.youtube.com/playlist?list=PL123456789012", "")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if len(got) != 1 || got[0].Lazy == nil {
+		t.Fatalf("expected one lazy entry, got %d tracks", len(got))
+	}
+	lazy := got[0].Lazy
+	if served, total := lazy.Progress(); served != 0 || total != 2000 {
+		t.Fatalf("progress = %d/%d, want 0/2000", served, total)
+	}
+	fetchedAtResolve := requests.Load()
+
+	// The first cap's worth is served from what Resolve already fetched.
+	for served := 0; served < source.MaxPlaylistItems; {
+		page, err := lazy.NextPage()
+		if err != nil || len(page) == 0 {
+			t.Fatalf("page after %d: %d tracks, err %v", served, len(page), err)
+		}
+		served += len(page)
+	}
+	if n := requests.Load(); n != fetchedAtResolve {
+		t.Fatalf("paged %d extra requests while buffered tracks remained", n-fetchedAtResolve)
+	}
+	page, err := lazy.NextPage()
+	if err != nil || len(page) == 0 {
+		t.Fatalf("next page past the cap: %d tracks, err %v", len(page), err)
+	}
+	if lazy.Done() {
+		t.Fatal("a list with a continuation reported done")
+	}
+	if got := lazy.Label(); !strings.HasPrefix(got, "Long List (1") || !strings.HasSuffix(got, "/2000)") {
+		t.Fatalf("label = %q", got)
+	}
+}
+
+func TestResolveShortPlaylistStaysEager(t *testing.T) {
+	t.Parallel()
+	f := newFetcher(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, browsePage("Short", []string{"aaaaaaaaaaa", "bbbbbbbbbbb"}, ""))
+	})
+	got, err := (&Source{playlists: f}).Resolve("https://www.youtube.com/playlist?list=PL123456789012", "")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if len(got) != 2 || got[0].Lazy != nil {
+		t.Fatalf("expected 2 plain tracks, got %+v", got)
+	}
+}
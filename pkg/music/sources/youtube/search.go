@@ -2,6 +2,7 @@ package youtube
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/keshon/melodix/pkg/music/httpreplay"
 	"github.com/keshon/melodix/pkg/music/innertube"
 	source "github.com/keshon/melodix/pkg/music/sources"
 )
@@ -39,7 +41,8 @@ func NewSearcher() *Searcher {
 	return &Searcher{
 		BaseURL: "https://www.youtube.com",
 		Client: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout:   10 * time.Second,
+			Transport: httpreplay.Wrap(nil),
 		},
 	}
 }
@@ -50,6 +53,11 @@ func NewSearcher() *Searcher {
 // only ever yielded video ids, and a chooser needs titles, authors and
 // durations to be worth showing.
 func (r *Searcher) Search(query string, limit int) ([]source.SearchResult, error) {
+	return r.SearchContext(context.Background(), query, limit)
+}
+
+// SearchContext is Search with its request bound to ctx.
+func (r *Searcher) SearchContext(ctx context.Context, query string, limit int) ([]source.SearchResult, error) {
 	query = strings.TrimSpace(query)
 	if query == "" {
 		return nil, errors.New("youtube: empty search query")
@@ -66,7 +74,7 @@ func (r *Searcher) Search(query string, limit int) ([]source.SearchResult, error
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequest(http.MethodPost, r.BaseURL+"/youtubei/v1/search?prettyPrint=false", bytes.NewReader(payload))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.BaseURL+"/youtubei/v1/search?prettyPrint=false", bytes.NewReader(payload))
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package youtube
 
 import (
+	"context"
 	"errors"
 	"slices"
 	"strings"
@@ -13,6 +14,8 @@ const Name string = "youtube"
 
 // Source resolves YouTube URLs and search queries.
 type Source struct {
+	source.TakesQueries
+
 	searcher  *Searcher
 	playlists *PlaylistFetcher
 }
@@ -30,6 +33,13 @@ func (y *Source) Match(input string) bool {
 }
 
 func (y *Source) Resolve(input string, selectedParser string) ([]source.TrackInfo, error) {
+	return y.ResolveContext(context.Background(), input, selectedParser)
+}
+
+// ResolveContext is Resolve with its InnerTube requests bound to ctx. A lazy
+// list it returns fetches its later pages under the context NextPageContext
+// is given, not this one.
+func (y *Source) ResolveContext(ctx context.Context, input string, selectedParser string) ([]source.TrackInfo, error) {
 	parsers := y.AvailableParsers()
 
 	if selectedParser == "" {
@@ -49,21 +59,30 @@ func (y *Source) Resolve(input string, selectedParser string) ([]source.TrackInf
 	// playlist or mix: one link expands to many tracks
 	if listID := ExtractListID(input); shouldExpandList(listID) {
 		seed := ExtractVideoID(input)
-		result, err := y.playlists.Fetch(listID, seed)
+		result, err := y.playlists.FetchContext(ctx, listID, seed)
 		if err != nil {
 			return nil, err
 		}
 		entries := seedFirst(result.Entries, seed)
-		tracks := make([]source.TrackInfo, 0, len(entries))
-		for _, e := range entries {
-			tracks = append(tracks, source.TrackInfo{
-				URL:              VideoURL(e.VideoID),
-				Title:            e.Title,
-				SourceName:       Name,
-				AvailableParsers: preferred,
-			})
+		if result.Continuation == "" && len(entries) <= source.MaxPlaylistItems {
+			return entryTracks(entries, preferred), nil
+		}
+		return []source.TrackInfo{y.lazyList(listID, result, entries, preferred)}, nil
+	}
+
+	// channel, artist or album: a list in all but the URL
+	if listID, ok, err := y.playlists.ListIDForURLContext(ctx, input); ok {
+		if err != nil {
+			return nil, err
+		}
+		result, err := y.playlists.FetchContext(ctx, listID, "")
+		if err != nil {
+			return nil, err
+		}
+		if result.Continuation == "" {
+			return entryTracks(result.Entries, preferred), nil
 		}
-		return tracks, nil
+		return []source.TrackInfo{y.lazyList(listID, result, result.Entries, preferred)}, nil
 	}
 
 	// direct video URL
@@ -84,7 +103,10 @@ func (y *Source) Resolve(input string, selectedParser string) ([]source.TrackInf
 	}
 
 	// by title
-	hits, err := y.searcher.Search(input, 1)
+	hits, err := y.searcher.SearchContext(ctx, input, 1)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
 	if err != nil || len(hits) == 0 {
 		return nil, errors.New("could not find YouTube video for query")
 	}
@@ -101,10 +123,84 @@ func (y *Source) Resolve(input string, selectedParser string) ([]source.TrackInf
 	}, nil
 }
 
+// lazyList queues a list that runs past the per-link cap, or a mix, which
+// never ends, as one entry (see sources.LazyList). The entries fetched while
+// finding that out are served first; after them a stored playlist continues
+// with its /browse token, and a mix asks /next again from its last video,
+// keeping only videos it has not yielded yet — once a window brings nothing
+// new, the mix has started repeating itself and the entry ends.
+func (y *Source) lazyList(listID string, first PlaylistResult, entries []PlaylistEntry, parsers []string) source.TrackInfo {
+	var page source.PageContextFunc
+	if IsMixID(listID) {
+		seen := make(map[string]bool, len(entries))
+		for _, e := range entries {
+			seen[e.VideoID] = true
+		}
+		page = func(ctx context.Context, last string) ([]source.TrackInfo, string, error) {
+			res, err := y.playlists.FetchContext(ctx, listID, last)
+			if err != nil {
+				return nil, "", err
+			}
+			var fresh []PlaylistEntry
+			for _, e := range res.Entries {
+				if !seen[e.VideoID] {
+					seen[e.VideoID] = true
+					fresh = append(fresh, e)
+				}
+			}
+			if len(fresh) == 0 {
+				return nil, "", nil
+			}
+			return entryTracks(fresh, parsers), fresh[len(fresh)-1].VideoID, nil
+		}
+	} else {
+		page = func(ctx context.Context, token string) ([]source.TrackInfo, string, error) {
+			res, err := y.playlists.playlistPage(ctx, listID, token)
+			if errors.Is(err, ErrPlaylistEmpty) {
+				return nil, "", nil
+			}
+			if err != nil {
+				return nil, "", err
+			}
+			return entryTracks(res.Entries, parsers), res.Continuation, nil
+		}
+	}
+
+	title := first.Title
+	if title == "" {
+		title = "YouTube playlist"
+	}
+	return source.TrackInfo{
+		URL:              "https://www.youtube.com/playlist?list=" + listID,
+		Title:            title,
+		SourceName:       Name,
+		AvailableParsers: parsers,
+		Lazy: source.NewLazyListContext(listID, title, first.Total,
+			entryTracks(entries, parsers), first.Continuation, page),
+	}
+}
+
+func entryTracks(entries []PlaylistEntry, parsers []string) []source.TrackInfo {
+	tracks := make([]source.TrackInfo, 0, len(entries))
+	for _, e := range entries {
+		tracks = append(tracks, source.TrackInfo{
+			URL:              VideoURL(e.VideoID),
+			Title:            e.Title,
+			SourceName:       Name,
+			AvailableParsers: parsers,
+		})
+	}
+	return tracks
+}
+
 func (y *Source) SourceName() string {
 	return Name
 }
 
+func (y *Source) DisplayName() string {
+	return "YouTube"
+}
+
 func (y *Source) AvailableParsers() []string {
 	// Passthrough paths first (ytnative, then kkdai-pipe — no ffmpeg), then the
 	// ffmpeg-encode fallbacks (kkdai-link, yt-dlp).
@@ -0,0 +1,57 @@
+package stream
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/keshon/melodix/pkg/music/bandcampapi"
+	"github.com/keshon/melodix/pkg/music/breaker"
+	"github.com/keshon/melodix/pkg/music/httpfile"
+	"github.com/keshon/melodix/pkg/music/library"
+	"github.com/keshon/melodix/pkg/music/opus"
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/parsers/ytnative"
+	"github.com/keshon/melodix/pkg/music/soundcloudapi"
+)
+
+// breakers holds a circuit breaker per parser key, so a parser whose tool or
+// site is down (yt-dlp not installed, an extraction broken for everyone) fails
+// at once instead of costing every track a spawn or a round trip first. It is
+// breaker.Default() in production; tests swap it with SetBreakers.
+var breakers atomic.Pointer[breaker.Set]
+
+func init() { breakers.Store(breaker.Default()) }
+
+// SetBreakers swaps the set parser breakers come from and returns the previous
+// one. Intended for tests, which restore the original in a cleanup.
+func SetBreakers(s *breaker.Set) *breaker.Set { return breakers.Swap(s) }
+
+// parserBreaker returns the breaker guarding the parser's Open.
+func parserBreaker(parser string) *breaker.Breaker {
+	return breakers.Load().Get("parser " + parser)
+}
+
+// trackLevel reports whether an Open error is about the track rather than the
+// parser: the video is cipher-only, the file is not audio, the link expired.
+// Those say nothing about the parser's health, so they neither trip its
+// breaker nor close it.
+func trackLevel(err error) bool {
+	for _, target := range []error{
+		ytnative.ErrCipherOnly,
+		ytnative.ErrNotPlayable,
+		ytnative.ErrNoAudio,
+		opus.ErrNotPassthrough,
+		soundcloudapi.ErrNoTranscoding,
+		bandcampapi.ErrNotStreamable,
+		httpfile.ErrNotAFile,
+		httpfile.ErrAttachmentExpired,
+		library.ErrNotFound,
+		library.ErrOutsideRoots,
+		parsers.ErrSignInRequired,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/keshon/melodix/pkg/music/breaker"
+	"github.com/keshon/melodix/pkg/music/opus"
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/parsers/ytnative"
+)
+
+func TestOpen_SkipsAParserWithAnOpenBreaker(t *testing.T) {
+	set := breaker.NewSet()
+	origBreakers := SetBreakers(set)
+	origHealth := SetHealth(NewHealthTracker())
+	t.Cleanup(func() {
+		SetBreakers(origBreakers)
+		SetHealth(origHealth)
+	})
+
+	missingCalls := 0
+	orig := SetRegistry(map[string]parsers.Streamer{
+		"missing": fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+			missingCalls++
+			return nil, nil, errors.New(`exec: "yt-dlp": executable file not found in $PATH`)
+		}},
+		"good": fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+			return &pktReader{pkts: [][]byte{{0xAA}}}, func() {}, nil
+		}},
+	})
+	t.Cleanup(func() { SetRegistry(orig) })
+
+	// The failing parser alone, so health ordering cannot hide it.
+	open := func() error {
+		rs := NewRecoveryStream(hedgeTrack("missing"))
+		defer rs.Close()
+		return rs.Open(0)
+	}
+	for range breaker.DefaultThreshold {
+		if err := open(); err == nil {
+			t.Fatal("Open succeeded on a missing tool")
+		}
+	}
+	if st := set.Get("parser missing").Status(); st.State != breaker.Open {
+		t.Fatalf("breaker state = %v after %d failures, want open", st.State, breaker.DefaultThreshold)
+	}
+	if err := open(); err == nil {
+		t.Fatal("Open succeeded on a tripped parser")
+	}
+	if missingCalls != breaker.DefaultThreshold {
+		t.Fatalf("tripped parser opened %d times, want %d", missingCalls, breaker.DefaultThreshold)
+	}
+	// Skipping it was not another failure of the parser.
+	if snap := Health().Snapshot(); len(snap) != 1 || snap[0].FailedOpens != breaker.DefaultThreshold {
+		t.Fatalf("health = %+v", snap)
+	}
+
+	// The rest of the chain is unaffected.
+	rs := NewRecoveryStream(hedgeTrack("missing", "good"))
+	defer rs.Close()
+	if err := rs.Open(0); err != nil || rs.Parser() != "good" {
+		t.Fatalf("Open = %v playing %q, want good", err, rs.Parser())
+	}
+}
+
+// A spawned process that never produces audio (a CDN 403 behind ffmpeg) is
+// the commonest parser failure, and it only shows on the first read: the
+// breaker must count it there, and only a packet may close it.
+func TestFirstReadFailuresTripTheBreaker(t *testing.T) {
+	set := breaker.NewSet()
+	origBreakers := SetBreakers(set)
+	origHealth := SetHealth(NewHealthTracker())
+	t.Cleanup(func() {
+		SetBreakers(origBreakers)
+		SetHealth(origHealth)
+	})
+	orig := SetRegistry(map[string]parsers.Streamer{
+		"spawns": fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+			return errFirst{}, func() {}, nil
+		}},
+		"good": fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+			return &pktReader{pkts: [][]byte{{0xAA}}}, func() {}, nil
+		}},
+	})
+	t.Cleanup(func() { SetRegistry(orig) })
+
+	// Each parser alone, so health ordering cannot route around one.
+	play := func(parser string) error {
+		rs := NewRecoveryStream(hedgeTrack(parser))
+		defer rs.Close()
+		if err := rs.Open(0); err != nil {
+			return err
+		}
+		_, err := rs.ReadPacket()
+		return err
+	}
+	for range breaker.DefaultThreshold {
+		if err := play("spawns"); err == nil {
+			t.Fatal("a stream with no packets played")
+		}
+		if err := play("good"); err != nil {
+			t.Fatalf("good: %v", err)
+		}
+	}
+	if st := set.Get("parser spawns").Status(); st.State != breaker.Open {
+		t.Fatalf("spawns breaker = %v after %d failed first reads, want open", st.State, breaker.DefaultThreshold)
+	}
+	if st := set.Get("parser good").Status(); st.State != breaker.Closed {
+		t.Fatalf("good breaker = %v, want closed", st.State)
+	}
+}
+
+func TestOpenWithParser_TrackErrorsDoNotTrip(t *testing.T) {
+	set := breaker.NewSet()
+	origBreakers := SetBreakers(set)
+	t.Cleanup(func() { SetBreakers(origBreakers) })
+
+	orig := SetRegistry(map[string]parsers.Streamer{
+		"yt": fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+			return nil, nil, fmt.Errorf("ytnative: %w", ytnative.ErrCipherOnly)
+		}},
+	})
+	t.Cleanup(func() { SetRegistry(orig) })
+
+	for range 2 * breaker.DefaultThreshold {
+		if _, _, err := openWithParser(context.Background(), &parsers.Track{}, "yt", 0); !errors.Is(err, ytnative.ErrCipherOnly) {
+			t.Fatalf("openWithParser = %v, want the parser's own error", err)
+		}
+	}
+	if st := set.Get("parser yt").Status(); st.State != breaker.Closed {
+		t.Fatalf("breaker state = %v, want closed: cipher-only is the video, not the parser", st.State)
+	}
+}
+
+// A sign-in refusal neither trips the breaker nor gets lost: when every parser
+// fails, Open's error still says why, so the listener can be told.
+func TestOpen_SignInRequiredSurvivesExhaustion(t *testing.T) {
+	set := breaker.NewSet()
+	origBreakers := SetBreakers(set)
+	t.Cleanup(func() { SetBreakers(origBreakers) })
+
+	orig := SetRegistry(map[string]parsers.Streamer{
+		"native": fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+			return nil, nil, fmt.Errorf("ytnative: %w: %w: LOGIN_REQUIRED", ytnative.ErrNotPlayable, parsers.ErrSignInRequired)
+		}},
+		"other": fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+			return nil, nil, errors.New("kkdai: something else")
+		}},
+	})
+	t.Cleanup(func() { SetRegistry(orig) })
+
+	rs := NewRecoveryStream(hedgeTrack("native", "other"))
+	defer rs.Close()
+	err := rs.Open(0)
+	if !errors.Is(err, parsers.ErrSignInRequired) {
+		t.Fatalf("Open = %v, want ErrSignInRequired in the chain", err)
+	}
+	if st := set.Get("parser native").Status(); st.State != breaker.Closed || st.Trips != 0 {
+		t.Fatalf("breaker = %+v, want untouched", st)
+	}
+}
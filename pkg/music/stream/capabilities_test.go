@@ -0,0 +1,213 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/breaker"
+	"github.com/keshon/melodix/pkg/music/opus"
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/sources"
+)
+
+// capsStreamer is a fakeStreamer that reports capabilities.
+type capsStreamer struct {
+	fakeStreamer
+	caps parsers.Capabilities
+}
+
+func (s capsStreamer) Capabilities() parsers.Capabilities { return s.caps }
+
+// ctxStreamer is a fakeStreamer whose open can be cancelled.
+type ctxStreamer struct {
+	fakeStreamer
+	openContext func(ctx context.Context) (opus.Reader, func(), error)
+}
+
+func (s ctxStreamer) OpenContext(ctx context.Context, _ *parsers.Track, _ float64) (opus.Reader, func(), error) {
+	return s.openContext(ctx)
+}
+
+// A track whose duration nobody filled in used to be taken for live, so its
+// natural end was "recovered" into a replay. A parser that cannot play live
+// streams says otherwise: the clean EOF is the end.
+func TestRecoveryStream_NonLiveParserEndsAtEOFWithoutDuration(t *testing.T) {
+	opens := 0
+	orig := SetRegistry(map[string]parsers.Streamer{
+		"yt": capsStreamer{
+			fakeStreamer: fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+				opens++
+				return &cutReader{n: 50, err: io.EOF}, func() {}, nil
+			}},
+			caps: parsers.Capabilities{Seekable: true, Passthrough: true},
+		},
+	})
+	defer func() { SetRegistry(orig) }()
+
+	rs := NewRecoveryStream(&parsers.Track{
+		SourceInfo: sources.TrackInfo{SourceName: sources.YouTube, AvailableParsers: []string{"yt"}},
+	})
+	if err := rs.Open(0); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer rs.Close()
+
+	for {
+		if _, err := rs.ReadPacket(); err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.Fatalf("ReadPacket = %v, want io.EOF", err)
+			}
+			break
+		}
+	}
+	if opens != 1 {
+		t.Fatalf("opened %d times, want 1 — a finished track is not an interruption", opens)
+	}
+}
+
+// A reopen part way through a finite track passes over a parser that cannot
+// seek: it would restart the track from the top.
+func TestRecoveryStream_ReopenSkipsAParserThatCannotSeek(t *testing.T) {
+	var opened []string
+	orig := SetRegistry(map[string]parsers.Streamer{
+		"noseek": capsStreamer{
+			fakeStreamer: fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+				opened = append(opened, "noseek")
+				return &pktReader{pkts: [][]byte{{0xAA}}}, func() {}, nil
+			}},
+			caps: parsers.Capabilities{Live: true},
+		},
+		"seek": capsStreamer{
+			fakeStreamer: fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+				opened = append(opened, "seek")
+				return &pktReader{pkts: [][]byte{{0xBB}}}, func() {}, nil
+			}},
+			caps: parsers.Capabilities{Seekable: true},
+		},
+	})
+	defer func() { SetRegistry(orig) }()
+
+	rs := NewRecoveryStream(&parsers.Track{
+		Duration:   time.Minute,
+		SourceInfo: sources.TrackInfo{AvailableParsers: []string{"noseek", "seek"}},
+	})
+	defer rs.Close()
+
+	if err := rs.Open(0); err != nil || rs.Parser() != "noseek" {
+		t.Fatalf("Open(0) = %v with %q, want noseek: from the top, seeking is not needed", err, rs.Parser())
+	}
+	if err := rs.Open(30); err != nil || rs.Parser() != "seek" {
+		t.Fatalf("Open(30) = %v with %q, want seek", err, rs.Parser())
+	}
+	if len(opened) != 2 || opened[1] != "seek" {
+		t.Fatalf("opened = %q, want noseek never asked to seek", opened)
+	}
+}
+
+// Close abandons an open still extracting — the listener skipped — and the
+// cancellation costs the parser nothing.
+func TestRecoveryStream_CloseCancelsAnOpen(t *testing.T) {
+	started := make(chan struct{})
+	hedgeTest(t, 0, map[string]parsers.Streamer{
+		"slow": ctxStreamer{openContext: func(ctx context.Context) (opus.Reader, func(), error) {
+			close(started)
+			<-ctx.Done()
+			return nil, nil, ctx.Err()
+		}},
+	})
+
+	rs := NewRecoveryStream(hedgeTrack("slow"))
+	done := make(chan error, 1)
+	go func() { done <- rs.Open(0) }()
+	<-started
+	_ = rs.Close()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Open = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Open still running after Close")
+	}
+	if rs.retries["slow"] != 0 {
+		t.Fatalf("slow retries = %d, want 0", rs.retries["slow"])
+	}
+	if snap := Health().Snapshot(); len(snap) != 0 {
+		t.Fatalf("health = %+v, want nothing recorded", snap)
+	}
+	if st := parserBreaker("slow").Status(); st.State != breaker.Closed {
+		t.Fatalf("breaker = %+v, want closed", st)
+	}
+}
+
+func TestValidateParser(t *testing.T) {
+	orig := SetRegistry(map[string]parsers.Streamer{
+		"radio-only": capsStreamer{caps: parsers.Capabilities{Live: true}},
+		"files-only": capsStreamer{caps: parsers.Capabilities{Seekable: true, Passthrough: true}},
+		"both":       capsStreamer{caps: parsers.Capabilities{Seekable: true, Live: true}},
+		"unknown":    fakeStreamer{},
+	})
+	defer func() { SetRegistry(orig) }()
+
+	radio := sources.TrackInfo{SourceName: sources.Radio}
+	video := sources.TrackInfo{SourceName: sources.YouTube}
+	for _, tc := range []struct {
+		parser string
+		track  sources.TrackInfo
+		want   string // substring of the error; "" = valid
+	}{
+		{"radio-only", radio, ""},
+		{"radio-only", video, "live streams only"},
+		{"files-only", radio, "cannot play live radio"},
+		{"files-only", video, ""},
+		{"both", radio, ""},
+		{"both", video, ""},
+		{"unknown", radio, ""}, // no capabilities, nothing to hold against it
+		{"missing", video, "unknown parser"},
+	} {
+		err := ValidateParser(tc.parser, tc.track)
+		switch {
+		case tc.want == "" && err != nil:
+			t.Errorf("ValidateParser(%s, %s) = %v, want nil", tc.parser, tc.track.SourceName, err)
+		case tc.want != "" && (err == nil || !strings.Contains(err.Error(), tc.want)):
+			t.Errorf("ValidateParser(%s, %s) = %v, want %q", tc.parser, tc.track.SourceName, err, tc.want)
+		}
+	}
+}
+
+// A pick that cannot play leaves the track playable through the fallbacks
+// behind it; only a track no parser can play is refused.
+func TestValidateTrack(t *testing.T) {
+	orig := SetRegistry(map[string]parsers.Streamer{
+		"radio-only": capsStreamer{caps: parsers.Capabilities{Live: true}},
+		"files-only": capsStreamer{caps: parsers.Capabilities{Seekable: true}},
+	})
+	defer func() { SetRegistry(orig) }()
+
+	video := sources.TrackInfo{SourceName: sources.YouTube, AvailableParsers: []string{"radio-only", "files-only"}}
+	if err := ValidateTrack(video); err != nil {
+		t.Errorf("a fallback can play it: ValidateTrack = %v", err)
+	}
+	video.AvailableParsers = []string{"radio-only", "missing"}
+	if err := ValidateTrack(video); err == nil || !strings.Contains(err.Error(), "live streams only") {
+		t.Errorf("ValidateTrack = %v, want the picked parser's reason", err)
+	}
+	if err := ValidateTrack(sources.TrackInfo{SourceName: sources.YouTube}); err != nil {
+		t.Errorf("a track listing no parsers: ValidateTrack = %v, want nil", err)
+	}
+}
+
+// Recovery and /play validation fall back to guessing for a parser without
+// capabilities; none of the built-in ones should need that.
+func TestRegistry_BuiltinsReportCapabilities(t *testing.T) {
+	for key, s := range registryEntries {
+		if _, ok := parsers.CapabilitiesOf(s); !ok {
+			t.Errorf("parser %s reports no capabilities", key)
+		}
+	}
+}
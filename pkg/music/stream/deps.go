@@ -0,0 +1,264 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"slices"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/opus"
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/parsers/ffmpeg"
+	"github.com/keshon/melodix/pkg/music/parsers/ytdlp"
+	"github.com/keshon/melodix/pkg/music/sources"
+)
+
+// Names of the dependencies ProbeDependencies reports on.
+const (
+	DepFFmpeg    = "ffmpeg"
+	DepYtdlp     = "yt-dlp"
+	DepJSRuntime = "js-runtime"
+	DepOpus      = "opus"
+)
+
+// versionTimeout bounds each `<binary> --version` run. A binary that hangs
+// that long is as good as missing.
+const versionTimeout = 5 * time.Second
+
+// Dependency is one external program or library playback relies on, as found
+// by ProbeDependencies.
+type Dependency struct {
+	Name string
+	// Path is where the binary was found; empty for the built-in codec.
+	Path    string
+	Version string
+	// Err says why the dependency is unavailable; nil when it is.
+	Err error
+	// Optional dependencies only degrade playback when missing: the parsers
+	// that run them are disabled (see DependencyReport.Disabled), and the
+	// native ones play on. Only the Opus codec is required.
+	Optional bool
+}
+
+// OK reports whether the dependency was found and answered.
+func (d Dependency) OK() bool { return d.Err == nil }
+
+// DependencyReport is the result of a startup probe.
+type DependencyReport struct {
+	Dependencies []Dependency
+	// Disabled maps each parser key that cannot work here to the dependency
+	// it is missing.
+	Disabled  map[string]string
+	CheckedAt time.Time
+}
+
+// Missing returns the dependencies that were not found, in report order.
+func (r DependencyReport) Missing() []Dependency {
+	var out []Dependency
+	for _, d := range r.Dependencies {
+		if !d.OK() {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// DisabledParsers returns the keys of r.Disabled, sorted.
+func (r DependencyReport) DisabledParsers() []string {
+	out := make([]string, 0, len(r.Disabled))
+	for p := range r.Disabled {
+		out = append(out, p)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Stranded returns the names of the sources in srcs that are left with no
+// parser that can play here: every parser they list is disabled or not
+// registered. A missing optional binary only matters when it strands one.
+func (r DependencyReport) Stranded(srcs []sources.Source) []string {
+	reg := Registry()
+	var out []string
+	for _, src := range srcs {
+		playable := slices.ContainsFunc(src.AvailableParsers(), func(key string) bool {
+			_, registered := reg[key]
+			_, off := r.Disabled[key]
+			return registered && !off
+		})
+		if !playable {
+			out = append(out, src.SourceName())
+		}
+	}
+	return out
+}
+
+// Seams for tests.
+var (
+	depLookPath   = exec.LookPath
+	depRunVersion = runVersion
+	depJSRuntime  = ytdlp.JSRuntime
+	depOpus       = opus.CodecVersion
+)
+
+// ProbeDependencies looks for ffmpeg and yt-dlp and asks each for its
+// version, finds the JavaScript runtime yt-dlp will use, and checks that the
+// Opus encoder starts. It works out which registered parsers cannot play
+// without what is missing, but changes nothing: DisableMissing does that.
+func ProbeDependencies(ctx context.Context) DependencyReport {
+	r := DependencyReport{
+		Dependencies: []Dependency{
+			probeBinary(ctx, DepFFmpeg, ffmpeg.FFmpegPath, ffmpegVersion, "-version"),
+			probeBinary(ctx, DepYtdlp, ytdlp.YtdlpPath, firstLine, "--version"),
+			probeJSRuntime(ctx),
+			probeOpus(),
+		},
+		Disabled:  map[string]string{},
+		CheckedAt: time.Now(),
+	}
+	missing := map[string]bool{}
+	for _, d := range r.Missing() {
+		missing[d.Name] = true
+	}
+	for key, streamer := range Registry() {
+		caps, ok := parsers.CapabilitiesOf(streamer)
+		if !ok {
+			continue
+		}
+		switch {
+		case caps.NeedsFFmpeg && missing[DepFFmpeg]:
+			r.Disabled[key] = DepFFmpeg
+		case caps.NeedsYtdlp && missing[DepYtdlp]:
+			r.Disabled[key] = DepYtdlp
+		}
+	}
+	return r
+}
+
+// probeBinary finds an external program. Both of them, ffmpeg and yt-dlp,
+// are optional: what a host without one loses is its parsers.
+func probeBinary(ctx context.Context, name, bin string, parse func(string) string, versionArg string) Dependency {
+	d := Dependency{Name: name, Optional: true}
+	path, err := depLookPath(bin)
+	if err != nil {
+		d.Err = fmt.Errorf("%s not found: %w", bin, err)
+		return d
+	}
+	d.Path = path
+	out, err := depRunVersion(ctx, path, versionArg)
+	if err != nil {
+		d.Err = fmt.Errorf("%s %s: %w", path, versionArg, err)
+		return d
+	}
+	d.Version = parse(out)
+	return d
+}
+
+// probeJSRuntime reports the runtime yt-dlp is handed for YouTube's
+// challenges. Without one yt-dlp keeps its own defaults, which lose live
+// streams but play everything else, so it is optional.
+func probeJSRuntime(ctx context.Context) Dependency {
+	d := Dependency{Name: DepJSRuntime, Optional: true}
+	name, path, ok := depJSRuntime()
+	if !ok {
+		d.Err = fmt.Errorf("none of %s found", strings.Join(ytdlp.JSRuntimeCandidates(), ", "))
+		return d
+	}
+	d.Path = path
+	out, err := depRunVersion(ctx, path, "--version")
+	if err != nil {
+		d.Err = fmt.Errorf("%s --version: %w", path, err)
+		return d
+	}
+	d.Version = name + " " + runtimeVersion(name, out)
+	return d
+}
+
+// runtimeVersion picks the version out of "deno 2.1.4 (stable, ...)",
+// "v22.11.0" (node) or "1.1.38" (bun).
+func runtimeVersion(name, out string) string {
+	fields := strings.Fields(strings.TrimPrefix(firstLine(out), name+" "))
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(fields[0], "v")
+}
+
+func probeOpus() Dependency {
+	d := Dependency{Name: DepOpus}
+	d.Version, d.Err = depOpus()
+	return d
+}
+
+func runVersion(ctx context.Context, path string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, versionTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, args...).Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", err
+	}
+	return string(out), nil
+}
+
+func firstLine(out string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(out), "\n")
+	return strings.TrimSpace(line)
+}
+
+// ffmpegVersion picks the version out of "ffmpeg version 6.1.1 Copyright ...".
+func ffmpegVersion(out string) string {
+	fields := strings.Fields(firstLine(out))
+	if len(fields) >= 3 && fields[1] == "version" {
+		return fields[2]
+	}
+	return firstLine(out)
+}
+
+// dependencies holds the report DisableMissing last applied; nil until then.
+var dependencies atomic.Pointer[DependencyReport]
+
+// Dependencies returns the report DisableMissing applied at startup; ok is
+// false when no probe has run.
+func Dependencies() (DependencyReport, bool) {
+	r := dependencies.Load()
+	if r == nil {
+		return DependencyReport{}, false
+	}
+	return *r, true
+}
+
+// DisableMissing removes the parsers in r.Disabled from the registry, so
+// fallback never spends a spawn or a breaker trip on a binary that is not
+// there, and keeps r for Dependencies. Call once at startup, before playback.
+func DisableMissing(r DependencyReport) {
+	if len(r.Disabled) > 0 {
+		active := Registry()
+		kept := make(map[string]parsers.Streamer, len(active))
+		for key, streamer := range active {
+			if _, off := r.Disabled[key]; !off {
+				kept[key] = streamer
+			}
+		}
+		SetRegistry(kept)
+	}
+	dependencies.Store(&r)
+}
+
+// errDisabled explains a parser DisableMissing took out of the registry.
+func errDisabled(parser string) error {
+	r, ok := Dependencies()
+	if !ok {
+		return nil
+	}
+	dep, off := r.Disabled[parser]
+	if !off {
+		return nil
+	}
+	return fmt.Errorf("parser %s is disabled: it needs %s, which is not installed", parser, dep)
+}
@@ -0,0 +1,178 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/keshon/melodix/pkg/music/opus"
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/sources"
+)
+
+// fakeDeps makes the probe find every binary except those in missing, and
+// restores the seams, the registry and the applied report afterwards.
+func fakeDeps(t *testing.T, missing ...string) {
+	t.Helper()
+	origLook, origRun, origJS, origOpus := depLookPath, depRunVersion, depJSRuntime, depOpus
+	origReg := Registry()
+	t.Cleanup(func() {
+		depLookPath, depRunVersion, depJSRuntime, depOpus = origLook, origRun, origJS, origOpus
+		SetRegistry(origReg)
+		dependencies.Store(nil)
+	})
+	gone := map[string]bool{}
+	for _, m := range missing {
+		gone[m] = true
+	}
+	depLookPath = func(bin string) (string, error) {
+		if gone[bin] {
+			return "", errors.New("executable file not found in $PATH")
+		}
+		return "/usr/bin/" + bin, nil
+	}
+	depRunVersion = func(_ context.Context, path string, _ ...string) (string, error) {
+		switch path {
+		case "/usr/bin/ffmpeg":
+			return "ffmpeg version 6.1.1-3ubuntu5 Copyright (c) 2000-2023\nbuilt with gcc\n", nil
+		case "/usr/bin/yt-dlp":
+			return "2024.12.13\n", nil
+		default:
+			return "v22.11.0\n", nil
+		}
+	}
+	depJSRuntime = func() (string, string, bool) {
+		if gone["node"] {
+			return "", "", false
+		}
+		return "node", "/usr/bin/node", true
+	}
+	depOpus = func() (string, error) { return "libopus 1.5.2", nil }
+}
+
+func TestProbeDependencies_ReportsVersions(t *testing.T) {
+	fakeDeps(t)
+
+	r := ProbeDependencies(context.Background())
+	want := map[string]string{
+		DepFFmpeg:    "6.1.1-3ubuntu5",
+		DepYtdlp:     "2024.12.13",
+		DepJSRuntime: "node 22.11.0",
+		DepOpus:      "libopus 1.5.2",
+	}
+	for _, d := range r.Dependencies {
+		if !d.OK() {
+			t.Errorf("%s: unexpected error %v", d.Name, d.Err)
+		}
+		if d.Version != want[d.Name] {
+			t.Errorf("%s version = %q, want %q", d.Name, d.Version, want[d.Name])
+		}
+	}
+	if len(r.Disabled) != 0 {
+		t.Errorf("Disabled = %v, want none", r.Disabled)
+	}
+}
+
+func TestProbeDependencies_MissingBinaryDisablesItsParsers(t *testing.T) {
+	fakeDeps(t, "ffmpeg", "node")
+
+	r := ProbeDependencies(context.Background())
+	for _, d := range r.Missing() {
+		if !d.Optional {
+			t.Errorf("a missing %s should be optional: its parsers are disabled instead", d.Name)
+		}
+	}
+	if got := len(r.Missing()); got != 2 {
+		t.Fatalf("Missing() has %d entries, want ffmpeg and the JS runtime", got)
+	}
+	for key, streamer := range Registry() {
+		caps, _ := parsers.CapabilitiesOf(streamer)
+		_, off := r.Disabled[key]
+		if off != caps.NeedsFFmpeg {
+			t.Errorf("%s: disabled = %v, NeedsFFmpeg = %v", key, off, caps.NeedsFFmpeg)
+		}
+	}
+	if _, off := r.Disabled[sources.ParserYtnativeLink]; off {
+		t.Error("ytnative needs no binary and must stay enabled")
+	}
+}
+
+// stubSource lists parsers and nothing else.
+type stubSource struct {
+	name    string
+	parsers []string
+}
+
+func (s stubSource) Match(string) bool                                   { return false }
+func (s stubSource) Resolve(string, string) ([]sources.TrackInfo, error) { return nil, nil }
+func (s stubSource) SourceName() string                                  { return s.name }
+func (s stubSource) AvailableParsers() []string                          { return s.parsers }
+
+// Only a source whose every parser is out is stranded; one that keeps a
+// native parser plays on without the binary.
+func TestDependencyReport_Stranded(t *testing.T) {
+	fakeDeps(t, "ffmpeg")
+	SetRegistry(map[string]parsers.Streamer{
+		"needs-ffmpeg": capsStreamer{caps: parsers.Capabilities{NeedsFFmpeg: true}},
+		"native":       capsStreamer{},
+	})
+	r := ProbeDependencies(context.Background())
+
+	got := r.Stranded([]sources.Source{
+		stubSource{"mixed", []string{"needs-ffmpeg", "native"}},
+		stubSource{"ffmpeg-only", []string{"needs-ffmpeg"}},
+		stubSource{"unregistered", []string{"gone"}},
+	})
+	if want := []string{"ffmpeg-only", "unregistered"}; !slices.Equal(got, want) {
+		t.Fatalf("Stranded = %q, want %q", got, want)
+	}
+}
+
+// A disabled parser is gone from the registry, is passed over without a
+// failed open being booked against it, and is refused by name with the
+// reason rather than as unknown.
+func TestDisableMissing_SkipsAndExplainsDisabledParsers(t *testing.T) {
+	fakeDeps(t, "ffmpeg")
+	opened := ""
+	SetRegistry(map[string]parsers.Streamer{
+		"needs-ffmpeg": capsStreamer{
+			fakeStreamer: fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+				opened = "needs-ffmpeg"
+				return &pktReader{}, func() {}, nil
+			}},
+			caps: parsers.Capabilities{NeedsFFmpeg: true},
+		},
+		"native": capsStreamer{
+			fakeStreamer: fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+				opened = "native"
+				return &pktReader{}, func() {}, nil
+			}},
+		},
+	})
+
+	DisableMissing(ProbeDependencies(context.Background()))
+	if _, ok := Registry()["needs-ffmpeg"]; ok {
+		t.Fatal("needs-ffmpeg is still registered")
+	}
+
+	rs := NewRecoveryStream(&parsers.Track{
+		SourceInfo: sources.TrackInfo{SourceName: sources.YouTube, AvailableParsers: []string{"needs-ffmpeg", "native"}},
+	})
+	if err := rs.Open(0); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer rs.Close()
+	if opened != "native" {
+		t.Errorf("opened %q, want native", opened)
+	}
+	if rs.retries["needs-ffmpeg"] != 0 {
+		t.Error("a disabled parser was booked a failed open")
+	}
+
+	err := ValidateParser("needs-ffmpeg", sources.TrackInfo{SourceName: sources.YouTube})
+	if err == nil || !strings.Contains(err.Error(), "disabled") {
+		t.Errorf("ValidateParser = %v, want a disabled error", err)
+	}
+}
@@ -0,0 +1,235 @@
+package stream
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/sources"
+)
+
+const (
+	// healthHalfLife is how fast old outcomes fade. A parser YouTube broke
+	// yesterday and fixed overnight is trusted again within a couple of hours,
+	// while a run of failures this morning still counts this afternoon.
+	healthHalfLife = 30 * time.Minute
+	// healthMinEvidence is the decayed number of opens below which a parser is
+	// not judged at all: two unlucky tracks are not a broken parser.
+	healthMinEvidence = 3
+	// healthDemoteBelow is the score under which a parser is moved to the end
+	// of the fallback list (see HealthTracker.Order).
+	healthDemoteBelow = 0.5
+	// healthDropWeight is what a mid-track failure costs against the score,
+	// relative to a failed open: the parser did play, but not to the end.
+	healthDropWeight = 0.5
+	// healthProbeEvery is how often a demoted parser keeps its place anyway:
+	// every Nth ordering that would demote it. Without probes it would never be
+	// opened again, and its score could only recover by fading to no evidence.
+	healthProbeEvery = 10
+	// latencyAlpha weights the newest open in the latency average.
+	latencyAlpha = 0.3
+)
+
+// HealthTracker keeps per source and parser outcomes of every stream the
+// process opens, and orders a track's fallback list by them. A track's
+// AvailableParsers are a static preference; the tracker only demotes, so
+// that a parser failing for everyone stops being tried first by everyone.
+//
+// Counts decay with healthHalfLife. Safe for concurrent use.
+type HealthTracker struct {
+	mu    sync.Mutex
+	stats map[healthKey]*parserStats
+	now   func() time.Time
+}
+
+type healthKey struct {
+	source, parser string
+}
+
+type parserStats struct {
+	// Decayed counts, as of updated.
+	successes, failures, drops float64
+	updated                    time.Time
+	// latency is the moving average of open-to-first-packet time.
+	latency time.Duration
+	// Lifetime totals, for display.
+	opens, failedOpens, midFailures int
+	// skipped counts orderings that demoted the parser since its last probe.
+	skipped int
+}
+
+// ParserHealth is one row of HealthTracker.Snapshot.
+type ParserHealth struct {
+	Source string
+	Parser string
+	// Opens, FailedOpens and MidTrackFailures are lifetime totals.
+	Opens            int
+	FailedOpens      int
+	MidTrackFailures int
+	// Score is the decayed success rate the ordering uses, with mid-track
+	// failures counted at healthDropWeight; 1 without evidence.
+	Score float64
+	// Latency is the moving average time from open to first packet.
+	Latency time.Duration
+	// Demoted reports whether Order currently moves the parser to the end.
+	Demoted bool
+}
+
+// NewHealthTracker creates an empty tracker.
+func NewHealthTracker() *HealthTracker {
+	return &HealthTracker{stats: make(map[healthKey]*parserStats), now: time.Now}
+}
+
+// health is the process-wide tracker every RecoveryStream reports to. Tests
+// swap it with SetHealth, hence the atomic pointer (as with the registry).
+var health atomic.Pointer[HealthTracker]
+
+func init() { health.Store(NewHealthTracker()) }
+
+// Health returns the process-wide parser health tracker.
+func Health() *HealthTracker { return health.Load() }
+
+// SetHealth swaps the process-wide tracker and returns the previous one.
+// Intended for tests, which restore the original in a cleanup.
+func SetHealth(h *HealthTracker) *HealthTracker { return health.Swap(h) }
+
+// entry returns the stats for the source and parser, decayed to now, creating
+// them if needed. Caller holds mu.
+func (h *HealthTracker) entry(source, parser string) *parserStats {
+	if st := h.lookup(source, parser); st != nil {
+		return st
+	}
+	st := &parserStats{updated: h.now()}
+	h.stats[healthKey{source, parser}] = st
+	return st
+}
+
+// lookup returns the stats for the source and parser decayed to now, or nil
+// when there are none. Caller holds mu.
+func (h *HealthTracker) lookup(source, parser string) *parserStats {
+	st, ok := h.stats[healthKey{source, parser}]
+	if !ok {
+		return nil
+	}
+	now := h.now()
+	if dt := now.Sub(st.updated); dt > 0 {
+		f := math.Exp2(-float64(dt) / float64(healthHalfLife))
+		st.successes *= f
+		st.failures *= f
+		st.drops *= f
+		st.updated = now
+	}
+	return st
+}
+
+// RecordOpen notes a parser that produced its first packet latency after it
+// was asked to open.
+func (h *HealthTracker) RecordOpen(source, parser string, latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	st := h.entry(source, parser)
+	st.successes++
+	st.opens++
+	if st.latency == 0 {
+		st.latency = latency
+	} else {
+		st.latency = time.Duration(latencyAlpha*float64(latency) + (1-latencyAlpha)*float64(st.latency))
+	}
+}
+
+// RecordFailedOpen notes a parser that failed to open, or failed on its very
+// first read.
+func (h *HealthTracker) RecordFailedOpen(source, parser string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	st := h.entry(source, parser)
+	st.failures++
+	st.failedOpens++
+}
+
+// RecordMidTrackFailure notes a parser whose stream ended early after playing.
+func (h *HealthTracker) RecordMidTrackFailure(source, parser string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	st := h.entry(source, parser)
+	st.drops++
+	st.midFailures++
+}
+
+// score is st's decayed success rate; ok is false without enough evidence to
+// judge. Caller holds mu and has decayed st.
+func (st *parserStats) score() (float64, bool) {
+	attempts := st.successes + st.failures
+	if attempts < healthMinEvidence {
+		return 1, false
+	}
+	return st.successes / (attempts + healthDropWeight*st.drops), true
+}
+
+// Order returns parsers with the unhealthy ones for source moved to the end,
+// keeping the given order otherwise (demoted parsers among themselves too).
+// A demoted parser keeps its place on every healthProbeEvery-th call, so its
+// recovery gets noticed. parsers is not modified.
+func (h *HealthTracker) Order(source string, parsers []string) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	healthy := make([]string, 0, len(parsers))
+	var demoted []string
+	for _, p := range parsers {
+		st := h.lookup(source, p)
+		if st == nil {
+			healthy = append(healthy, p)
+			continue
+		}
+		if s, ok := st.score(); ok && s < healthDemoteBelow {
+			st.skipped++
+			if st.skipped < healthProbeEvery {
+				demoted = append(demoted, p)
+				continue
+			}
+			st.skipped = 0
+		}
+		healthy = append(healthy, p)
+	}
+	return append(healthy, demoted...)
+}
+
+// parserOrder is the order a stream tries info's parsers in: health order,
+// except that a parser the listener picked (info.PinnedParser) stays first
+// however it has fared. Only the fallbacks behind it are reordered.
+func parserOrder(source string, info sources.TrackInfo) []string {
+	pin := info.PinnedParser
+	if pin == "" || !slices.Contains(info.AvailableParsers, pin) {
+		return Health().Order(source, info.AvailableParsers)
+	}
+	rest := slices.DeleteFunc(slices.Clone(info.AvailableParsers), func(p string) bool { return p == pin })
+	return append([]string{pin}, Health().Order(source, rest)...)
+}
+
+// Snapshot returns every parser the tracker has seen, by source and parser.
+func (h *HealthTracker) Snapshot() []ParserHealth {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make([]ParserHealth, 0, len(h.stats))
+	for key := range h.stats {
+		st := h.lookup(key.source, key.parser)
+		s, ok := st.score()
+		out = append(out, ParserHealth{
+			Source:           key.source,
+			Parser:           key.parser,
+			Opens:            st.opens,
+			FailedOpens:      st.failedOpens,
+			MidTrackFailures: st.midFailures,
+			Score:            s,
+			Latency:          st.latency,
+			Demoted:          ok && s < healthDemoteBelow,
+		})
+	}
+	slices.SortFunc(out, func(a, b ParserHealth) int {
+		return cmp.Or(cmp.Compare(a.Source, b.Source), cmp.Compare(a.Parser, b.Parser))
+	})
+	return out
+}
@@ -0,0 +1,205 @@
+package stream
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/breaker"
+	"github.com/keshon/melodix/pkg/music/opus"
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/sources"
+)
+
+// fakeClock is a settable time source for decay tests.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func newTestHealth() (*HealthTracker, *fakeClock) {
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	h := NewHealthTracker()
+	h.now = clock.now
+	return h, clock
+}
+
+func TestHealth_DemotesAFailingParser(t *testing.T) {
+	h, _ := newTestHealth()
+	list := []string{"a", "b", "c"}
+
+	// Two failures are not enough evidence to judge.
+	h.RecordFailedOpen("yt", "a")
+	h.RecordFailedOpen("yt", "a")
+	if got := h.Order("yt", list); !slices.Equal(got, list) {
+		t.Fatalf("order after two failures = %q, want unchanged", got)
+	}
+
+	h.RecordFailedOpen("yt", "a")
+	if got, want := h.Order("yt", list), []string{"b", "c", "a"}; !slices.Equal(got, want) {
+		t.Fatalf("order = %q, want %q", got, want)
+	}
+	// Health is per source: the same parser elsewhere keeps its place.
+	if got := h.Order("sc", list); !slices.Equal(got, list) {
+		t.Fatalf("other source's order = %q, want unchanged", got)
+	}
+	if list[0] != "a" {
+		t.Fatal("Order modified its argument")
+	}
+
+	h.RecordOpen("yt", "b", 300*time.Millisecond)
+	snap := h.Snapshot()
+	if len(snap) != 2 || snap[0].Parser != "a" || !snap[0].Demoted || snap[0].FailedOpens != 3 {
+		t.Fatalf("snapshot = %+v", snap)
+	}
+	if snap[1].Parser != "b" || snap[1].Demoted || snap[1].Latency != 300*time.Millisecond {
+		t.Fatalf("snapshot b = %+v", snap[1])
+	}
+}
+
+func TestHealth_ProbesADemotedParser(t *testing.T) {
+	h, _ := newTestHealth()
+	for range 5 {
+		h.RecordFailedOpen("yt", "a")
+	}
+	list := []string{"a", "b"}
+	probes := 0
+	for range 3 * healthProbeEvery {
+		if h.Order("yt", list)[0] == "a" {
+			probes++
+		}
+	}
+	if probes != 3 {
+		t.Fatalf("demoted parser probed %d times in %d orderings, want 3", probes, 3*healthProbeEvery)
+	}
+}
+
+func TestHealth_FailuresDecay(t *testing.T) {
+	h, clock := newTestHealth()
+	for range 4 {
+		h.RecordFailedOpen("yt", "a")
+	}
+	list := []string{"a", "b"}
+	if h.Order("yt", list)[0] == "a" {
+		t.Fatal("failing parser not demoted")
+	}
+	// Two half-lives leave one failure's worth of evidence: too little to judge.
+	clock.t = clock.t.Add(2 * healthHalfLife)
+	if got := h.Order("yt", list); !slices.Equal(got, list) {
+		t.Fatalf("order after decay = %q, want restored", got)
+	}
+}
+
+func TestHealth_MidTrackFailuresCount(t *testing.T) {
+	h, _ := newTestHealth()
+	for range 3 {
+		h.RecordOpen("yt", "a", time.Second)
+	}
+	if s := h.Snapshot()[0].Score; s != 1 {
+		t.Fatalf("clean score = %v, want 1", s)
+	}
+	for range 4 {
+		h.RecordMidTrackFailure("yt", "a")
+	}
+	snap := h.Snapshot()[0]
+	if snap.MidTrackFailures != 4 || snap.Score >= 1 || snap.Score < healthDemoteBelow {
+		t.Fatalf("after drops: %+v; want a lower score that still plays first", snap)
+	}
+}
+
+func TestRecoveryStream_FeedsAndFollowsHealth(t *testing.T) {
+	h, _ := newTestHealth()
+	origHealth := SetHealth(h)
+	t.Cleanup(func() { SetHealth(origHealth) })
+
+	opened := map[string]int{}
+	orig := SetRegistry(map[string]parsers.Streamer{
+		"broken": fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+			opened["broken"]++
+			return errFirst{}, func() {}, nil
+		}},
+		"good": fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+			opened["good"]++
+			return &pktReader{pkts: [][]byte{{0xAA}}}, func() {}, nil
+		}},
+	})
+	t.Cleanup(func() { SetRegistry(orig) })
+
+	play := func() {
+		t.Helper()
+		track := &parsers.Track{SourceInfo: sources.TrackInfo{
+			SourceName:       sources.YouTube,
+			AvailableParsers: []string{"broken", "good"},
+		}}
+		rs := NewRecoveryStream(track)
+		if err := rs.Open(0); err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		if _, err := rs.ReadPacket(); err != nil {
+			t.Fatalf("ReadPacket: %v", err)
+		}
+		if track.CurrentParser != "good" {
+			t.Fatalf("playing %q, want good", track.CurrentParser)
+		}
+	}
+
+	for range healthMinEvidence {
+		play()
+	}
+	if opened["broken"] != healthMinEvidence {
+		t.Fatalf("broken opened %d times while undemoted, want %d", opened["broken"], healthMinEvidence)
+	}
+	// Demoted now: the next track goes straight to the good parser.
+	play()
+	if opened["broken"] != healthMinEvidence {
+		t.Fatalf("demoted parser was tried first (opened %d times)", opened["broken"])
+	}
+	snap := h.Snapshot()
+	if len(snap) != 2 || !snap[0].Demoted || snap[1].Opens != healthMinEvidence+1 {
+		t.Fatalf("snapshot = %+v", snap)
+	}
+}
+
+// A parser the listener picked is tried first even once health has demoted
+// it; only the fallbacks behind it follow health.
+func TestRecoveryStream_PinnedParserIgnoresDemotion(t *testing.T) {
+	h, _ := newTestHealth()
+	origHealth := SetHealth(h)
+	origBreakers := SetBreakers(breaker.NewSet())
+	t.Cleanup(func() {
+		SetHealth(origHealth)
+		SetBreakers(origBreakers)
+	})
+	for range healthMinEvidence {
+		h.RecordFailedOpen(sources.YouTube, "picked")
+		h.RecordFailedOpen(sources.YouTube, "fallback-bad")
+	}
+
+	var opened []string
+	open := func(name string) fakeStreamer {
+		return fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+			opened = append(opened, name)
+			return errFirst{}, func() {}, nil
+		}}
+	}
+	orig := SetRegistry(map[string]parsers.Streamer{
+		"picked":       open("picked"),
+		"fallback-bad": open("fallback-bad"),
+		"fallback":     open("fallback"),
+	})
+	t.Cleanup(func() { SetRegistry(orig) })
+
+	rs := NewRecoveryStream(&parsers.Track{SourceInfo: sources.TrackInfo{
+		SourceName:       sources.YouTube,
+		AvailableParsers: []string{"picked", "fallback-bad", "fallback"},
+		PinnedParser:     "picked",
+	}})
+	defer rs.Close()
+	if err := rs.Open(0); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	_, _ = rs.ReadPacket() // every parser fails its first read; the order is the point
+
+	if want := []string{"picked", "fallback", "fallback-bad"}; !slices.Equal(opened, want) {
+		t.Fatalf("opened %q, want %q", opened, want)
+	}
+}
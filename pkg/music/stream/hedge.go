@@ -0,0 +1,227 @@
+package stream
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/opus"
+	"github.com/keshon/melodix/pkg/music/parsers"
+)
+
+// hedgeAfter is how long the preferred parser gets to produce its first packet
+// before the next one is started alongside it (0 = hedging disabled, parsers
+// are tried one after another). Set once at boot, like the cache and buffer.
+var hedgeAfter time.Duration
+
+// SetHedgeOpen sets the hedged-open deadline in milliseconds (<=0 disables
+// hedging). Call once at startup.
+func SetHedgeOpen(ms int) {
+	if ms <= 0 {
+		hedgeAfter = 0
+		return
+	}
+	hedgeAfter = time.Duration(ms) * time.Millisecond
+}
+
+// maxHedgeRacers caps how many parsers open at once: the preferred one and
+// the hedge. More would only multiply the extraction load on the site.
+const maxHedgeRacers = 2
+
+// hedgeRacer is one parser opening during a hedged Open. It opens into a copy
+// of the track, because the parsers write the track's metadata as they open
+// and two of them must not write the same one; the winner's copy is adopted.
+type hedgeRacer struct {
+	index    int // position in RecoveryStream.order
+	parser   string
+	track    parsers.Track
+	openedAt time.Time
+	ctx      context.Context
+	cancel   context.CancelFunc // abandons the open itself, if still running
+
+	mu        sync.Mutex
+	cleanup   func() // set once the parser opened; nil again once taken
+	abandoned bool   // lost the race; cleanup as soon as there is one
+}
+
+// hedgeResult is a racer's outcome: its first packet, or why there is none.
+type hedgeResult struct {
+	racer   *hedgeRacer
+	reader  opus.Reader
+	cleanup func()
+	first   []byte
+	err     error
+}
+
+// run opens the parser and reads its first packet. A racer abandoned while
+// blocked in either is unblocked by the cleanup abandon calls, and reports
+// nothing anyone waits for any more; results must be buffered for that.
+func (r *hedgeRacer) run(seek float64, results chan<- hedgeResult) {
+	reader, cleanup, err := openWithParser(r.ctx, &r.track, r.parser, seek)
+	if err != nil {
+		results <- hedgeResult{racer: r, err: err}
+		return
+	}
+	r.mu.Lock()
+	if r.abandoned {
+		r.mu.Unlock()
+		parserBreaker(r.parser).Skip()
+		cleanup()
+		return
+	}
+	r.cleanup = cleanup
+	r.mu.Unlock()
+
+	// The first read is where the breaker learns the outcome; an abandoned
+	// racer's read fails on its cancelled ctx and is skipped.
+	pkt, err := reader.ReadPacket()
+	reportFirstRead(r.ctx, r.parser, err)
+	if err != nil {
+		// Whoever takes the cleanup under the lock runs it: this racer, or
+		// abandon if it got there first.
+		if cleanup := r.takeCleanup(); cleanup != nil {
+			cleanup()
+		}
+		results <- hedgeResult{racer: r, err: err}
+		return
+	}
+	// The cleanup stays with the racer: a winner whose result is never received
+	// (Open gave up first) is abandoned, and abandon runs it.
+	results <- hedgeResult{racer: r, reader: reader, cleanup: cleanup, first: pkt}
+}
+
+// takeCleanup hands the racer's cleanup to the caller and clears it, so no one
+// else can run it too.
+func (r *hedgeRacer) takeCleanup() func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cleanup := r.cleanup
+	r.cleanup = nil
+	return cleanup
+}
+
+// abandon tears the racer's stream down, now or as soon as it opens.
+func (r *hedgeRacer) abandon() {
+	r.cancel()
+	r.mu.Lock()
+	r.abandoned = true
+	cleanup := r.cleanup
+	r.cleanup = nil
+	r.mu.Unlock()
+	if cleanup != nil {
+		cleanup()
+	}
+}
+
+// openHedged is Open's parser loop with hedging: the next parser is started
+// when the running one has not produced a packet within hedgeAfter (or as
+// soon as it fails), and the first to produce one is kept. Losers are torn
+// down; only failures count against a parser, never being slower.
+//
+// The first packet is read here, so an immediate failure is handled here as
+// well, and ReadPacket replays the packet to confirm the winner as usual.
+func (rs *RecoveryStream) openHedged(seek float64) error {
+	var candidates []int
+	for i := rs.parserIndex; i < len(rs.order); i++ {
+		if !rs.usable(rs.order[i], seek) {
+			continue
+		}
+		candidates = append(candidates, i)
+	}
+
+	results := make(chan hedgeResult, len(candidates))
+	var racing []*hedgeRacer
+	start := func() {
+		i := candidates[0]
+		candidates = candidates[1:]
+		r := &hedgeRacer{index: i, parser: rs.order[i], track: *rs.track, openedAt: time.Now()}
+		r.ctx, r.cancel = context.WithCancel(rs.ctx)
+		r.track.Passthrough = false // parser sets true if it opens passthrough
+		r.track.Cached = false
+		racing = append(racing, r)
+		if len(racing) > 1 {
+			rs.log.Info().Str("parser", r.parser).Str("hedging", racing[0].parser).Msg("stream_open_hedged")
+			rs.trace.Add(TraceEvent{Kind: TraceHedged, Parser: r.parser, Pos: seek, Against: racing[0].parser})
+		}
+		go r.run(seek, results)
+	}
+
+	deadline := time.NewTimer(hedgeAfter)
+	defer deadline.Stop()
+	for len(candidates) > 0 || len(racing) > 0 {
+		if len(racing) == 0 {
+			start()
+			deadline.Reset(hedgeAfter)
+		}
+		select {
+		case <-rs.ctx.Done():
+			for _, r := range racing {
+				r.abandon()
+			}
+			return rs.ctx.Err()
+		case <-deadline.C:
+			if len(candidates) > 0 && len(racing) < maxHedgeRacers {
+				start()
+			}
+			deadline.Reset(hedgeAfter)
+		case res := <-results:
+			racing = slices.DeleteFunc(racing, func(r *hedgeRacer) bool { return r == res.racer })
+			if res.err != nil {
+				rs.openFailed(res.racer.parser, res.err, time.Since(res.racer.openedAt))
+				if len(candidates) > 0 && len(racing) < maxHedgeRacers {
+					start()
+				}
+				continue
+			}
+			for _, loser := range racing {
+				rs.log.Info().Str("parser", loser.parser).Str("winner", res.racer.parser).Msg("stream_open_hedge_lost")
+				loser.abandon()
+			}
+			return rs.adoptWinner(res, seek)
+		}
+	}
+	return rs.exhausted()
+}
+
+// adoptWinner installs a hedged Open's winner as the active stream, taking the
+// metadata its parser wrote into the racer's copy of the track.
+func (rs *RecoveryStream) adoptWinner(res hedgeResult, seek float64) error {
+	r := res.racer
+	if err := rs.setActive(&primedReader{first: res.first, Reader: res.reader}, res.cleanup, ""); err != nil {
+		return err
+	}
+	rs.track.Title = r.track.Title
+	rs.track.Artist = r.track.Artist
+	rs.track.Duration = r.track.Duration
+	rs.track.StreamURL = r.track.StreamURL
+	rs.track.Passthrough = r.track.Passthrough
+	rs.track.Cached = false
+
+	rs.openedAt = r.openedAt
+	rs.startCacheWrite(seek)
+	rs.parserIndex = r.index
+	rs.seekSec = seek
+	rs.curParser = r.parser
+	rs.track.CurrentParser = r.parser
+	rs.fromCache = false
+	rs.firstRead = true
+	rs.log.Info().Str("parser", r.parser).Float64("seek", seek).Msg("stream_opening")
+	rs.trace.Add(TraceEvent{Kind: TraceOpened, Parser: r.parser, Pos: seek, Took: time.Since(r.openedAt)})
+	return nil
+}
+
+// primedReader replays a packet already read from its reader, then continues
+// with the reader.
+type primedReader struct {
+	first []byte
+	opus.Reader
+}
+
+func (r *primedReader) ReadPacket() ([]byte, error) {
+	if pkt := r.first; pkt != nil {
+		r.first = nil
+		return pkt, nil
+	}
+	return r.Reader.ReadPacket()
+}
@@ -0,0 +1,204 @@
+package stream
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/breaker"
+	"github.com/keshon/melodix/pkg/music/opus"
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/sources"
+)
+
+// stallReader produces nothing until its cleanup closes it, as an extraction
+// stuck on a slow site does.
+type stallReader struct {
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newStallReader() *stallReader { return &stallReader{done: make(chan struct{})} }
+
+func (r *stallReader) ReadPacket() ([]byte, error) {
+	<-r.done
+	return nil, io.ErrClosedPipe
+}
+
+func (r *stallReader) Close() error {
+	r.closeOnce.Do(func() { close(r.done) })
+	return nil
+}
+
+// hedgeTest installs the hedge deadline, a fresh health tracker and breakers,
+// and the given registry for one test.
+func hedgeTest(t *testing.T, after time.Duration, reg map[string]parsers.Streamer) {
+	t.Helper()
+	SetHedgeOpen(int(after / time.Millisecond))
+	origHealth := SetHealth(NewHealthTracker())
+	origBreakers := SetBreakers(breaker.NewSet())
+	origReg := SetRegistry(reg)
+	t.Cleanup(func() {
+		SetHedgeOpen(0)
+		SetHealth(origHealth)
+		SetBreakers(origBreakers)
+		SetRegistry(origReg)
+	})
+}
+
+func hedgeTrack(parsersList ...string) *parsers.Track {
+	return &parsers.Track{
+		Duration:   time.Minute,
+		SourceInfo: sources.TrackInfo{SourceName: sources.YouTube, AvailableParsers: parsersList},
+	}
+}
+
+func TestHedgedOpen_SlowParserLosesToTheHedge(t *testing.T) {
+	stalled := newStallReader()
+	var slowClosed atomic.Int32
+	hedgeTest(t, 20*time.Millisecond, map[string]parsers.Streamer{
+		"slow": fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+			return stalled, func() { slowClosed.Add(1); _ = stalled.Close() }, nil
+		}},
+		"fast": fakeStreamer{open: func(track *parsers.Track, _ float64) (opus.Reader, func(), error) {
+			track.Title = "from fast"
+			track.Passthrough = true
+			return &pktReader{pkts: [][]byte{{0xBB}, {0xBC}}}, func() {}, nil
+		}},
+	})
+
+	track := hedgeTrack("slow", "fast")
+	rs := NewRecoveryStream(track)
+	defer rs.Close()
+	var confirmed []string
+	rs.SetOnParserConfirmed(func(p string) { confirmed = append(confirmed, p) })
+
+	if err := rs.Open(0); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if slowClosed.Load() != 1 {
+		t.Fatalf("losing parser cleaned up %d times, want once", slowClosed.Load())
+	}
+	for _, want := range []byte{0xBB, 0xBC} {
+		pkt, err := rs.ReadPacket()
+		if err != nil || len(pkt) != 1 || pkt[0] != want {
+			t.Fatalf("ReadPacket = %v, %v; want [%#x]", pkt, err, want)
+		}
+	}
+	if len(confirmed) != 1 || confirmed[0] != "fast" {
+		t.Fatalf("confirmed = %q, want [fast]", confirmed)
+	}
+	if track.CurrentParser != "fast" || track.Title != "from fast" || !track.Passthrough {
+		t.Fatalf("track = %+v, want the winner's metadata", track)
+	}
+	// Being slower is not a failure: the loser keeps its budget and its health.
+	if rs.retries["slow"] != 0 {
+		t.Fatalf("slow retries = %d, want 0", rs.retries["slow"])
+	}
+	for _, row := range Health().Snapshot() {
+		if row.Parser == "slow" {
+			t.Fatalf("loser recorded in health: %+v", row)
+		}
+	}
+}
+
+func TestHedgedOpen_FastParserIsNotHedged(t *testing.T) {
+	var secondOpened atomic.Bool
+	hedgeTest(t, time.Second, map[string]parsers.Streamer{
+		"p1": fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+			return &pktReader{pkts: [][]byte{{0xAA}}}, func() {}, nil
+		}},
+		"p2": fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+			secondOpened.Store(true)
+			return &pktReader{pkts: [][]byte{{0xBB}}}, func() {}, nil
+		}},
+	})
+
+	track := hedgeTrack("p1", "p2")
+	rs := NewRecoveryStream(track)
+	defer rs.Close()
+	if err := rs.Open(0); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	pkt, err := rs.ReadPacket()
+	if err != nil || pkt[0] != 0xAA {
+		t.Fatalf("ReadPacket = %v, %v; want p1's packet", pkt, err)
+	}
+	if secondOpened.Load() {
+		t.Fatal("hedge started although the preferred parser answered in time")
+	}
+}
+
+func TestHedgedOpen_FailureStartsTheNextAtOnce(t *testing.T) {
+	// A deadline no test waits out: the switch must come from the failure.
+	hedgeTest(t, time.Minute, map[string]parsers.Streamer{
+		"p1": fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+			return errFirst{}, func() {}, nil
+		}},
+		"p2": fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+			return &pktReader{pkts: [][]byte{{0xBB}}}, func() {}, nil
+		}},
+	})
+
+	track := hedgeTrack("p1", "p2")
+	rs := NewRecoveryStream(track)
+	defer rs.Close()
+	if err := rs.Open(0); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if pkt, err := rs.ReadPacket(); err != nil || pkt[0] != 0xBB {
+		t.Fatalf("ReadPacket = %v, %v; want p2's packet", pkt, err)
+	}
+	if rs.retries["p1"] != 1 {
+		t.Fatalf("p1 retries = %d, want 1", rs.retries["p1"])
+	}
+	if snap := Health().Snapshot(); len(snap) != 2 || snap[0].Parser != "p1" || snap[0].FailedOpens != 1 {
+		t.Fatalf("health = %+v", snap)
+	}
+}
+
+func TestHedgedOpen_SlowParserWinsWhenTheHedgeFails(t *testing.T) {
+	release := make(chan struct{})
+	hedgeTest(t, 10*time.Millisecond, map[string]parsers.Streamer{
+		"slow": fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+			<-release
+			return &pktReader{pkts: [][]byte{{0xAA}}}, func() {}, nil
+		}},
+		"broken": fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+			close(release) // fail only once the hedge is certainly running
+			return nil, nil, io.ErrUnexpectedEOF
+		}},
+	})
+
+	track := hedgeTrack("slow", "broken")
+	rs := NewRecoveryStream(track)
+	defer rs.Close()
+	if err := rs.Open(0); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if pkt, err := rs.ReadPacket(); err != nil || pkt[0] != 0xAA {
+		t.Fatalf("ReadPacket = %v, %v; want slow's packet", pkt, err)
+	}
+	if track.CurrentParser != "slow" {
+		t.Fatalf("CurrentParser = %q, want slow", track.CurrentParser)
+	}
+}
+
+func TestHedgedOpen_AllFail(t *testing.T) {
+	hedgeTest(t, 10*time.Millisecond, map[string]parsers.Streamer{
+		"p1": fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+			return errFirst{}, func() {}, nil
+		}},
+		"p2": fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+			return nil, nil, io.ErrUnexpectedEOF
+		}},
+	})
+
+	rs := NewRecoveryStream(hedgeTrack("p1", "p2"))
+	defer rs.Close()
+	if err := rs.Open(0); err == nil {
+		t.Fatal("Open succeeded with every parser failing")
+	}
+}
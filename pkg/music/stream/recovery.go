@@ -1,12 +1,15 @@
 package stream
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"io"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/keshon/melodix/pkg/music/breaker"
 	"github.com/keshon/melodix/pkg/music/cache"
 	"github.com/keshon/melodix/pkg/music/opus"
 	"github.com/keshon/melodix/pkg/music/parsers"
@@ -28,32 +31,51 @@ const (
 // as decoded PCM (io.ReadCloser) for consumers that still want samples.
 type RecoveryStream struct {
 	track       *parsers.Track
-	parserIndex int
+	order       []string       // the track's parsers as health ordered them; nil until the first Open
+	parserIndex int            // position in order
 	reader      opus.Reader    // active packet stream
 	cleanup     func()         // cleanup for the active stream
 	curParser   string         // registry key of the active parser
 	seekSec     float64        // approximate playback position (packets × 20ms)
 	retries     map[string]int // parser => recovery attempts
 	firstRead   bool           // detect immediate failure at start
+	openedAt    time.Time      // when the active parser was asked to open, for open latency
+	signInErr   error          // a parser's open refused for want of a signed-in session
 	pcm         io.ReadCloser  // lazily-built decode view (Read)
 	log         zerolog.Logger
+	trace       *Tracer // what this playback tried and how it went; see Trace
 
 	// onParserConfirmed is called when a freshly opened stream yields its first
 	// packet, i.e. when the active parser is proven to actually produce audio.
 	// Fired from the ReadPacket goroutine; nil disables the notification.
 	onParserConfirmed func(parser string)
 
+	// ctx bounds the parser opens; Close cancels it, so a skip abandons an
+	// extraction still in progress instead of waiting it out.
+	ctx    context.Context
+	cancel context.CancelFunc
+
 	// closed is set by Close so a read that fails because the stream was torn
 	// down is not mistaken for a recoverable one. It is atomic because the
 	// read-ahead buffer drives ReadPacket from its own goroutine while Close
 	// runs on the playback goroutine.
 	closed atomic.Bool
 
+	// transportReopen asks the read-ahead producer to reopen the stream in
+	// place of its next read; see ReopenAfterTransportFailure.
+	transportReopen atomic.Bool
+
 	// buffered is the anti-skip view handed to the sink; see Packets.
 	buffered *opus.BufferedReader
 
-	// mu guards reader and cleanup — the only fields Close touches while the
-	// read-ahead producer may still be running. Every other field belongs to the
+	// unconfirmed is the parser whose breaker report is owed to the active
+	// stream's first read (see reportFirstRead); "" once reported, and for a
+	// cached or hedged stream, which owe none. A stream torn down before its
+	// first read settles it with a Skip.
+	unconfirmed string
+
+	// mu guards reader, cleanup and unconfirmed — the only fields Close
+	// touches while the read-ahead producer may still be running. Every other field belongs to the
 	// producer goroutine alone, and Close reaches them only after Wait proves it
 	// has exited. The lock is never held across a blocking read or a parser
 	// Open, so a stalled source cannot block teardown.
@@ -71,11 +93,15 @@ func NewRecoveryStream(track *parsers.Track) *RecoveryStream {
 
 // NewRecoveryStreamWithLogger creates a resilient wrapper using the given logger.
 func NewRecoveryStreamWithLogger(track *parsers.Track, log zerolog.Logger) *RecoveryStream {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &RecoveryStream{
 		track:     track,
 		retries:   make(map[string]int),
 		firstRead: true,
 		log:       log,
+		trace:     NewTracer(track),
+		ctx:       ctx,
+		cancel:    cancel,
 	}
 }
 
@@ -88,10 +114,20 @@ func (rs *RecoveryStream) SetOnParserConfirmed(fn func(parser string)) {
 
 // Open acquires the packet stream for the current parser, advancing through the
 // track's parser list past any that fail or exhausted their recovery budget.
+// The list is the track's AvailableParsers as the health tracker orders them
+// on the first Open (see parserOrder). With SetHedgeOpen, a slow
+// parser gets the next one started alongside it (see openHedged).
 // A successful Open is NOT proof that audio will flow: the ffmpeg-backed parsers
 // only spawn a process here, so a CDN 403 surfaces later, on the first read.
 // confirmOpen is where a parser is known to be playing.
+//
+// Close cancels an Open in progress: parsers that implement
+// parsers.ContextStreamer give up mid-extraction, and Open returns the
+// context's error.
 func (rs *RecoveryStream) Open(seek float64) error {
+	if err := rs.ctx.Err(); err != nil {
+		return err
+	}
 	// Cache-first: serve a completed blob for this track if one exists (shared
 	// across guilds). A miss or open failure falls through to the parser list.
 	if activeCache != nil && !rs.cacheDisabled {
@@ -99,9 +135,12 @@ func (rs *RecoveryStream) Open(seek float64) error {
 			reader, err := activeCache.OpenAt(key, opus.SeekPackets(seek))
 			if err != nil {
 				rs.log.Warn().Str("cache_key", key).Err(err).Msg("cache_open_failed")
+				rs.trace.Add(TraceEvent{Kind: TraceCacheFailed, Pos: seek, Err: err.Error()})
 				rs.cacheDisabled = true
 			} else {
-				rs.setActive(reader, func() { _ = reader.Close() })
+				if err := rs.setActive(reader, func() { _ = reader.Close() }, ""); err != nil {
+					return err
+				}
 				rs.seekSec = seek
 				rs.curParser = ""
 				rs.track.CurrentParser = ""
@@ -110,39 +149,113 @@ func (rs *RecoveryStream) Open(seek float64) error {
 				rs.fromCache = true
 				rs.firstRead = true
 				rs.log.Info().Str("cache_key", key).Float64("seek", seek).Msg("stream_opening_from_cache")
+				rs.trace.Add(TraceEvent{Kind: TraceCacheHit, Pos: seek})
 				return nil
 			}
+		} else if ok && rs.order == nil { // a miss is only news on the first Open
+			rs.trace.Add(TraceEvent{Kind: TraceCacheMiss})
 		}
 	}
 
-	for i := rs.parserIndex; i < len(rs.track.SourceInfo.AvailableParsers); i++ {
-		parser := rs.track.SourceInfo.AvailableParsers[i]
-		if rs.retries[parser] >= maxRecoveryAttempts {
-			rs.log.Warn().Str("parser", parser).Msg("parser_exceeded_recovery_attempts")
+	// Ordered once per stream: a reopen mid-track must not find the list
+	// reshuffled under parserIndex.
+	if rs.order == nil {
+		rs.order = parserOrder(rs.source(), rs.track.SourceInfo)
+	}
+	if hedgeAfter > 0 {
+		return rs.openHedged(seek)
+	}
+	for i := rs.parserIndex; i < len(rs.order); i++ {
+		parser := rs.order[i]
+		if !rs.usable(parser, seek) {
 			continue
 		}
 		rs.track.Passthrough = false // parser sets true if it opens passthrough
 		rs.track.Cached = false
-		reader, cleanup, err := openWithParser(rs.track, parser, seek)
+		openedAt := time.Now()
+		reader, cleanup, err := openWithParser(rs.ctx, rs.track, parser, seek)
 		if err != nil {
-			rs.log.Warn().Str("parser", parser).Err(err).Msg("stream_open_failed")
-			rs.retries[parser]++
+			rs.openFailed(parser, err, time.Since(openedAt))
+			if rs.ctx.Err() != nil {
+				return rs.ctx.Err()
+			}
 			continue
 		}
+		if err := rs.setActive(reader, cleanup, parser); err != nil {
+			return err
+		}
+		rs.openedAt = openedAt
 		rs.startCacheWrite(seek)
 		rs.parserIndex = i
-		rs.setActive(reader, cleanup)
 		rs.seekSec = seek
 		rs.curParser = parser
 		rs.track.CurrentParser = parser
 		rs.fromCache = false
 		rs.firstRead = true
 		rs.log.Info().Str("parser", parser).Float64("seek", seek).Msg("stream_opening")
+		rs.trace.Add(TraceEvent{Kind: TraceOpened, Parser: parser, Pos: seek, Took: time.Since(openedAt)})
 		return nil
 	}
+	return rs.exhausted()
+}
+
+// exhausted is Open's error once no parser is left. A parser that was refused
+// for want of a signed-in session is the cause worth telling the listener
+// about, since the others usually failed for the same reason less clearly.
+func (rs *RecoveryStream) exhausted() error {
+	if rs.signInErr != nil {
+		return fmt.Errorf("all parsers failed or exceeded recovery attempts: %w", rs.signInErr)
+	}
 	return errors.New("all parsers failed or exceeded recovery attempts")
 }
 
+// usable reports whether Open should try parser at seek. A parser disabled at
+// startup for a missing dependency is passed over, as is one out of recovery
+// budget, and one that cannot seek when the open has to resume part way
+// through a finite track: it would restart the track from the top.
+func (rs *RecoveryStream) usable(parser string, seek float64) bool {
+	if err := errDisabled(parser); err != nil {
+		rs.log.Debug().Str("parser", parser).Err(err).Msg("parser_disabled")
+		rs.trace.Add(TraceEvent{Kind: TraceSkipped, Parser: parser, Pos: seek, Err: err.Error()})
+		return false
+	}
+	if rs.retries[parser] >= maxRecoveryAttempts {
+		rs.log.Warn().Str("parser", parser).Msg("parser_exceeded_recovery_attempts")
+		rs.trace.Add(TraceEvent{Kind: TraceSkipped, Parser: parser, Pos: seek, Err: "out of recovery attempts"})
+		return false
+	}
+	if seek > 0 && rs.track.Duration > 0 {
+		if caps, ok := parserCapabilities(parser); ok && !caps.Seekable {
+			rs.log.Info().Str("parser", parser).Float64("seek", seek).Msg("parser_cannot_seek")
+			rs.trace.Add(TraceEvent{Kind: TraceSkipped, Parser: parser, Pos: seek, Err: "cannot seek"})
+			return false
+		}
+	}
+	return true
+}
+
+// openFailed books a parser that did not open after took. One skipped by its
+// open circuit breaker was never tried, and one cancelled by Close did not get
+// to finish, so neither costs recovery budget or health.
+func (rs *RecoveryStream) openFailed(parser string, err error, took time.Duration) {
+	if rs.ctx.Err() != nil {
+		rs.log.Info().Str("parser", parser).Err(err).Msg("stream_open_cancelled")
+		return
+	}
+	if errors.Is(err, breaker.ErrOpen) {
+		rs.log.Info().Str("parser", parser).Err(err).Msg("parser_circuit_open")
+		rs.trace.Add(TraceEvent{Kind: TraceSkipped, Parser: parser, Err: err.Error()})
+		return
+	}
+	rs.trace.Add(TraceEvent{Kind: TraceOpenFailed, Parser: parser, Took: took, Err: err.Error()})
+	if errors.Is(err, parsers.ErrSignInRequired) {
+		rs.signInErr = err
+	}
+	rs.log.Warn().Str("parser", parser).Err(err).Msg("stream_open_failed")
+	rs.retries[parser]++
+	Health().RecordFailedOpen(rs.source(), parser)
+}
+
 // startCacheWrite begins caching a clean from-start play of an as-yet-uncached
 // track (once per stream). Writing happens in ReadPacket, above the recovery
 // logic, so a single blob spans parser switches and transport reopens; it is
@@ -191,13 +304,26 @@ func (rs *RecoveryStream) abortCache() {
 // the current position.
 func (rs *RecoveryStream) ReadPacket() ([]byte, error) {
 	for {
+		if rs.transportReopen.Swap(false) {
+			rs.closeCurrent()
+			if err := rs.Open(rs.seekSec); err != nil {
+				rs.abortCache()
+				return nil, err
+			}
+		}
 		rs.mu.Lock()
 		reader := rs.reader
 		rs.mu.Unlock()
 		if reader == nil {
+			if rs.transportReopen.Load() {
+				continue // torn down for a transport reopen, which is next
+			}
 			return nil, errors.New("stream not opened")
 		}
 		pkt, err := reader.ReadPacket()
+		if err != nil && rs.transportReopen.Load() {
+			continue // the read failed because its stream was torn down for the reopen
+		}
 		if err == nil {
 			if rs.firstRead {
 				rs.firstRead = false
@@ -220,6 +346,7 @@ func (rs *RecoveryStream) ReadPacket() ([]byte, error) {
 			// advance parserIndex, since the cache is not one of its entries.
 			if rs.fromCache {
 				rs.log.Warn().Err(err).Msg("cache_immediate_failure_falling_back")
+				rs.trace.Add(TraceEvent{Kind: TraceCacheFailed, Pos: rs.seekSec, Err: err.Error()})
 				rs.cacheDisabled = true
 				rs.fromCache = false
 				rs.closeCurrent()
@@ -230,8 +357,12 @@ func (rs *RecoveryStream) ReadPacket() ([]byte, error) {
 				continue
 			}
 			// Otherwise advance to the next parser.
+			rs.settleFirstRead(err)
 			rs.retries[rs.curParser]++
+			Health().RecordFailedOpen(rs.source(), rs.curParser)
 			rs.log.Warn().Str("parser", rs.curParser).Err(err).Msg("immediate_failure_switching_parser")
+			rs.trace.Add(TraceEvent{Kind: TraceFirstReadFailed, Parser: rs.curParser, Pos: rs.seekSec,
+				Took: time.Since(rs.openedAt), Err: err.Error()})
 			rs.closeCurrent()
 			rs.parserIndex++
 			if reopenErr := rs.Open(rs.seekSec); reopenErr != nil {
@@ -258,6 +389,7 @@ func (rs *RecoveryStream) ReadPacket() ([]byte, error) {
 		// A cache blob is only committed on a clean EOF, so it is always
 		// complete: a cache EOF is a natural end, never an early one.
 		if !rs.closed.Load() && !rs.fromCache && rs.shouldRecover(err) {
+			Health().RecordMidTrackFailure(rs.source(), rs.curParser)
 			if reopenErr := rs.reopen(err); reopenErr != nil {
 				rs.abortCache()
 				return nil, err
@@ -266,8 +398,9 @@ func (rs *RecoveryStream) ReadPacket() ([]byte, error) {
 		}
 
 		// Terminal: a clean EOF means the track ended → commit the cache; any
-		// other error means we gave up → discard the partial.
-		if errors.Is(err, io.EOF) {
+		// other error means we gave up → discard the partial. An EOF from a
+		// stream Close tore down is not the end of the track.
+		if errors.Is(err, io.EOF) && !rs.closed.Load() {
 			rs.commitCache()
 		} else {
 			rs.abortCache()
@@ -281,16 +414,39 @@ func (rs *RecoveryStream) ReadPacket() ([]byte, error) {
 // opened. It fires again after every reopen (parser switch, early-EOF recovery,
 // transport reopen), so the parser it names is always the live one.
 func (rs *RecoveryStream) confirmOpen() {
+	rs.settleFirstRead(nil)
 	if rs.fromCache {
 		rs.log.Info().Float64("seek", rs.seekSec).Msg("stream_opened_from_cache")
+		rs.trace.Add(TraceEvent{Kind: TracePlaying, Parser: TraceCacheParser, Pos: rs.seekSec})
 	} else {
-		rs.log.Info().Str("parser", rs.curParser).Float64("seek", rs.seekSec).Msg("stream_opened")
+		latency := time.Since(rs.openedAt)
+		rs.log.Info().Str("parser", rs.curParser).Float64("seek", rs.seekSec).
+			Dur("open_latency", latency).Msg("stream_opened")
+		rs.trace.Add(TraceEvent{Kind: TracePlaying, Parser: rs.curParser, Pos: rs.seekSec, Took: latency})
+		Health().RecordOpen(rs.source(), rs.curParser, latency)
 	}
 	if rs.onParserConfirmed != nil {
 		rs.onParserConfirmed(rs.curParser)
 	}
 }
 
+// settleFirstRead reports the active stream's first read to the breaker its
+// open left owing, if it has not been settled already.
+func (rs *RecoveryStream) settleFirstRead(err error) {
+	rs.mu.Lock()
+	parser := rs.unconfirmed
+	rs.unconfirmed = ""
+	rs.mu.Unlock()
+	if parser != "" {
+		reportFirstRead(rs.ctx, parser, err)
+	}
+}
+
+// source is the track's source name, which health is kept per.
+func (rs *RecoveryStream) source() string {
+	return rs.track.SourceInfo.SourceName
+}
+
 // Read exposes the recovered packet stream as decoded PCM (s16le, 48kHz stereo).
 func (rs *RecoveryStream) Read(p []byte) (int, error) {
 	if rs.pcm == nil {
@@ -309,7 +465,19 @@ func (v packetView) Close() error                { return nil }
 // isLive reports whether the track has no fixed end. Internet radio is the
 // obvious case; a YouTube live broadcast is the other, and both arrive here the
 // same way — as a track whose duration nobody could fill in.
+//
+// The active parser's capabilities settle it where they can: one that cannot
+// play live streams is not playing one, and one that plays nothing else is.
+// Only a parser that does both leaves the guess to the missing duration.
 func (rs *RecoveryStream) isLive() bool {
+	if caps, ok := parserCapabilities(rs.curParser); ok {
+		switch {
+		case !caps.Live:
+			return false
+		case !caps.Seekable:
+			return true
+		}
+	}
 	return rs.track.Duration <= 0
 }
 
@@ -331,6 +499,16 @@ func (rs *RecoveryStream) shouldRecover(cause error) bool {
 		}
 		return false
 	}
+	// A finite track whose duration is unknown has no position to judge by
+	// either, but it does end: a clean EOF is that end, and only an error is
+	// worth a reopen.
+	if !rs.isLive() {
+		if errors.Is(cause, io.EOF) {
+			return false
+		}
+		rs.log.Warn().Float64("played", rs.seekSec).Err(cause).Msg("early_stream_end_detected")
+		return true
+	}
 	// A live stream has no natural end to compare against, so every stop is an
 	// interruption rather than a finish — there is no such thing as "near the
 	// end" here. Recover, and let the attempt budget checked above be what stops
@@ -352,6 +530,7 @@ func (rs *RecoveryStream) reopen(cause error) error {
 
 	rs.log.Warn().Str("parser", rs.curParser).Int("attempt", rs.retries[rs.curParser]).
 		Float64("seek", seek).Bool("live", rs.isLive()).Err(cause).Msg("recovering_stream")
+	rs.trace.Add(TraceEvent{Kind: TraceRecovering, Parser: rs.curParser, Pos: rs.seekSec, Err: cause.Error()})
 	rs.closeCurrent()
 
 	if rs.isLive() {
@@ -365,26 +544,52 @@ func (rs *RecoveryStream) reopen(cause error) error {
 
 // ReopenAfterTransportFailure reopens the media stream at the current position
 // (e.g. after a Discord voice reconnect); does not count against parser recovery.
+//
+// With the read-ahead buffer, the stream belongs to the buffer's producer and
+// is not this goroutine's to reopen. The reopen is left to the producer: the
+// active stream is torn down and the producer reopens it in place of its next
+// read, after the lead it has buffered. A failed reopen then surfaces from
+// ReadPacket rather than from here.
 func (rs *RecoveryStream) ReopenAfterTransportFailure() error {
+	if rs.buffered == nil {
+		rs.closeCurrent()
+		return rs.Open(rs.seekSec)
+	}
+	rs.transportReopen.Store(true)
 	rs.closeCurrent()
-	return rs.Open(rs.seekSec)
+	return nil
 }
 
 // setActive installs the freshly opened stream under the lock Close also takes.
-func (rs *RecoveryStream) setActive(reader opus.Reader, cleanup func()) {
+// A Close that ran while the stream was opening found nothing to tear down, so
+// the stream is torn down here instead and the cancellation returned.
+// unconfirmed names the parser whose breaker awaits the first read, if any.
+func (rs *RecoveryStream) setActive(reader opus.Reader, cleanup func(), unconfirmed string) error {
 	rs.mu.Lock()
 	rs.reader = reader
 	rs.cleanup = cleanup
+	rs.unconfirmed = unconfirmed
 	rs.mu.Unlock()
+	if err := rs.ctx.Err(); err != nil {
+		rs.closeCurrent()
+		return err
+	}
+	return nil
 }
 
 func (rs *RecoveryStream) closeCurrent() {
 	rs.mu.Lock()
 	cleanup := rs.cleanup
+	unconfirmed := rs.unconfirmed
 	rs.cleanup = nil
 	rs.reader = nil
+	rs.unconfirmed = ""
 	rs.mu.Unlock()
 
+	if unconfirmed != "" {
+		parserBreaker(unconfirmed).Skip() // never got to show whether it plays
+	}
+
 	// Outside the lock: tearing a socket down can block, and a producer parked
 	// in ReadPacket is unblocked by exactly this call.
 	if cleanup != nil {
@@ -415,19 +620,27 @@ func (rs *RecoveryStream) Packets() opus.Reader {
 	return wrapped
 }
 
-// Close releases the active stream. Safe to call multiple times.
-func (rs *RecoveryStream) Close() error {
+// Interrupt tears the active stream down without waiting for whoever is
+// reading it: a ReadPacket parked on a stalled source returns, and the stream
+// does not recover. Unlike Close it is safe while another goroutine is in
+// ReadPacket — the sink's, when there is no read-ahead buffer — and that
+// reader still has to Close the stream once it is out.
+func (rs *RecoveryStream) Interrupt() {
 	// Order matters. closed first, so a read failing because of this teardown is
 	// not mistaken for an early end worth reopening. Then Stop, which only
 	// signals — the producer parked in ReadPacket is unblocked by closing the
 	// source underneath it, which closeCurrent does last.
 	rs.closed.Store(true)
+	rs.cancel()
 	if rs.buffered != nil {
 		rs.buffered.Stop()
 	}
-	// Unblocks a producer parked in a read, so the goroutine can notice it is
-	// stopped and exit.
 	rs.closeCurrent()
+}
+
+// Close releases the active stream. Safe to call multiple times.
+func (rs *RecoveryStream) Close() error {
+	rs.Interrupt()
 	if rs.buffered != nil {
 		rs.buffered.Wait()
 	}
@@ -441,3 +654,8 @@ func (rs *RecoveryStream) Track() *parsers.Track { return rs.track }
 
 // Parser returns the current parser key.
 func (rs *RecoveryStream) Parser() string { return rs.curParser }
+
+// Trace returns the stream's playback trace. The stream records into it as it
+// opens, fails over and recovers; the player adds what happens above it and
+// finishes it.
+func (rs *RecoveryStream) Trace() *Tracer { return rs.trace }
@@ -3,6 +3,8 @@ package stream
 import (
 	"errors"
 	"io"
+	"math"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -287,6 +289,48 @@ func TestRecoveryStream_ClosedStream_DoesNotReopen(t *testing.T) {
 	}
 }
 
+// A read parked on a source that stopped sending returns once the stream is
+// interrupted from another goroutine, and the failure is not recovered from.
+func TestRecoveryStream_InterruptUnblocksAStalledRead(t *testing.T) {
+	var opens atomic.Int32
+	orig := SetRegistry(map[string]parsers.Streamer{
+		"p1": fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+			opens.Add(1)
+			r := newStallReader()
+			return r, func() { _ = r.Close() }, nil
+		}},
+	})
+	defer func() { SetRegistry(orig) }()
+
+	track := &parsers.Track{
+		Duration:   20 * time.Second,
+		SourceInfo: sources.TrackInfo{AvailableParsers: []string{"p1"}},
+	}
+	rs := NewRecoveryStream(track)
+	if err := rs.Open(0); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	read := make(chan error, 1)
+	go func() {
+		_, err := rs.ReadPacket()
+		read <- err
+	}()
+
+	rs.Interrupt()
+	select {
+	case err := <-read:
+		if err == nil {
+			t.Fatal("an interrupted read yielded a packet")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the stalled read was not interrupted")
+	}
+	_ = rs.Close()
+	if n := opens.Load(); n != 1 {
+		t.Fatalf("opened %d times, want no reopen after Interrupt", n)
+	}
+}
+
 // gatedReader yields n packets, then fails; the reopen that follows is held
 // until release is closed, so the test can observe what the consumer hears
 // while a reconnect is in progress.
@@ -372,6 +416,62 @@ func TestRecoveryStream_BufferCoversTheReconnectGap(t *testing.T) {
 	}
 }
 
+// With the read-ahead buffer, a transport reopen is the producer's to do: it
+// reopens after the lead it already holds, so the consumer hears the whole
+// track once, with nothing skipped or repeated.
+func TestRecoveryStream_BufferedTransportReopenKeepsTheLead(t *testing.T) {
+	SetBufferAhead(1000) // 50 packets of lead
+	defer SetBufferAhead(0)
+
+	var opens atomic.Int32
+	orig := SetRegistry(map[string]parsers.Streamer{
+		"p1": fakeStreamer{open: func(_ *parsers.Track, seek float64) (opus.Reader, func(), error) {
+			opens.Add(1)
+			// The rest of a 1000-packet track from seek.
+			return &cutReader{n: 1000 - int(math.Round(seek*1000/opus.FrameMs)), err: io.EOF}, func() {}, nil
+		}},
+	})
+	defer func() { SetRegistry(orig) }()
+
+	track := &parsers.Track{
+		Duration:   20 * time.Second,
+		SourceInfo: sources.TrackInfo{AvailableParsers: []string{"p1"}},
+	}
+	rs := NewRecoveryStream(track)
+	if err := rs.Open(0); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer rs.Close()
+
+	packets := rs.Packets()
+	read := 0
+	for ; read < 100; read++ {
+		if _, err := packets.ReadPacket(); err != nil {
+			t.Fatalf("packet %d: %v", read, err)
+		}
+	}
+	// From the consumer's goroutine, with the producer still reading.
+	if err := rs.ReopenAfterTransportFailure(); err != nil {
+		t.Fatalf("ReopenAfterTransportFailure: %v", err)
+	}
+	for {
+		_, err := packets.ReadPacket()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.Fatalf("ended with %v", err)
+			}
+			break
+		}
+		read++
+	}
+	if read != 1000 {
+		t.Fatalf("heard %d packets, want the 1000 of the track exactly once", read)
+	}
+	if n := opens.Load(); n != 2 {
+		t.Fatalf("opened %d times, want the one reopen", n)
+	}
+}
+
 // Packets is cached: a second call must not start a second read-ahead goroutine
 // competing for the same source.
 func TestRecoveryStream_PacketsIsStable(t *testing.T) {
@@ -0,0 +1,66 @@
+package stream
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keshon/melodix/pkg/music/httpreplay/replaytest"
+	"github.com/keshon/melodix/pkg/music/opus"
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/resolve"
+	"github.com/keshon/melodix/pkg/music/sources"
+)
+
+// TestReplay_YouTubeQueryPlaysOffline runs a search query the whole way from
+// the resolver to Opus packets, the way the player does, against recorded
+// responses: InnerTube /search, the visitor bootstrap, /player, and the CDN's
+// WebM. Nothing here is stubbed below the HTTP layer, so a change anywhere in
+// resolve → open → demux shows up without a network.
+//
+// The checked-in set keeps the shapes and request keys of a real recording
+// with a three-second synthetic track as the media, to keep the repository
+// small. MELODIX_RECORD_FIXTURES=1 replaces it with a real recording.
+func TestReplay_YouTubeQueryPlaysOffline(t *testing.T) {
+	replaytest.Start(t, "testdata/replay/youtube")
+
+	infos, err := resolve.New().ResolveContext(context.Background(), "never gonna give you up",
+		sources.YouTube, sources.ParserYtnativeLink)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(infos) != 1 || infos[0].URL == "" {
+		t.Fatalf("resolve gave %+v, want one video", infos)
+	}
+	// ytnative alone: the other YouTube parsers run binaries, which a recording
+	// cannot stand in for.
+	info := infos[0]
+	info.AvailableParsers = []string{sources.ParserYtnativeLink}
+	track := &parsers.Track{URL: info.URL, Title: info.Title, SourceInfo: info}
+
+	rs := NewRecoveryStream(track)
+	defer rs.Close()
+	if err := rs.Open(0); err != nil {
+		t.Fatalf("Open: %v\n%s", err, rs.Trace().Snapshot())
+	}
+	if !track.Passthrough {
+		t.Error("the Opus format was not played through")
+	}
+	if track.Duration == 0 {
+		t.Error("duration not filled in from the player response")
+	}
+
+	n := 0
+	for ; n < 100; n++ {
+		pkt, err := rs.ReadPacket()
+		if err != nil {
+			t.Fatalf("packet %d: %v", n, err)
+		}
+		if !opus.IsSingle20ms(pkt) {
+			t.Fatalf("packet %d is not a single 20ms frame", n)
+		}
+	}
+	tr := rs.Trace().Snapshot()
+	if last := tr.Events[len(tr.Events)-1]; last.Kind != TracePlaying || last.Parser != sources.ParserYtnativeLink {
+		t.Errorf("trace ends %s %s, want ytnative playing", last.Kind, last.Parser)
+	}
+}
@@ -2,14 +2,18 @@
 package stream
 
 import (
+	"context"
 	"fmt"
 	"sync/atomic"
 
 	"github.com/keshon/melodix/pkg/music/cache"
 	"github.com/keshon/melodix/pkg/music/opus"
 	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/parsers/bcnative"
+	"github.com/keshon/melodix/pkg/music/parsers/directfile"
 	"github.com/keshon/melodix/pkg/music/parsers/ffmpeg"
 	"github.com/keshon/melodix/pkg/music/parsers/kkdai"
+	"github.com/keshon/melodix/pkg/music/parsers/localfile"
 	"github.com/keshon/melodix/pkg/music/parsers/scnative"
 	"github.com/keshon/melodix/pkg/music/parsers/ytdlp"
 	"github.com/keshon/melodix/pkg/music/parsers/ytnative"
@@ -23,15 +27,23 @@ import (
 var registryEntries = map[string]parsers.Streamer{
 	sources.ParserYtnativeLink: &ytnative.Streamer{},
 	sources.ParserScnativeLink: &scnative.Streamer{},
+	sources.ParserBcnativeLink: &bcnative.Streamer{},
 	sources.ParserKkdaiLink:    &kkdai.Streamer{Mode: kkdai.ModeLink},
 	sources.ParserKkdaiPipe:    &kkdai.Streamer{Mode: kkdai.ModePipe},
 	sources.ParserYtdlpLink:    &ytdlp.Streamer{Mode: ytdlp.ModeLink},
 	sources.ParserYtdlpPipe:    &ytdlp.Streamer{Mode: ytdlp.ModePipe},
 	sources.ParserFFmpegLink:   &ffmpeg.Streamer{},
+
+	sources.ParserLocalPassthrough: &localfile.Streamer{Mode: localfile.ModePassthrough},
+	sources.ParserLocalFFmpeg:      &localfile.Streamer{Mode: localfile.ModeFFmpeg},
+
+	sources.ParserDirectPassthrough: &directfile.Streamer{Mode: directfile.ModePassthrough},
+	sources.ParserDirectFFmpeg:      &directfile.Streamer{Mode: directfile.ModeFFmpeg},
 }
 
 // registry holds the active parser registry behind an atomic pointer.
-// Production stores registryEntries once at init and never swaps; tests swap in
+// Production stores registryEntries at init and swaps once more at startup, when
+// DisableMissing drops parsers whose binaries are not installed; tests swap in
 // fakes via SetRegistry. Atomic access keeps that swap race-free against player
 // goroutines that read the registry while opening a stream.
 var registry atomic.Pointer[map[string]parsers.Streamer]
@@ -69,6 +81,16 @@ func SetBufferAhead(ms int) {
 	bufferAheadPackets = ms / opus.FrameMs
 }
 
+// parserCapabilities returns the capabilities of the registered parser; ok is
+// false for an unknown parser or one that does not report them.
+func parserCapabilities(parser string) (parsers.Capabilities, bool) {
+	streamer, ok := Registry()[parser]
+	if !ok {
+		return parsers.Capabilities{}, false
+	}
+	return parsers.CapabilitiesOf(streamer)
+}
+
 // bufferWrapReader adds the anti-skip read-ahead buffer around a reader; ok is
 // false when buffering is disabled. The caller owns stopping the result and
 // tearing the source down (see RecoveryStream.Packets and Close).
@@ -80,11 +102,47 @@ func bufferWrapReader(reader opus.Reader) (*opus.BufferedReader, bool) {
 	return buf, ok
 }
 
-// openWithParser opens the Opus packet stream for the given parser key.
-func openWithParser(track *parsers.Track, parser string, seekSec float64) (opus.Reader, func(), error) {
+// openWithParser opens the Opus packet stream for the given parser key. The
+// parser's circuit breaker is consulted first; an open breaker fails the call
+// with breaker.ErrOpen without touching the parser. A failed open is reported
+// to the breaker here, and one cancelled through ctx says nothing about the
+// parser and is skipped. A successful one is not reported: for the ffmpeg and
+// yt-dlp parsers it only means a process started, so the caller reports the
+// first read instead (reportFirstRead), or a Skip if the stream is torn down
+// before it.
+func openWithParser(ctx context.Context, track *parsers.Track, parser string, seekSec float64) (opus.Reader, func(), error) {
 	streamer, ok := Registry()[parser]
 	if !ok {
 		return nil, nil, fmt.Errorf("stream: no streamer for parser %q", parser)
 	}
-	return streamer.Open(track, seekSec)
+	b := parserBreaker(parser)
+	if err := b.Allow(); err != nil {
+		return nil, nil, err
+	}
+	reader, cleanup, err := parsers.OpenContext(ctx, streamer, track, seekSec)
+	switch {
+	case err == nil:
+		// Owed to the first read.
+	case ctx.Err() != nil, trackLevel(err):
+		b.Skip()
+	default:
+		b.Failure(err)
+	}
+	return reader, cleanup, err
+}
+
+// reportFirstRead settles the breaker report openWithParser left owing, once
+// the parser's first read says whether audio flows: a packet (err nil) closes
+// the breaker, a failure counts against it. A read cut short by ctx, or
+// failing for reasons of the track, proves nothing either way.
+func reportFirstRead(ctx context.Context, parser string, err error) {
+	b := parserBreaker(parser)
+	switch {
+	case err == nil:
+		b.Success()
+	case ctx.Err() != nil, trackLevel(err):
+		b.Skip()
+	default:
+		b.Failure(err)
+	}
 }
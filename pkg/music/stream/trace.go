@@ -0,0 +1,222 @@
+package stream
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/parsers"
+)
+
+// TraceEventKind names one step in a track's playback.
+type TraceEventKind string
+
+const (
+	// TraceCacheHit: the track was served from the track cache.
+	TraceCacheHit TraceEventKind = "cache_hit"
+	// TraceCacheMiss: the cache is on but does not hold the track.
+	TraceCacheMiss TraceEventKind = "cache_miss"
+	// TraceCacheFailed: a cached blob would not open or read; parsers follow.
+	TraceCacheFailed TraceEventKind = "cache_failed"
+	// TraceSkipped: a parser was passed over without being asked to open —
+	// disabled, out of recovery budget, unable to seek, or its breaker open.
+	TraceSkipped TraceEventKind = "skipped"
+	// TraceHedged: a parser was started alongside a slower one (SetHedgeOpen).
+	TraceHedged TraceEventKind = "hedged"
+	// TraceOpened: a parser's open returned a stream. Not yet proof of audio.
+	TraceOpened TraceEventKind = "opened"
+	// TraceOpenFailed: a parser's open returned an error.
+	TraceOpenFailed TraceEventKind = "open_failed"
+	// TracePlaying: the first packet arrived; the parser is really playing.
+	TracePlaying TraceEventKind = "playing"
+	// TraceFirstReadFailed: the stream failed on its first read and the next
+	// parser was tried.
+	TraceFirstReadFailed TraceEventKind = "first_read_failed"
+	// TraceRecovering: the media stopped early and the parser was reopened at
+	// the current position.
+	TraceRecovering TraceEventKind = "recovering"
+	// TraceTransportFailed: Discord's voice transport failed and the stream
+	// was reopened underneath it.
+	TraceTransportFailed TraceEventKind = "transport_failed"
+	// TraceSinkFailed: no voice connection could be had to play into.
+	TraceSinkFailed TraceEventKind = "sink_failed"
+)
+
+// TraceOutcome is how a traced playback ended, or TraceInProgress while it
+// has not.
+type TraceOutcome string
+
+const (
+	TraceInProgress TraceOutcome = "in progress"
+	TraceFinished   TraceOutcome = "finished"
+	TraceStopped    TraceOutcome = "stopped"
+	TraceFailed     TraceOutcome = "failed"
+)
+
+// TraceCacheParser stands in for the parser name on steps served from the
+// track cache.
+const TraceCacheParser = "cache"
+
+// maxTraceEvents bounds one trace. A radio station that drops every few
+// minutes reconnects within its budget for hours; past the cap the trace
+// keeps its first traceHead events, which say how the playback started, and
+// the most recent ones, which say how it is ending, and counts what it drops
+// from between them instead of growing.
+const (
+	maxTraceEvents = 64
+	traceHead      = 16
+)
+
+// TraceEvent is one step of a playback.
+type TraceEvent struct {
+	At     time.Time
+	Kind   TraceEventKind
+	Parser string
+	// Pos is the playback position, in seconds, the step happened at.
+	Pos float64
+	// Took is how long the step took, where it has a duration: an open, or
+	// the wait for the first packet.
+	Took time.Duration
+	// Against is the parser a TraceHedged one was started alongside.
+	Against string
+	// Err is the step's error; empty for a step that did not fail.
+	Err string
+}
+
+// Trace is the structured record of one playback of one track: every parser
+// tried and what became of it, in order. It exists to answer "why did this
+// track fail" without reading interleaved logs from every guild.
+type Trace struct {
+	Title   string
+	URL     string
+	Source  string
+	Started time.Time
+	Ended   time.Time
+	Outcome TraceOutcome
+	Err     string
+	Events  []TraceEvent
+	// Dropped counts events past maxTraceEvents, left out between the first
+	// traceHead events and the rest.
+	Dropped int
+}
+
+// Tracer records a Trace. RecoveryStream writes to it from the goroutine
+// that opens and reads the stream, the player from its own, and /why reads
+// it from a third, so every method locks. A nil Tracer records nothing.
+type Tracer struct {
+	mu sync.Mutex
+	t  Trace
+}
+
+// NewTracer starts the trace of a playback of track.
+func NewTracer(track *parsers.Track) *Tracer {
+	return &Tracer{t: Trace{
+		Title:   track.Title,
+		URL:     track.URL,
+		Source:  track.SourceInfo.SourceName,
+		Started: time.Now(),
+		Outcome: TraceInProgress,
+	}}
+}
+
+// Add records ev, stamping it with the current time when ev.At is zero.
+func (tr *Tracer) Add(ev TraceEvent) {
+	if tr == nil {
+		return
+	}
+	if ev.At.IsZero() {
+		ev.At = time.Now()
+	}
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if len(tr.t.Events) >= maxTraceEvents {
+		// Shift the tail over the oldest event after the head; the copy is
+		// cheap at this size and keeps Events in order.
+		tail := tr.t.Events[traceHead:]
+		copy(tail, tail[1:])
+		tail[len(tail)-1] = ev
+		tr.t.Dropped++
+		return
+	}
+	tr.t.Events = append(tr.t.Events, ev)
+}
+
+// Finish records how the playback ended. Only the first call counts: a
+// failure is not overwritten by the stop that tears the stream down after it.
+func (tr *Tracer) Finish(outcome TraceOutcome, err error) {
+	if tr == nil {
+		return
+	}
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if tr.t.Outcome != TraceInProgress {
+		return
+	}
+	tr.t.Outcome = outcome
+	tr.t.Ended = time.Now()
+	if err != nil {
+		tr.t.Err = err.Error()
+	}
+}
+
+// Snapshot returns a copy of the trace so far.
+func (tr *Tracer) Snapshot() Trace {
+	if tr == nil {
+		return Trace{}
+	}
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	t := tr.t
+	t.Events = append([]TraceEvent(nil), tr.t.Events...)
+	return t
+}
+
+// String renders the trace as plain text, one step per line, with times
+// relative to the start: what /why and the CLI's why print.
+func (t Trace) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n", t.Title)
+	if t.URL != "" {
+		fmt.Fprintf(&b, "%s\n", t.URL)
+	}
+	fmt.Fprintf(&b, "source %s · started %s · %s", orDash(t.Source), t.Started.Format("15:04:05"), t.Outcome)
+	if !t.Ended.IsZero() {
+		fmt.Fprintf(&b, " after %s", t.Ended.Sub(t.Started).Round(10*time.Millisecond))
+	}
+	b.WriteString("\n")
+	if t.Err != "" {
+		fmt.Fprintf(&b, "error: %s\n", t.Err)
+	}
+	for i, ev := range t.Events {
+		if i == traceHead && t.Dropped > 0 {
+			fmt.Fprintf(&b, "… %d steps not recorded\n", t.Dropped)
+		}
+		fmt.Fprintf(&b, "%8s  %-18s %-20s", fmtOffset(ev.At.Sub(t.Started)), ev.Kind, ev.Parser)
+		if ev.Pos > 0 {
+			fmt.Fprintf(&b, " at %s", (time.Duration(ev.Pos * float64(time.Second))).Round(time.Second))
+		}
+		if ev.Took > 0 {
+			fmt.Fprintf(&b, " took %s", ev.Took.Round(10*time.Millisecond))
+		}
+		if ev.Against != "" {
+			fmt.Fprintf(&b, " against %s", ev.Against)
+		}
+		if ev.Err != "" {
+			fmt.Fprintf(&b, ": %s", ev.Err)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func fmtOffset(d time.Duration) string {
+	return fmt.Sprintf("+%.2fs", d.Seconds())
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,142 @@
+package stream
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/opus"
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/sources"
+)
+
+// The trace names every parser tried, in order, with what became of it: one
+// that would not open, one that opened and died on its first read, and the
+// one that played.
+func TestRecoveryStream_TraceRecordsEachParser(t *testing.T) {
+	orig := SetRegistry(map[string]parsers.Streamer{
+		"tr-refused": fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+			return nil, nil, errors.New("HTTP 403")
+		}},
+		"tr-silent": fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+			return errFirst{}, func() {}, nil
+		}},
+		"tr-good": fakeStreamer{open: func(*parsers.Track, float64) (opus.Reader, func(), error) {
+			return &pktReader{pkts: [][]byte{{0xAA}}}, func() {}, nil
+		}},
+	})
+	defer func() { SetRegistry(orig) }()
+
+	track := &parsers.Track{Title: "Song", SourceInfo: sources.TrackInfo{
+		SourceName:       "trace-test",
+		AvailableParsers: []string{"tr-refused", "tr-silent", "tr-good"},
+	}}
+	rs := NewRecoveryStream(track)
+	defer rs.Close()
+	if err := rs.Open(0); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if _, err := rs.ReadPacket(); err != nil {
+		t.Fatalf("ReadPacket: %v", err)
+	}
+
+	type step struct {
+		kind   TraceEventKind
+		parser string
+	}
+	want := []step{
+		{TraceOpenFailed, "tr-refused"},
+		{TraceOpened, "tr-silent"},
+		{TraceFirstReadFailed, "tr-silent"},
+		{TraceOpened, "tr-good"},
+		{TracePlaying, "tr-good"},
+	}
+	tr := rs.Trace().Snapshot()
+	var got []step
+	for _, ev := range tr.Events {
+		got = append(got, step{ev.Kind, ev.Parser})
+	}
+	if len(got) != len(want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("events = %v, want %v", got, want)
+		}
+	}
+	if tr.Events[0].Err != "HTTP 403" {
+		t.Errorf("open_failed Err = %q, want the parser's error", tr.Events[0].Err)
+	}
+	if tr.Outcome != TraceInProgress {
+		t.Errorf("Outcome = %q before Finish", tr.Outcome)
+	}
+	if s := tr.String(); !strings.Contains(s, "HTTP 403") || !strings.Contains(s, "tr-good") {
+		t.Errorf("String() is missing steps:\n%s", s)
+	}
+}
+
+// A hedge is not a failure, so it must not read as one in /why.
+func TestTraceString_HedgedIsNotAnError(t *testing.T) {
+	tr := Trace{Title: "Song", Started: time.Now(), Events: []TraceEvent{
+		{Kind: TraceHedged, Parser: "ytdlp-link", Against: "ytnative-link"},
+	}}
+	s := tr.String()
+	if !strings.Contains(s, "ytdlp-link") || !strings.Contains(s, "against ytnative-link") {
+		t.Errorf("hedge step missing:\n%s", s)
+	}
+	if strings.Contains(s, ": ") {
+		t.Errorf("hedge step rendered with an error:\n%s", s)
+	}
+}
+
+func TestTracer_FirstFinishWinsAndEventsAreCapped(t *testing.T) {
+	tr := NewTracer(&parsers.Track{Title: "Station"})
+	for range maxTraceEvents + 5 {
+		tr.Add(TraceEvent{Kind: TraceRecovering, Parser: "p"})
+	}
+	tr.Finish(TraceFailed, errors.New("gone"))
+	tr.Finish(TraceStopped, nil)
+
+	got := tr.Snapshot()
+	if got.Outcome != TraceFailed || got.Err != "gone" {
+		t.Errorf("Outcome, Err = %q, %q; want the failure kept", got.Outcome, got.Err)
+	}
+	if len(got.Events) != maxTraceEvents || got.Dropped != 5 {
+		t.Errorf("kept %d events, dropped %d; want %d and 5", len(got.Events), got.Dropped, maxTraceEvents)
+	}
+
+	var nilTracer *Tracer
+	nilTracer.Add(TraceEvent{Kind: TraceOpened})
+	nilTracer.Finish(TraceFinished, nil)
+}
+
+// Past the cap the trace keeps how the playback started and how it is ending;
+// what it drops comes from between the two.
+func TestTracer_KeepsHeadAndLatestEvents(t *testing.T) {
+	tr := NewTracer(&parsers.Track{Title: "Station"})
+	const total = maxTraceEvents + 40
+	for i := range total {
+		tr.Add(TraceEvent{Kind: TraceRecovering, Parser: fmt.Sprintf("p%d", i)})
+	}
+
+	got := tr.Snapshot()
+	if len(got.Events) != maxTraceEvents || got.Dropped != 40 {
+		t.Fatalf("kept %d events, dropped %d; want %d and 40", len(got.Events), got.Dropped, maxTraceEvents)
+	}
+	if first := got.Events[0].Parser; first != "p0" {
+		t.Errorf("first event is %s, want p0", first)
+	}
+	if last := got.Events[maxTraceEvents-1].Parser; last != fmt.Sprintf("p%d", total-1) {
+		t.Errorf("last event is %s, want the latest, p%d", last, total-1)
+	}
+	if ev := got.Events[traceHead].Parser; ev != fmt.Sprintf("p%d", traceHead+40) {
+		t.Errorf("event after the head is %s, want p%d", ev, traceHead+40)
+	}
+	s := got.String()
+	gap := strings.Index(s, "… 40 steps not recorded")
+	if gap < 0 || gap < strings.Index(s, fmt.Sprintf(" p%d ", traceHead-1)) || gap > strings.Index(s, fmt.Sprintf(" p%d ", traceHead+40)) {
+		t.Errorf("String() should mark the gap between the head and the tail:\n%s", s)
+	}
+}
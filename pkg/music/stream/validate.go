@@ -0,0 +1,68 @@
+package stream
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/parsers/ffmpeg"
+	"github.com/keshon/melodix/pkg/music/parsers/ytdlp"
+	"github.com/keshon/melodix/pkg/music/sources"
+)
+
+// ValidateParser reports why parser cannot play track, or nil if it might.
+// Checked are the binaries the parser runs, and whether it plays live streams
+// when the track is one (radio) or only those when it is not. It is for a
+// parser picked by hand (/play parser:), which sources.PreferParser only moves
+// to the front of the track's list: the source's other parsers stay behind it
+// as fallbacks, so a pick that fails here is worth telling the listener about
+// but does not by itself stop the track (see ValidateTrack).
+func ValidateParser(parser string, track sources.TrackInfo) error {
+	streamer, ok := Registry()[parser]
+	if !ok {
+		if err := errDisabled(parser); err != nil {
+			return err
+		}
+		return fmt.Errorf("unknown parser %q", parser)
+	}
+	caps, ok := parsers.CapabilitiesOf(streamer)
+	if !ok {
+		return nil
+	}
+	if caps.NeedsYtdlp {
+		if _, err := exec.LookPath(ytdlp.YtdlpPath); err != nil {
+			return fmt.Errorf("parser %s needs yt-dlp, which is not installed: %w", parser, err)
+		}
+	}
+	if caps.NeedsFFmpeg {
+		if _, err := exec.LookPath(ffmpeg.FFmpegPath); err != nil {
+			return fmt.Errorf("parser %s needs ffmpeg, which is not installed: %w", parser, err)
+		}
+	}
+	live := track.SourceName == sources.Radio
+	switch {
+	case live && !caps.Live:
+		return fmt.Errorf("parser %s cannot play live radio", parser)
+	case !live && caps.Live && !caps.Seekable:
+		return fmt.Errorf("parser %s plays live streams only, and %s tracks are not live", parser, track.SourceName)
+	}
+	return nil
+}
+
+// ValidateTrack reports why none of track's parsers can play it, or nil if
+// one of them might. The error is the first parser's, which after a /play
+// pick is the one the listener asked for. A track listing no parsers (a lazy
+// playlist entry) is not judged.
+func ValidateTrack(track sources.TrackInfo) error {
+	var first error
+	for _, parser := range track.AvailableParsers {
+		err := ValidateParser(parser, track)
+		if err == nil {
+			return nil
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	return first
+}